@@ -0,0 +1,50 @@
+package controller
+
+import (
+	"backend-ecommerce/internal/application/dto"
+	"backend-ecommerce/internal/application/service"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// BundleController handles bundle and kit HTTP requests
+type BundleController struct {
+}
+
+// UpdateBundle handles PUT /api/admin/products/:id/bundle
+// @Summary Configure a bundle
+// @Description Turns a product into a bundle of other products or variants and sets how it is priced
+// @Tags Bundle
+// @Security ApiKeyAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "Product ID"
+// @Param request body dto.BundleUpdateRequest true "Bundle composition"
+// @Success 200 {object} dto.ResponseDto "Bundle updated successfully"
+// @Failure 400 {object} dto.ResponseDto "Invalid request data"
+// @Router /api/admin/products/{id}/bundle [put]
+func (bc *BundleController) UpdateBundle(c *gin.Context) {
+	var req dto.BundleUpdateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.Fail("Invalid request data"))
+		return
+	}
+
+	response := service.IBundleService.UpdateBundle(c.Param("id"), req, c.GetString("user_id"))
+	c.JSON(http.StatusOK, response)
+}
+
+// RemoveBundle handles DELETE /api/admin/products/:id/bundle
+// @Summary Remove a bundle
+// @Description Removes every component of a bundle and turns it back into a simple product
+// @Tags Bundle
+// @Security ApiKeyAuth
+// @Produce json
+// @Param id path string true "Product ID"
+// @Success 200 {object} dto.ResponseDto "Bundle removed successfully"
+// @Router /api/admin/products/{id}/bundle [delete]
+func (bc *BundleController) RemoveBundle(c *gin.Context) {
+	response := service.IBundleService.RemoveBundle(c.Param("id"), c.GetString("user_id"))
+	c.JSON(http.StatusOK, response)
+}
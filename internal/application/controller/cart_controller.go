@@ -13,57 +13,66 @@ type CartController struct {
 }
 
 // GetOrCreateCart handles GET /api/carts
-// @Summary Get or create user's shopping cart
-// @Description Retrieves the user's shopping cart or creates a new one if it doesn't exist
+// @Summary Get the shopping cart
+// @Description Retrieves the cart of the signed-in user or guest, creating an empty one if it doesn't exist, with a full price breakdown. Shipping and tax are estimated unless a shipping address is given.
 // @Tags Cart
 // @Security ApiKeyAuth
 // @Produce json
+// @Param X-Guest-Token header string false "Guest token, for shoppers who are not signed in"
+// @Param shipping_address_id query string false "Address of the signed-in user to quote shipping and tax for"
 // @Success 200 {object} dto.ResponseDto "Successfully retrieved or created cart"
-// @Failure 401 {object} dto.ResponseDto "Unauthorized"
 // @Failure 500 {object} dto.ResponseDto "Internal server error"
 // @Router /api/carts [get]
 func (cc *CartController) GetOrCreateCart(c *gin.Context) {
-	userID := c.GetString("user_id")
-	if userID == "" {
-		c.JSON(http.StatusUnauthorized, dto.Fail("User not authenticated"))
-		return
-	}
-
-	response := service.ICartService.GetOrCreateCart(&userID, "")
+	response := service.ICartService.GetCart(c.GetString("user_id"), c.GetString("guest_token"), c.Query("shipping_address_id"))
 	c.JSON(http.StatusOK, response)
 }
 
 // AddCartItem handles POST /api/carts/items
 // @Summary Add item to cart
-// @Description Adds a product to the user's shopping cart
+// @Description Adds a product, or one of its variants, to the shopping cart. The product must be on sale and in stock.
 // @Tags Cart
 // @Security ApiKeyAuth
 // @Accept json
 // @Produce json
-// @Param request body dto.AddCartItemRequest true "Add item request"
+// @Param X-Guest-Token header string false "Guest token, for shoppers who are not signed in"
+// @Param request body dto.CartItemAddRequest true "Add item request"
 // @Success 200 {object} dto.ResponseDto "Item added successfully"
 // @Failure 400 {object} dto.ResponseDto "Invalid request data"
-// @Failure 401 {object} dto.ResponseDto "Unauthorized"
 // @Router /api/carts/items [post]
 func (cc *CartController) AddCartItem(c *gin.Context) {
+	var req dto.CartItemAddRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.Fail("Invalid request data"))
+		return
+	}
 
+	response := service.ICartService.AddCartItem(c.GetString("user_id"), c.GetString("guest_token"), req)
+	c.JSON(http.StatusOK, response)
 }
 
 // UpdateCartItem handles PUT /api/carts/items/:item_id
 // @Summary Update cart item quantity
-// @Description Updates the quantity of an item in the cart
+// @Description Updates the quantity of an item in the cart; a quantity of 0 removes it
 // @Tags Cart
 // @Security ApiKeyAuth
 // @Accept json
 // @Produce json
+// @Param X-Guest-Token header string false "Guest token, for shoppers who are not signed in"
 // @Param item_id path string true "Cart Item ID"
-// @Param request body dto.UpdateCartItemRequest true "Update item request"
+// @Param request body dto.CartItemUpdateRequest true "Update item request"
 // @Success 200 {object} dto.ResponseDto "Item updated successfully"
 // @Failure 400 {object} dto.ResponseDto "Invalid request data"
-// @Failure 401 {object} dto.ResponseDto "Unauthorized"
 // @Router /api/carts/items/{item_id} [put]
 func (cc *CartController) UpdateCartItem(c *gin.Context) {
+	var req dto.CartItemUpdateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.Fail("Invalid request data"))
+		return
+	}
 
+	response := service.ICartService.UpdateCartItem(c.GetString("user_id"), c.GetString("guest_token"), c.Param("item_id"), req)
+	c.JSON(http.StatusOK, response)
 }
 
 // RemoveCartItem handles DELETE /api/carts/items/:item_id
@@ -71,13 +80,59 @@ func (cc *CartController) UpdateCartItem(c *gin.Context) {
 // @Description Removes an item from the shopping cart
 // @Tags Cart
 // @Security ApiKeyAuth
+// @Produce json
+// @Param X-Guest-Token header string false "Guest token, for shoppers who are not signed in"
 // @Param item_id path string true "Cart Item ID"
 // @Success 200 {object} dto.ResponseDto "Item removed successfully"
-// @Failure 400 {object} dto.ResponseDto "Invalid item ID"
-// @Failure 401 {object} dto.ResponseDto "Unauthorized"
 // @Router /api/carts/items/{item_id} [delete]
 func (cc *CartController) RemoveCartItem(c *gin.Context) {
+	response := service.ICartService.RemoveCartItem(c.GetString("user_id"), c.GetString("guest_token"), c.Param("item_id"))
+	c.JSON(http.StatusOK, response)
+}
+
+// SaveForLater handles POST /api/carts/items/:item_id/save-for-later
+// @Summary Save cart item for later
+// @Description Moves an item from the cart to the cart's saved for later list, adding to the saved line for the same item if there is one. Saved items are not priced or bought with the cart.
+// @Tags Cart
+// @Security ApiKeyAuth
+// @Produce json
+// @Param X-Guest-Token header string false "Guest token, for shoppers who are not signed in"
+// @Param item_id path string true "Cart Item ID"
+// @Success 200 {object} dto.ResponseDto "Item saved for later successfully"
+// @Router /api/carts/items/{item_id}/save-for-later [post]
+func (cc *CartController) SaveForLater(c *gin.Context) {
+	response := service.ICartService.SaveForLater(c.GetString("user_id"), c.GetString("guest_token"), c.Param("item_id"))
+	c.JSON(http.StatusOK, response)
+}
+
+// MoveSavedToCart handles POST /api/carts/saved-items/:saved_id/move-to-cart
+// @Summary Move saved item to cart
+// @Description Moves an item saved for later back into the cart. The product must be on sale and in stock for the resulting quantity.
+// @Tags Cart
+// @Security ApiKeyAuth
+// @Produce json
+// @Param X-Guest-Token header string false "Guest token, for shoppers who are not signed in"
+// @Param saved_id path string true "Saved Item ID"
+// @Success 200 {object} dto.ResponseDto "Item moved to cart successfully"
+// @Router /api/carts/saved-items/{saved_id}/move-to-cart [post]
+func (cc *CartController) MoveSavedToCart(c *gin.Context) {
+	response := service.ICartService.MoveSavedToCart(c.GetString("user_id"), c.GetString("guest_token"), c.Param("saved_id"))
+	c.JSON(http.StatusOK, response)
+}
 
+// RemoveSavedItem handles DELETE /api/carts/saved-items/:saved_id
+// @Summary Remove saved item
+// @Description Removes an item from the cart's saved for later list
+// @Tags Cart
+// @Security ApiKeyAuth
+// @Produce json
+// @Param X-Guest-Token header string false "Guest token, for shoppers who are not signed in"
+// @Param saved_id path string true "Saved Item ID"
+// @Success 200 {object} dto.ResponseDto "Saved item removed successfully"
+// @Router /api/carts/saved-items/{saved_id} [delete]
+func (cc *CartController) RemoveSavedItem(c *gin.Context) {
+	response := service.ICartService.RemoveSavedItem(c.GetString("user_id"), c.GetString("guest_token"), c.Param("saved_id"))
+	c.JSON(http.StatusOK, response)
 }
 
 // ClearCart handles DELETE /api/carts
@@ -85,9 +140,39 @@ func (cc *CartController) RemoveCartItem(c *gin.Context) {
 // @Description Removes all items from the shopping cart
 // @Tags Cart
 // @Security ApiKeyAuth
+// @Produce json
+// @Param X-Guest-Token header string false "Guest token, for shoppers who are not signed in"
 // @Success 200 {object} dto.ResponseDto "Cart cleared successfully"
-// @Failure 401 {object} dto.ResponseDto "Unauthorized"
 // @Router /api/carts [delete]
 func (cc *CartController) ClearCart(c *gin.Context) {
+	response := service.ICartService.ClearCart(c.GetString("user_id"), c.GetString("guest_token"))
+	c.JSON(http.StatusOK, response)
+}
 
+// AcknowledgeChanges handles POST /api/carts/acknowledge
+// @Summary Accept cart changes
+// @Description Accepts the changes reported in the cart warnings: items take their current price, quantities are reduced to the stock available and items that can no longer be bought are removed. Required before checking out a cart with warnings.
+// @Tags Cart
+// @Security ApiKeyAuth
+// @Produce json
+// @Param X-Guest-Token header string false "Guest token, for shoppers who are not signed in"
+// @Success 200 {object} dto.ResponseDto "Cart updated"
+// @Router /api/carts/acknowledge [post]
+func (cc *CartController) AcknowledgeChanges(c *gin.Context) {
+	response := service.ICartService.AcknowledgeChanges(c.GetString("user_id"), c.GetString("guest_token"))
+	c.JSON(http.StatusOK, response)
+}
+
+// GetRecommendations handles GET /api/carts/recommendations
+// @Summary Get cart recommendations
+// @Description Suggests cross-sells, accessories and products frequently bought with the items in the cart
+// @Tags Cart
+// @Security ApiKeyAuth
+// @Produce json
+// @Param X-Guest-Token header string false "Guest token, for shoppers who are not signed in"
+// @Success 200 {object} dto.ResponseDto "Recommendations retrieved successfully"
+// @Router /api/carts/recommendations [get]
+func (cc *CartController) GetRecommendations(c *gin.Context) {
+	response := service.ICartService.GetCartRecommendations(c.GetString("user_id"), c.GetString("guest_token"))
+	c.JSON(http.StatusOK, response)
 }
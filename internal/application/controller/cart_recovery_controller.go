@@ -0,0 +1,51 @@
+package controller
+
+import (
+	"backend-ecommerce/internal/application/dto"
+	"backend-ecommerce/internal/application/service"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// CartRecoveryController handles abandoned cart recovery HTTP requests
+type CartRecoveryController struct {
+}
+
+// RestoreCart handles POST /api/carts/restore
+// @Summary Restore an abandoned cart
+// @Description Copies the items of the cart in an abandoned cart reminder's link into the shopper's cart, on any device and whether or not the shopper is signed in. Items that can no longer be bought are left out.
+// @Tags Cart
+// @Security ApiKeyAuth
+// @Accept json
+// @Produce json
+// @Param X-Guest-Token header string false "Guest token, for shoppers who are not signed in"
+// @Param request body dto.CartRestoreRequest true "Cart and token from the restore link"
+// @Success 200 {object} dto.ResponseDto "Cart restored successfully"
+// @Failure 400 {object} dto.ResponseDto "Invalid request data"
+// @Router /api/carts/restore [post]
+func (cc *CartRecoveryController) RestoreCart(c *gin.Context) {
+	var req dto.CartRestoreRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.Fail("Invalid request data"))
+		return
+	}
+
+	response := service.ICartRecoveryService.RestoreCart(c.GetString("user_id"), c.GetString("guest_token"), req)
+	c.JSON(http.StatusOK, response)
+}
+
+// GetRecoveryReport handles GET /api/admin/carts/recovery-report
+// @Summary Abandoned cart recovery report
+// @Description Reports the carts sent abandoned cart reminders in a period, how many were restored and recovered by an order, the recovery rate and the recovered revenue, overall and per reminder
+// @Tags Cart
+// @Security ApiKeyAuth
+// @Produce json
+// @Param from query string false "First day (YYYY-MM-DD), defaults to 30 days before to"
+// @Param to query string false "Last day (YYYY-MM-DD), defaults to today"
+// @Success 200 {object} dto.ResponseDto "Recovery report retrieved successfully"
+// @Router /api/admin/carts/recovery-report [get]
+func (cc *CartRecoveryController) GetRecoveryReport(c *gin.Context) {
+	response := service.ICartRecoveryService.GetRecoveryReport(c.Query("from"), c.Query("to"))
+	c.JSON(http.StatusOK, response)
+}
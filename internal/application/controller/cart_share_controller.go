@@ -0,0 +1,65 @@
+package controller
+
+import (
+	"backend-ecommerce/internal/application/dto"
+	"backend-ecommerce/internal/application/service"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// CartShareController handles shared cart HTTP requests
+type CartShareController struct {
+}
+
+// ShareCart handles POST /api/carts/share
+// @Summary Share cart
+// @Description Takes a snapshot of the shopper's cart and returns a signed link to it that expires after a configured time. Whoever opens the link can copy the items into their own cart; later changes to the cart are not shared.
+// @Tags Cart
+// @Security ApiKeyAuth
+// @Produce json
+// @Param X-Guest-Token header string false "Guest token, for shoppers who are not signed in"
+// @Success 200 {object} dto.ResponseDto "Cart shared successfully"
+// @Router /api/carts/share [post]
+func (cc *CartShareController) ShareCart(c *gin.Context) {
+	response := service.ICartShareService.ShareCart(c.GetString("user_id"), c.GetString("guest_token"))
+	c.JSON(http.StatusOK, response)
+}
+
+// GetSharedCart handles GET /api/shared-carts/:id
+// @Summary Get shared cart
+// @Description Returns the items of a shared cart at current prices, for the recipient to review before copying them
+// @Tags Cart
+// @Produce json
+// @Param id path string true "Shared cart ID"
+// @Param token query string true "Token from the share link"
+// @Success 200 {object} dto.ResponseDto "Shared cart retrieved successfully"
+// @Router /api/shared-carts/{id} [get]
+func (cc *CartShareController) GetSharedCart(c *gin.Context) {
+	response := service.ICartShareService.GetSharedCart(c.Param("id"), c.Query("token"))
+	c.JSON(http.StatusOK, response)
+}
+
+// CopySharedCart handles POST /api/shared-carts/:id/copy
+// @Summary Copy shared cart
+// @Description Copies the items of a shared cart into the shopper's own cart at current prices. Lines already in the cart keep the larger quantity; items that can no longer be bought are left out.
+// @Tags Cart
+// @Security ApiKeyAuth
+// @Accept json
+// @Produce json
+// @Param X-Guest-Token header string false "Guest token, for shoppers who are not signed in"
+// @Param id path string true "Shared cart ID"
+// @Param request body dto.CartSharedCopyRequest true "Token from the share link"
+// @Success 200 {object} dto.ResponseDto "Shared cart copied successfully"
+// @Failure 400 {object} dto.ResponseDto "Invalid request data"
+// @Router /api/shared-carts/{id}/copy [post]
+func (cc *CartShareController) CopySharedCart(c *gin.Context) {
+	var req dto.CartSharedCopyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.Fail("Invalid request data"))
+		return
+	}
+
+	response := service.ICartShareService.CopySharedCart(c.GetString("user_id"), c.GetString("guest_token"), c.Param("id"), req)
+	c.JSON(http.StatusOK, response)
+}
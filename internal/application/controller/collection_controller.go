@@ -0,0 +1,158 @@
+package controller
+
+import (
+	"backend-ecommerce/internal/application/dto"
+	"backend-ecommerce/internal/application/service"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// CollectionController handles product collection HTTP requests
+type CollectionController struct {
+}
+
+// GetCollections handles GET /api/collections
+// @Summary List collections
+// @Description Returns the active collections with the number of products in each
+// @Tags Collections
+// @Produce json
+// @Success 200 {object} dto.ResponseDto "Collections retrieved successfully"
+// @Router /api/collections [get]
+func (cc *CollectionController) GetCollections(c *gin.Context) {
+	response := service.ICollectionService.GetStorefrontCollections()
+	c.JSON(http.StatusOK, response)
+}
+
+// GetCollection handles GET /api/collections/:slug
+// @Summary Get a collection
+// @Description Returns an active collection with a page of its published products
+// @Tags Collections
+// @Produce json
+// @Param slug path string true "Collection slug"
+// @Param page query int false "Page number" default(1)
+// @Param page_size query int false "Page size" default(20)
+// @Success 200 {object} dto.ResponseDto "Collection retrieved successfully"
+// @Router /api/collections/{slug} [get]
+func (cc *CollectionController) GetCollection(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	if page < 1 {
+		page = 1
+	}
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "20"))
+	if pageSize < 1 || pageSize > 100 {
+		pageSize = 20
+	}
+
+	response := service.ICollectionService.GetCollectionBySlug(c.Param("slug"), page, pageSize)
+	c.JSON(http.StatusOK, response)
+}
+
+// GetAdminCollections handles GET /api/admin/collections
+// @Summary List all collections
+// @Description Returns every collection, including inactive ones
+// @Tags Collections
+// @Security ApiKeyAuth
+// @Produce json
+// @Success 200 {object} dto.ResponseDto "Collections retrieved successfully"
+// @Router /api/admin/collections [get]
+func (cc *CollectionController) GetAdminCollections(c *gin.Context) {
+	response := service.ICollectionService.GetCollections()
+	c.JSON(http.StatusOK, response)
+}
+
+// CreateCollection handles POST /api/admin/collections
+// @Summary Create a collection
+// @Description Creates a manual collection or an automatic one filled from its rules
+// @Tags Collections
+// @Security ApiKeyAuth
+// @Accept json
+// @Produce json
+// @Param request body dto.CollectionCreateRequest true "Collection"
+// @Success 200 {object} dto.ResponseDto "Collection created successfully"
+// @Failure 400 {object} dto.ResponseDto "Invalid request data"
+// @Router /api/admin/collections [post]
+func (cc *CollectionController) CreateCollection(c *gin.Context) {
+	var req dto.CollectionCreateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.Fail("Invalid request data"))
+		return
+	}
+
+	response := service.ICollectionService.CreateCollection(req)
+	c.JSON(http.StatusOK, response)
+}
+
+// UpdateCollection handles PUT /api/admin/collections/:id
+// @Summary Update a collection
+// @Description Updates a collection. Changing the rules re-evaluates its products.
+// @Tags Collections
+// @Security ApiKeyAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "Collection ID"
+// @Param request body dto.CollectionUpdateRequest true "Fields to update"
+// @Success 200 {object} dto.ResponseDto "Collection updated successfully"
+// @Failure 400 {object} dto.ResponseDto "Invalid request data"
+// @Router /api/admin/collections/{id} [put]
+func (cc *CollectionController) UpdateCollection(c *gin.Context) {
+	var req dto.CollectionUpdateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.Fail("Invalid request data"))
+		return
+	}
+
+	response := service.ICollectionService.UpdateCollection(c.Param("id"), req)
+	c.JSON(http.StatusOK, response)
+}
+
+// DeleteCollection handles DELETE /api/admin/collections/:id
+// @Summary Delete a collection
+// @Tags Collections
+// @Security ApiKeyAuth
+// @Produce json
+// @Param id path string true "Collection ID"
+// @Success 200 {object} dto.ResponseDto "Collection deleted successfully"
+// @Router /api/admin/collections/{id} [delete]
+func (cc *CollectionController) DeleteCollection(c *gin.Context) {
+	response := service.ICollectionService.DeleteCollection(c.Param("id"))
+	c.JSON(http.StatusOK, response)
+}
+
+// SetCollectionProducts handles PUT /api/admin/collections/:id/products
+// @Summary Set the products of a manual collection
+// @Description Replaces the products of a manual collection; their order is the display order
+// @Tags Collections
+// @Security ApiKeyAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "Collection ID"
+// @Param request body dto.CollectionProductsRequest true "Product IDs in display order"
+// @Success 200 {object} dto.ResponseDto "Collection products updated successfully"
+// @Failure 400 {object} dto.ResponseDto "Invalid request data"
+// @Router /api/admin/collections/{id}/products [put]
+func (cc *CollectionController) SetCollectionProducts(c *gin.Context) {
+	var req dto.CollectionProductsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.Fail("Invalid request data"))
+		return
+	}
+
+	response := service.ICollectionService.SetCollectionProducts(c.Param("id"), req.ProductIDs)
+	c.JSON(http.StatusOK, response)
+}
+
+// RefreshCollection handles POST /api/admin/collections/:id/refresh
+// @Summary Refresh an automatic collection
+// @Description Re-evaluates the rules of an automatic collection against every product
+// @Tags Collections
+// @Security ApiKeyAuth
+// @Produce json
+// @Param id path string true "Collection ID"
+// @Success 200 {object} dto.ResponseDto "Collection refreshed successfully"
+// @Router /api/admin/collections/{id}/refresh [post]
+func (cc *CollectionController) RefreshCollection(c *gin.Context) {
+	response := service.ICollectionService.RefreshCollection(c.Param("id"))
+	c.JSON(http.StatusOK, response)
+}
@@ -3,16 +3,45 @@ package controller
 // Controllers holds all the controller instances
 var (
 	// User related
-	UserCtrl  = &UserController{}
+	UserCtrl = &UserController{}
 
 	// Product related
-	ProductCtrl  = &ProductController{}
-	CategoryCtrl = &CategoryController{}
+	ProductCtrl           = &ProductController{}
+	CategoryCtrl          = &CategoryController{}
+	ProductImageCtrl      = &ProductImageController{}
+	PriceScheduleCtrl     = &PriceScheduleController{}
+	BundleCtrl            = &BundleController{}
+	DigitalProductCtrl    = &DigitalProductController{}
+	ProductRevisionCtrl   = &ProductRevisionController{}
+	ProductPublishingCtrl = &ProductPublishingController{}
+	CollectionCtrl        = &CollectionController{}
+	ProductRelationCtrl   = &ProductRelationController{}
+
+	// Inventory related
+	StockLocationCtrl = &StockLocationController{}
+	InventoryCtrl     = &InventoryController{}
+	SupplierCtrl      = &SupplierController{}
+	PurchaseOrderCtrl = &PurchaseOrderController{}
+
+	// Shopper related
+	RecentlyViewedCtrl = &RecentlyViewedController{}
+	WishlistCtrl       = &WishlistController{}
+	ProductAlertCtrl   = &ProductAlertController{}
 
 	// Order related
 	OrderCtrl   = &OrderController{}
 	PaymentCtrl = &PaymentController{}
 
 	// Cart related
-	CartCtrl = &CartController{}
+	CartCtrl         = &CartController{}
+	CartRecoveryCtrl = &CartRecoveryController{}
+	CartShareCtrl    = &CartShareController{}
+
+	// Promotion related
+	CouponCtrl    = &CouponController{}
+	PromotionCtrl = &PromotionController{}
+	GiftCardCtrl  = &GiftCardController{}
+
+	// File related
+	FileCtrl = &FileController{}
 )
@@ -0,0 +1,185 @@
+package controller
+
+import (
+	"backend-ecommerce/internal/application/dto"
+	"backend-ecommerce/internal/application/service"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// CouponController handles coupon and discount code HTTP requests
+type CouponController struct {
+}
+
+// ApplyCoupon handles POST /api/carts/coupons
+// @Summary Apply a discount code
+// @Description Applies a discount code to the shopper's cart. The code must be active, within its validity window and usage limits, combinable with the codes already applied and apply to the cart as it is. It is redeemed when an order is placed.
+// @Tags Cart
+// @Security ApiKeyAuth
+// @Accept json
+// @Produce json
+// @Param X-Guest-Token header string false "Guest token, for shoppers who are not signed in"
+// @Param request body dto.CouponApplyRequest true "Discount code"
+// @Success 200 {object} dto.ResponseDto "Discount code applied successfully"
+// @Failure 400 {object} dto.ResponseDto "Invalid request data"
+// @Router /api/carts/coupons [post]
+func (cc *CouponController) ApplyCoupon(c *gin.Context) {
+	var req dto.CouponApplyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.Fail("Invalid request data"))
+		return
+	}
+
+	response := service.ICouponService.ApplyCoupon(c.GetString("user_id"), c.GetString("guest_token"), req)
+	c.JSON(http.StatusOK, response)
+}
+
+// RemoveCoupon handles DELETE /api/carts/coupons/:code
+// @Summary Remove a discount code
+// @Description Takes a discount code off the shopper's cart
+// @Tags Cart
+// @Security ApiKeyAuth
+// @Produce json
+// @Param X-Guest-Token header string false "Guest token, for shoppers who are not signed in"
+// @Param code path string true "Discount code"
+// @Success 200 {object} dto.ResponseDto "Discount code removed successfully"
+// @Router /api/carts/coupons/{code} [delete]
+func (cc *CouponController) RemoveCoupon(c *gin.Context) {
+	response := service.ICouponService.RemoveCoupon(c.GetString("user_id"), c.GetString("guest_token"), c.Param("code"))
+	c.JSON(http.StatusOK, response)
+}
+
+// GetCoupons handles GET /api/admin/coupons
+// @Summary List coupons
+// @Description Returns every coupon, newest first, with the number of its codes
+// @Tags Promotions
+// @Security ApiKeyAuth
+// @Produce json
+// @Success 200 {object} dto.ResponseDto "Coupons retrieved successfully"
+// @Router /api/admin/coupons [get]
+func (cc *CouponController) GetCoupons(c *gin.Context) {
+	response := service.ICouponService.GetCoupons()
+	c.JSON(http.StatusOK, response)
+}
+
+// GetCoupon handles GET /api/admin/coupons/:id
+// @Summary Get a coupon
+// @Tags Promotions
+// @Security ApiKeyAuth
+// @Produce json
+// @Param id path string true "Coupon ID"
+// @Success 200 {object} dto.ResponseDto "Coupon retrieved successfully"
+// @Router /api/admin/coupons/{id} [get]
+func (cc *CouponController) GetCoupon(c *gin.Context) {
+	response := service.ICouponService.GetCoupon(c.Param("id"))
+	c.JSON(http.StatusOK, response)
+}
+
+// CreateCoupon handles POST /api/admin/coupons
+// @Summary Create a coupon
+// @Description Creates a percentage, fixed amount or free shipping discount with its rules: minimum subtotal, product and category inclusions and exclusions, usage limits, validity window and whether it combines with other codes. A code given here can be used by any number of customers within the limits.
+// @Tags Promotions
+// @Security ApiKeyAuth
+// @Accept json
+// @Produce json
+// @Param request body dto.CouponCreateRequest true "Coupon"
+// @Success 200 {object} dto.ResponseDto "Coupon created successfully"
+// @Failure 400 {object} dto.ResponseDto "Invalid request data"
+// @Router /api/admin/coupons [post]
+func (cc *CouponController) CreateCoupon(c *gin.Context) {
+	var req dto.CouponCreateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.Fail("Invalid request data"))
+		return
+	}
+
+	response := service.ICouponService.CreateCoupon(req)
+	c.JSON(http.StatusOK, response)
+}
+
+// UpdateCoupon handles PUT /api/admin/coupons/:id
+// @Summary Update a coupon
+// @Description Updates the rules of a coupon or deactivates it. Changes apply to all its codes, including codes already applied to carts.
+// @Tags Promotions
+// @Security ApiKeyAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "Coupon ID"
+// @Param request body dto.CouponUpdateRequest true "Fields to update"
+// @Success 200 {object} dto.ResponseDto "Coupon updated successfully"
+// @Failure 400 {object} dto.ResponseDto "Invalid request data"
+// @Router /api/admin/coupons/{id} [put]
+func (cc *CouponController) UpdateCoupon(c *gin.Context) {
+	var req dto.CouponUpdateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.Fail("Invalid request data"))
+		return
+	}
+
+	response := service.ICouponService.UpdateCoupon(c.Param("id"), req)
+	c.JSON(http.StatusOK, response)
+}
+
+// DeleteCoupon handles DELETE /api/admin/coupons/:id
+// @Summary Delete a coupon
+// @Description Deletes a coupon that was never redeemed, with its codes; redeemed coupons can be deactivated instead
+// @Tags Promotions
+// @Security ApiKeyAuth
+// @Produce json
+// @Param id path string true "Coupon ID"
+// @Success 200 {object} dto.ResponseDto "Coupon deleted successfully"
+// @Router /api/admin/coupons/{id} [delete]
+func (cc *CouponController) DeleteCoupon(c *gin.Context) {
+	response := service.ICouponService.DeleteCoupon(c.Param("id"))
+	c.JSON(http.StatusOK, response)
+}
+
+// GetCouponCodes handles GET /api/admin/coupons/:id/codes
+// @Summary List coupon codes
+// @Description Returns the codes of a coupon with how often each was used
+// @Tags Promotions
+// @Security ApiKeyAuth
+// @Produce json
+// @Param id path string true "Coupon ID"
+// @Param page query int false "Page number" default(1)
+// @Param page_size query int false "Page size" default(100)
+// @Success 200 {object} dto.ResponseDto "Coupon codes retrieved successfully"
+// @Router /api/admin/coupons/{id}/codes [get]
+func (cc *CouponController) GetCouponCodes(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	if page < 1 {
+		page = 1
+	}
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "100"))
+	if pageSize < 1 || pageSize > 1000 {
+		pageSize = 100
+	}
+
+	response := service.ICouponService.GetCouponCodes(c.Param("id"), page, pageSize)
+	c.JSON(http.StatusOK, response)
+}
+
+// GenerateCodes handles POST /api/admin/coupons/:id/codes
+// @Summary Generate single-use codes
+// @Description Generates unique single-use codes for a coupon, up to 10000 at a time, and returns them
+// @Tags Promotions
+// @Security ApiKeyAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "Coupon ID"
+// @Param request body dto.CouponCodeGenerateRequest true "Number and format of the codes"
+// @Success 200 {object} dto.ResponseDto "Codes generated successfully"
+// @Failure 400 {object} dto.ResponseDto "Invalid request data"
+// @Router /api/admin/coupons/{id}/codes [post]
+func (cc *CouponController) GenerateCodes(c *gin.Context) {
+	var req dto.CouponCodeGenerateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.Fail("Invalid request data"))
+		return
+	}
+
+	response := service.ICouponService.GenerateCodes(c.Param("id"), req)
+	c.JSON(http.StatusOK, response)
+}
@@ -0,0 +1,174 @@
+package controller
+
+import (
+	"backend-ecommerce/internal/application/dto"
+	"backend-ecommerce/internal/application/service"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// DigitalProductController handles digital asset, licence key and download HTTP requests
+type DigitalProductController struct {
+}
+
+// GetDigitalAssets handles GET /api/admin/products/:id/digital-assets
+// @Summary List digital assets
+// @Description Returns the downloadable files attached to a digital product
+// @Tags DigitalProduct
+// @Security ApiKeyAuth
+// @Produce json
+// @Param id path string true "Product ID"
+// @Success 200 {object} dto.ResponseDto "Successfully retrieved digital assets"
+// @Router /api/admin/products/{id}/digital-assets [get]
+func (dpc *DigitalProductController) GetDigitalAssets(c *gin.Context) {
+	response := service.IDigitalProductService.GetDigitalAssets(c.Param("id"))
+	c.JSON(http.StatusOK, response)
+}
+
+// UploadDigitalAsset handles POST /api/admin/products/:id/digital-assets
+// @Summary Upload a digital asset
+// @Description Uploads a file to private storage and attaches it to a digital product
+// @Tags DigitalProduct
+// @Security ApiKeyAuth
+// @Accept multipart/form-data
+// @Produce json
+// @Param id path string true "Product ID"
+// @Param file formData file true "File delivered to buyers"
+// @Param download_limit formData int false "Downloads allowed per purchased unit, 0 for unlimited"
+// @Param access_days formData int false "Days the download stays available after purchase, 0 for no expiry"
+// @Success 200 {object} dto.ResponseDto "Digital asset uploaded successfully"
+// @Failure 400 {object} dto.ResponseDto "Invalid request data"
+// @Router /api/admin/products/{id}/digital-assets [post]
+func (dpc *DigitalProductController) UploadDigitalAsset(c *gin.Context) {
+	var req dto.DigitalAssetUploadRequest
+	if err := c.ShouldBind(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.Fail("Invalid request data"))
+		return
+	}
+
+	file, header, err := c.Request.FormFile("file")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.Fail("File is required"))
+		return
+	}
+	defer file.Close()
+
+	response := service.IDigitalProductService.UploadDigitalAsset(c.Request.Context(), c.Param("id"), file, header, req)
+	c.JSON(http.StatusOK, response)
+}
+
+// UpdateDigitalAsset handles PUT /api/admin/digital-assets/:id
+// @Summary Update digital asset limits
+// @Description Changes the download limit and access period granted to future buyers
+// @Tags DigitalProduct
+// @Security ApiKeyAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "Digital asset ID"
+// @Param request body dto.DigitalAssetUpdateRequest true "Download limits"
+// @Success 200 {object} dto.ResponseDto "Digital asset updated successfully"
+// @Failure 400 {object} dto.ResponseDto "Invalid request data"
+// @Router /api/admin/digital-assets/{id} [put]
+func (dpc *DigitalProductController) UpdateDigitalAsset(c *gin.Context) {
+	var req dto.DigitalAssetUpdateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.Fail("Invalid request data"))
+		return
+	}
+
+	response := service.IDigitalProductService.UpdateDigitalAsset(c.Param("id"), req)
+	c.JSON(http.StatusOK, response)
+}
+
+// DeleteDigitalAsset handles DELETE /api/admin/digital-assets/:id
+// @Summary Delete a digital asset
+// @Description Deletes a file that has not been sold yet
+// @Tags DigitalProduct
+// @Security ApiKeyAuth
+// @Produce json
+// @Param id path string true "Digital asset ID"
+// @Success 200 {object} dto.ResponseDto "Digital asset deleted successfully"
+// @Router /api/admin/digital-assets/{id} [delete]
+func (dpc *DigitalProductController) DeleteDigitalAsset(c *gin.Context) {
+	response := service.IDigitalProductService.DeleteDigitalAsset(c.Request.Context(), c.Param("id"))
+	c.JSON(http.StatusOK, response)
+}
+
+// GetLicenseKeyStock handles GET /api/admin/products/:id/license-keys
+// @Summary Licence key stock
+// @Description Returns how many licence keys of a product are assigned and still available
+// @Tags DigitalProduct
+// @Security ApiKeyAuth
+// @Produce json
+// @Param id path string true "Product ID"
+// @Success 200 {object} dto.ResponseDto "Successfully retrieved licence key stock"
+// @Router /api/admin/products/{id}/license-keys [get]
+func (dpc *DigitalProductController) GetLicenseKeyStock(c *gin.Context) {
+	response := service.IDigitalProductService.GetLicenseKeyStock(c.Param("id"))
+	c.JSON(http.StatusOK, response)
+}
+
+// AddLicenseKeys handles POST /api/admin/products/:id/license-keys
+// @Summary Add licence keys
+// @Description Adds keys to a product's licence key pool; one key is handed out per unit sold
+// @Tags DigitalProduct
+// @Security ApiKeyAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "Product ID"
+// @Param request body dto.LicenseKeyAddRequest true "Licence keys"
+// @Success 200 {object} dto.ResponseDto "Licence keys added successfully"
+// @Failure 400 {object} dto.ResponseDto "Invalid request data"
+// @Router /api/admin/products/{id}/license-keys [post]
+func (dpc *DigitalProductController) AddLicenseKeys(c *gin.Context) {
+	var req dto.LicenseKeyAddRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.Fail("Invalid request data"))
+		return
+	}
+
+	response := service.IDigitalProductService.AddLicenseKeys(c.Param("id"), req.Keys)
+	c.JSON(http.StatusOK, response)
+}
+
+// GetDownloads handles GET /api/downloads
+// @Summary List my downloads
+// @Description Returns the files and licence keys the user has bought
+// @Tags DigitalProduct
+// @Security ApiKeyAuth
+// @Produce json
+// @Success 200 {object} dto.ResponseDto "Successfully retrieved downloads"
+// @Failure 401 {object} dto.ResponseDto "Unauthorized"
+// @Router /api/downloads [get]
+func (dpc *DigitalProductController) GetDownloads(c *gin.Context) {
+	userID := c.GetString("user_id")
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, dto.Fail("User not authenticated"))
+		return
+	}
+
+	response := service.IDigitalProductService.GetUserDownloads(userID)
+	c.JSON(http.StatusOK, response)
+}
+
+// CreateDownloadLink handles POST /api/downloads/:id/link
+// @Summary Create a download link
+// @Description Uses up one download and returns a short-lived signed URL to the file
+// @Tags DigitalProduct
+// @Security ApiKeyAuth
+// @Produce json
+// @Param id path string true "Download ID"
+// @Success 200 {object} dto.ResponseDto "Download link created successfully"
+// @Failure 401 {object} dto.ResponseDto "Unauthorized"
+// @Router /api/downloads/{id}/link [post]
+func (dpc *DigitalProductController) CreateDownloadLink(c *gin.Context) {
+	userID := c.GetString("user_id")
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, dto.Fail("User not authenticated"))
+		return
+	}
+
+	response := service.IDigitalProductService.CreateDownloadLink(c.Request.Context(), userID, c.Param("id"))
+	c.JSON(http.StatusOK, response)
+}
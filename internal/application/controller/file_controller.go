@@ -0,0 +1,80 @@
+package controller
+
+import (
+	"backend-ecommerce/internal/application/dto"
+	"backend-ecommerce/internal/infrastructure/logger"
+	"backend-ecommerce/internal/infrastructure/storage"
+	"errors"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// FileController serves files from the configured storage backend
+type FileController struct {
+}
+
+// ServeFile handles GET /api/files/*key
+// @Summary Download a public file
+// @Description Streams a public file from storage. Private files require a signed URL.
+// @Tags File
+// @Param key path string true "Storage key"
+// @Success 200 {file} file "File content"
+// @Failure 404 {object} dto.ResponseDto "File not found"
+// @Router /api/files/{key} [get]
+func (fc *FileController) ServeFile(c *gin.Context) {
+	// Check the key as the backend will read it, so "//private/..." or
+	// "public/../private/..." cannot reach a private file
+	key := storage.CleanKey(c.Param("key"))
+	if slices.Contains(strings.Split(key, "/"), "..") || strings.HasPrefix(key+"/", storage.PrivatePrefix) {
+		c.JSON(http.StatusNotFound, dto.Fail("File not found"))
+		return
+	}
+
+	c.Header("Cache-Control", "public, max-age=86400")
+	fc.serve(c, key)
+}
+
+// ServeSignedFile handles GET /api/signed-files/*key
+// @Summary Download a file through a signed URL
+// @Description Verifies the HMAC signature and expiry of a URL issued by the local or memory storage backend and streams the file
+// @Tags File
+// @Param key path string true "Storage key"
+// @Param expires query int true "Expiry as a Unix timestamp"
+// @Param signature query string true "HMAC signature"
+// @Success 200 {file} file "File content"
+// @Failure 403 {object} dto.ResponseDto "Invalid or expired signature"
+// @Failure 404 {object} dto.ResponseDto "File not found"
+// @Router /api/signed-files/{key} [get]
+func (fc *FileController) ServeSignedFile(c *gin.Context) {
+	key := storage.CleanKey(c.Param("key"))
+	if err := storage.VerifySignedURL(key, c.Query("expires"), c.Query("signature")); err != nil {
+		if errors.Is(err, storage.ErrSignatureExpired) {
+			c.JSON(http.StatusForbidden, dto.Fail("Link has expired"))
+			return
+		}
+		c.JSON(http.StatusForbidden, dto.Fail("Invalid signature"))
+		return
+	}
+
+	c.Header("Cache-Control", "private, no-store")
+	fc.serve(c, key)
+}
+
+func (fc *FileController) serve(c *gin.Context, key string) {
+	reader, object, err := storage.GetStorage().Get(c.Request.Context(), key)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			c.JSON(http.StatusNotFound, dto.Fail("File not found"))
+			return
+		}
+		logger.Error("Error reading file %s: %v", key, err)
+		c.JSON(http.StatusInternalServerError, dto.Fail("Error reading file"))
+		return
+	}
+	defer reader.Close()
+
+	c.DataFromReader(http.StatusOK, object.Size, object.ContentType, reader, nil)
+}
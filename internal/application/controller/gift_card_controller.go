@@ -0,0 +1,156 @@
+package controller
+
+import (
+	"backend-ecommerce/internal/application/dto"
+	"backend-ecommerce/internal/application/service"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GiftCardController handles gift card HTTP requests
+type GiftCardController struct {
+}
+
+// CheckBalance handles POST /api/gift-cards/balance
+// @Summary Check a gift card balance
+// @Description Returns the balance, currency and expiry of a gift card and whether it can be used at checkout. Open to signed-in users and guests; checks are limited per client IP, and an IP that tries too many unknown codes is blocked for a while.
+// @Tags Gift Cards
+// @Security ApiKeyAuth
+// @Accept json
+// @Produce json
+// @Param request body dto.GiftCardBalanceRequest true "Gift card code"
+// @Success 200 {object} dto.ResponseDto "Gift card balance retrieved successfully"
+// @Failure 400 {object} dto.ResponseDto "Invalid request data"
+// @Router /api/gift-cards/balance [post]
+func (gc *GiftCardController) CheckBalance(c *gin.Context) {
+	var req dto.GiftCardBalanceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.Fail("Invalid request data"))
+		return
+	}
+
+	response := service.IGiftCardService.CheckBalance(c.ClientIP(), req)
+	c.JSON(http.StatusOK, response)
+}
+
+// GetGiftCards handles GET /api/admin/gift-cards
+// @Summary List gift cards
+// @Description Returns the gift cards, newest first. Codes are never shown; cards are told apart by the last characters of their code.
+// @Tags Gift Cards
+// @Security ApiKeyAuth
+// @Produce json
+// @Param page query int false "Page number" default(1)
+// @Param page_size query int false "Page size" default(20)
+// @Success 200 {object} dto.ResponseDto "Gift cards retrieved successfully"
+// @Router /api/admin/gift-cards [get]
+func (gc *GiftCardController) GetGiftCards(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	if page < 1 {
+		page = 1
+	}
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "20"))
+	if pageSize < 1 || pageSize > 100 {
+		pageSize = 20
+	}
+
+	response := service.IGiftCardService.GetGiftCards(page, pageSize)
+	c.JSON(http.StatusOK, response)
+}
+
+// GetGiftCard handles GET /api/admin/gift-cards/:id
+// @Summary Get a gift card
+// @Description Returns a gift card with its ledger of balance changes
+// @Tags Gift Cards
+// @Security ApiKeyAuth
+// @Produce json
+// @Param id path string true "Gift card ID"
+// @Success 200 {object} dto.ResponseDto "Gift card retrieved successfully"
+// @Router /api/admin/gift-cards/{id} [get]
+func (gc *GiftCardController) GetGiftCard(c *gin.Context) {
+	response := service.IGiftCardService.GetGiftCard(c.Param("id"))
+	c.JSON(http.StatusOK, response)
+}
+
+// IssueGiftCard handles POST /api/admin/gift-cards
+// @Summary Issue a gift card
+// @Description Issues a gift card with a balance, currency and optional expiry. The code is returned only in this response; when a customer is given it is also emailed to them.
+// @Tags Gift Cards
+// @Security ApiKeyAuth
+// @Accept json
+// @Produce json
+// @Param request body dto.GiftCardIssueRequest true "Gift card"
+// @Success 200 {object} dto.ResponseDto "Gift card issued successfully"
+// @Failure 400 {object} dto.ResponseDto "Invalid request data"
+// @Router /api/admin/gift-cards [post]
+func (gc *GiftCardController) IssueGiftCard(c *gin.Context) {
+	var req dto.GiftCardIssueRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.Fail("Invalid request data"))
+		return
+	}
+
+	response := service.IGiftCardService.IssueGiftCard(req)
+	c.JSON(http.StatusOK, response)
+}
+
+// UpdateGiftCard handles PUT /api/admin/gift-cards/:id
+// @Summary Update a gift card
+// @Description Disables or re-enables a gift card, or changes its expiry or note. The balance only changes through the ledger.
+// @Tags Gift Cards
+// @Security ApiKeyAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "Gift card ID"
+// @Param request body dto.GiftCardUpdateRequest true "Fields to update"
+// @Success 200 {object} dto.ResponseDto "Gift card updated successfully"
+// @Failure 400 {object} dto.ResponseDto "Invalid request data"
+// @Router /api/admin/gift-cards/{id} [put]
+func (gc *GiftCardController) UpdateGiftCard(c *gin.Context) {
+	var req dto.GiftCardUpdateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.Fail("Invalid request data"))
+		return
+	}
+
+	response := service.IGiftCardService.UpdateGiftCard(c.Param("id"), req)
+	c.JSON(http.StatusOK, response)
+}
+
+// SetGiftCardProduct handles PUT /api/admin/products/:id/gift-card
+// @Summary Sell a product as a gift card
+// @Description Makes a digital product a gift card: each unit bought issues a card worth the price paid, emailed to the buyer once the order is paid
+// @Tags Gift Cards
+// @Security ApiKeyAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "Product ID"
+// @Param request body dto.GiftCardProductRequest true "Gift card settings"
+// @Success 200 {object} dto.ResponseDto "Gift card product updated successfully"
+// @Failure 400 {object} dto.ResponseDto "Invalid request data"
+// @Router /api/admin/products/{id}/gift-card [put]
+func (gc *GiftCardController) SetGiftCardProduct(c *gin.Context) {
+	var req dto.GiftCardProductRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.Fail("Invalid request data"))
+		return
+	}
+
+	response := service.IGiftCardService.SetGiftCardProduct(c.Param("id"), req)
+	c.JSON(http.StatusOK, response)
+}
+
+// RemoveGiftCardProduct handles DELETE /api/admin/products/:id/gift-card
+// @Summary Stop selling a product as a gift card
+// @Description Stops issuing gift cards for the product; cards already issued are kept
+// @Tags Gift Cards
+// @Security ApiKeyAuth
+// @Produce json
+// @Param id path string true "Product ID"
+// @Success 200 {object} dto.ResponseDto "Gift card product removed successfully"
+// @Router /api/admin/products/{id}/gift-card [delete]
+func (gc *GiftCardController) RemoveGiftCardProduct(c *gin.Context) {
+	response := service.IGiftCardService.RemoveGiftCardProduct(c.Param("id"))
+	c.JSON(http.StatusOK, response)
+}
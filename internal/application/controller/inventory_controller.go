@@ -0,0 +1,169 @@
+package controller
+
+import (
+	"backend-ecommerce/internal/application/dto"
+	"backend-ecommerce/internal/application/service"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// InventoryController handles stock level HTTP requests
+type InventoryController struct {
+}
+
+// GetProductStock handles GET /api/admin/products/:id/stock
+// @Summary Get product stock
+// @Description Returns the stock of a product and its variants at every location
+// @Tags Inventory
+// @Security ApiKeyAuth
+// @Produce json
+// @Param id path string true "Product ID"
+// @Success 200 {object} dto.ResponseDto "Stock retrieved successfully"
+// @Router /api/admin/products/{id}/stock [get]
+func (ic *InventoryController) GetProductStock(c *gin.Context) {
+	response := service.IInventoryService.GetProductStock(c.Param("id"))
+	c.JSON(http.StatusOK, response)
+}
+
+// SetStockLevel handles PUT /api/admin/inventory
+// @Summary Set a stock level
+// @Description Sets the quantity on hand of a product or variant at a location, e.g. after a stock count; the difference is recorded in the stock ledger
+// @Tags Inventory
+// @Security ApiKeyAuth
+// @Accept json
+// @Produce json
+// @Param request body dto.StockLevelUpdateRequest true "Stock level"
+// @Success 200 {object} dto.ResponseDto "Stock updated successfully"
+// @Failure 400 {object} dto.ResponseDto "Invalid request data"
+// @Router /api/admin/inventory [put]
+func (ic *InventoryController) SetStockLevel(c *gin.Context) {
+	var req dto.StockLevelUpdateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.Fail("Invalid request data"))
+		return
+	}
+
+	response := service.IInventoryService.SetStockLevel(c.GetString("user_id"), req)
+	c.JSON(http.StatusOK, response)
+}
+
+// AdjustStock handles POST /api/admin/inventory/adjustments
+// @Summary Adjust stock
+// @Description Changes the quantity on hand by a signed amount and records the reason in the stock ledger
+// @Tags Inventory
+// @Security ApiKeyAuth
+// @Accept json
+// @Produce json
+// @Param request body dto.StockAdjustmentRequest true "Adjustment"
+// @Success 200 {object} dto.ResponseDto "Stock adjusted successfully"
+// @Failure 400 {object} dto.ResponseDto "Invalid request data"
+// @Router /api/admin/inventory/adjustments [post]
+func (ic *InventoryController) AdjustStock(c *gin.Context) {
+	var req dto.StockAdjustmentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.Fail("Invalid request data"))
+		return
+	}
+
+	response := service.IStockMovementService.AdjustStock(c.GetString("user_id"), req)
+	c.JSON(http.StatusOK, response)
+}
+
+// TransferStock handles POST /api/admin/inventory/transfers
+// @Summary Transfer stock
+// @Description Moves stock from one location to another
+// @Tags Inventory
+// @Security ApiKeyAuth
+// @Accept json
+// @Produce json
+// @Param request body dto.StockTransferRequest true "Transfer"
+// @Success 200 {object} dto.ResponseDto "Stock transferred successfully"
+// @Failure 400 {object} dto.ResponseDto "Invalid request data"
+// @Router /api/admin/inventory/transfers [post]
+func (ic *InventoryController) TransferStock(c *gin.Context) {
+	var req dto.StockTransferRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.Fail("Invalid request data"))
+		return
+	}
+
+	response := service.IStockMovementService.TransferStock(c.GetString("user_id"), req)
+	c.JSON(http.StatusOK, response)
+}
+
+// GetMovements handles GET /api/admin/inventory/movements
+// @Summary Stock movement report
+// @Description Lists stock ledger entries, newest first, with totals per reason
+// @Tags Inventory
+// @Security ApiKeyAuth
+// @Produce json
+// @Param sku query string false "SKU"
+// @Param product_id query string false "Product ID"
+// @Param location_id query string false "Stock location ID"
+// @Param reason query string false "Movement reason"
+// @Param from query string false "First day (YYYY-MM-DD)"
+// @Param to query string false "Last day (YYYY-MM-DD)"
+// @Param page query int false "Page number" default(1)
+// @Param page_size query int false "Page size" default(50)
+// @Success 200 {object} dto.ResponseDto "Stock movements retrieved successfully"
+// @Failure 400 {object} dto.ResponseDto "Invalid request data"
+// @Router /api/admin/inventory/movements [get]
+func (ic *InventoryController) GetMovements(c *gin.Context) {
+	var query dto.StockMovementQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.JSON(http.StatusBadRequest, dto.Fail("Invalid request data"))
+		return
+	}
+
+	response := service.IStockMovementService.GetMovements(query)
+	c.JSON(http.StatusOK, response)
+}
+
+// ReconcileStock handles GET /api/admin/inventory/reconciliation
+// @Summary Reconcile stock with the ledger
+// @Description Lists stock levels whose quantity on hand differs from the sum of their ledger entries
+// @Tags Inventory
+// @Security ApiKeyAuth
+// @Produce json
+// @Success 200 {object} dto.ResponseDto "Discrepancies retrieved successfully"
+// @Router /api/admin/inventory/reconciliation [get]
+func (ic *InventoryController) ReconcileStock(c *gin.Context) {
+	response := service.IStockMovementService.ReconcileStock()
+	c.JSON(http.StatusOK, response)
+}
+
+// SetReorderSettings handles PUT /api/admin/inventory/reorder-settings
+// @Summary Set reorder settings
+// @Description Sets the reorder point and quantity of a product or variant at a location; a reorder point of 0 turns low-stock reporting off
+// @Tags Inventory
+// @Security ApiKeyAuth
+// @Accept json
+// @Produce json
+// @Param request body dto.ReorderSettingsRequest true "Reorder settings"
+// @Success 200 {object} dto.ResponseDto "Reorder settings updated successfully"
+// @Failure 400 {object} dto.ResponseDto "Invalid request data"
+// @Router /api/admin/inventory/reorder-settings [put]
+func (ic *InventoryController) SetReorderSettings(c *gin.Context) {
+	var req dto.ReorderSettingsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.Fail("Invalid request data"))
+		return
+	}
+
+	response := service.IReplenishmentService.SetReorderSettings(req)
+	c.JSON(http.StatusOK, response)
+}
+
+// GetLowStockReport handles GET /api/admin/inventory/low-stock
+// @Summary Low-stock report
+// @Description Lists stock levels at or below their reorder point with recent sales velocity and days of cover, the ones running out soonest first
+// @Tags Inventory
+// @Security ApiKeyAuth
+// @Produce json
+// @Success 200 {object} dto.ResponseDto "Low-stock report retrieved successfully"
+// @Router /api/admin/inventory/low-stock [get]
+func (ic *InventoryController) GetLowStockReport(c *gin.Context) {
+	response := service.IReplenishmentService.GetLowStockReport()
+	c.JSON(http.StatusOK, response)
+}
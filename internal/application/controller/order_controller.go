@@ -1,6 +1,11 @@
 package controller
 
 import (
+	"backend-ecommerce/internal/application/dto"
+	"backend-ecommerce/internal/application/service"
+	"net/http"
+	"strconv"
+
 	"github.com/gin-gonic/gin"
 )
 
@@ -9,21 +14,87 @@ type OrderController struct {
 }
 
 // CreateOrder handles POST /api/orders
+// @Summary Create an order from the cart
+// @Description Prices the signed-in user's cart exactly as the cart view does, reserves the stock until payment, redeems the discount codes that applied and empties the cart. If prices or stock changed since the cart was last acknowledged, the order is refused with the cart warnings as data.
+// @Tags Orders
+// @Security ApiKeyAuth
+// @Accept json
+// @Produce json
+// @Param request body dto.OrderCreateRequest true "Addresses"
+// @Success 200 {object} dto.ResponseDto "Order created successfully"
+// @Failure 400 {object} dto.ResponseDto "Invalid request data"
+// @Failure 401 {object} dto.ResponseDto "Unauthorized"
+// @Router /api/orders [post]
 func (oc *OrderController) CreateOrder(c *gin.Context) {
-	
+	userID := c.GetString("user_id")
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, dto.Fail("User not authenticated"))
+		return
+	}
+
+	var req dto.OrderCreateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.Fail("Invalid request data"))
+		return
+	}
+
+	response := service.IOrderService.CreateOrder(userID, req)
+	c.JSON(http.StatusOK, response)
 }
 
 // GetOrder handles GET /api/orders/:id
+// @Summary Get an order
+// @Description Returns one of the signed-in user's orders with its lines and price adjustments
+// @Tags Orders
+// @Security ApiKeyAuth
+// @Produce json
+// @Param id path string true "Order ID"
+// @Success 200 {object} dto.ResponseDto "Order retrieved successfully"
+// @Failure 401 {object} dto.ResponseDto "Unauthorized"
+// @Router /api/orders/{id} [get]
 func (oc *OrderController) GetOrder(c *gin.Context) {
+	userID := c.GetString("user_id")
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, dto.Fail("User not authenticated"))
+		return
+	}
 
+	response := service.IOrderService.GetOrder(userID, c.Param("id"))
+	c.JSON(http.StatusOK, response)
 }
 
 // GetUserOrders handles GET /api/orders
+// @Summary List orders
+// @Description Returns the signed-in user's orders, newest first
+// @Tags Orders
+// @Security ApiKeyAuth
+// @Produce json
+// @Param page query int false "Page number" default(1)
+// @Param page_size query int false "Page size" default(20)
+// @Success 200 {object} dto.ResponseDto "Orders retrieved successfully"
+// @Failure 401 {object} dto.ResponseDto "Unauthorized"
+// @Router /api/orders [get]
 func (oc *OrderController) GetUserOrders(c *gin.Context) {
-	
+	userID := c.GetString("user_id")
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, dto.Fail("User not authenticated"))
+		return
+	}
+
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	if page < 1 {
+		page = 1
+	}
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "20"))
+	if pageSize < 1 || pageSize > 100 {
+		pageSize = 20
+	}
+
+	response := service.IOrderService.GetUserOrders(userID, page, pageSize)
+	c.JSON(http.StatusOK, response)
 }
 
 // CancelOrder handles PUT /api/orders/:id/cancel
 func (oc *OrderController) CancelOrder(c *gin.Context) {
-	
+
 }
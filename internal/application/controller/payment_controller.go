@@ -1,6 +1,11 @@
 package controller
 
 import (
+	"backend-ecommerce/internal/application/dto"
+	"backend-ecommerce/internal/application/service"
+	"errors"
+	"net/http"
+
 	"github.com/gin-gonic/gin"
 )
 
@@ -23,7 +28,53 @@ func (pc *PaymentController) GetPaymentsByOrder(c *gin.Context) {
 
 }
 
-// RefundPayment handles POST /api/payments/:id/refund
+// RefundPayment handles POST /api/admin/payments/:id/refund
+// @Summary Refund a payment
+// @Description Refunds all or part of a payment. Payments made with a gift card are refunded to the card.
+// @Tags Payments
+// @Security ApiKeyAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "Payment ID"
+// @Param request body dto.PaymentRefundRequest true "Amount to refund, 0 for the rest of the payment"
+// @Success 200 {object} dto.ResponseDto "Payment refunded successfully"
+// @Failure 400 {object} dto.ResponseDto "Invalid request data"
+// @Router /api/admin/payments/{id}/refund [post]
 func (pc *PaymentController) RefundPayment(c *gin.Context) {
+	var req dto.PaymentRefundRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.Fail("Invalid request data"))
+		return
+	}
+
+	response := service.IPaymentService.RefundPayment(c.Param("id"), req)
+	c.JSON(http.StatusOK, response)
+}
+
+// HandleWebhook handles POST /api/payments/webhook
+// @Summary Receive Stripe events
+// @Description Settles card payments from Stripe's signed payment intent events: a succeeded intent marks the order paid, commits its stock and delivers its digital goods; a failed or cancelled one gives back the stock, discount codes and gift card balances. A server error asks Stripe to send the event again.
+// @Tags Payments
+// @Accept json
+// @Produce json
+// @Param Stripe-Signature header string true "Stripe webhook signature"
+// @Success 200 {object} dto.ResponseDto "Event received"
+// @Failure 400 {object} dto.ResponseDto "Invalid webhook"
+// @Router /api/payments/webhook [post]
+func (pc *PaymentController) HandleWebhook(c *gin.Context) {
+	payload, err := c.GetRawData()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.Fail("Invalid webhook"))
+		return
+	}
 
+	if err := service.IPaymentService.HandleWebhook(payload, c.GetHeader("Stripe-Signature")); err != nil {
+		if errors.Is(err, service.ErrInvalidWebhook) {
+			c.JSON(http.StatusBadRequest, dto.Fail("Invalid webhook"))
+			return
+		}
+		c.JSON(http.StatusInternalServerError, dto.Fail("Error handling webhook"))
+		return
+	}
+	c.JSON(http.StatusOK, dto.Success(nil))
 }
@@ -0,0 +1,64 @@
+package controller
+
+import (
+	"backend-ecommerce/internal/application/dto"
+	"backend-ecommerce/internal/application/service"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// PriceScheduleController handles scheduled price HTTP requests
+type PriceScheduleController struct {
+}
+
+// GetPriceSchedules handles GET /api/admin/products/:id/price-schedules
+// @Summary List price schedules
+// @Description Returns every sale window and scheduled price change of a product
+// @Tags PriceSchedule
+// @Security ApiKeyAuth
+// @Produce json
+// @Param id path string true "Product ID"
+// @Success 200 {object} dto.ResponseDto "Successfully retrieved price schedules"
+// @Router /api/admin/products/{id}/price-schedules [get]
+func (psc *PriceScheduleController) GetPriceSchedules(c *gin.Context) {
+	response := service.IPriceScheduleService.GetProductPriceSchedules(c.Param("id"))
+	c.JSON(http.StatusOK, response)
+}
+
+// CreatePriceSchedule handles POST /api/admin/products/:id/price-schedules
+// @Summary Schedule a price
+// @Description Schedules a sale window or a future permanent price change for a product or one of its variants
+// @Tags PriceSchedule
+// @Security ApiKeyAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "Product ID"
+// @Param request body dto.PriceScheduleCreateRequest true "Price schedule"
+// @Success 200 {object} dto.ResponseDto "Price scheduled successfully"
+// @Failure 400 {object} dto.ResponseDto "Invalid request data"
+// @Router /api/admin/products/{id}/price-schedules [post]
+func (psc *PriceScheduleController) CreatePriceSchedule(c *gin.Context) {
+	var req dto.PriceScheduleCreateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.Fail("Invalid request data"))
+		return
+	}
+
+	response := service.IPriceScheduleService.CreatePriceSchedule(c.Param("id"), req, c.GetString("user_id"))
+	c.JSON(http.StatusOK, response)
+}
+
+// DeletePriceSchedule handles DELETE /api/admin/price-schedules/:id
+// @Summary Delete a price schedule
+// @Description Deletes a sale window or a permanent price change that has not been applied yet
+// @Tags PriceSchedule
+// @Security ApiKeyAuth
+// @Produce json
+// @Param id path string true "Price schedule ID"
+// @Success 200 {object} dto.ResponseDto "Price schedule deleted successfully"
+// @Router /api/admin/price-schedules/{id} [delete]
+func (psc *PriceScheduleController) DeletePriceSchedule(c *gin.Context) {
+	response := service.IPriceScheduleService.DeletePriceSchedule(c.Param("id"), c.GetString("user_id"))
+	c.JSON(http.StatusOK, response)
+}
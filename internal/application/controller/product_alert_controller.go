@@ -0,0 +1,77 @@
+package controller
+
+import (
+	"backend-ecommerce/internal/application/dto"
+	"backend-ecommerce/internal/application/service"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ProductAlertController handles back-in-stock and price-drop alert HTTP requests
+type ProductAlertController struct {
+}
+
+// GetAlerts handles GET /api/alerts
+// @Summary List my alerts
+// @Description Returns the customer's active and triggered stock and price alerts
+// @Tags ProductAlerts
+// @Security ApiKeyAuth
+// @Produce json
+// @Success 200 {object} dto.ResponseDto "Alerts retrieved successfully"
+// @Failure 401 {object} dto.ResponseDto "Unauthorized"
+// @Router /api/alerts [get]
+func (pac *ProductAlertController) GetAlerts(c *gin.Context) {
+	response := service.IProductAlertService.GetUserAlerts(c.GetString("user_id"))
+	c.JSON(http.StatusOK, response)
+}
+
+// CreateAlert handles POST /api/alerts
+// @Summary Subscribe to a product alert
+// @Description Notifies the customer when an out-of-stock product, variant or wishlist item is available again, or when its price drops below a target
+// @Tags ProductAlerts
+// @Security ApiKeyAuth
+// @Accept json
+// @Produce json
+// @Param request body dto.ProductAlertCreateRequest true "Alert"
+// @Success 200 {object} dto.ResponseDto "Alert created successfully"
+// @Failure 400 {object} dto.ResponseDto "Invalid request data"
+// @Failure 401 {object} dto.ResponseDto "Unauthorized"
+// @Router /api/alerts [post]
+func (pac *ProductAlertController) CreateAlert(c *gin.Context) {
+	var req dto.ProductAlertCreateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.Fail("Invalid request data"))
+		return
+	}
+
+	response := service.IProductAlertService.CreateAlert(c.GetString("user_id"), req)
+	c.JSON(http.StatusOK, response)
+}
+
+// CancelAlert handles DELETE /api/alerts/:id
+// @Summary Cancel an alert
+// @Tags ProductAlerts
+// @Security ApiKeyAuth
+// @Produce json
+// @Param id path string true "Alert ID"
+// @Success 200 {object} dto.ResponseDto "Alert cancelled successfully"
+// @Failure 401 {object} dto.ResponseDto "Unauthorized"
+// @Router /api/alerts/{id} [delete]
+func (pac *ProductAlertController) CancelAlert(c *gin.Context) {
+	response := service.IProductAlertService.CancelAlert(c.GetString("user_id"), c.Param("id"))
+	c.JSON(http.StatusOK, response)
+}
+
+// GetDemandReport handles GET /api/admin/alerts/demand
+// @Summary Alert demand report
+// @Description Lists active subscriber counts per SKU, most wanted first, to prioritise restocking
+// @Tags ProductAlerts
+// @Security ApiKeyAuth
+// @Produce json
+// @Success 200 {object} dto.ResponseDto "Demand report retrieved successfully"
+// @Router /api/admin/alerts/demand [get]
+func (pac *ProductAlertController) GetDemandReport(c *gin.Context) {
+	response := service.IProductAlertService.GetDemandReport()
+	c.JSON(http.StatusOK, response)
+}
@@ -1,6 +1,11 @@
 package controller
 
 import (
+	"backend-ecommerce/internal/application/dto"
+	"backend-ecommerce/internal/application/service"
+	"net/http"
+	"strconv"
+
 	"github.com/gin-gonic/gin"
 )
 
@@ -9,21 +14,72 @@ type ProductController struct {
 }
 
 // GetProducts handles GET /api/products
+// @Summary List products
+// @Description Returns active products with prices resolved at the current time
+// @Tags Product
+// @Produce json
+// @Param page query int false "Page number" default(1)
+// @Param page_size query int false "Page size" default(20)
+// @Param category_id query string false "Filter by category"
+// @Success 200 {object} dto.ResponseDto "Successfully retrieved products"
+// @Router /api/products [get]
 func (pc *ProductController) GetProducts(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	if page < 1 {
+		page = 1
+	}
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "20"))
+	if pageSize < 1 || pageSize > 100 {
+		pageSize = 20
+	}
+
+	var categoryID *string
+	if category := c.Query("category_id"); category != "" {
+		categoryID = &category
+	}
+
+	response := service.IProductService.GetAllProducts(page, pageSize, categoryID)
+	c.JSON(http.StatusOK, response)
 }
 
 // GetProduct handles GET /api/products/:id
+// @Summary Get a product
+// @Description Returns an active product with prices resolved at the current time
+// @Tags Product
+// @Produce json
+// @Param id path string true "Product ID"
+// @Success 200 {object} dto.ResponseDto "Successfully retrieved product"
+// @Router /api/products/{id} [get]
 func (pc *ProductController) GetProduct(c *gin.Context) {
-
+	response := service.IProductService.GetProductByID(c.Param("id"))
+	c.JSON(http.StatusOK, response)
 }
 
 // CreateProduct handles POST /api/products
 func (pc *ProductController) CreateProduct(c *gin.Context) {
 }
 
-// UpdateProduct handles PUT /api/products/:id
+// UpdateProduct handles PUT /api/admin/products/:id
+// @Summary Update a product
+// @Description Updates the given fields of a product and records the change in its revision history
+// @Tags Product
+// @Security ApiKeyAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "Product ID"
+// @Param request body dto.ProductUpdateRequest true "Fields to update"
+// @Success 200 {object} dto.ResponseDto "Product updated successfully"
+// @Failure 400 {object} dto.ResponseDto "Invalid request data"
+// @Router /api/admin/products/{id} [put]
 func (pc *ProductController) UpdateProduct(c *gin.Context) {
+	var req dto.ProductUpdateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.Fail("Invalid request data"))
+		return
+	}
 
+	response := service.IProductService.UpdateProduct(c.Param("id"), req, c.GetString("user_id"))
+	c.JSON(http.StatusOK, response)
 }
 
 // DeleteProduct handles DELETE /api/products/:id
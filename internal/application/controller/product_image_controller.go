@@ -0,0 +1,90 @@
+package controller
+
+import (
+	"backend-ecommerce/internal/application/dto"
+	"backend-ecommerce/internal/application/service"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ProductImageController handles product image HTTP requests
+type ProductImageController struct {
+}
+
+// GetProductImages handles GET /api/admin/products/:id/images
+// @Summary List product images
+// @Description Returns a product's images and their renditions in display order
+// @Tags ProductImage
+// @Security ApiKeyAuth
+// @Produce json
+// @Param id path string true "Product ID"
+// @Success 200 {object} dto.ResponseDto "Successfully retrieved images"
+// @Router /api/admin/products/{id}/images [get]
+func (pic *ProductImageController) GetProductImages(c *gin.Context) {
+	response := service.IProductImageService.GetProductImages(c.Param("id"))
+	c.JSON(http.StatusOK, response)
+}
+
+// UploadProductImage handles POST /api/admin/products/:id/images
+// @Summary Upload a product image
+// @Description Uploads an image, generates thumbnail, medium, large and WebP renditions and attaches it to the product
+// @Tags ProductImage
+// @Security ApiKeyAuth
+// @Accept multipart/form-data
+// @Produce json
+// @Param id path string true "Product ID"
+// @Param file formData file true "Image file (JPEG, PNG, GIF or WebP)"
+// @Param alt_text formData string false "Alternative text"
+// @Success 200 {object} dto.ResponseDto "Image uploaded successfully"
+// @Failure 400 {object} dto.ResponseDto "Invalid request data"
+// @Router /api/admin/products/{id}/images [post]
+func (pic *ProductImageController) UploadProductImage(c *gin.Context) {
+	file, header, err := c.Request.FormFile("file")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.Fail("Image file is required"))
+		return
+	}
+	defer file.Close()
+
+	response := service.IProductImageService.UploadProductImage(c.Request.Context(), c.Param("id"), file, header, c.PostForm("alt_text"), c.GetString("user_id"))
+	c.JSON(http.StatusOK, response)
+}
+
+// ReorderProductImages handles PUT /api/admin/products/:id/images/order
+// @Summary Reorder product images
+// @Description Sets the display order of all images of a product
+// @Tags ProductImage
+// @Security ApiKeyAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "Product ID"
+// @Param request body dto.ProductImageReorderRequest true "Image IDs in display order"
+// @Success 200 {object} dto.ResponseDto "Images reordered successfully"
+// @Failure 400 {object} dto.ResponseDto "Invalid request data"
+// @Router /api/admin/products/{id}/images/order [put]
+func (pic *ProductImageController) ReorderProductImages(c *gin.Context) {
+	var req dto.ProductImageReorderRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.Fail("Invalid request data"))
+		return
+	}
+
+	response := service.IProductImageService.ReorderProductImages(c.Param("id"), req.ImageIDs, c.GetString("user_id"))
+	c.JSON(http.StatusOK, response)
+}
+
+// DeleteProductImage handles DELETE /api/admin/products/:id/images/:image_id
+// @Summary Delete a product image
+// @Description Deletes an image, its renditions and the stored files
+// @Tags ProductImage
+// @Security ApiKeyAuth
+// @Produce json
+// @Param id path string true "Product ID"
+// @Param image_id path string true "Image ID"
+// @Success 200 {object} dto.ResponseDto "Image deleted successfully"
+// @Router /api/admin/products/{id}/images/{image_id} [delete]
+func (pic *ProductImageController) DeleteProductImage(c *gin.Context) {
+	response := service.IProductImageService.DeleteProductImage(c.Request.Context(), c.Param("id"), c.Param("image_id"), c.GetString("user_id"))
+	c.JSON(http.StatusOK, response)
+}
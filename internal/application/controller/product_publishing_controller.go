@@ -0,0 +1,94 @@
+package controller
+
+import (
+	"backend-ecommerce/internal/application/dto"
+	"backend-ecommerce/internal/application/service"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ProductPublishingController handles product publishing workflow HTTP requests
+type ProductPublishingController struct {
+}
+
+// UpdateStatus handles PUT /api/admin/products/:id/status
+// @Summary Change product status
+// @Description Moves a product between draft, in review and published
+// @Tags ProductPublishing
+// @Security ApiKeyAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "Product ID"
+// @Param request body dto.ProductStatusUpdateRequest true "New status"
+// @Success 200 {object} dto.ResponseDto "Product status updated successfully"
+// @Failure 400 {object} dto.ResponseDto "Invalid request data"
+// @Router /api/admin/products/{id}/status [put]
+func (ppc *ProductPublishingController) UpdateStatus(c *gin.Context) {
+	var req dto.ProductStatusUpdateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.Fail("Invalid request data"))
+		return
+	}
+
+	response := service.IProductPublishingService.UpdateProductStatus(c.Param("id"), req)
+	c.JSON(http.StatusOK, response)
+}
+
+// SchedulePublication handles PUT /api/admin/products/:id/publication-schedule
+// @Summary Schedule product publication
+// @Description Sets when a reviewed product is published and when it is unpublished again
+// @Tags ProductPublishing
+// @Security ApiKeyAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "Product ID"
+// @Param request body dto.ProductPublicationScheduleRequest true "Publication schedule"
+// @Success 200 {object} dto.ResponseDto "Publication scheduled successfully"
+// @Failure 400 {object} dto.ResponseDto "Invalid request data"
+// @Router /api/admin/products/{id}/publication-schedule [put]
+func (ppc *ProductPublishingController) SchedulePublication(c *gin.Context) {
+	var req dto.ProductPublicationScheduleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.Fail("Invalid request data"))
+		return
+	}
+
+	response := service.IProductPublishingService.ScheduleProductPublication(c.Param("id"), req)
+	c.JSON(http.StatusOK, response)
+}
+
+// CreatePreviewToken handles POST /api/admin/products/:id/preview-token
+// @Summary Create a preview link
+// @Description Issues a signed token for viewing the product before it is published
+// @Tags ProductPublishing
+// @Security ApiKeyAuth
+// @Produce json
+// @Param id path string true "Product ID"
+// @Success 200 {object} dto.ResponseDto "Preview token created successfully"
+// @Router /api/admin/products/{id}/preview-token [post]
+func (ppc *ProductPublishingController) CreatePreviewToken(c *gin.Context) {
+	response := service.IProductPublishingService.CreatePreviewToken(c.Param("id"))
+	c.JSON(http.StatusOK, response)
+}
+
+// GetPreview handles GET /api/products/:id/preview
+// @Summary Preview a product
+// @Description Returns a product of any status to the holder of a valid preview token
+// @Tags ProductPublishing
+// @Produce json
+// @Param id path string true "Product ID"
+// @Param token query string true "Preview token"
+// @Success 200 {object} dto.ResponseDto "Successfully retrieved product"
+// @Failure 400 {object} dto.ResponseDto "Preview token is required"
+// @Router /api/products/{id}/preview [get]
+func (ppc *ProductPublishingController) GetPreview(c *gin.Context) {
+	token := c.Query("token")
+	if token == "" {
+		c.JSON(http.StatusBadRequest, dto.Fail("Preview token is required"))
+		return
+	}
+
+	response := service.IProductPublishingService.GetProductPreview(c.Param("id"), token)
+	c.JSON(http.StatusOK, response)
+}
@@ -0,0 +1,53 @@
+package controller
+
+import (
+	"backend-ecommerce/internal/application/dto"
+	"backend-ecommerce/internal/application/entity"
+	"backend-ecommerce/internal/application/service"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ProductRelationController handles related product HTTP requests
+type ProductRelationController struct {
+}
+
+// GetRelations handles GET /api/admin/products/:id/relations
+// @Summary List product relations
+// @Description Returns the hand-curated relations of a product and the computed "frequently bought together" list
+// @Tags ProductRelations
+// @Security ApiKeyAuth
+// @Produce json
+// @Param id path string true "Product ID"
+// @Success 200 {object} dto.ResponseDto "Relations retrieved successfully"
+// @Router /api/admin/products/{id}/relations [get]
+func (prc *ProductRelationController) GetRelations(c *gin.Context) {
+	response := service.IProductRelationService.GetProductRelations(c.Param("id"))
+	c.JSON(http.StatusOK, response)
+}
+
+// SetRelations handles PUT /api/admin/products/:id/relations/:type
+// @Summary Set product relations
+// @Description Replaces the related products of one type (related, upsell, cross_sell or accessory); their order is the display order
+// @Tags ProductRelations
+// @Security ApiKeyAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "Product ID"
+// @Param type path string true "Relation type"
+// @Param request body dto.ProductRelationsUpdateRequest true "Product IDs in display order"
+// @Success 200 {object} dto.ResponseDto "Relations updated successfully"
+// @Failure 400 {object} dto.ResponseDto "Invalid request data"
+// @Router /api/admin/products/{id}/relations/{type} [put]
+func (prc *ProductRelationController) SetRelations(c *gin.Context) {
+	var req dto.ProductRelationsUpdateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.Fail("Invalid request data"))
+		return
+	}
+
+	relationType := entity.ProductRelationType(c.Param("type"))
+	response := service.IProductRelationService.SetProductRelations(c.Param("id"), relationType, req.ProductIDs)
+	c.JSON(http.StatusOK, response)
+}
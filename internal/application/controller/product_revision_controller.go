@@ -0,0 +1,100 @@
+package controller
+
+import (
+	"backend-ecommerce/internal/application/dto"
+	"backend-ecommerce/internal/application/service"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ProductRevisionController handles product revision history HTTP requests
+type ProductRevisionController struct {
+}
+
+// GetRevisions handles GET /api/admin/products/:id/revisions
+// @Summary List product revisions
+// @Description Returns the revision history of a product, newest first
+// @Tags ProductRevision
+// @Security ApiKeyAuth
+// @Produce json
+// @Param id path string true "Product ID"
+// @Success 200 {object} dto.ResponseDto "Successfully retrieved revisions"
+// @Router /api/admin/products/{id}/revisions [get]
+func (prc *ProductRevisionController) GetRevisions(c *gin.Context) {
+	response := service.IProductRevisionService.GetProductRevisions(c.Param("id"))
+	c.JSON(http.StatusOK, response)
+}
+
+// GetRevision handles GET /api/admin/products/:id/revisions/:version
+// @Summary Get a product revision
+// @Description Returns a revision including the full product snapshot
+// @Tags ProductRevision
+// @Security ApiKeyAuth
+// @Produce json
+// @Param id path string true "Product ID"
+// @Param version path int true "Revision number"
+// @Success 200 {object} dto.ResponseDto "Successfully retrieved revision"
+// @Failure 400 {object} dto.ResponseDto "Invalid revision number"
+// @Router /api/admin/products/{id}/revisions/{version} [get]
+func (prc *ProductRevisionController) GetRevision(c *gin.Context) {
+	version, err := strconv.Atoi(c.Param("version"))
+	if err != nil || version < 1 {
+		c.JSON(http.StatusBadRequest, dto.Fail("Invalid revision number"))
+		return
+	}
+
+	response := service.IProductRevisionService.GetProductRevision(c.Param("id"), version)
+	c.JSON(http.StatusOK, response)
+}
+
+// DiffRevisions handles GET /api/admin/products/:id/revisions/diff
+// @Summary Compare product revisions
+// @Description Lists the fields that changed between two revisions. Defaults to the latest revision and the one before it.
+// @Tags ProductRevision
+// @Security ApiKeyAuth
+// @Produce json
+// @Param id path string true "Product ID"
+// @Param from query int false "Older revision number"
+// @Param to query int false "Newer revision number"
+// @Success 200 {object} dto.ResponseDto "Successfully compared revisions"
+// @Failure 400 {object} dto.ResponseDto "Invalid revision number"
+// @Router /api/admin/products/{id}/revisions/diff [get]
+func (prc *ProductRevisionController) DiffRevisions(c *gin.Context) {
+	from, err := strconv.Atoi(c.DefaultQuery("from", "0"))
+	if err != nil || from < 0 {
+		c.JSON(http.StatusBadRequest, dto.Fail("Invalid revision number"))
+		return
+	}
+	to, err := strconv.Atoi(c.DefaultQuery("to", "0"))
+	if err != nil || to < 0 {
+		c.JSON(http.StatusBadRequest, dto.Fail("Invalid revision number"))
+		return
+	}
+
+	response := service.IProductRevisionService.DiffProductRevisions(c.Param("id"), from, to)
+	c.JSON(http.StatusOK, response)
+}
+
+// RestoreRevision handles POST /api/admin/products/:id/revisions/:version/restore
+// @Summary Restore a product revision
+// @Description Rolls the product, its variants, prices, images and attributes back to a revision in a single transaction
+// @Tags ProductRevision
+// @Security ApiKeyAuth
+// @Produce json
+// @Param id path string true "Product ID"
+// @Param version path int true "Revision number"
+// @Success 200 {object} dto.ResponseDto "Revision restored successfully"
+// @Failure 400 {object} dto.ResponseDto "Invalid revision number"
+// @Router /api/admin/products/{id}/revisions/{version}/restore [post]
+func (prc *ProductRevisionController) RestoreRevision(c *gin.Context) {
+	version, err := strconv.Atoi(c.Param("version"))
+	if err != nil || version < 1 {
+		c.JSON(http.StatusBadRequest, dto.Fail("Invalid revision number"))
+		return
+	}
+
+	response := service.IProductRevisionService.RestoreProductRevision(c.Param("id"), version, c.GetString("user_id"))
+	c.JSON(http.StatusOK, response)
+}
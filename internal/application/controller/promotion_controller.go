@@ -0,0 +1,119 @@
+package controller
+
+import (
+	"backend-ecommerce/internal/application/dto"
+	"backend-ecommerce/internal/application/service"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// PromotionController handles automatic promotion HTTP requests
+type PromotionController struct {
+}
+
+// GetPromotions handles GET /api/admin/promotions
+// @Summary List promotions
+// @Description Returns every automatic promotion in the order they are evaluated: highest priority first
+// @Tags Promotions
+// @Security ApiKeyAuth
+// @Produce json
+// @Success 200 {object} dto.ResponseDto "Promotions retrieved successfully"
+// @Router /api/admin/promotions [get]
+func (pc *PromotionController) GetPromotions(c *gin.Context) {
+	response := service.IPromotionService.GetPromotions()
+	c.JSON(http.StatusOK, response)
+}
+
+// GetPromotion handles GET /api/admin/promotions/:id
+// @Summary Get a promotion
+// @Tags Promotions
+// @Security ApiKeyAuth
+// @Produce json
+// @Param id path string true "Promotion ID"
+// @Success 200 {object} dto.ResponseDto "Promotion retrieved successfully"
+// @Router /api/admin/promotions/{id} [get]
+func (pc *PromotionController) GetPromotion(c *gin.Context) {
+	response := service.IPromotionService.GetPromotion(c.Param("id"))
+	c.JSON(http.StatusOK, response)
+}
+
+// CreatePromotion handles POST /api/admin/promotions
+// @Summary Create a promotion
+// @Description Creates a promotion applied without a code to carts that qualify: buy X get Y, spend thresholds with tiers, multi-buys such as 3 for 2 within a category, and bundles of products bought together. Promotions are evaluated by descending priority; an exclusive promotion is never combined with others.
+// @Tags Promotions
+// @Security ApiKeyAuth
+// @Accept json
+// @Produce json
+// @Param request body dto.PromotionCreateRequest true "Promotion"
+// @Success 200 {object} dto.ResponseDto "Promotion created successfully"
+// @Failure 400 {object} dto.ResponseDto "Invalid request data"
+// @Router /api/admin/promotions [post]
+func (pc *PromotionController) CreatePromotion(c *gin.Context) {
+	var req dto.PromotionCreateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.Fail("Invalid request data"))
+		return
+	}
+
+	response := service.IPromotionService.CreatePromotion(req)
+	c.JSON(http.StatusOK, response)
+}
+
+// UpdatePromotion handles PUT /api/admin/promotions/:id
+// @Summary Update a promotion
+// @Description Updates the rules, priority, exclusivity or validity window of a promotion, or deactivates it
+// @Tags Promotions
+// @Security ApiKeyAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "Promotion ID"
+// @Param request body dto.PromotionUpdateRequest true "Fields to update"
+// @Success 200 {object} dto.ResponseDto "Promotion updated successfully"
+// @Failure 400 {object} dto.ResponseDto "Invalid request data"
+// @Router /api/admin/promotions/{id} [put]
+func (pc *PromotionController) UpdatePromotion(c *gin.Context) {
+	var req dto.PromotionUpdateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.Fail("Invalid request data"))
+		return
+	}
+
+	response := service.IPromotionService.UpdatePromotion(c.Param("id"), req)
+	c.JSON(http.StatusOK, response)
+}
+
+// DeletePromotion handles DELETE /api/admin/promotions/:id
+// @Summary Delete a promotion
+// @Tags Promotions
+// @Security ApiKeyAuth
+// @Produce json
+// @Param id path string true "Promotion ID"
+// @Success 200 {object} dto.ResponseDto "Promotion deleted successfully"
+// @Router /api/admin/promotions/{id} [delete]
+func (pc *PromotionController) DeletePromotion(c *gin.Context) {
+	response := service.IPromotionService.DeletePromotion(c.Param("id"))
+	c.JSON(http.StatusOK, response)
+}
+
+// SimulatePromotion handles POST /api/admin/promotions/simulate
+// @Summary Simulate a promotion
+// @Description Prices a sample cart with a saved promotion, or a draft that is not saved, whatever its status and validity window, optionally together with the active promotions. Returns each line with the promotions that applied to it and why the others did not.
+// @Tags Promotions
+// @Security ApiKeyAuth
+// @Accept json
+// @Produce json
+// @Param request body dto.PromotionSimulateRequest true "Promotion and sample cart"
+// @Success 200 {object} dto.ResponseDto "Simulation completed successfully"
+// @Failure 400 {object} dto.ResponseDto "Invalid request data"
+// @Router /api/admin/promotions/simulate [post]
+func (pc *PromotionController) SimulatePromotion(c *gin.Context) {
+	var req dto.PromotionSimulateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.Fail("Invalid request data"))
+		return
+	}
+
+	response := service.IPromotionService.SimulatePromotion(req)
+	c.JSON(http.StatusOK, response)
+}
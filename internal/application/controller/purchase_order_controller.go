@@ -0,0 +1,162 @@
+package controller
+
+import (
+	"backend-ecommerce/internal/application/dto"
+	"backend-ecommerce/internal/application/service"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// PurchaseOrderController handles purchase order and goods receiving HTTP requests
+type PurchaseOrderController struct {
+}
+
+// GetPurchaseOrders handles GET /api/admin/purchase-orders
+// @Summary List purchase orders
+// @Tags Purchasing
+// @Security ApiKeyAuth
+// @Produce json
+// @Param status query string false "Filter by status" Enums(draft, sent, partially_received, received, closed)
+// @Param supplier_id query string false "Filter by supplier"
+// @Param page query int false "Page number" default(1)
+// @Param page_size query int false "Page size" default(20)
+// @Success 200 {object} dto.ResponseDto "Purchase orders retrieved successfully"
+// @Router /api/admin/purchase-orders [get]
+func (poc *PurchaseOrderController) GetPurchaseOrders(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	if page < 1 {
+		page = 1
+	}
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "20"))
+	if pageSize < 1 || pageSize > 100 {
+		pageSize = 20
+	}
+
+	response := service.IPurchaseOrderService.GetPurchaseOrders(c.Query("status"), c.Query("supplier_id"), page, pageSize)
+	c.JSON(http.StatusOK, response)
+}
+
+// GetPurchaseOrder handles GET /api/admin/purchase-orders/:id
+// @Summary Get a purchase order
+// @Description Returns a purchase order with its lines, received quantities and landed costs
+// @Tags Purchasing
+// @Security ApiKeyAuth
+// @Produce json
+// @Param id path string true "Purchase order ID"
+// @Success 200 {object} dto.ResponseDto "Purchase order retrieved successfully"
+// @Router /api/admin/purchase-orders/{id} [get]
+func (poc *PurchaseOrderController) GetPurchaseOrder(c *gin.Context) {
+	response := service.IPurchaseOrderService.GetPurchaseOrder(c.Param("id"))
+	c.JSON(http.StatusOK, response)
+}
+
+// CreatePurchaseOrder handles POST /api/admin/purchase-orders
+// @Summary Create a purchase order
+// @Description Raises a draft purchase order with a supplier for delivery to a stock location
+// @Tags Purchasing
+// @Security ApiKeyAuth
+// @Accept json
+// @Produce json
+// @Param request body dto.PurchaseOrderRequest true "Purchase order"
+// @Success 200 {object} dto.ResponseDto "Purchase order created successfully"
+// @Failure 400 {object} dto.ResponseDto "Invalid request data"
+// @Router /api/admin/purchase-orders [post]
+func (poc *PurchaseOrderController) CreatePurchaseOrder(c *gin.Context) {
+	var req dto.PurchaseOrderRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.Fail("Invalid request data"))
+		return
+	}
+
+	response := service.IPurchaseOrderService.CreatePurchaseOrder(c.GetString("user_id"), req)
+	c.JSON(http.StatusOK, response)
+}
+
+// UpdatePurchaseOrder handles PUT /api/admin/purchase-orders/:id
+// @Summary Update a purchase order
+// @Description Replaces the details and lines of a draft purchase order
+// @Tags Purchasing
+// @Security ApiKeyAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "Purchase order ID"
+// @Param request body dto.PurchaseOrderRequest true "Purchase order"
+// @Success 200 {object} dto.ResponseDto "Purchase order updated successfully"
+// @Failure 400 {object} dto.ResponseDto "Invalid request data"
+// @Router /api/admin/purchase-orders/{id} [put]
+func (poc *PurchaseOrderController) UpdatePurchaseOrder(c *gin.Context) {
+	var req dto.PurchaseOrderRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.Fail("Invalid request data"))
+		return
+	}
+
+	response := service.IPurchaseOrderService.UpdatePurchaseOrder(c.Param("id"), req)
+	c.JSON(http.StatusOK, response)
+}
+
+// DeletePurchaseOrder handles DELETE /api/admin/purchase-orders/:id
+// @Summary Delete a draft purchase order
+// @Tags Purchasing
+// @Security ApiKeyAuth
+// @Produce json
+// @Param id path string true "Purchase order ID"
+// @Success 200 {object} dto.ResponseDto "Purchase order deleted successfully"
+// @Router /api/admin/purchase-orders/{id} [delete]
+func (poc *PurchaseOrderController) DeletePurchaseOrder(c *gin.Context) {
+	response := service.IPurchaseOrderService.DeletePurchaseOrder(c.Param("id"))
+	c.JSON(http.StatusOK, response)
+}
+
+// SendPurchaseOrder handles POST /api/admin/purchase-orders/:id/send
+// @Summary Send a purchase order
+// @Description Marks a draft purchase order as sent to the supplier so goods can be received against it
+// @Tags Purchasing
+// @Security ApiKeyAuth
+// @Produce json
+// @Param id path string true "Purchase order ID"
+// @Success 200 {object} dto.ResponseDto "Purchase order sent"
+// @Router /api/admin/purchase-orders/{id}/send [post]
+func (poc *PurchaseOrderController) SendPurchaseOrder(c *gin.Context) {
+	response := service.IPurchaseOrderService.SendPurchaseOrder(c.Param("id"))
+	c.JSON(http.StatusOK, response)
+}
+
+// ReceiveGoods handles POST /api/admin/purchase-orders/:id/receipts
+// @Summary Receive goods
+// @Description Books a full or partial delivery into stock at its landed cost and records it in the stock ledger
+// @Tags Purchasing
+// @Security ApiKeyAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "Purchase order ID"
+// @Param request body dto.GoodsReceiptRequest true "Received quantities"
+// @Success 200 {object} dto.ResponseDto "Goods received successfully"
+// @Failure 400 {object} dto.ResponseDto "Invalid request data"
+// @Router /api/admin/purchase-orders/{id}/receipts [post]
+func (poc *PurchaseOrderController) ReceiveGoods(c *gin.Context) {
+	var req dto.GoodsReceiptRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.Fail("Invalid request data"))
+		return
+	}
+
+	response := service.IPurchaseOrderService.ReceiveGoods(c.GetString("user_id"), c.Param("id"), req)
+	c.JSON(http.StatusOK, response)
+}
+
+// ClosePurchaseOrder handles POST /api/admin/purchase-orders/:id/close
+// @Summary Close a purchase order
+// @Description Stops a purchase order from accepting further deliveries
+// @Tags Purchasing
+// @Security ApiKeyAuth
+// @Produce json
+// @Param id path string true "Purchase order ID"
+// @Success 200 {object} dto.ResponseDto "Purchase order closed"
+// @Router /api/admin/purchase-orders/{id}/close [post]
+func (poc *PurchaseOrderController) ClosePurchaseOrder(c *gin.Context) {
+	response := service.IPurchaseOrderService.ClosePurchaseOrder(c.Param("id"))
+	c.JSON(http.StatusOK, response)
+}
@@ -0,0 +1,63 @@
+package controller
+
+import (
+	"backend-ecommerce/internal/application/dto"
+	"backend-ecommerce/internal/application/service"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RecentlyViewedController handles recently viewed product HTTP requests
+type RecentlyViewedController struct {
+}
+
+// GetRecentlyViewed handles GET /api/recently-viewed
+// @Summary List recently viewed products
+// @Description Returns the products the shopper viewed most recently, newest first
+// @Tags RecentlyViewed
+// @Security ApiKeyAuth
+// @Produce json
+// @Param X-Guest-Token header string false "Guest token, for shoppers who are not signed in"
+// @Success 200 {object} dto.ResponseDto "Recently viewed products retrieved successfully"
+// @Router /api/recently-viewed [get]
+func (rvc *RecentlyViewedController) GetRecentlyViewed(c *gin.Context) {
+	response := service.IRecentlyViewedService.GetRecentlyViewed(c.GetString("user_id"), c.GetString("guest_token"))
+	c.JSON(http.StatusOK, response)
+}
+
+// RecordView handles POST /api/recently-viewed
+// @Summary Record a product view
+// @Description Moves a product to the top of the shopper's recently viewed list
+// @Tags RecentlyViewed
+// @Security ApiKeyAuth
+// @Accept json
+// @Produce json
+// @Param X-Guest-Token header string false "Guest token, for shoppers who are not signed in"
+// @Param request body dto.RecentlyViewedRequest true "Viewed product"
+// @Success 200 {object} dto.ResponseDto "Product view recorded"
+// @Failure 400 {object} dto.ResponseDto "Invalid request data"
+// @Router /api/recently-viewed [post]
+func (rvc *RecentlyViewedController) RecordView(c *gin.Context) {
+	var req dto.RecentlyViewedRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.Fail("Invalid request data"))
+		return
+	}
+
+	response := service.IRecentlyViewedService.RecordView(c.GetString("user_id"), c.GetString("guest_token"), req.ProductID)
+	c.JSON(http.StatusOK, response)
+}
+
+// ClearRecentlyViewed handles DELETE /api/recently-viewed
+// @Summary Clear recently viewed products
+// @Tags RecentlyViewed
+// @Security ApiKeyAuth
+// @Produce json
+// @Param X-Guest-Token header string false "Guest token, for shoppers who are not signed in"
+// @Success 200 {object} dto.ResponseDto "Recently viewed products cleared"
+// @Router /api/recently-viewed [delete]
+func (rvc *RecentlyViewedController) ClearRecentlyViewed(c *gin.Context) {
+	response := service.IRecentlyViewedService.ClearRecentlyViewed(c.GetString("user_id"), c.GetString("guest_token"))
+	c.JSON(http.StatusOK, response)
+}
@@ -0,0 +1,84 @@
+package controller
+
+import (
+	"backend-ecommerce/internal/application/dto"
+	"backend-ecommerce/internal/application/service"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// StockLocationController handles warehouse and store HTTP requests
+type StockLocationController struct {
+}
+
+// GetStockLocations handles GET /api/admin/stock-locations
+// @Summary List stock locations
+// @Description Returns every warehouse and store in allocation order
+// @Tags Inventory
+// @Security ApiKeyAuth
+// @Produce json
+// @Success 200 {object} dto.ResponseDto "Stock locations retrieved successfully"
+// @Router /api/admin/stock-locations [get]
+func (slc *StockLocationController) GetStockLocations(c *gin.Context) {
+	response := service.IStockLocationService.GetStockLocations()
+	c.JSON(http.StatusOK, response)
+}
+
+// CreateStockLocation handles POST /api/admin/stock-locations
+// @Summary Create a stock location
+// @Tags Inventory
+// @Security ApiKeyAuth
+// @Accept json
+// @Produce json
+// @Param request body dto.StockLocationCreateRequest true "Stock location"
+// @Success 200 {object} dto.ResponseDto "Stock location created successfully"
+// @Failure 400 {object} dto.ResponseDto "Invalid request data"
+// @Router /api/admin/stock-locations [post]
+func (slc *StockLocationController) CreateStockLocation(c *gin.Context) {
+	var req dto.StockLocationCreateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.Fail("Invalid request data"))
+		return
+	}
+
+	response := service.IStockLocationService.CreateStockLocation(req)
+	c.JSON(http.StatusOK, response)
+}
+
+// UpdateStockLocation handles PUT /api/admin/stock-locations/:id
+// @Summary Update a stock location
+// @Description Updates the address, priority or status of a location; inactive locations are left out of availability and allocation
+// @Tags Inventory
+// @Security ApiKeyAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "Stock location ID"
+// @Param request body dto.StockLocationUpdateRequest true "Fields to update"
+// @Success 200 {object} dto.ResponseDto "Stock location updated successfully"
+// @Failure 400 {object} dto.ResponseDto "Invalid request data"
+// @Router /api/admin/stock-locations/{id} [put]
+func (slc *StockLocationController) UpdateStockLocation(c *gin.Context) {
+	var req dto.StockLocationUpdateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.Fail("Invalid request data"))
+		return
+	}
+
+	response := service.IStockLocationService.UpdateStockLocation(c.Param("id"), req)
+	c.JSON(http.StatusOK, response)
+}
+
+// DeleteStockLocation handles DELETE /api/admin/stock-locations/:id
+// @Summary Delete a stock location
+// @Description Deletes a location that holds no stock
+// @Tags Inventory
+// @Security ApiKeyAuth
+// @Produce json
+// @Param id path string true "Stock location ID"
+// @Success 200 {object} dto.ResponseDto "Stock location deleted successfully"
+// @Router /api/admin/stock-locations/{id} [delete]
+func (slc *StockLocationController) DeleteStockLocation(c *gin.Context) {
+	response := service.IStockLocationService.DeleteStockLocation(c.Param("id"))
+	c.JSON(http.StatusOK, response)
+}
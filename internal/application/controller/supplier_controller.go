@@ -0,0 +1,83 @@
+package controller
+
+import (
+	"backend-ecommerce/internal/application/dto"
+	"backend-ecommerce/internal/application/service"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SupplierController handles supplier HTTP requests
+type SupplierController struct {
+}
+
+// GetSuppliers handles GET /api/admin/suppliers
+// @Summary List suppliers
+// @Tags Purchasing
+// @Security ApiKeyAuth
+// @Produce json
+// @Success 200 {object} dto.ResponseDto "Suppliers retrieved successfully"
+// @Router /api/admin/suppliers [get]
+func (sc *SupplierController) GetSuppliers(c *gin.Context) {
+	response := service.ISupplierService.GetSuppliers()
+	c.JSON(http.StatusOK, response)
+}
+
+// CreateSupplier handles POST /api/admin/suppliers
+// @Summary Create a supplier
+// @Tags Purchasing
+// @Security ApiKeyAuth
+// @Accept json
+// @Produce json
+// @Param request body dto.SupplierCreateRequest true "Supplier"
+// @Success 200 {object} dto.ResponseDto "Supplier created successfully"
+// @Failure 400 {object} dto.ResponseDto "Invalid request data"
+// @Router /api/admin/suppliers [post]
+func (sc *SupplierController) CreateSupplier(c *gin.Context) {
+	var req dto.SupplierCreateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.Fail("Invalid request data"))
+		return
+	}
+
+	response := service.ISupplierService.CreateSupplier(req)
+	c.JSON(http.StatusOK, response)
+}
+
+// UpdateSupplier handles PUT /api/admin/suppliers/:id
+// @Summary Update a supplier
+// @Description Updates contact details or deactivates a supplier; inactive suppliers cannot receive new purchase orders
+// @Tags Purchasing
+// @Security ApiKeyAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "Supplier ID"
+// @Param request body dto.SupplierUpdateRequest true "Fields to update"
+// @Success 200 {object} dto.ResponseDto "Supplier updated successfully"
+// @Failure 400 {object} dto.ResponseDto "Invalid request data"
+// @Router /api/admin/suppliers/{id} [put]
+func (sc *SupplierController) UpdateSupplier(c *gin.Context) {
+	var req dto.SupplierUpdateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.Fail("Invalid request data"))
+		return
+	}
+
+	response := service.ISupplierService.UpdateSupplier(c.Param("id"), req)
+	c.JSON(http.StatusOK, response)
+}
+
+// DeleteSupplier handles DELETE /api/admin/suppliers/:id
+// @Summary Delete a supplier
+// @Description Deletes a supplier with no purchase orders
+// @Tags Purchasing
+// @Security ApiKeyAuth
+// @Produce json
+// @Param id path string true "Supplier ID"
+// @Success 200 {object} dto.ResponseDto "Supplier deleted successfully"
+// @Router /api/admin/suppliers/{id} [delete]
+func (sc *SupplierController) DeleteSupplier(c *gin.Context) {
+	response := service.ISupplierService.DeleteSupplier(c.Param("id"))
+	c.JSON(http.StatusOK, response)
+}
@@ -1,6 +1,11 @@
 package controller
 
 import (
+	"backend-ecommerce/internal/application/dto"
+	"backend-ecommerce/internal/application/service"
+	"backend-ecommerce/internal/infrastructure/config"
+	"net/http"
+
 	"github.com/gin-gonic/gin"
 )
 
@@ -32,3 +37,70 @@ func (uc *UserController) UpdateUser(c *gin.Context) {
 func (uc *UserController) DeleteUser(c *gin.Context) {
 
 }
+
+// MergeGuestData handles POST /api/users/me/merge-guest
+// @Summary Merge guest data into the account
+// @Description Moves the cart, recently viewed products and wishlists saved under a guest token into the signed-in user's account. Signing in does not merge anything by itself: clients must call this endpoint after every sign-in, sending the guest token they used before, or the guest cart stays under the guest token and is lost when it expires. Calling it again, or without a guest token, changes nothing.
+// @Tags Users
+// @Security ApiKeyAuth
+// @Produce json
+// @Param X-Guest-Token header string false "Guest token used before signing in, unless sent in the guest token cookie"
+// @Success 200 {object} dto.ResponseDto "Guest data merged successfully"
+// @Failure 401 {object} dto.ResponseDto "Unauthorized"
+// @Router /api/users/me/merge-guest [post]
+func (uc *UserController) MergeGuestData(c *gin.Context) {
+	userID := c.GetString("user_id")
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, dto.Fail("User not authenticated"))
+		return
+	}
+
+	response := service.IUserService.MergeGuestData(userID, config.GuestToken(c))
+	if response.Code == 0 {
+		config.ClearGuestToken(c)
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// Unsubscribe handles POST /api/marketing/unsubscribe
+// @Summary Unsubscribe from marketing emails
+// @Description Opts a user out of marketing emails such as abandoned cart reminders, using the signed link from one of them; no sign-in needed
+// @Tags Users
+// @Accept json
+// @Produce json
+// @Param request body dto.MarketingUnsubscribeRequest true "User and token from the unsubscribe link"
+// @Success 200 {object} dto.ResponseDto "Unsubscribed successfully"
+// @Failure 400 {object} dto.ResponseDto "Invalid request data"
+// @Router /api/marketing/unsubscribe [post]
+func (uc *UserController) Unsubscribe(c *gin.Context) {
+	var req dto.MarketingUnsubscribeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.Fail("Invalid request data"))
+		return
+	}
+
+	response := service.IUserService.Unsubscribe(req)
+	c.JSON(http.StatusOK, response)
+}
+
+// UpdateMarketingPreferences handles PUT /api/users/me/marketing-preferences
+// @Summary Update marketing email preferences
+// @Description Subscribes the signed-in user to, or unsubscribes them from, marketing emails such as abandoned cart reminders
+// @Tags Users
+// @Security ApiKeyAuth
+// @Accept json
+// @Produce json
+// @Param request body dto.MarketingPreferencesRequest true "Preferences"
+// @Success 200 {object} dto.ResponseDto "Preferences updated successfully"
+// @Failure 400 {object} dto.ResponseDto "Invalid request data"
+// @Router /api/users/me/marketing-preferences [put]
+func (uc *UserController) UpdateMarketingPreferences(c *gin.Context) {
+	var req dto.MarketingPreferencesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.Fail("Invalid request data"))
+		return
+	}
+
+	response := service.IUserService.UpdateMarketingPreferences(c.GetString("user_id"), req)
+	c.JSON(http.StatusOK, response)
+}
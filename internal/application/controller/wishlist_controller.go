@@ -0,0 +1,193 @@
+package controller
+
+import (
+	"backend-ecommerce/internal/application/dto"
+	"backend-ecommerce/internal/application/service"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// WishlistController handles wishlist HTTP requests for signed-in users and guests
+type WishlistController struct {
+}
+
+// GetWishlists handles GET /api/wishlists
+// @Summary List wishlists
+// @Description Returns the shopper's wishlists with their items
+// @Tags Wishlists
+// @Security ApiKeyAuth
+// @Produce json
+// @Param X-Guest-Token header string false "Guest token, for shoppers who are not signed in"
+// @Success 200 {object} dto.ResponseDto "Wishlists retrieved successfully"
+// @Router /api/wishlists [get]
+func (wc *WishlistController) GetWishlists(c *gin.Context) {
+	response := service.IWishlistService.GetWishlists(c.GetString("user_id"), c.GetString("guest_token"))
+	c.JSON(http.StatusOK, response)
+}
+
+// CreateWishlist handles POST /api/wishlists
+// @Summary Create a wishlist
+// @Tags Wishlists
+// @Security ApiKeyAuth
+// @Accept json
+// @Produce json
+// @Param X-Guest-Token header string false "Guest token, for shoppers who are not signed in"
+// @Param request body dto.WishlistCreateRequest true "Wishlist"
+// @Success 200 {object} dto.ResponseDto "Wishlist created successfully"
+// @Failure 400 {object} dto.ResponseDto "Invalid request data"
+// @Router /api/wishlists [post]
+func (wc *WishlistController) CreateWishlist(c *gin.Context) {
+	var req dto.WishlistCreateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.Fail("Invalid request data"))
+		return
+	}
+
+	response := service.IWishlistService.CreateWishlist(c.GetString("user_id"), c.GetString("guest_token"), req)
+	c.JSON(http.StatusOK, response)
+}
+
+// RenameWishlist handles PUT /api/wishlists/:id
+// @Summary Rename a wishlist
+// @Tags Wishlists
+// @Security ApiKeyAuth
+// @Accept json
+// @Produce json
+// @Param X-Guest-Token header string false "Guest token, for shoppers who are not signed in"
+// @Param id path string true "Wishlist ID"
+// @Param request body dto.WishlistUpdateRequest true "New name"
+// @Success 200 {object} dto.ResponseDto "Wishlist updated successfully"
+// @Failure 400 {object} dto.ResponseDto "Invalid request data"
+// @Router /api/wishlists/{id} [put]
+func (wc *WishlistController) RenameWishlist(c *gin.Context) {
+	var req dto.WishlistUpdateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.Fail("Invalid request data"))
+		return
+	}
+
+	response := service.IWishlistService.RenameWishlist(c.GetString("user_id"), c.GetString("guest_token"), c.Param("id"), req)
+	c.JSON(http.StatusOK, response)
+}
+
+// DeleteWishlist handles DELETE /api/wishlists/:id
+// @Summary Delete a wishlist
+// @Tags Wishlists
+// @Security ApiKeyAuth
+// @Produce json
+// @Param X-Guest-Token header string false "Guest token, for shoppers who are not signed in"
+// @Param id path string true "Wishlist ID"
+// @Success 200 {object} dto.ResponseDto "Wishlist deleted successfully"
+// @Router /api/wishlists/{id} [delete]
+func (wc *WishlistController) DeleteWishlist(c *gin.Context) {
+	response := service.IWishlistService.DeleteWishlist(c.GetString("user_id"), c.GetString("guest_token"), c.Param("id"))
+	c.JSON(http.StatusOK, response)
+}
+
+// AddItem handles POST /api/wishlists/:id/items
+// @Summary Save a product to a wishlist
+// @Tags Wishlists
+// @Security ApiKeyAuth
+// @Accept json
+// @Produce json
+// @Param X-Guest-Token header string false "Guest token, for shoppers who are not signed in"
+// @Param id path string true "Wishlist ID"
+// @Param request body dto.WishlistItemAddRequest true "Product to save"
+// @Success 200 {object} dto.ResponseDto "Item added successfully"
+// @Failure 400 {object} dto.ResponseDto "Invalid request data"
+// @Router /api/wishlists/{id}/items [post]
+func (wc *WishlistController) AddItem(c *gin.Context) {
+	var req dto.WishlistItemAddRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.Fail("Invalid request data"))
+		return
+	}
+
+	response := service.IWishlistService.AddWishlistItem(c.GetString("user_id"), c.GetString("guest_token"), c.Param("id"), req)
+	c.JSON(http.StatusOK, response)
+}
+
+// RemoveItem handles DELETE /api/wishlists/:id/items/:item_id
+// @Summary Remove a product from a wishlist
+// @Tags Wishlists
+// @Security ApiKeyAuth
+// @Produce json
+// @Param X-Guest-Token header string false "Guest token, for shoppers who are not signed in"
+// @Param id path string true "Wishlist ID"
+// @Param item_id path string true "Wishlist item ID"
+// @Success 200 {object} dto.ResponseDto "Item removed successfully"
+// @Router /api/wishlists/{id}/items/{item_id} [delete]
+func (wc *WishlistController) RemoveItem(c *gin.Context) {
+	response := service.IWishlistService.RemoveWishlistItem(c.GetString("user_id"), c.GetString("guest_token"), c.Param("id"), c.Param("item_id"))
+	c.JSON(http.StatusOK, response)
+}
+
+// MoveItemToCart handles POST /api/wishlists/:id/items/:item_id/move-to-cart
+// @Summary Move a wishlist item to the cart
+// @Description Adds the product to the shopper's cart at its current price and removes it from the wishlist
+// @Tags Wishlists
+// @Security ApiKeyAuth
+// @Accept json
+// @Produce json
+// @Param X-Guest-Token header string false "Guest token, for shoppers who are not signed in"
+// @Param id path string true "Wishlist ID"
+// @Param item_id path string true "Wishlist item ID"
+// @Param request body dto.WishlistMoveToCartRequest false "Quantity, 1 by default"
+// @Success 200 {object} dto.ResponseDto "Item moved to cart successfully"
+// @Failure 400 {object} dto.ResponseDto "Invalid request data"
+// @Router /api/wishlists/{id}/items/{item_id}/move-to-cart [post]
+func (wc *WishlistController) MoveItemToCart(c *gin.Context) {
+	var req dto.WishlistMoveToCartRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, dto.Fail("Invalid request data"))
+			return
+		}
+	}
+
+	response := service.IWishlistService.MoveItemToCart(c.GetString("user_id"), c.GetString("guest_token"), c.Param("id"), c.Param("item_id"), req.Quantity)
+	c.JSON(http.StatusOK, response)
+}
+
+// ShareWishlist handles POST /api/wishlists/:id/share
+// @Summary Share a wishlist
+// @Description Creates a public link to the wishlist; sharing again replaces the previous link
+// @Tags Wishlists
+// @Security ApiKeyAuth
+// @Produce json
+// @Param X-Guest-Token header string false "Guest token, for shoppers who are not signed in"
+// @Param id path string true "Wishlist ID"
+// @Success 200 {object} dto.ResponseDto "Wishlist shared successfully"
+// @Router /api/wishlists/{id}/share [post]
+func (wc *WishlistController) ShareWishlist(c *gin.Context) {
+	response := service.IWishlistService.ShareWishlist(c.GetString("user_id"), c.GetString("guest_token"), c.Param("id"))
+	c.JSON(http.StatusOK, response)
+}
+
+// UnshareWishlist handles DELETE /api/wishlists/:id/share
+// @Summary Stop sharing a wishlist
+// @Tags Wishlists
+// @Security ApiKeyAuth
+// @Produce json
+// @Param X-Guest-Token header string false "Guest token, for shoppers who are not signed in"
+// @Param id path string true "Wishlist ID"
+// @Success 200 {object} dto.ResponseDto "Wishlist unshared successfully"
+// @Router /api/wishlists/{id}/share [delete]
+func (wc *WishlistController) UnshareWishlist(c *gin.Context) {
+	response := service.IWishlistService.UnshareWishlist(c.GetString("user_id"), c.GetString("guest_token"), c.Param("id"))
+	c.JSON(http.StatusOK, response)
+}
+
+// GetSharedWishlist handles GET /api/shared-wishlists/:token
+// @Summary View a shared wishlist
+// @Description Returns a wishlist by its public link
+// @Tags Wishlists
+// @Produce json
+// @Param token path string true "Share token"
+// @Success 200 {object} dto.ResponseDto "Wishlist retrieved successfully"
+// @Router /api/shared-wishlists/{token} [get]
+func (wc *WishlistController) GetSharedWishlist(c *gin.Context) {
+	response := service.IWishlistService.GetSharedWishlist(c.Param("token"))
+	c.JSON(http.StatusOK, response)
+}
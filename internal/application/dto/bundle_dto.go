@@ -0,0 +1,35 @@
+package dto
+
+// BundleUpdateRequest represents the data needed to turn a product into a bundle
+type BundleUpdateRequest struct {
+	Pricing         string                   `json:"pricing" binding:"required,oneof=fixed computed"`
+	DiscountPercent float64                  `json:"discount_percent" binding:"gte=0,lt=100"`
+	Components      []BundleComponentRequest `json:"components" binding:"required,min=1,dive"`
+}
+
+// BundleComponentRequest represents one component of a bundle
+type BundleComponentRequest struct {
+	ProductID string  `json:"product_id" binding:"required"`
+	VariantID *string `json:"variant_id,omitempty"`
+	Quantity  int     `json:"quantity" binding:"required,min=1"`
+}
+
+// BundleResponse represents the composition and availability of a bundle product
+type BundleResponse struct {
+	Pricing         string                    `json:"pricing"`
+	DiscountPercent float64                   `json:"discount_percent,omitempty"`
+	Available       int                       `json:"available"`
+	Components      []BundleComponentResponse `json:"components"`
+}
+
+// BundleComponentResponse represents a component of a bundle in the response
+type BundleComponentResponse struct {
+	ProductID   string  `json:"product_id"`
+	VariantID   *string `json:"variant_id,omitempty"`
+	Name        string  `json:"name"`
+	SKU         string  `json:"sku,omitempty"`
+	Quantity    int     `json:"quantity"`
+	UnitPrice   float64 `json:"unit_price"`
+	Available   int     `json:"available"`
+	IsAvailable bool    `json:"is_available"`
+}
@@ -1,38 +1,245 @@
 package dto
 
-// CartCreateRequest represents the data needed to create a new cart
-type CartCreateRequest struct {
-	UserID     *string `json:"user_id,omitempty" validate:"omitempty,uuid4"`
-	GuestToken string  `json:"guest_token,omitempty"`
-}
+import (
+	"time"
+
+	"backend-ecommerce/internal/application/entity"
+)
 
 // CartItemAddRequest represents the data needed to add an item to a cart
 type CartItemAddRequest struct {
-	ProductID string `json:"product_id" validate:"required,uuid4"`
-	Quantity  int    `json:"quantity" validate:"required,min=1"`
+	ProductID string `json:"product_id" binding:"required"`
+	// VariantID is required for products sold in variants
+	VariantID *string `json:"variant_id,omitempty"`
+	Quantity  int     `json:"quantity" binding:"required,min=1"`
 }
 
-// CartItemUpdateRequest represents the data needed to update a cart item
+// CartItemUpdateRequest represents the data needed to update a cart item.
+// A quantity of 0 removes the item.
 type CartItemUpdateRequest struct {
-	Quantity int `json:"quantity" validate:"required,min=0"`
+	Quantity int `json:"quantity" binding:"min=0"`
 }
 
 // CartResponse represents the cart data returned to the client
 type CartResponse struct {
-	ID         string             `json:"id"`
-	UserID     *string            `json:"user_id,omitempty"`
-	GuestToken string             `json:"guest_token,omitempty"`
-	Items      []CartItemResponse `json:"items,omitempty"`
-	CreatedAt  string             `json:"created_at"`
-	UpdatedAt  string             `json:"updated_at"`
+	ID     string             `json:"id"`
+	UserID *string            `json:"user_id,omitempty"`
+	Items  []CartItemResponse `json:"items"`
+	Totals CartTotalsResponse `json:"totals"`
+	// SavedItems are saved for later and left out of the totals
+	SavedItems []CartSavedItemResponse `json:"saved_items"`
+	// Coupons are the discount codes applied, in the order they were entered
+	Coupons []CartCouponResponse `json:"coupons"`
+	// Promotions are the automatic promotions evaluated, by priority
+	Promotions []CartPromotionResponse `json:"promotions"`
+	// Warnings lists what changed since items were added. Checkout is refused
+	// while RequiresAcknowledgement is set, until the shopper accepts the
+	// changes.
+	Warnings                []CartWarningResponse `json:"warnings"`
+	RequiresAcknowledgement bool                  `json:"requires_acknowledgement"`
+	// Recommendations suggests products to add, based on what is in the cart
+	Recommendations *ProductRecommendationsResponse `json:"recommendations,omitempty"`
+	CreatedAt       string                          `json:"created_at"`
+	UpdatedAt       string                          `json:"updated_at"`
 }
 
 // CartItemResponse represents a cart item in the response
 type CartItemResponse struct {
 	ID        string           `json:"id"`
 	ProductID string           `json:"product_id"`
+	VariantID *string          `json:"variant_id,omitempty"`
 	Product   *ProductResponse `json:"product,omitempty"`
 	Quantity  int              `json:"quantity"`
 	UnitPrice float64          `json:"unit_price"`
-	CreatedAt string           `json:"created_at"`
+	Subtotal  float64          `json:"subtotal"`
+	Discount  float64          `json:"discount"`
+	Tax       float64          `json:"tax"`
+	Total     float64          `json:"total"`
+	// IsAvailable is false when the product is no longer sold or out of
+	// stock. Quantity is capped at the stock available.
+	IsAvailable bool   `json:"is_available"`
+	CreatedAt   string `json:"created_at"`
+	// Promotions explains which automatic promotions discounted the item
+	Promotions []LinePromotionResponse `json:"promotions"`
+}
+
+// CartSavedItemResponse represents an item saved for later, at its current
+// price
+type CartSavedItemResponse struct {
+	ID        string           `json:"id"`
+	ProductID string           `json:"product_id"`
+	VariantID *string          `json:"variant_id,omitempty"`
+	Product   *ProductResponse `json:"product,omitempty"`
+	Quantity  int              `json:"quantity"`
+	UnitPrice float64          `json:"unit_price"`
+	// IsAvailable is false when the product is no longer sold or out of stock
+	IsAvailable bool   `json:"is_available"`
+	SavedAt     string `json:"saved_at"`
+}
+
+// Cart warning codes
+const (
+	CartWarningPriceIncreased  = "price_increased"
+	CartWarningPriceDecreased  = "price_decreased"
+	CartWarningUnavailable     = "item_unavailable"
+	CartWarningQuantityReduced = "quantity_reduced"
+)
+
+// CartWarningResponse describes a change to a cart line since it was added
+type CartWarningResponse struct {
+	Code        string   `json:"code"`
+	ItemID      string   `json:"item_id"`
+	ProductID   string   `json:"product_id"`
+	Message     string   `json:"message"`
+	OldPrice    *float64 `json:"old_price,omitempty"`
+	NewPrice    *float64 `json:"new_price,omitempty"`
+	OldQuantity *int     `json:"old_quantity,omitempty"`
+	NewQuantity *int     `json:"new_quantity,omitempty"`
+	// RequiresAcknowledgement is set for changes the shopper must accept
+	// before checking out; price drops are only reported
+	RequiresAcknowledgement bool `json:"requires_acknowledgement"`
+}
+
+// CartTotalsResponse is the price breakdown of a cart. GrandTotal is what
+// an order created from the cart is charged.
+type CartTotalsResponse struct {
+	Currency      string                    `json:"currency"`
+	Subtotal      float64                   `json:"subtotal"`
+	DiscountTotal float64                   `json:"discount_total"`
+	ShippingTotal float64                   `json:"shipping_total"`
+	TaxTotal      float64                   `json:"tax_total"`
+	GrandTotal    float64                   `json:"grand_total"`
+	Adjustments   []PriceAdjustmentResponse `json:"adjustments"`
+}
+
+// PriceAdjustmentResponse is an itemised discount, shipping or tax
+// adjustment and where it came from
+type PriceAdjustmentResponse struct {
+	Type   string `json:"type"`
+	Source string `json:"source"`
+	Label  string `json:"label"`
+	// ItemID is the cart item adjusted, or empty for the whole cart
+	ItemID string  `json:"item_id,omitempty"`
+	Amount float64 `json:"amount"`
+}
+
+// CartLineOffer returns the current unit price of a product, or of one of
+// its variants, and the inventory the line is sold from. Inventory is nil
+// for products that are never out of stock. ok is false when the variant is
+// not sold, or a variant must be chosen and none was.
+func CartLineOffer(product ProductResponse, variantID *string) (price float64, inventory *InventoryResponse, ok bool) {
+	if variantID == nil {
+		return product.Price, product.Inventory, len(product.Variants) == 0
+	}
+	for _, variant := range product.Variants {
+		if variant.ID != *variantID {
+			continue
+		}
+		// Variants without stock of their own are sold from the product's stock
+		inventory = product.Inventory
+		if variant.Inventory != nil {
+			inventory = variant.Inventory
+		}
+		return variant.Price, inventory, true
+	}
+	return 0, nil, false
+}
+
+// GetCartResponse converts a priced cart to a response. products holds the
+// published products in the cart, including saved items, keyed by ID.
+func GetCartResponse(cart entity.Cart, products map[string]ProductResponse, quote entity.PriceQuote, warnings []CartWarningResponse) CartResponse {
+	lines := make(map[string]entity.QuoteLine, len(quote.Lines))
+	for _, line := range quote.Lines {
+		lines[line.LineID] = line
+	}
+
+	items := make([]CartItemResponse, len(cart.Items))
+	for i, item := range cart.Items {
+		items[i] = CartItemResponse{
+			ID:        item.ID,
+			ProductID: item.ProductID,
+			VariantID: item.VariantID,
+			Quantity:  item.Quantity,
+			UnitPrice: item.PriceAtAdd,
+			CreatedAt: item.CreatedAt.Format(time.RFC3339),
+		}
+		items[i].Promotions = GetLinePromotionResponses(lines[item.ID].Promotions)
+		if product, ok := products[item.ProductID]; ok {
+			items[i].Product = &product
+		}
+		if line, ok := lines[item.ID]; ok && line.Offered {
+			items[i].Quantity = line.Quantity
+			items[i].UnitPrice = line.UnitPrice
+			items[i].Subtotal = line.Subtotal
+			items[i].Discount = line.Discount
+			items[i].Tax = line.Tax
+			items[i].Total = line.Total
+			items[i].IsAvailable = line.Quantity > 0
+		}
+	}
+
+	saved := make([]CartSavedItemResponse, len(cart.SavedItems))
+	for i, item := range cart.SavedItems {
+		saved[i] = CartSavedItemResponse{
+			ID:        item.ID,
+			ProductID: item.ProductID,
+			VariantID: item.VariantID,
+			Quantity:  item.Quantity,
+			SavedAt:   item.CreatedAt.Format(time.RFC3339),
+		}
+		product, ok := products[item.ProductID]
+		if !ok {
+			continue
+		}
+		saved[i].Product = &product
+		if price, inventory, ok := CartLineOffer(product, item.VariantID); ok {
+			saved[i].UnitPrice = price
+			saved[i].IsAvailable = inventory == nil || inventory.Available > 0
+		}
+	}
+
+	response := CartResponse{
+		ID:         cart.ID,
+		UserID:     cart.UserID,
+		Items:      items,
+		Totals:     GetCartTotalsResponse(quote),
+		SavedItems: saved,
+		Coupons:    GetCartCouponResponses(quote.Coupons),
+		Promotions: GetCartPromotionResponses(quote.Promotions),
+		Warnings:   warnings,
+		CreatedAt:  cart.CreatedAt.Format(time.RFC3339),
+		UpdatedAt:  cart.UpdatedAt.Format(time.RFC3339),
+	}
+	if response.Warnings == nil {
+		response.Warnings = []CartWarningResponse{}
+	}
+	for _, warning := range warnings {
+		if warning.RequiresAcknowledgement {
+			response.RequiresAcknowledgement = true
+		}
+	}
+	return response
+}
+
+// GetCartTotalsResponse converts a price quote to its totals breakdown
+func GetCartTotalsResponse(quote entity.PriceQuote) CartTotalsResponse {
+	adjustments := make([]PriceAdjustmentResponse, len(quote.Adjustments))
+	for i, adjustment := range quote.Adjustments {
+		adjustments[i] = PriceAdjustmentResponse{
+			Type:   string(adjustment.Type),
+			Source: adjustment.Source,
+			Label:  adjustment.Label,
+			ItemID: adjustment.LineID,
+			Amount: adjustment.Amount,
+		}
+	}
+	return CartTotalsResponse{
+		Currency:      quote.Currency,
+		Subtotal:      quote.Subtotal,
+		DiscountTotal: quote.DiscountTotal,
+		ShippingTotal: quote.ShippingTotal,
+		TaxTotal:      quote.TaxTotal,
+		GrandTotal:    quote.GrandTotal,
+		Adjustments:   adjustments,
+	}
 }
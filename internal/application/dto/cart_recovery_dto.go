@@ -0,0 +1,35 @@
+package dto
+
+// CartRestoreRequest carries the cart and signed token of a restore link from
+// an abandoned cart reminder
+type CartRestoreRequest struct {
+	CartID string `json:"cart_id" binding:"required"`
+	Token  string `json:"token" binding:"required"`
+}
+
+// CartRecoveryReportResponse reports how many carts that were sent abandoned
+// cart reminders in a period were recovered by an order
+type CartRecoveryReportResponse struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+	// Reminded counts the abandoned carts that were sent at least one reminder
+	Reminded      int64 `json:"reminded"`
+	RemindersSent int64 `json:"reminders_sent"`
+	// Restored counts the carts whose restore link was used
+	Restored  int64 `json:"restored"`
+	Recovered int64 `json:"recovered"`
+	// RecoveryRate is the percentage of reminded carts that were recovered
+	RecoveryRate float64 `json:"recovery_rate"`
+	// RecoveredRevenue totals the recovered orders per currency
+	RecoveredRevenue map[string]float64         `json:"recovered_revenue"`
+	Reminders        []CartRecoveryStepResponse `json:"reminders"`
+}
+
+// CartRecoveryStepResponse reports one reminder of the sequence: how many
+// carts received it and how many were recovered with it as the last reminder
+type CartRecoveryStepResponse struct {
+	Reminder  int    `json:"reminder"`
+	Window    string `json:"window"`
+	Carts     int64  `json:"carts"`
+	Recovered int64  `json:"recovered"`
+}
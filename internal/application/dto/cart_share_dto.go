@@ -0,0 +1,68 @@
+package dto
+
+import (
+	"time"
+
+	"backend-ecommerce/internal/application/entity"
+)
+
+// CartShareResponse is a link to a snapshot of the shopper's cart
+type CartShareResponse struct {
+	ID        string `json:"id"`
+	Token     string `json:"token"`
+	URL       string `json:"url"`
+	ExpiresAt string `json:"expires_at"`
+}
+
+// CartSharedCopyRequest carries the signed token of a shared cart link
+type CartSharedCopyRequest struct {
+	Token string `json:"token" binding:"required"`
+}
+
+// CartSnapshotResponse represents a shared cart as seen by the recipient,
+// at current prices
+type CartSnapshotResponse struct {
+	ID        string                     `json:"id"`
+	Items     []CartSnapshotItemResponse `json:"items"`
+	ExpiresAt string                     `json:"expires_at"`
+	CreatedAt string                     `json:"created_at"`
+}
+
+// CartSnapshotItemResponse represents a line of a shared cart
+type CartSnapshotItemResponse struct {
+	ProductID string           `json:"product_id"`
+	VariantID *string          `json:"variant_id,omitempty"`
+	Product   *ProductResponse `json:"product,omitempty"`
+	Quantity  int              `json:"quantity"`
+	UnitPrice float64          `json:"unit_price"`
+	// IsAvailable is false when the product is no longer sold or out of stock
+	IsAvailable bool `json:"is_available"`
+}
+
+// GetCartSnapshotResponse converts a cart snapshot to a response. products
+// holds the published products in the snapshot keyed by ID.
+func GetCartSnapshotResponse(snapshot entity.CartSnapshot, products map[string]ProductResponse) CartSnapshotResponse {
+	items := make([]CartSnapshotItemResponse, len(snapshot.Items))
+	for i, item := range snapshot.Items {
+		items[i] = CartSnapshotItemResponse{
+			ProductID: item.ProductID,
+			VariantID: item.VariantID,
+			Quantity:  item.Quantity,
+		}
+		product, ok := products[item.ProductID]
+		if !ok {
+			continue
+		}
+		items[i].Product = &product
+		if price, inventory, ok := CartLineOffer(product, item.VariantID); ok {
+			items[i].UnitPrice = price
+			items[i].IsAvailable = inventory == nil || inventory.Available >= item.Quantity
+		}
+	}
+	return CartSnapshotResponse{
+		ID:        snapshot.ID,
+		Items:     items,
+		ExpiresAt: snapshot.ExpiresAt.Format(time.RFC3339),
+		CreatedAt: snapshot.CreatedAt.Format(time.RFC3339),
+	}
+}
@@ -0,0 +1,77 @@
+package dto
+
+import (
+	"time"
+
+	"backend-ecommerce/internal/application/entity"
+)
+
+// CollectionCreateRequest represents the data needed to create a collection
+type CollectionCreateRequest struct {
+	Name        string                  `json:"name" binding:"required,min=2,max=255"`
+	Slug        string                  `json:"slug" binding:"required,max=255"`
+	Description string                  `json:"description,omitempty"`
+	Type        string                  `json:"type" binding:"required,oneof=manual automatic"`
+	Rules       *entity.CollectionRules `json:"rules,omitempty"`
+	SortBy      string                  `json:"sort_by,omitempty" binding:"omitempty,oneof=newest name price_asc price_desc"`
+	IsActive    *bool                   `json:"is_active,omitempty"`
+}
+
+// CollectionUpdateRequest represents the data needed to update a collection
+type CollectionUpdateRequest struct {
+	Name        *string                 `json:"name,omitempty" binding:"omitempty,min=2,max=255"`
+	Slug        *string                 `json:"slug,omitempty" binding:"omitempty,max=255"`
+	Description *string                 `json:"description,omitempty"`
+	Rules       *entity.CollectionRules `json:"rules,omitempty"`
+	SortBy      *string                 `json:"sort_by,omitempty" binding:"omitempty,oneof=newest name price_asc price_desc"`
+	IsActive    *bool                   `json:"is_active,omitempty"`
+}
+
+// CollectionProductsRequest represents the products of a manual collection in display order
+type CollectionProductsRequest struct {
+	ProductIDs []string `json:"product_ids" binding:"dive,required"`
+}
+
+// CollectionResponse represents a collection returned to the client
+type CollectionResponse struct {
+	ID           string                  `json:"id"`
+	Name         string                  `json:"name"`
+	Slug         string                  `json:"slug"`
+	Description  string                  `json:"description,omitempty"`
+	Type         string                  `json:"type"`
+	Rules        *entity.CollectionRules `json:"rules,omitempty"`
+	SortBy       string                  `json:"sort_by,omitempty"`
+	IsActive     bool                    `json:"is_active"`
+	ProductCount int64                   `json:"product_count"`
+	RefreshedAt  *string                 `json:"refreshed_at,omitempty"`
+	CreatedAt    string                  `json:"created_at"`
+	UpdatedAt    string                  `json:"updated_at"`
+}
+
+// CollectionDetailResponse represents a collection together with a page of its products
+type CollectionDetailResponse struct {
+	CollectionResponse
+	Products []ProductResponse `json:"products"`
+}
+
+// GetCollectionResponse converts a Collection entity to CollectionResponse DTO
+func GetCollectionResponse(collection entity.Collection, productCount int64) CollectionResponse {
+	response := CollectionResponse{
+		ID:           collection.ID,
+		Name:         collection.Name,
+		Slug:         collection.Slug,
+		Description:  collection.Description,
+		Type:         string(collection.Type),
+		IsActive:     collection.IsActive,
+		ProductCount: productCount,
+		RefreshedAt:  formatOptionalTime(collection.RefreshedAt),
+		CreatedAt:    collection.CreatedAt.Format(time.RFC3339),
+		UpdatedAt:    collection.UpdatedAt.Format(time.RFC3339),
+	}
+	if collection.Type == entity.CollectionTypeAutomatic {
+		rules := collection.Rules
+		response.Rules = &rules
+		response.SortBy = string(collection.SortBy)
+	}
+	return response
+}
@@ -0,0 +1,177 @@
+package dto
+
+import (
+	"time"
+
+	"backend-ecommerce/internal/application/entity"
+)
+
+// CouponCreateRequest represents the data needed to create a coupon. Code,
+// when given, creates a code any number of customers can use; single-use
+// codes are generated separately.
+type CouponCreateRequest struct {
+	Name        string  `json:"name" binding:"required,min=2,max=255"`
+	Description string  `json:"description,omitempty"`
+	Type        string  `json:"type" binding:"required,oneof=percentage fixed_amount free_shipping"`
+	Value       float64 `json:"value" binding:"min=0"`
+	MinSubtotal float64 `json:"min_subtotal" binding:"min=0"`
+	// ProductIDs and CategoryIDs limit the discount to those products or
+	// categories; excluded products and categories are never discounted
+	ProductIDs          []string   `json:"product_ids,omitempty" binding:"dive,required"`
+	CategoryIDs         []string   `json:"category_ids,omitempty" binding:"dive,required"`
+	ExcludedProductIDs  []string   `json:"excluded_product_ids,omitempty" binding:"dive,required"`
+	ExcludedCategoryIDs []string   `json:"excluded_category_ids,omitempty" binding:"dive,required"`
+	UsageLimit          int        `json:"usage_limit" binding:"min=0"`
+	PerCustomerLimit    int        `json:"per_customer_limit" binding:"min=0"`
+	StartsAt            *time.Time `json:"starts_at,omitempty"`
+	EndsAt              *time.Time `json:"ends_at,omitempty"`
+	Combinable          bool       `json:"combinable"`
+	Code                string     `json:"code,omitempty" binding:"omitempty,min=3,max=64"`
+}
+
+// CouponUpdateRequest represents the data needed to update a coupon. The
+// type cannot change once the coupon exists.
+type CouponUpdateRequest struct {
+	Name                *string    `json:"name,omitempty" binding:"omitempty,min=2,max=255"`
+	Description         *string    `json:"description,omitempty"`
+	Value               *float64   `json:"value,omitempty" binding:"omitempty,min=0"`
+	MinSubtotal         *float64   `json:"min_subtotal,omitempty" binding:"omitempty,min=0"`
+	ProductIDs          *[]string  `json:"product_ids,omitempty"`
+	CategoryIDs         *[]string  `json:"category_ids,omitempty"`
+	ExcludedProductIDs  *[]string  `json:"excluded_product_ids,omitempty"`
+	ExcludedCategoryIDs *[]string  `json:"excluded_category_ids,omitempty"`
+	UsageLimit          *int       `json:"usage_limit,omitempty" binding:"omitempty,min=0"`
+	PerCustomerLimit    *int       `json:"per_customer_limit,omitempty" binding:"omitempty,min=0"`
+	StartsAt            *time.Time `json:"starts_at,omitempty"`
+	EndsAt              *time.Time `json:"ends_at,omitempty"`
+	Combinable          *bool      `json:"combinable,omitempty"`
+	IsActive            *bool      `json:"is_active,omitempty"`
+}
+
+// CouponCodeGenerateRequest represents the data needed to generate unique
+// single-use codes for a coupon
+type CouponCodeGenerateRequest struct {
+	Count int `json:"count" binding:"required,min=1,max=10000"`
+	// Prefix is put in front of the random part, e.g. "SPRING-"
+	Prefix string `json:"prefix,omitempty" binding:"omitempty,max=20"`
+	// Length is the length of the random part, 10 by default
+	Length int `json:"length,omitempty" binding:"omitempty,min=6,max=32"`
+}
+
+// CouponApplyRequest represents a discount code entered in the cart
+type CouponApplyRequest struct {
+	Code string `json:"code" binding:"required,max=64"`
+}
+
+// CouponResponse represents a coupon returned to the client
+type CouponResponse struct {
+	ID                  string   `json:"id"`
+	Name                string   `json:"name"`
+	Description         string   `json:"description,omitempty"`
+	Type                string   `json:"type"`
+	Value               float64  `json:"value"`
+	MinSubtotal         float64  `json:"min_subtotal"`
+	ProductIDs          []string `json:"product_ids"`
+	CategoryIDs         []string `json:"category_ids"`
+	ExcludedProductIDs  []string `json:"excluded_product_ids"`
+	ExcludedCategoryIDs []string `json:"excluded_category_ids"`
+	UsageLimit          int      `json:"usage_limit"`
+	PerCustomerLimit    int      `json:"per_customer_limit"`
+	TimesUsed           int      `json:"times_used"`
+	StartsAt            *string  `json:"starts_at,omitempty"`
+	EndsAt              *string  `json:"ends_at,omitempty"`
+	Combinable          bool     `json:"combinable"`
+	IsActive            bool     `json:"is_active"`
+	// CodeCount is how many codes redeem the coupon
+	CodeCount int64  `json:"code_count"`
+	CreatedAt string `json:"created_at"`
+	UpdatedAt string `json:"updated_at"`
+}
+
+// CouponCodeResponse represents a coupon code returned to the client
+type CouponCodeResponse struct {
+	ID         string `json:"id"`
+	Code       string `json:"code"`
+	UsageLimit int    `json:"usage_limit"`
+	TimesUsed  int    `json:"times_used"`
+	CreatedAt  string `json:"created_at"`
+}
+
+// CartCouponResponse represents a discount code applied to a cart
+type CartCouponResponse struct {
+	Code string `json:"code"`
+	Name string `json:"name"`
+	Type string `json:"type"`
+	// Applied is false when the code does not apply to the cart as it is;
+	// Reason says why
+	Applied bool    `json:"applied"`
+	Reason  string  `json:"reason,omitempty"`
+	Amount  float64 `json:"amount"`
+}
+
+// GetCouponResponse converts a Coupon entity to CouponResponse DTO
+func GetCouponResponse(coupon entity.Coupon, codeCount int64) CouponResponse {
+	response := CouponResponse{
+		ID:                  coupon.ID,
+		Name:                coupon.Name,
+		Description:         coupon.Description,
+		Type:                string(coupon.Type),
+		Value:               coupon.Value,
+		MinSubtotal:         coupon.MinSubtotal,
+		ProductIDs:          nonNilIDs(coupon.ProductIDs),
+		CategoryIDs:         nonNilIDs(coupon.CategoryIDs),
+		ExcludedProductIDs:  nonNilIDs(coupon.ExcludedProductIDs),
+		ExcludedCategoryIDs: nonNilIDs(coupon.ExcludedCategoryIDs),
+		UsageLimit:          coupon.UsageLimit,
+		PerCustomerLimit:    coupon.PerCustomerLimit,
+		TimesUsed:           coupon.TimesUsed,
+		Combinable:          coupon.Combinable,
+		IsActive:            coupon.IsActive,
+		CodeCount:           codeCount,
+		CreatedAt:           coupon.CreatedAt.Format(time.RFC3339),
+		UpdatedAt:           coupon.UpdatedAt.Format(time.RFC3339),
+	}
+	if coupon.StartsAt != nil {
+		startsAt := coupon.StartsAt.Format(time.RFC3339)
+		response.StartsAt = &startsAt
+	}
+	if coupon.EndsAt != nil {
+		endsAt := coupon.EndsAt.Format(time.RFC3339)
+		response.EndsAt = &endsAt
+	}
+	return response
+}
+
+// GetCouponCodeResponse converts a CouponCode entity to CouponCodeResponse DTO
+func GetCouponCodeResponse(code entity.CouponCode) CouponCodeResponse {
+	return CouponCodeResponse{
+		ID:         code.ID,
+		Code:       code.Code,
+		UsageLimit: code.UsageLimit,
+		TimesUsed:  code.TimesUsed,
+		CreatedAt:  code.CreatedAt.Format(time.RFC3339),
+	}
+}
+
+// GetCartCouponResponses converts the codes of a quote to responses
+func GetCartCouponResponses(coupons []entity.QuoteCoupon) []CartCouponResponse {
+	responses := make([]CartCouponResponse, len(coupons))
+	for i, coupon := range coupons {
+		responses[i] = CartCouponResponse{
+			Code:    coupon.Code,
+			Name:    coupon.Name,
+			Type:    string(coupon.Type),
+			Applied: coupon.Applied,
+			Reason:  coupon.Reason,
+			Amount:  coupon.Amount,
+		}
+	}
+	return responses
+}
+
+func nonNilIDs(ids entity.CouponIDs) []string {
+	if ids == nil {
+		return []string{}
+	}
+	return ids
+}
@@ -0,0 +1,107 @@
+package dto
+
+import (
+	"time"
+
+	"backend-ecommerce/internal/application/entity"
+)
+
+// DigitalAssetUploadRequest represents the form fields sent with a digital asset upload
+type DigitalAssetUploadRequest struct {
+	DownloadLimit int `form:"download_limit" binding:"gte=0"`
+	AccessDays    int `form:"access_days" binding:"gte=0"`
+}
+
+// DigitalAssetUpdateRequest represents the download limits of a digital asset
+type DigitalAssetUpdateRequest struct {
+	DownloadLimit int `json:"download_limit" binding:"gte=0"`
+	AccessDays    int `json:"access_days" binding:"gte=0"`
+}
+
+// DigitalAssetResponse represents a digital asset returned to admins
+type DigitalAssetResponse struct {
+	ID            string `json:"id"`
+	ProductID     string `json:"product_id"`
+	FileName      string `json:"file_name"`
+	MimeType      string `json:"mime_type"`
+	FileSize      int64  `json:"file_size"`
+	DownloadLimit int    `json:"download_limit"`
+	AccessDays    int    `json:"access_days"`
+	CreatedAt     string `json:"created_at"`
+	UpdatedAt     string `json:"updated_at"`
+}
+
+// GetDigitalAssetResponse converts a DigitalAsset entity to DigitalAssetResponse DTO
+func GetDigitalAssetResponse(asset entity.DigitalAsset) DigitalAssetResponse {
+	return DigitalAssetResponse{
+		ID:            asset.ID,
+		ProductID:     asset.ProductID,
+		FileName:      asset.FileName,
+		MimeType:      asset.MimeType,
+		FileSize:      asset.FileSize,
+		DownloadLimit: asset.DownloadLimit,
+		AccessDays:    asset.AccessDays,
+		CreatedAt:     asset.CreatedAt.Format(time.RFC3339),
+		UpdatedAt:     asset.UpdatedAt.Format(time.RFC3339),
+	}
+}
+
+// LicenseKeyAddRequest represents licence keys added to a product's pool
+type LicenseKeyAddRequest struct {
+	Keys []string `json:"keys" binding:"required,min=1,dive,required,max=255"`
+}
+
+// LicenseKeyStockResponse represents the state of a product's licence key pool
+type LicenseKeyStockResponse struct {
+	ProductID string `json:"product_id"`
+	Total     int64  `json:"total"`
+	Assigned  int64  `json:"assigned"`
+	Available int64  `json:"available"`
+}
+
+// DownloadResponse represents a download the buyer is entitled to
+type DownloadResponse struct {
+	ID                 string   `json:"id"`
+	OrderID            string   `json:"order_id"`
+	ProductID          string   `json:"product_id"`
+	FileName           string   `json:"file_name"`
+	FileSize           int64    `json:"file_size"`
+	DownloadLimit      int      `json:"download_limit"`
+	DownloadCount      int      `json:"download_count"`
+	RemainingDownloads *int     `json:"remaining_downloads,omitempty"`
+	ExpiresAt          *string  `json:"expires_at,omitempty"`
+	IsAvailable        bool     `json:"is_available"`
+	LicenseKeys        []string `json:"license_keys,omitempty"`
+	CreatedAt          string   `json:"created_at"`
+}
+
+// GetDownloadResponse converts a DownloadEntitlement entity to DownloadResponse DTO.
+// The entitlement's Asset must be loaded.
+func GetDownloadResponse(entitlement entity.DownloadEntitlement, licenseKeys []string, t time.Time) DownloadResponse {
+	response := DownloadResponse{
+		ID:            entitlement.ID,
+		OrderID:       entitlement.OrderID,
+		DownloadLimit: entitlement.DownloadLimit,
+		DownloadCount: entitlement.DownloadCount,
+		ExpiresAt:     formatOptionalTime(entitlement.ExpiresAt),
+		IsAvailable:   entitlement.CanDownload(t),
+		LicenseKeys:   licenseKeys,
+		CreatedAt:     entitlement.CreatedAt.Format(time.RFC3339),
+	}
+	if entitlement.Asset != nil {
+		response.ProductID = entitlement.Asset.ProductID
+		response.FileName = entitlement.Asset.FileName
+		response.FileSize = entitlement.Asset.FileSize
+	}
+	if entitlement.DownloadLimit > 0 {
+		remaining := max(0, entitlement.DownloadLimit-entitlement.DownloadCount)
+		response.RemainingDownloads = &remaining
+	}
+	return response
+}
+
+// DownloadLinkResponse represents a short-lived download URL
+type DownloadLinkResponse struct {
+	URL       string `json:"url"`
+	ExpiresAt string `json:"expires_at"`
+}
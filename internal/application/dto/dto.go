@@ -75,6 +75,14 @@ func Fail(msg string) *ResponseDto {
     }
 }
 
+func FailData(msg string, data interface{}) *ResponseDto {
+    return &ResponseDto{
+        Code: 1,
+        Msg:  msg,
+        Data: data,
+    }
+}
+
 func FailCode(code int) *ResponseDto {
     return &ResponseDto{
         Code: code,
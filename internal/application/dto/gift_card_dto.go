@@ -0,0 +1,130 @@
+package dto
+
+import (
+	"time"
+
+	"backend-ecommerce/internal/application/entity"
+)
+
+// GiftCardIssueRequest represents the data needed for an admin to issue a
+// gift card. The code is returned once; with UserID it is also emailed to
+// that customer.
+type GiftCardIssueRequest struct {
+	Amount float64 `json:"amount" binding:"required,gt=0"`
+	// Currency defaults to the store currency
+	Currency  string     `json:"currency,omitempty" binding:"omitempty,len=3"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+	UserID    string     `json:"user_id,omitempty"`
+	Note      string     `json:"note,omitempty" binding:"max=255"`
+}
+
+// GiftCardUpdateRequest represents the data needed to update a gift card.
+// The balance only changes through the ledger.
+type GiftCardUpdateRequest struct {
+	IsActive  *bool      `json:"is_active,omitempty"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+	Note      *string    `json:"note,omitempty" binding:"omitempty,max=255"`
+}
+
+// GiftCardBalanceRequest represents a gift card code to check the balance of
+type GiftCardBalanceRequest struct {
+	Code string `json:"code" binding:"required,max=64"`
+}
+
+// GiftCardProductRequest represents the data needed to sell a digital
+// product as a gift card
+type GiftCardProductRequest struct {
+	// ValidityDays is how long cards stay usable after purchase, 0 for no expiry
+	ValidityDays int `json:"validity_days" binding:"min=0,max=3650"`
+}
+
+// GiftCardResponse represents a gift card returned to admins
+type GiftCardResponse struct {
+	ID             string  `json:"id"`
+	Last4          string  `json:"last4"`
+	Currency       string  `json:"currency"`
+	InitialBalance float64 `json:"initial_balance"`
+	Balance        float64 `json:"balance"`
+	ExpiresAt      *string `json:"expires_at,omitempty"`
+	IsActive       bool    `json:"is_active"`
+	Source         string  `json:"source"`
+	OrderItemID    *string `json:"order_item_id,omitempty"`
+	UserID         *string `json:"user_id,omitempty"`
+	Note           string  `json:"note,omitempty"`
+	CreatedAt      string  `json:"created_at"`
+	UpdatedAt      string  `json:"updated_at"`
+	// Transactions is the ledger of the card, oldest first, when requested
+	Transactions []GiftCardTransactionResponse `json:"transactions,omitempty"`
+}
+
+// GiftCardIssuedResponse is a newly issued gift card with its code, which
+// cannot be retrieved again
+type GiftCardIssuedResponse struct {
+	GiftCardResponse
+	Code string `json:"code"`
+}
+
+// GiftCardTransactionResponse represents an entry of a gift card's ledger
+type GiftCardTransactionResponse struct {
+	ID           string  `json:"id"`
+	Type         string  `json:"type"`
+	Amount       float64 `json:"amount"`
+	BalanceAfter float64 `json:"balance_after"`
+	OrderID      *string `json:"order_id,omitempty"`
+	PaymentID    *string `json:"payment_id,omitempty"`
+	Note         string  `json:"note,omitempty"`
+	CreatedAt    string  `json:"created_at"`
+}
+
+// GiftCardBalanceResponse is what a customer sees when checking a code
+type GiftCardBalanceResponse struct {
+	Last4     string  `json:"last4"`
+	Currency  string  `json:"currency"`
+	Balance   float64 `json:"balance"`
+	ExpiresAt *string `json:"expires_at,omitempty"`
+	// Usable is false when the card cannot pay for an order; Reason says why
+	Usable bool   `json:"usable"`
+	Reason string `json:"reason,omitempty"`
+}
+
+// GiftCardProductResponse represents a product sold as a gift card
+type GiftCardProductResponse struct {
+	ProductID    string `json:"product_id"`
+	ValidityDays int    `json:"validity_days"`
+}
+
+// GetGiftCardResponse converts a GiftCard entity to GiftCardResponse DTO,
+// with its ledger if loaded
+func GetGiftCardResponse(card entity.GiftCard) GiftCardResponse {
+	response := GiftCardResponse{
+		ID:             card.ID,
+		Last4:          card.Last4,
+		Currency:       card.Currency,
+		InitialBalance: card.InitialBalance,
+		Balance:        card.Balance,
+		IsActive:       card.IsActive,
+		Source:         string(card.Source),
+		OrderItemID:    card.OrderItemID,
+		UserID:         card.UserID,
+		Note:           card.Note,
+		CreatedAt:      card.CreatedAt.Format(time.RFC3339),
+		UpdatedAt:      card.UpdatedAt.Format(time.RFC3339),
+	}
+	if card.ExpiresAt != nil {
+		expiresAt := card.ExpiresAt.Format(time.RFC3339)
+		response.ExpiresAt = &expiresAt
+	}
+	for _, transaction := range card.Transactions {
+		response.Transactions = append(response.Transactions, GiftCardTransactionResponse{
+			ID:           transaction.ID,
+			Type:         string(transaction.Type),
+			Amount:       transaction.Amount,
+			BalanceAfter: transaction.BalanceAfter,
+			OrderID:      transaction.OrderID,
+			PaymentID:    transaction.PaymentID,
+			Note:         transaction.Note,
+			CreatedAt:    transaction.CreatedAt.Format(time.RFC3339),
+		})
+	}
+	return response
+}
@@ -0,0 +1,206 @@
+package dto
+
+import (
+	"time"
+
+	"backend-ecommerce/internal/application/entity"
+)
+
+// StockLevelUpdateRequest sets the quantity on hand of a product, or of one
+// of its variants, at a stock location, e.g. after a stock count
+type StockLevelUpdateRequest struct {
+	LocationID string  `json:"location_id" binding:"required"`
+	ProductID  string  `json:"product_id" binding:"required"`
+	VariantID  *string `json:"variant_id,omitempty"`
+	Quantity   int     `json:"quantity" binding:"min=0"`
+	Reason     string  `json:"reason" binding:"required,oneof=return restock adjustment damage"`
+	Note       string  `json:"note,omitempty" binding:"max=500"`
+}
+
+// ReorderSettingsRequest sets when the stock of a product or variant at a
+// location is reported as low and how much to reorder
+type ReorderSettingsRequest struct {
+	LocationID      string  `json:"location_id" binding:"required"`
+	ProductID       string  `json:"product_id" binding:"required"`
+	VariantID       *string `json:"variant_id,omitempty"`
+	ReorderPoint    int     `json:"reorder_point" binding:"min=0"`
+	ReorderQuantity int     `json:"reorder_quantity" binding:"min=0"`
+}
+
+// StockAdjustmentRequest changes the quantity on hand by a signed amount
+type StockAdjustmentRequest struct {
+	LocationID    string  `json:"location_id" binding:"required"`
+	ProductID     string  `json:"product_id" binding:"required"`
+	VariantID     *string `json:"variant_id,omitempty"`
+	Quantity      int     `json:"quantity" binding:"required"`
+	Reason        string  `json:"reason" binding:"required,oneof=return restock adjustment damage"`
+	ReferenceType string  `json:"reference_type,omitempty" binding:"omitempty,oneof=order purchase_order"`
+	ReferenceID   *string `json:"reference_id,omitempty"`
+	Note          string  `json:"note,omitempty" binding:"max=500"`
+}
+
+// StockTransferRequest moves stock from one location to another
+type StockTransferRequest struct {
+	FromLocationID string  `json:"from_location_id" binding:"required"`
+	ToLocationID   string  `json:"to_location_id" binding:"required,nefield=FromLocationID"`
+	ProductID      string  `json:"product_id" binding:"required"`
+	VariantID      *string `json:"variant_id,omitempty"`
+	Quantity       int     `json:"quantity" binding:"required,min=1"`
+	Note           string  `json:"note,omitempty" binding:"max=500"`
+}
+
+// StockMovementQuery filters the stock movement report. From and To are
+// dates (YYYY-MM-DD) and both inclusive.
+type StockMovementQuery struct {
+	SKU        string `form:"sku"`
+	ProductID  string `form:"product_id"`
+	LocationID string `form:"location_id"`
+	Reason     string `form:"reason" binding:"omitempty,oneof=sale return restock adjustment transfer damage"`
+	From       string `form:"from"`
+	To         string `form:"to"`
+	Page       int    `form:"page"`
+	PageSize   int    `form:"page_size"`
+}
+
+// StockMovementResponse represents a stock ledger entry returned to the client
+type StockMovementResponse struct {
+	ID            string   `json:"id"`
+	LocationID    string   `json:"location_id"`
+	LocationCode  string   `json:"location_code,omitempty"`
+	ProductID     string   `json:"product_id"`
+	VariantID     *string  `json:"variant_id,omitempty"`
+	SKU           string   `json:"sku"`
+	Quantity      int      `json:"quantity"`
+	UnitCost      *float64 `json:"unit_cost,omitempty"`
+	Reason        string   `json:"reason"`
+	ReferenceType string   `json:"reference_type,omitempty"`
+	ReferenceID   *string  `json:"reference_id,omitempty"`
+	ActorID       *string  `json:"actor_id,omitempty"`
+	Note          string   `json:"note,omitempty"`
+	CreatedAt     string   `json:"created_at"`
+}
+
+// StockMovementReportResponse represents a page of stock movements with the
+// totals of every movement matching the filters
+type StockMovementReportResponse struct {
+	Movements []StockMovementResponse `json:"movements"`
+	// Totals sums the quantities per reason
+	Totals map[string]int `json:"totals"`
+	Net    int            `json:"net"`
+}
+
+// StockDiscrepancyResponse represents a stock level that does not match its ledger
+type StockDiscrepancyResponse struct {
+	InventoryID    string  `json:"inventory_id"`
+	LocationID     string  `json:"location_id"`
+	ProductID      string  `json:"product_id"`
+	VariantID      *string `json:"variant_id,omitempty"`
+	Quantity       int     `json:"quantity"`
+	LedgerQuantity int     `json:"ledger_quantity"`
+	Difference     int     `json:"difference"`
+}
+
+// StockLevelResponse represents the stock of a product or variant at one location
+type StockLevelResponse struct {
+	ID           string  `json:"id"`
+	LocationID   string  `json:"location_id"`
+	LocationCode string  `json:"location_code,omitempty"`
+	LocationName string  `json:"location_name,omitempty"`
+	ProductID    string  `json:"product_id"`
+	VariantID    *string `json:"variant_id,omitempty"`
+	Quantity     int     `json:"quantity"`
+	Reserved     int     `json:"reserved"`
+	Available    int     `json:"available"`
+	// ReorderPoint and ReorderQuantity are 0 when no threshold is set
+	ReorderPoint    int     `json:"reorder_point"`
+	ReorderQuantity int     `json:"reorder_quantity"`
+	AverageCost     float64 `json:"average_cost"`
+	UpdatedAt       string  `json:"updated_at"`
+}
+
+// ProductStockResponse represents the stock of a product across all locations
+type ProductStockResponse struct {
+	ProductID string               `json:"product_id"`
+	Available int                  `json:"available"`
+	Levels    []StockLevelResponse `json:"levels"`
+}
+
+// LowStockItemResponse represents a stock level at or below its reorder point.
+// Sales velocity is measured per SKU over all locations, so DaysOfCover is
+// how long the SKU's stock at all active locations lasts at the recent rate
+// of sales; it is left out for SKUs without recent sales.
+type LowStockItemResponse struct {
+	InventoryID     string   `json:"inventory_id"`
+	LocationID      string   `json:"location_id"`
+	LocationCode    string   `json:"location_code"`
+	ProductID       string   `json:"product_id"`
+	VariantID       *string  `json:"variant_id,omitempty"`
+	SKU             string   `json:"sku"`
+	Name            string   `json:"name"`
+	Quantity        int      `json:"quantity"`
+	Reserved        int      `json:"reserved"`
+	Available       int      `json:"available"`
+	ReorderPoint    int      `json:"reorder_point"`
+	ReorderQuantity int      `json:"reorder_quantity"`
+	TotalAvailable  int      `json:"total_available"`
+	DailySales      float64  `json:"daily_sales"`
+	DaysOfCover     *float64 `json:"days_of_cover,omitempty"`
+}
+
+// InventoryResponse represents the stock shown in the storefront, summed over
+// all active locations
+type InventoryResponse struct {
+	Available int  `json:"available"`
+	InStock   bool `json:"in_stock"`
+}
+
+// GetStockLevelResponse converts an Inventory entity to StockLevelResponse DTO
+func GetStockLevelResponse(inventory entity.Inventory) StockLevelResponse {
+	inventory.CalculateAvailable()
+	response := StockLevelResponse{
+		ID:              inventory.ID,
+		LocationID:      inventory.LocationID,
+		ProductID:       inventory.ProductID,
+		VariantID:       inventory.VariantID,
+		Quantity:        inventory.Quantity,
+		Reserved:        inventory.Reserved,
+		Available:       inventory.Available,
+		ReorderPoint:    inventory.ReorderPoint,
+		ReorderQuantity: inventory.ReorderQuantity,
+		AverageCost:     inventory.AverageCost,
+		UpdatedAt:       inventory.UpdatedAt.Format(time.RFC3339),
+	}
+	if inventory.Location != nil {
+		response.LocationCode = inventory.Location.Code
+		response.LocationName = inventory.Location.Name
+	}
+	return response
+}
+
+// GetStockMovementResponse converts a StockMovement entity to StockMovementResponse DTO
+func GetStockMovementResponse(movement entity.StockMovement) StockMovementResponse {
+	response := StockMovementResponse{
+		ID:            movement.ID,
+		LocationID:    movement.LocationID,
+		ProductID:     movement.ProductID,
+		VariantID:     movement.VariantID,
+		SKU:           movement.SKU,
+		Quantity:      movement.Quantity,
+		UnitCost:      movement.UnitCost,
+		Reason:        string(movement.Reason),
+		ReferenceType: string(movement.ReferenceType),
+		ReferenceID:   movement.ReferenceID,
+		ActorID:       movement.ActorID,
+		Note:          movement.Note,
+		CreatedAt:     movement.CreatedAt.Format(time.RFC3339),
+	}
+	if movement.Location != nil {
+		response.LocationCode = movement.Location.Code
+	}
+	return response
+}
+
+// NewInventoryResponse builds the storefront stock summary of an available quantity
+func NewInventoryResponse(available int) *InventoryResponse {
+	return &InventoryResponse{Available: available, InStock: available > 0}
+}
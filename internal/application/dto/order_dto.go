@@ -1,11 +1,21 @@
 package dto
 
-// OrderCreateRequest represents the data needed to create a new order
+import (
+	"time"
+
+	"backend-ecommerce/internal/application/entity"
+	"backend-ecommerce/internal/application/tools"
+)
+
+// OrderCreateRequest represents the data needed to create an order from the
+// signed-in user's cart
 type OrderCreateRequest struct {
-	UserID            string   `json:"user_id" validate:"required,uuid4"`
-	ShippingAddressID string   `json:"shipping_address_id" validate:"required,uuid4"`
-	BillingAddressID  string   `json:"billing_address_id" validate:"required,uuid4"`
-	CartID            string   `json:"cart_id" validate:"required,uuid4"`
+	ShippingAddressID string `json:"shipping_address_id" binding:"required"`
+	// BillingAddressID defaults to the shipping address
+	BillingAddressID string `json:"billing_address_id,omitempty"`
+	// GiftCardCodes are spent in the order given until the order is paid;
+	// the rest is paid through Stripe
+	GiftCardCodes []string `json:"gift_card_codes,omitempty" binding:"max=5,dive,required,max=64"`
 }
 
 // OrderUpdateRequest represents the data needed to update an order
@@ -15,26 +25,107 @@ type OrderUpdateRequest struct {
 
 // OrderResponse represents the order data returned to the client
 type OrderResponse struct {
-	ID                string             `json:"id"`
-	UserID            string             `json:"user_id"`
-	Status            string             `json:"status"`
-	TotalAmount       float64            `json:"total_amount"`
-	Currency          string             `json:"currency"`
-	ShippingAddressID string             `json:"shipping_address_id"`
-	BillingAddressID  string             `json:"billing_address_id"`
-	Items             []OrderItemResponse `json:"items,omitempty"`
-	Payment           *PaymentResponse    `json:"payment,omitempty"`
-	CreatedAt         string             `json:"created_at"`
-	UpdatedAt         string             `json:"updated_at"`
+	ID                string                    `json:"id"`
+	UserID            string                    `json:"user_id"`
+	Status            string                    `json:"status"`
+	Subtotal          float64                   `json:"subtotal"`
+	DiscountTotal     float64                   `json:"discount_total"`
+	ShippingTotal     float64                   `json:"shipping_total"`
+	TaxTotal          float64                   `json:"tax_total"`
+	TotalAmount       float64                   `json:"total_amount"`
+	GiftCardTotal     float64                   `json:"gift_card_total"`
+	AmountDue         float64                   `json:"amount_due"`
+	Currency          string                    `json:"currency"`
+	ShippingAddressID string                    `json:"shipping_address_id"`
+	BillingAddressID  string                    `json:"billing_address_id"`
+	Items             []OrderItemResponse       `json:"items,omitempty"`
+	Adjustments       []OrderAdjustmentResponse `json:"adjustments,omitempty"`
+	Payment           *PaymentResponse          `json:"payment,omitempty"`
+	CreatedAt         string                    `json:"created_at"`
+	UpdatedAt         string                    `json:"updated_at"`
 }
 
 // OrderItemResponse represents an order item in the response
 type OrderItemResponse struct {
 	ID          string  `json:"id"`
 	ProductID   string  `json:"product_id"`
+	VariantID   *string `json:"variant_id,omitempty"`
 	ProductName string  `json:"product_name"`
 	SKU         string  `json:"sku,omitempty"`
 	Quantity    int     `json:"quantity"`
 	UnitPrice   float64 `json:"unit_price"`
 	TotalPrice  float64 `json:"total_price"`
+	// Components lists the products in a bundle line
+	Components []OrderItemResponse `json:"components,omitempty"`
+}
+
+// OrderAdjustmentResponse is a discount, shipping or tax adjustment of an order
+type OrderAdjustmentResponse struct {
+	Type        string  `json:"type"`
+	Source      string  `json:"source"`
+	Label       string  `json:"label"`
+	OrderItemID *string `json:"order_item_id,omitempty"`
+	Amount      float64 `json:"amount"`
+}
+
+// GetOrderResponse converts an Order entity to OrderResponse DTO. Only
+// top-level items are expected in Items, with bundle components preloaded.
+func GetOrderResponse(order entity.Order) OrderResponse {
+	response := OrderResponse{
+		ID:            order.ID,
+		Status:        string(order.Status),
+		Subtotal:      order.Subtotal,
+		DiscountTotal: order.DiscountTotal,
+		ShippingTotal: order.ShippingTotal,
+		TaxTotal:      order.TaxTotal,
+		TotalAmount:   order.TotalAmount,
+		GiftCardTotal: order.GiftCardTotal,
+		AmountDue:     tools.RoundPrice(order.TotalAmount - order.GiftCardTotal),
+		Currency:      order.Currency,
+		Items:         make([]OrderItemResponse, len(order.Items)),
+		Adjustments:   make([]OrderAdjustmentResponse, len(order.Adjustments)),
+		CreatedAt:     order.CreatedAt.Format(time.RFC3339),
+		UpdatedAt:     order.UpdatedAt.Format(time.RFC3339),
+	}
+	if order.UserID != nil {
+		response.UserID = *order.UserID
+	}
+	if order.ShippingAddressID != nil {
+		response.ShippingAddressID = *order.ShippingAddressID
+	}
+	if order.BillingAddressID != nil {
+		response.BillingAddressID = *order.BillingAddressID
+	}
+	for i, item := range order.Items {
+		response.Items[i] = getOrderItemResponse(item)
+	}
+	for i, adjustment := range order.Adjustments {
+		response.Adjustments[i] = OrderAdjustmentResponse{
+			Type:        string(adjustment.Type),
+			Source:      adjustment.Source,
+			Label:       adjustment.Label,
+			OrderItemID: adjustment.OrderItemID,
+			Amount:      adjustment.Amount,
+		}
+	}
+	return response
+}
+
+func getOrderItemResponse(item entity.OrderItem) OrderItemResponse {
+	response := OrderItemResponse{
+		ID:          item.ID,
+		VariantID:   item.VariantID,
+		ProductName: item.ProductName,
+		SKU:         item.SKU,
+		Quantity:    item.Quantity,
+		UnitPrice:   item.UnitPrice,
+		TotalPrice:  item.TotalPrice,
+	}
+	if item.ProductID != nil {
+		response.ProductID = *item.ProductID
+	}
+	for _, component := range item.Components {
+		response.Components = append(response.Components, getOrderItemResponse(component))
+	}
+	return response
 }
@@ -1,5 +1,11 @@
 package dto
 
+import (
+	"time"
+
+	"backend-ecommerce/internal/application/entity"
+)
+
 // PaymentCreateRequest represents the data needed to process a payment
 type PaymentCreateRequest struct {
 	OrderID     string  `json:"order_id" validate:"required,uuid4"`
@@ -18,6 +24,30 @@ type PaymentResponse struct {
 	Amount            float64           `json:"amount"`
 	Currency          string            `json:"currency"`
 	Status            string            `json:"status"`
+	ClientSecret      string            `json:"client_secret,omitempty"` // Confirms a card payment; only set when it is started
 	CreatedAt         string            `json:"created_at"`
 	UpdatedAt         string            `json:"updated_at"`
 }
+
+// PaymentRefundRequest represents the data needed to refund a payment. An
+// amount of 0 refunds everything not yet refunded.
+type PaymentRefundRequest struct {
+	Amount float64 `json:"amount" binding:"min=0"`
+	Reason string  `json:"reason,omitempty" binding:"max=255"`
+}
+
+// GetPaymentResponse converts a Payment entity to PaymentResponse DTO
+func GetPaymentResponse(payment entity.Payment) PaymentResponse {
+	return PaymentResponse{
+		ID:                payment.ID,
+		OrderID:           payment.OrderID,
+		Provider:          payment.Provider,
+		ProviderPaymentID: payment.ProviderPaymentID,
+		Amount:            payment.Amount,
+		Currency:          payment.Currency,
+		Status:            string(payment.Status),
+		ClientSecret:      payment.ClientSecret,
+		CreatedAt:         payment.CreatedAt.Format(time.RFC3339),
+		UpdatedAt:         payment.UpdatedAt.Format(time.RFC3339),
+	}
+}
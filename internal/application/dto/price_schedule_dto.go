@@ -0,0 +1,43 @@
+package dto
+
+import (
+	"time"
+
+	"backend-ecommerce/internal/application/entity"
+)
+
+// PriceScheduleCreateRequest represents the data needed to schedule a price
+type PriceScheduleCreateRequest struct {
+	VariantID *string    `json:"variant_id,omitempty"`
+	Type      string     `json:"type" binding:"required,oneof=sale permanent"`
+	Price     float64    `json:"price" binding:"required,gt=0"`
+	StartsAt  time.Time  `json:"starts_at" binding:"required"`
+	EndsAt    *time.Time `json:"ends_at,omitempty"`
+}
+
+// PriceScheduleResponse represents a scheduled price returned to the client
+type PriceScheduleResponse struct {
+	ID        string  `json:"id"`
+	ProductID string  `json:"product_id"`
+	VariantID *string `json:"variant_id,omitempty"`
+	Type      string  `json:"type"`
+	Price     float64 `json:"price"`
+	StartsAt  string  `json:"starts_at"`
+	EndsAt    *string `json:"ends_at,omitempty"`
+	AppliedAt *string `json:"applied_at,omitempty"`
+	CreatedAt string  `json:"created_at"`
+}
+
+func GetPriceScheduleResponse(schedule entity.PriceSchedule) PriceScheduleResponse {
+	return PriceScheduleResponse{
+		ID:        schedule.ID,
+		ProductID: schedule.ProductID,
+		VariantID: schedule.VariantID,
+		Type:      string(schedule.Type),
+		Price:     schedule.Price,
+		StartsAt:  schedule.StartsAt.Format(time.RFC3339),
+		EndsAt:    formatOptionalTime(schedule.EndsAt),
+		AppliedAt: formatOptionalTime(schedule.AppliedAt),
+		CreatedAt: schedule.CreatedAt.Format(time.RFC3339),
+	}
+}
@@ -0,0 +1,69 @@
+package dto
+
+import (
+	"time"
+
+	"backend-ecommerce/internal/application/entity"
+)
+
+// ProductAlertCreateRequest represents a subscription to a stock or price
+// change. The product is given directly, optionally with a variant, or
+// through a wishlist item.
+type ProductAlertCreateRequest struct {
+	Type           string   `json:"type" binding:"required,oneof=back_in_stock price_drop"`
+	ProductID      string   `json:"product_id,omitempty"`
+	VariantID      *string  `json:"variant_id,omitempty"`
+	WishlistItemID *string  `json:"wishlist_item_id,omitempty"`
+	TargetPrice    *float64 `json:"target_price,omitempty" binding:"omitempty,gt=0"`
+}
+
+// ProductAlertResponse represents an alert returned to the customer
+type ProductAlertResponse struct {
+	ID             string   `json:"id"`
+	Type           string   `json:"type"`
+	ProductID      string   `json:"product_id"`
+	ProductName    string   `json:"product_name,omitempty"`
+	VariantID      *string  `json:"variant_id,omitempty"`
+	VariantName    string   `json:"variant_name,omitempty"`
+	WishlistItemID *string  `json:"wishlist_item_id,omitempty"`
+	TargetPrice    *float64 `json:"target_price,omitempty"`
+	PriceAtSignup  float64  `json:"price_at_signup"`
+	Status         string   `json:"status"`
+	TriggeredAt    *string  `json:"triggered_at,omitempty"`
+	CreatedAt      string   `json:"created_at"`
+}
+
+// ProductAlertDemandResponse represents the active subscribers of one SKU
+type ProductAlertDemandResponse struct {
+	ProductID          string  `json:"product_id"`
+	VariantID          *string `json:"variant_id,omitempty"`
+	SKU                string  `json:"sku,omitempty"`
+	Name               string  `json:"name"`
+	BackInStockCount   int     `json:"back_in_stock_count"`
+	PriceDropCount     int     `json:"price_drop_count"`
+	Available          int     `json:"available"`
+	OldestSubscription string  `json:"oldest_subscription"`
+}
+
+// GetProductAlertResponse converts a ProductAlert entity to ProductAlertResponse DTO
+func GetProductAlertResponse(alert entity.ProductAlert) ProductAlertResponse {
+	response := ProductAlertResponse{
+		ID:             alert.ID,
+		Type:           string(alert.Type),
+		ProductID:      alert.ProductID,
+		VariantID:      alert.VariantID,
+		WishlistItemID: alert.WishlistItemID,
+		TargetPrice:    alert.TargetPrice,
+		PriceAtSignup:  alert.PriceAtSignup,
+		Status:         string(alert.Status),
+		TriggeredAt:    formatOptionalTime(alert.TriggeredAt),
+		CreatedAt:      alert.CreatedAt.Format(time.RFC3339),
+	}
+	if alert.Product != nil {
+		response.ProductName = alert.Product.Name
+	}
+	if alert.Variant != nil {
+		response.VariantName = alert.Variant.Name
+	}
+	return response
+}
@@ -1,15 +1,21 @@
 package dto
 
+import (
+	"time"
+
+	"backend-ecommerce/internal/application/entity"
+)
+
 // ProductCreateRequest represents the data needed to create a new product
 type ProductCreateRequest struct {
-	SKU         string   `json:"sku,omitempty" validate:"omitempty,max=100"`
-	Name        string   `json:"name" validate:"required,min=2,max=255"`
-	Slug        string   `json:"slug,omitempty" validate:"omitempty,slug,max=255"`
-	Description string   `json:"description,omitempty"`
-	Price       float64  `json:"price" validate:"required,gt=0"`
-	Currency    string   `json:"currency,omitempty" validate:"omitempty,iso4217"`
-	CategoryID  *string  `json:"category_id,omitempty" validate:"omitempty,uuid4"`
-	IsActive    *bool    `json:"is_active,omitempty"`
+	SKU         string  `json:"sku,omitempty" validate:"omitempty,max=100"`
+	Name        string  `json:"name" validate:"required,min=2,max=255"`
+	Slug        string  `json:"slug,omitempty" validate:"omitempty,slug,max=255"`
+	Description string  `json:"description,omitempty"`
+	Price       float64 `json:"price" validate:"required,gt=0"`
+	Currency    string  `json:"currency,omitempty" validate:"omitempty,iso4217"`
+	CategoryID  *string `json:"category_id,omitempty" validate:"omitempty,uuid4"`
+	IsActive    *bool   `json:"is_active,omitempty"`
 }
 
 // ProductUpdateRequest represents the data needed to update an existing product
@@ -22,22 +28,114 @@ type ProductUpdateRequest struct {
 	Currency    *string  `json:"currency,omitempty" validate:"omitempty,iso4217"`
 	CategoryID  *string  `json:"category_id,omitempty" validate:"omitempty,uuid4"`
 	IsActive    *bool    `json:"is_active,omitempty"`
+	// Attributes and Tags replace all existing values when set
+	Attributes map[string]string `json:"attributes,omitempty"`
+	Tags       []string          `json:"tags,omitempty"`
 }
 
 // ProductResponse represents the product data returned to the client
 type ProductResponse struct {
-	ID           string             `json:"id"`
-	SKU          string             `json:"sku,omitempty"`
-	Name         string             `json:"name"`
-	Slug         string             `json:"slug"`
-	Description  string             `json:"description,omitempty"`
-	Price        float64            `json:"price"`
-	Currency     string             `json:"currency"`
-	CategoryID   *string            `json:"category_id,omitempty"`
-	IsActive     bool               `json:"is_active"`
-	Category     *CategoryResponse  `json:"category,omitempty"`
-	Images       []ProductImageResponse `json:"images,omitempty"`
-	// Inventory    *InventoryResponse `json:"inventory,omitempty"`
-	CreatedAt    string             `json:"created_at"`
-	UpdatedAt    string             `json:"updated_at"`
+	ID             string                   `json:"id"`
+	SKU            string                   `json:"sku,omitempty"`
+	Name           string                   `json:"name"`
+	Slug           string                   `json:"slug"`
+	Description    string                   `json:"description,omitempty"`
+	Price          float64                  `json:"price"`
+	CompareAtPrice *float64                 `json:"compare_at_price,omitempty"`
+	SaleEndsAt     *string                  `json:"sale_ends_at,omitempty"`
+	Currency       string                   `json:"currency"`
+	CategoryID     *string                  `json:"category_id,omitempty"`
+	IsActive       bool                     `json:"is_active"`
+	Type           string                   `json:"type"`
+	Attributes     map[string]string        `json:"attributes,omitempty"`
+	Tags           []string                 `json:"tags,omitempty"`
+	Status         string                   `json:"status"`
+	PublishAt      *string                  `json:"publish_at,omitempty"`
+	UnpublishAt    *string                  `json:"unpublish_at,omitempty"`
+	PublishedAt    *string                  `json:"published_at,omitempty"`
+	Category       *CategoryResponse        `json:"category,omitempty"`
+	Images         []ProductImageResponse   `json:"images,omitempty"`
+	Variants       []ProductVariantResponse `json:"variants,omitempty"`
+	Bundle         *BundleResponse          `json:"bundle,omitempty"`
+	// Recommendations is only filled in on the product detail page
+	Recommendations *ProductRecommendationsResponse `json:"recommendations,omitempty"`
+	// Inventory is left out for digital products, which are never out of stock
+	Inventory *InventoryResponse `json:"inventory,omitempty"`
+	CreatedAt string             `json:"created_at"`
+	UpdatedAt string             `json:"updated_at"`
+}
+
+// ProductVariantResponse represents a product variant returned to the client
+type ProductVariantResponse struct {
+	ID             string   `json:"id"`
+	SKU            string   `json:"sku,omitempty"`
+	Name           string   `json:"name"`
+	Price          float64  `json:"price"`
+	CompareAtPrice *float64 `json:"compare_at_price,omitempty"`
+	SaleEndsAt     *string  `json:"sale_ends_at,omitempty"`
+	SortOrder      int      `json:"sort_order"`
+	// Inventory is only set for variants stocked on their own
+	Inventory *InventoryResponse `json:"inventory,omitempty"`
+}
+
+// GetProductResponse converts a product and its resolved prices into a response.
+// variantPrices is keyed by variant ID; inactive variants are left out.
+func GetProductResponse(product entity.Product, price entity.EffectivePrice, variantPrices map[string]entity.EffectivePrice) ProductResponse {
+	images := make([]ProductImageResponse, len(product.Images))
+	for i, image := range product.Images {
+		images[i] = GetProductImageResponse(image)
+	}
+
+	variants := make([]ProductVariantResponse, 0, len(product.Variants))
+	for _, variant := range product.Variants {
+		if !variant.IsActive {
+			continue
+		}
+		variantPrice, ok := variantPrices[variant.ID]
+		if !ok {
+			variantPrice = entity.EffectivePrice{Price: variant.Price}
+		}
+		variants = append(variants, ProductVariantResponse{
+			ID:             variant.ID,
+			SKU:            variant.SKU,
+			Name:           variant.Name,
+			Price:          variantPrice.Price,
+			CompareAtPrice: variantPrice.CompareAtPrice,
+			SaleEndsAt:     formatOptionalTime(variantPrice.SaleEndsAt),
+			SortOrder:      variant.SortOrder,
+		})
+	}
+
+	return ProductResponse{
+		ID:             product.ID,
+		SKU:            product.SKU,
+		Name:           product.Name,
+		Slug:           product.Slug,
+		Description:    product.Description,
+		Price:          price.Price,
+		CompareAtPrice: price.CompareAtPrice,
+		SaleEndsAt:     formatOptionalTime(price.SaleEndsAt),
+		Currency:       product.Currency,
+		CategoryID:     product.CategoryID,
+		IsActive:       product.IsActive,
+		Type:           string(product.Type),
+		Attributes:     product.Attributes,
+		Tags:           product.Tags,
+		Status:         string(product.Status),
+		PublishAt:      formatOptionalTime(product.PublishAt),
+		UnpublishAt:    formatOptionalTime(product.UnpublishAt),
+		PublishedAt:    formatOptionalTime(product.PublishedAt),
+		Images:         images,
+		Variants:       variants,
+		CreatedAt:      product.CreatedAt.Format(time.RFC3339),
+		UpdatedAt:      product.UpdatedAt.Format(time.RFC3339),
+	}
+}
+
+func formatOptionalTime(t *time.Time) *string {
+	if t == nil {
+		return nil
+	}
+	formatted := t.Format(time.RFC3339)
+	return &formatted
 }
@@ -1,5 +1,11 @@
 package dto
 
+import (
+	"time"
+
+	"backend-ecommerce/internal/application/entity"
+)
+
 // ProductImageCreateRequest represents the data needed to add an image to a product
 type ProductImageCreateRequest struct {
 	ProductID string `json:"product_id" validate:"required,uuid4"`
@@ -15,13 +21,62 @@ type ProductImageUpdateRequest struct {
 	SortOrder *int    `json:"sort_order,omitempty"`
 }
 
+// ProductImageReorderRequest represents the new display order of a product's images
+type ProductImageReorderRequest struct {
+	ImageIDs []string `json:"image_ids" binding:"required,min=1,dive,required"`
+}
+
 // ProductImageResponse represents the product image data returned to the client
 type ProductImageResponse struct {
-	ID        string `json:"id"`
-	ProductID string `json:"product_id"`
-	URL       string `json:"url"`
-	AltText   string `json:"alt_text,omitempty"`
-	SortOrder int    `json:"sort_order"`
-	CreatedAt string `json:"created_at"`
-	UpdatedAt string `json:"updated_at"`
+	ID         string                          `json:"id"`
+	ProductID  string                          `json:"product_id"`
+	URL        string                          `json:"url"`
+	AltText    string                          `json:"alt_text,omitempty"`
+	SortOrder  int                             `json:"sort_order"`
+	MimeType   string                          `json:"mime_type,omitempty"`
+	Width      int                             `json:"width"`
+	Height     int                             `json:"height"`
+	FileSize   int64                           `json:"file_size"`
+	Renditions []ProductImageRenditionResponse `json:"renditions,omitempty"`
+	CreatedAt  string                          `json:"created_at"`
+	UpdatedAt  string                          `json:"updated_at"`
+}
+
+// ProductImageRenditionResponse represents a resized copy of a product image
+type ProductImageRenditionResponse struct {
+	Name     string `json:"name"`
+	Format   string `json:"format"`
+	URL      string `json:"url"`
+	Width    int    `json:"width"`
+	Height   int    `json:"height"`
+	FileSize int64  `json:"file_size"`
+}
+
+func GetProductImageResponse(image entity.ProductImage) ProductImageResponse {
+	renditions := make([]ProductImageRenditionResponse, len(image.Renditions))
+	for i, rendition := range image.Renditions {
+		renditions[i] = ProductImageRenditionResponse{
+			Name:     rendition.Name,
+			Format:   rendition.Format,
+			URL:      rendition.URL,
+			Width:    rendition.Width,
+			Height:   rendition.Height,
+			FileSize: rendition.FileSize,
+		}
+	}
+
+	return ProductImageResponse{
+		ID:         image.ID,
+		ProductID:  image.ProductID,
+		URL:        image.URL,
+		AltText:    image.AltText,
+		SortOrder:  image.SortOrder,
+		MimeType:   image.MimeType,
+		Width:      image.Width,
+		Height:     image.Height,
+		FileSize:   image.FileSize,
+		Renditions: renditions,
+		CreatedAt:  image.CreatedAt.Format(time.RFC3339),
+		UpdatedAt:  image.UpdatedAt.Format(time.RFC3339),
+	}
 }
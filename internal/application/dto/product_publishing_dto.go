@@ -0,0 +1,24 @@
+package dto
+
+import (
+	"time"
+)
+
+// ProductStatusUpdateRequest represents a move of a product through the publishing workflow
+type ProductStatusUpdateRequest struct {
+	Status string `json:"status" binding:"required,oneof=draft in_review published"`
+}
+
+// ProductPublicationScheduleRequest represents scheduled publish and unpublish
+// times. A nil time clears that part of the schedule.
+type ProductPublicationScheduleRequest struct {
+	PublishAt   *time.Time `json:"publish_at,omitempty"`
+	UnpublishAt *time.Time `json:"unpublish_at,omitempty"`
+}
+
+// ProductPreviewTokenResponse represents a token that lets its holder view an unpublished product
+type ProductPreviewTokenResponse struct {
+	Token     string `json:"token"`
+	URL       string `json:"url"`
+	ExpiresAt string `json:"expires_at"`
+}
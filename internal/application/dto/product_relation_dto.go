@@ -0,0 +1,79 @@
+package dto
+
+import (
+	"time"
+
+	"backend-ecommerce/internal/application/entity"
+)
+
+// ProductRelationsUpdateRequest represents the products linked to a product
+// with one relation type, in display order
+type ProductRelationsUpdateRequest struct {
+	ProductIDs []string `json:"product_ids" binding:"dive,required"`
+}
+
+// ProductRelationResponse represents a hand-curated relation returned to admins
+type ProductRelationResponse struct {
+	ID               string `json:"id"`
+	RelatedProductID string `json:"related_product_id"`
+	Name             string `json:"name,omitempty"`
+	SKU              string `json:"sku,omitempty"`
+	Type             string `json:"type"`
+	Position         int    `json:"position"`
+}
+
+// FrequentlyBoughtTogetherResponse represents a computed association returned to admins
+type FrequentlyBoughtTogetherResponse struct {
+	RelatedProductID string  `json:"related_product_id"`
+	Name             string  `json:"name,omitempty"`
+	OrderCount       int     `json:"order_count"`
+	Support          float64 `json:"support"`
+	Confidence       float64 `json:"confidence"`
+	Lift             float64 `json:"lift"`
+	ComputedAt       string  `json:"computed_at"`
+}
+
+// ProductRelationsResponse represents all relations of a product as seen by admins
+type ProductRelationsResponse struct {
+	Relations                []ProductRelationResponse          `json:"relations"`
+	FrequentlyBoughtTogether []FrequentlyBoughtTogetherResponse `json:"frequently_bought_together"`
+}
+
+// ProductRecommendationsResponse represents the products suggested alongside a
+// product or a cart. Only published products are included.
+type ProductRecommendationsResponse struct {
+	Related                  []ProductResponse `json:"related,omitempty"`
+	Upsells                  []ProductResponse `json:"upsells,omitempty"`
+	CrossSells               []ProductResponse `json:"cross_sells,omitempty"`
+	Accessories              []ProductResponse `json:"accessories,omitempty"`
+	FrequentlyBoughtTogether []ProductResponse `json:"frequently_bought_together,omitempty"`
+}
+
+// GetProductRelationResponse converts a ProductRelation entity to ProductRelationResponse DTO
+func GetProductRelationResponse(relation entity.ProductRelation) ProductRelationResponse {
+	response := ProductRelationResponse{
+		ID:               relation.ID,
+		RelatedProductID: relation.RelatedProductID,
+		Type:             string(relation.Type),
+		Position:         relation.Position,
+	}
+	if relation.RelatedProduct != nil {
+		response.Name = relation.RelatedProduct.Name
+		response.SKU = relation.RelatedProduct.SKU
+	}
+	return response
+}
+
+// GetFrequentlyBoughtTogetherResponse converts a FrequentlyBoughtTogether entity
+// to FrequentlyBoughtTogetherResponse DTO
+func GetFrequentlyBoughtTogetherResponse(association entity.FrequentlyBoughtTogether, name string) FrequentlyBoughtTogetherResponse {
+	return FrequentlyBoughtTogetherResponse{
+		RelatedProductID: association.RelatedProductID,
+		Name:             name,
+		OrderCount:       association.OrderCount,
+		Support:          association.Support,
+		Confidence:       association.Confidence,
+		Lift:             association.Lift,
+		ComputedAt:       association.ComputedAt.Format(time.RFC3339),
+	}
+}
@@ -0,0 +1,52 @@
+package dto
+
+import (
+	"time"
+
+	"backend-ecommerce/internal/application/entity"
+	"backend-ecommerce/internal/application/tools"
+)
+
+// ProductRevisionResponse represents a revision in a product's history
+type ProductRevisionResponse struct {
+	ID        string                  `json:"id"`
+	ProductID string                  `json:"product_id"`
+	Version   int                     `json:"version"`
+	Summary   string                  `json:"summary"`
+	ChangedBy *string                 `json:"changed_by,omitempty"`
+	Snapshot  *entity.ProductSnapshot `json:"snapshot,omitempty"`
+	CreatedAt string                  `json:"created_at"`
+}
+
+// GetProductRevisionResponse converts a ProductRevision entity to ProductRevisionResponse DTO.
+// The snapshot is only included when withSnapshot is set.
+func GetProductRevisionResponse(revision entity.ProductRevision, withSnapshot bool) ProductRevisionResponse {
+	response := ProductRevisionResponse{
+		ID:        revision.ID,
+		ProductID: revision.ProductID,
+		Version:   revision.Version,
+		Summary:   revision.Summary,
+		ChangedBy: revision.ChangedBy,
+		CreatedAt: revision.CreatedAt.Format(time.RFC3339),
+	}
+	if withSnapshot {
+		response.Snapshot = &revision.Snapshot
+	}
+	return response
+}
+
+// ProductRevisionDiffResponse represents the changes between two revisions
+type ProductRevisionDiffResponse struct {
+	ProductID   string         `json:"product_id"`
+	FromVersion int            `json:"from_version"`
+	ToVersion   int            `json:"to_version"`
+	Changes     []tools.Change `json:"changes"`
+}
+
+// ProductRevisionRestoreResponse represents the outcome of restoring a revision
+type ProductRevisionRestoreResponse struct {
+	RestoredVersion int                     `json:"restored_version"`
+	Revision        ProductRevisionResponse `json:"revision"`
+	// MissingImages lists images of the restored revision whose files have since been deleted
+	MissingImages []string `json:"missing_images,omitempty"`
+}
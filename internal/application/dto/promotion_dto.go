@@ -0,0 +1,230 @@
+package dto
+
+import (
+	"time"
+
+	"backend-ecommerce/internal/application/entity"
+)
+
+// PromotionRulesRequest represents the conditions and reward of a
+// promotion. Which fields are needed depends on the type:
+//   - buy_x_get_y: buy_quantity, get_quantity and percent, with optional
+//     reward products or categories
+//   - spend_threshold: tiers
+//   - multi_buy: buy_quantity and get_quantity, e.g. 3 and 1 for 3 for 2
+//   - bundle: two or more product_ids, and percent or amount
+type PromotionRulesRequest struct {
+	ProductIDs        []string               `json:"product_ids,omitempty" binding:"dive,required"`
+	CategoryIDs       []string               `json:"category_ids,omitempty" binding:"dive,required"`
+	BuyQuantity       int                    `json:"buy_quantity,omitempty" binding:"min=0"`
+	GetQuantity       int                    `json:"get_quantity,omitempty" binding:"min=0"`
+	RewardProductIDs  []string               `json:"reward_product_ids,omitempty" binding:"dive,required"`
+	RewardCategoryIDs []string               `json:"reward_category_ids,omitempty" binding:"dive,required"`
+	Percent           float64                `json:"percent,omitempty" binding:"min=0,max=100"`
+	Amount            float64                `json:"amount,omitempty" binding:"min=0"`
+	Tiers             []PromotionTierRequest `json:"tiers,omitempty" binding:"dive"`
+}
+
+// PromotionTierRequest represents a step of a spend threshold promotion
+type PromotionTierRequest struct {
+	MinSubtotal float64 `json:"min_subtotal" binding:"gt=0"`
+	Percent     float64 `json:"percent" binding:"gt=0,max=100"`
+}
+
+// PromotionCreateRequest represents the data needed to create a promotion
+type PromotionCreateRequest struct {
+	Name        string                `json:"name" binding:"required,min=2,max=255"`
+	Description string                `json:"description,omitempty"`
+	Type        string                `json:"type" binding:"required,oneof=buy_x_get_y spend_threshold multi_buy bundle"`
+	Rules       PromotionRulesRequest `json:"rules"`
+	// Priority orders evaluation, highest first
+	Priority  int        `json:"priority"`
+	Exclusive bool       `json:"exclusive"`
+	StartsAt  *time.Time `json:"starts_at,omitempty"`
+	EndsAt    *time.Time `json:"ends_at,omitempty"`
+	// IsActive defaults to true
+	IsActive *bool `json:"is_active,omitempty"`
+}
+
+// PromotionUpdateRequest represents the data needed to update a promotion.
+// Rules, when given, replace the rules as a whole; the type cannot change.
+type PromotionUpdateRequest struct {
+	Name        *string                `json:"name,omitempty" binding:"omitempty,min=2,max=255"`
+	Description *string                `json:"description,omitempty"`
+	Rules       *PromotionRulesRequest `json:"rules,omitempty"`
+	Priority    *int                   `json:"priority,omitempty"`
+	Exclusive   *bool                  `json:"exclusive,omitempty"`
+	StartsAt    *time.Time             `json:"starts_at,omitempty"`
+	EndsAt      *time.Time             `json:"ends_at,omitempty"`
+	IsActive    *bool                  `json:"is_active,omitempty"`
+}
+
+// PromotionSimulateRequest represents a sample cart to test a promotion
+// against: a saved promotion by PromotionID, or a draft given in Promotion.
+// With IncludeActive the active promotions are evaluated too, to see how
+// priority and exclusivity play out.
+type PromotionSimulateRequest struct {
+	PromotionID   string                  `json:"promotion_id,omitempty"`
+	Promotion     *PromotionCreateRequest `json:"promotion,omitempty"`
+	Items         []CartItemAddRequest    `json:"items" binding:"required,min=1,max=100,dive"`
+	IncludeActive bool                    `json:"include_active"`
+}
+
+// PromotionResponse represents a promotion returned to the client
+type PromotionResponse struct {
+	ID          string                `json:"id"`
+	Name        string                `json:"name"`
+	Description string                `json:"description,omitempty"`
+	Type        string                `json:"type"`
+	Rules       entity.PromotionRules `json:"rules"`
+	Priority    int                   `json:"priority"`
+	Exclusive   bool                  `json:"exclusive"`
+	StartsAt    *string               `json:"starts_at,omitempty"`
+	EndsAt      *string               `json:"ends_at,omitempty"`
+	IsActive    bool                  `json:"is_active"`
+	CreatedAt   string                `json:"created_at"`
+	UpdatedAt   string                `json:"updated_at"`
+}
+
+// CartPromotionResponse represents an automatic promotion evaluated against
+// a cart
+type CartPromotionResponse struct {
+	PromotionID string `json:"promotion_id"`
+	Name        string `json:"name"`
+	Type        string `json:"type"`
+	// Applied is false when the cart does not qualify, or an exclusive
+	// promotion prevented it; Reason says why
+	Applied bool    `json:"applied"`
+	Reason  string  `json:"reason,omitempty"`
+	Amount  float64 `json:"amount"`
+}
+
+// LinePromotionResponse explains the discount of a promotion on a cart line
+type LinePromotionResponse struct {
+	PromotionID string  `json:"promotion_id"`
+	Name        string  `json:"name"`
+	Explanation string  `json:"explanation"`
+	Amount      float64 `json:"amount"`
+}
+
+// PromotionSimulationResponse is a sample cart priced with the promotions
+// tested. Lines have no cart item ID, so adjustments refer to them as
+// "line-1", "line-2" and so on, in the order of the request.
+type PromotionSimulationResponse struct {
+	Lines      []PromotionSimulationLineResponse `json:"lines"`
+	Promotions []CartPromotionResponse           `json:"promotions"`
+	Totals     CartTotalsResponse                `json:"totals"`
+}
+
+// PromotionSimulationLineResponse represents a priced line of a sample cart
+type PromotionSimulationLineResponse struct {
+	LineID     string                  `json:"line_id"`
+	ProductID  string                  `json:"product_id"`
+	VariantID  *string                 `json:"variant_id,omitempty"`
+	Name       string                  `json:"name"`
+	Quantity   int                     `json:"quantity"`
+	UnitPrice  float64                 `json:"unit_price"`
+	Subtotal   float64                 `json:"subtotal"`
+	Discount   float64                 `json:"discount"`
+	Total      float64                 `json:"total"`
+	Offered    bool                    `json:"offered"`
+	Promotions []LinePromotionResponse `json:"promotions"`
+}
+
+// GetPromotionRules converts the rules of a request to the stored rules
+func GetPromotionRules(req PromotionRulesRequest) entity.PromotionRules {
+	rules := entity.PromotionRules{
+		ProductIDs:        req.ProductIDs,
+		CategoryIDs:       req.CategoryIDs,
+		BuyQuantity:       req.BuyQuantity,
+		GetQuantity:       req.GetQuantity,
+		RewardProductIDs:  req.RewardProductIDs,
+		RewardCategoryIDs: req.RewardCategoryIDs,
+		Percent:           req.Percent,
+		Amount:            req.Amount,
+	}
+	for _, tier := range req.Tiers {
+		rules.Tiers = append(rules.Tiers, entity.PromotionTier{MinSubtotal: tier.MinSubtotal, Percent: tier.Percent})
+	}
+	return rules
+}
+
+// GetPromotionResponse converts a Promotion entity to PromotionResponse DTO
+func GetPromotionResponse(promotion entity.Promotion) PromotionResponse {
+	response := PromotionResponse{
+		ID:          promotion.ID,
+		Name:        promotion.Name,
+		Description: promotion.Description,
+		Type:        string(promotion.Type),
+		Rules:       promotion.Rules,
+		Priority:    promotion.Priority,
+		Exclusive:   promotion.Exclusive,
+		IsActive:    promotion.IsActive,
+		CreatedAt:   promotion.CreatedAt.Format(time.RFC3339),
+		UpdatedAt:   promotion.UpdatedAt.Format(time.RFC3339),
+	}
+	if promotion.StartsAt != nil {
+		startsAt := promotion.StartsAt.Format(time.RFC3339)
+		response.StartsAt = &startsAt
+	}
+	if promotion.EndsAt != nil {
+		endsAt := promotion.EndsAt.Format(time.RFC3339)
+		response.EndsAt = &endsAt
+	}
+	return response
+}
+
+// GetCartPromotionResponses converts the promotions of a quote to responses
+func GetCartPromotionResponses(promotions []entity.QuotePromotion) []CartPromotionResponse {
+	responses := make([]CartPromotionResponse, len(promotions))
+	for i, promotion := range promotions {
+		responses[i] = CartPromotionResponse{
+			PromotionID: promotion.PromotionID,
+			Name:        promotion.Name,
+			Type:        string(promotion.Type),
+			Applied:     promotion.Applied,
+			Reason:      promotion.Reason,
+			Amount:      promotion.Amount,
+		}
+	}
+	return responses
+}
+
+// GetLinePromotionResponses converts the promotions of a quote line to responses
+func GetLinePromotionResponses(promotions []entity.LinePromotion) []LinePromotionResponse {
+	responses := make([]LinePromotionResponse, len(promotions))
+	for i, promotion := range promotions {
+		responses[i] = LinePromotionResponse{
+			PromotionID: promotion.PromotionID,
+			Name:        promotion.Name,
+			Explanation: promotion.Explanation,
+			Amount:      promotion.Amount,
+		}
+	}
+	return responses
+}
+
+// GetPromotionSimulationResponse converts the quote of a sample cart to a response
+func GetPromotionSimulationResponse(quote entity.PriceQuote) PromotionSimulationResponse {
+	lines := make([]PromotionSimulationLineResponse, len(quote.Lines))
+	for i, line := range quote.Lines {
+		lines[i] = PromotionSimulationLineResponse{
+			LineID:     line.LineID,
+			ProductID:  line.ProductID,
+			VariantID:  line.VariantID,
+			Name:       line.Name,
+			Quantity:   line.Quantity,
+			UnitPrice:  line.UnitPrice,
+			Subtotal:   line.Subtotal,
+			Discount:   line.Discount,
+			Total:      line.Subtotal - line.Discount,
+			Offered:    line.Offered,
+			Promotions: GetLinePromotionResponses(line.Promotions),
+		}
+	}
+	return PromotionSimulationResponse{
+		Lines:      lines,
+		Promotions: GetCartPromotionResponses(quote.Promotions),
+		Totals:     GetCartTotalsResponse(quote),
+	}
+}
@@ -0,0 +1,131 @@
+package dto
+
+import (
+	"math"
+	"time"
+
+	"backend-ecommerce/internal/application/entity"
+	"backend-ecommerce/internal/application/tools"
+)
+
+// PurchaseOrderRequest represents the data needed to create a purchase order
+// or to replace a draft. ExpectedAt is a date (YYYY-MM-DD).
+type PurchaseOrderRequest struct {
+	SupplierID   string                     `json:"supplier_id" binding:"required"`
+	LocationID   string                     `json:"location_id" binding:"required"`
+	Currency     string                     `json:"currency,omitempty" binding:"omitempty,len=3"`
+	ShippingCost float64                    `json:"shipping_cost" binding:"gte=0"`
+	OtherCosts   float64                    `json:"other_costs" binding:"gte=0"`
+	Notes        string                     `json:"notes,omitempty"`
+	ExpectedAt   *string                    `json:"expected_at,omitempty"`
+	Lines        []PurchaseOrderLineRequest `json:"lines" binding:"required,min=1,dive"`
+}
+
+// PurchaseOrderLineRequest represents a SKU to order
+type PurchaseOrderLineRequest struct {
+	ProductID string  `json:"product_id" binding:"required"`
+	VariantID *string `json:"variant_id,omitempty"`
+	Quantity  int     `json:"quantity" binding:"required,min=1"`
+	UnitCost  float64 `json:"unit_cost" binding:"gte=0"`
+}
+
+// GoodsReceiptRequest records goods arriving against a purchase order. The
+// location defaults to the order's delivery location.
+type GoodsReceiptRequest struct {
+	LocationID *string                   `json:"location_id,omitempty"`
+	Lines      []GoodsReceiptLineRequest `json:"lines" binding:"required,min=1,dive"`
+	Note       string                    `json:"note,omitempty" binding:"max=500"`
+}
+
+// GoodsReceiptLineRequest represents the units of one line that arrived
+type GoodsReceiptLineRequest struct {
+	LineID   string `json:"line_id" binding:"required"`
+	Quantity int    `json:"quantity" binding:"required,min=1"`
+}
+
+// PurchaseOrderResponse represents a purchase order returned to the client
+type PurchaseOrderResponse struct {
+	ID           string                      `json:"id"`
+	Number       string                      `json:"number"`
+	SupplierID   string                      `json:"supplier_id"`
+	SupplierName string                      `json:"supplier_name,omitempty"`
+	LocationID   string                      `json:"location_id"`
+	LocationCode string                      `json:"location_code,omitempty"`
+	Status       string                      `json:"status"`
+	Currency     string                      `json:"currency"`
+	Subtotal     float64                     `json:"subtotal"`
+	ShippingCost float64                     `json:"shipping_cost"`
+	OtherCosts   float64                     `json:"other_costs"`
+	Total        float64                     `json:"total"`
+	Notes        string                      `json:"notes,omitempty"`
+	ExpectedAt   *string                     `json:"expected_at,omitempty"`
+	SentAt       *string                     `json:"sent_at,omitempty"`
+	ReceivedAt   *string                     `json:"received_at,omitempty"`
+	ClosedAt     *string                     `json:"closed_at,omitempty"`
+	Lines        []PurchaseOrderLineResponse `json:"lines"`
+	CreatedAt    string                      `json:"created_at"`
+	UpdatedAt    string                      `json:"updated_at"`
+}
+
+// PurchaseOrderLineResponse represents a purchase order line in the response
+type PurchaseOrderLineResponse struct {
+	ID               string  `json:"id"`
+	ProductID        string  `json:"product_id"`
+	VariantID        *string `json:"variant_id,omitempty"`
+	SKU              string  `json:"sku"`
+	Quantity         int     `json:"quantity"`
+	ReceivedQuantity int     `json:"received_quantity"`
+	Outstanding      int     `json:"outstanding"`
+	UnitCost         float64 `json:"unit_cost"`
+	LandedUnitCost   float64 `json:"landed_unit_cost"`
+}
+
+// GetPurchaseOrderResponse converts a PurchaseOrder entity to PurchaseOrderResponse DTO
+func GetPurchaseOrderResponse(order entity.PurchaseOrder) PurchaseOrderResponse {
+	subtotal := tools.RoundPrice(order.Subtotal())
+	response := PurchaseOrderResponse{
+		ID:           order.ID,
+		Number:       order.Number,
+		SupplierID:   order.SupplierID,
+		LocationID:   order.LocationID,
+		Status:       string(order.Status),
+		Currency:     order.Currency,
+		Subtotal:     subtotal,
+		ShippingCost: order.ShippingCost,
+		OtherCosts:   order.OtherCosts,
+		Total:        tools.RoundPrice(subtotal + order.ShippingCost + order.OtherCosts),
+		Notes:        order.Notes,
+		ExpectedAt:   formatOptionalTime(order.ExpectedAt),
+		SentAt:       formatOptionalTime(order.SentAt),
+		ReceivedAt:   formatOptionalTime(order.ReceivedAt),
+		ClosedAt:     formatOptionalTime(order.ClosedAt),
+		Lines:        make([]PurchaseOrderLineResponse, len(order.Lines)),
+		CreatedAt:    order.CreatedAt.Format(time.RFC3339),
+		UpdatedAt:    order.UpdatedAt.Format(time.RFC3339),
+	}
+	if order.Supplier != nil {
+		response.SupplierName = order.Supplier.Name
+	}
+	if order.Location != nil {
+		response.LocationCode = order.Location.Code
+	}
+	for i, line := range order.Lines {
+		landed := line.LandedUnitCost
+		if line.ReceivedQuantity == 0 {
+			// Until goods arrive, show what the landed cost would be today
+			landed = order.LandedUnitCost(line)
+		}
+		response.Lines[i] = PurchaseOrderLineResponse{
+			ID:               line.ID,
+			ProductID:        line.ProductID,
+			VariantID:        line.VariantID,
+			SKU:              line.SKU,
+			Quantity:         line.Quantity,
+			ReceivedQuantity: line.ReceivedQuantity,
+			Outstanding:      line.Outstanding(),
+			UnitCost:         line.UnitCost,
+			LandedUnitCost:   math.Round(landed*10000) / 10000,
+		}
+	}
+	return response
+}
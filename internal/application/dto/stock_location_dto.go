@@ -0,0 +1,70 @@
+package dto
+
+import (
+	"time"
+
+	"backend-ecommerce/internal/application/entity"
+)
+
+// StockLocationCreateRequest represents the data needed to create a stock location
+type StockLocationCreateRequest struct {
+	Code       string `json:"code" binding:"required,max=50"`
+	Name       string `json:"name" binding:"required,min=2,max=255"`
+	Type       string `json:"type" binding:"required,oneof=warehouse store"`
+	Street     string `json:"street,omitempty" binding:"max=255"`
+	City       string `json:"city,omitempty" binding:"max=100"`
+	State      string `json:"state,omitempty" binding:"max=100"`
+	PostalCode string `json:"postal_code,omitempty" binding:"max=50"`
+	Country    string `json:"country,omitempty" binding:"max=100"`
+	Priority   int    `json:"priority"`
+	IsActive   *bool  `json:"is_active,omitempty"`
+}
+
+// StockLocationUpdateRequest represents the data needed to update a stock location
+type StockLocationUpdateRequest struct {
+	Name       *string `json:"name,omitempty" binding:"omitempty,min=2,max=255"`
+	Type       *string `json:"type,omitempty" binding:"omitempty,oneof=warehouse store"`
+	Street     *string `json:"street,omitempty" binding:"omitempty,max=255"`
+	City       *string `json:"city,omitempty" binding:"omitempty,max=100"`
+	State      *string `json:"state,omitempty" binding:"omitempty,max=100"`
+	PostalCode *string `json:"postal_code,omitempty" binding:"omitempty,max=50"`
+	Country    *string `json:"country,omitempty" binding:"omitempty,max=100"`
+	Priority   *int    `json:"priority,omitempty"`
+	IsActive   *bool   `json:"is_active,omitempty"`
+}
+
+// StockLocationResponse represents a stock location returned to the client
+type StockLocationResponse struct {
+	ID         string `json:"id"`
+	Code       string `json:"code"`
+	Name       string `json:"name"`
+	Type       string `json:"type"`
+	Street     string `json:"street,omitempty"`
+	City       string `json:"city,omitempty"`
+	State      string `json:"state,omitempty"`
+	PostalCode string `json:"postal_code,omitempty"`
+	Country    string `json:"country,omitempty"`
+	Priority   int    `json:"priority"`
+	IsActive   bool   `json:"is_active"`
+	CreatedAt  string `json:"created_at"`
+	UpdatedAt  string `json:"updated_at"`
+}
+
+// GetStockLocationResponse converts a StockLocation entity to StockLocationResponse DTO
+func GetStockLocationResponse(location entity.StockLocation) StockLocationResponse {
+	return StockLocationResponse{
+		ID:         location.ID,
+		Code:       location.Code,
+		Name:       location.Name,
+		Type:       string(location.Type),
+		Street:     location.Street,
+		City:       location.City,
+		State:      location.State,
+		PostalCode: location.PostalCode,
+		Country:    location.Country,
+		Priority:   location.Priority,
+		IsActive:   location.IsActive,
+		CreatedAt:  location.CreatedAt.Format(time.RFC3339),
+		UpdatedAt:  location.UpdatedAt.Format(time.RFC3339),
+	}
+}
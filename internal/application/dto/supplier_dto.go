@@ -0,0 +1,82 @@
+package dto
+
+import (
+	"time"
+
+	"backend-ecommerce/internal/application/entity"
+)
+
+// SupplierCreateRequest represents the data needed to create a supplier
+type SupplierCreateRequest struct {
+	Name         string `json:"name" binding:"required,min=2,max=255"`
+	ContactName  string `json:"contact_name,omitempty" binding:"max=255"`
+	Email        string `json:"email,omitempty" binding:"omitempty,email,max=255"`
+	Phone        string `json:"phone,omitempty" binding:"max=50"`
+	Street       string `json:"street,omitempty" binding:"max=255"`
+	City         string `json:"city,omitempty" binding:"max=100"`
+	State        string `json:"state,omitempty" binding:"max=100"`
+	PostalCode   string `json:"postal_code,omitempty" binding:"max=50"`
+	Country      string `json:"country,omitempty" binding:"max=100"`
+	Currency     string `json:"currency,omitempty" binding:"omitempty,len=3"`
+	LeadTimeDays int    `json:"lead_time_days" binding:"min=0"`
+	Notes        string `json:"notes,omitempty"`
+}
+
+// SupplierUpdateRequest represents the data needed to update a supplier
+type SupplierUpdateRequest struct {
+	Name         *string `json:"name,omitempty" binding:"omitempty,min=2,max=255"`
+	ContactName  *string `json:"contact_name,omitempty" binding:"omitempty,max=255"`
+	Email        *string `json:"email,omitempty" binding:"omitempty,email,max=255"`
+	Phone        *string `json:"phone,omitempty" binding:"omitempty,max=50"`
+	Street       *string `json:"street,omitempty" binding:"omitempty,max=255"`
+	City         *string `json:"city,omitempty" binding:"omitempty,max=100"`
+	State        *string `json:"state,omitempty" binding:"omitempty,max=100"`
+	PostalCode   *string `json:"postal_code,omitempty" binding:"omitempty,max=50"`
+	Country      *string `json:"country,omitempty" binding:"omitempty,max=100"`
+	Currency     *string `json:"currency,omitempty" binding:"omitempty,len=3"`
+	LeadTimeDays *int    `json:"lead_time_days,omitempty" binding:"omitempty,min=0"`
+	IsActive     *bool   `json:"is_active,omitempty"`
+	Notes        *string `json:"notes,omitempty"`
+}
+
+// SupplierResponse represents a supplier returned to the client
+type SupplierResponse struct {
+	ID           string `json:"id"`
+	Name         string `json:"name"`
+	ContactName  string `json:"contact_name,omitempty"`
+	Email        string `json:"email,omitempty"`
+	Phone        string `json:"phone,omitempty"`
+	Street       string `json:"street,omitempty"`
+	City         string `json:"city,omitempty"`
+	State        string `json:"state,omitempty"`
+	PostalCode   string `json:"postal_code,omitempty"`
+	Country      string `json:"country,omitempty"`
+	Currency     string `json:"currency"`
+	LeadTimeDays int    `json:"lead_time_days"`
+	IsActive     bool   `json:"is_active"`
+	Notes        string `json:"notes,omitempty"`
+	CreatedAt    string `json:"created_at"`
+	UpdatedAt    string `json:"updated_at"`
+}
+
+// GetSupplierResponse converts a Supplier entity to SupplierResponse DTO
+func GetSupplierResponse(supplier entity.Supplier) SupplierResponse {
+	return SupplierResponse{
+		ID:           supplier.ID,
+		Name:         supplier.Name,
+		ContactName:  supplier.ContactName,
+		Email:        supplier.Email,
+		Phone:        supplier.Phone,
+		Street:       supplier.Street,
+		City:         supplier.City,
+		State:        supplier.State,
+		PostalCode:   supplier.PostalCode,
+		Country:      supplier.Country,
+		Currency:     supplier.Currency,
+		LeadTimeDays: supplier.LeadTimeDays,
+		IsActive:     supplier.IsActive,
+		Notes:        supplier.Notes,
+		CreatedAt:    supplier.CreatedAt.Format(time.RFC3339),
+		UpdatedAt:    supplier.UpdatedAt.Format(time.RFC3339),
+	}
+}
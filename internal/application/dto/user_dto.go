@@ -54,3 +54,16 @@ func GetUserResponse(entity entity.User) UserResponse {
 // 	ExpiresIn    int64  `json:"expires_in"`
 // 	TokenType    string `json:"token_type"`
 // }
+
+// MarketingUnsubscribeRequest carries the user and signed token of an
+// unsubscribe link from a marketing email
+type MarketingUnsubscribeRequest struct {
+	UserID string `json:"user_id" binding:"required"`
+	Token  string `json:"token" binding:"required"`
+}
+
+// MarketingPreferencesRequest represents a signed-in user's choice to receive
+// marketing emails such as abandoned cart reminders
+type MarketingPreferencesRequest struct {
+	Subscribed *bool `json:"subscribed" binding:"required"`
+}
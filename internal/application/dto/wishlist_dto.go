@@ -0,0 +1,94 @@
+package dto
+
+import (
+	"time"
+
+	"backend-ecommerce/internal/application/entity"
+)
+
+// RecentlyViewedRequest represents a product view to record
+type RecentlyViewedRequest struct {
+	ProductID string `json:"product_id" binding:"required"`
+}
+
+// RecentlyViewedResponse represents a recently viewed product
+type RecentlyViewedResponse struct {
+	ProductID string          `json:"product_id"`
+	ViewedAt  string          `json:"viewed_at"`
+	Product   ProductResponse `json:"product"`
+}
+
+// WishlistCreateRequest represents the data needed to create a wishlist
+type WishlistCreateRequest struct {
+	Name string `json:"name" binding:"required,max=100"`
+}
+
+// WishlistUpdateRequest represents the data needed to rename a wishlist
+type WishlistUpdateRequest struct {
+	Name string `json:"name" binding:"required,max=100"`
+}
+
+// WishlistItemAddRequest represents a product to save to a wishlist
+type WishlistItemAddRequest struct {
+	ProductID string `json:"product_id" binding:"required"`
+	Note      string `json:"note,omitempty" binding:"max=255"`
+}
+
+// WishlistMoveToCartRequest represents how many units of a wishlist item to put in the cart
+type WishlistMoveToCartRequest struct {
+	Quantity int `json:"quantity,omitempty" binding:"omitempty,min=1"`
+}
+
+// WishlistResponse represents a wishlist returned to the client. ShareToken
+// is only returned to the owner.
+type WishlistResponse struct {
+	ID         string                 `json:"id"`
+	Name       string                 `json:"name"`
+	Shared     bool                   `json:"shared"`
+	ShareToken *string                `json:"share_token,omitempty"`
+	Items      []WishlistItemResponse `json:"items"`
+	CreatedAt  string                 `json:"created_at"`
+	UpdatedAt  string                 `json:"updated_at"`
+}
+
+// WishlistItemResponse represents a wishlist item. Product is empty when the
+// product is no longer sold.
+type WishlistItemResponse struct {
+	ID          string           `json:"id"`
+	ProductID   string           `json:"product_id"`
+	Note        string           `json:"note,omitempty"`
+	IsAvailable bool             `json:"is_available"`
+	Product     *ProductResponse `json:"product,omitempty"`
+	CreatedAt   string           `json:"created_at"`
+}
+
+// GetWishlistResponse converts a Wishlist entity to WishlistResponse DTO.
+// products holds the published products among the items, keyed by ID.
+func GetWishlistResponse(wishlist entity.Wishlist, products map[string]ProductResponse, forOwner bool) WishlistResponse {
+	items := make([]WishlistItemResponse, len(wishlist.Items))
+	for i, item := range wishlist.Items {
+		items[i] = WishlistItemResponse{
+			ID:        item.ID,
+			ProductID: item.ProductID,
+			Note:      item.Note,
+			CreatedAt: item.CreatedAt.Format(time.RFC3339),
+		}
+		if product, ok := products[item.ProductID]; ok {
+			items[i].IsAvailable = true
+			items[i].Product = &product
+		}
+	}
+
+	response := WishlistResponse{
+		ID:        wishlist.ID,
+		Name:      wishlist.Name,
+		Shared:    wishlist.ShareToken != nil,
+		Items:     items,
+		CreatedAt: wishlist.CreatedAt.Format(time.RFC3339),
+		UpdatedAt: wishlist.UpdatedAt.Format(time.RFC3339),
+	}
+	if forOwner {
+		response.ShareToken = wishlist.ShareToken
+	}
+	return response
+}
@@ -0,0 +1,35 @@
+package entity
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// BundleComponent is a product or variant included in a bundle product
+type BundleComponent struct {
+	ID                 string    `json:"id" gorm:"primaryKey;column:id;type:varchar(36);default:(UUID());comment:'Primary Key'"`
+	BundleID           string    `json:"bundle_id" gorm:"column:bundle_id;type:varchar(36);not null;index;comment:'FK to the bundle product'"`
+	ComponentProductID string    `json:"component_product_id" gorm:"column:component_product_id;type:varchar(36);not null;index;comment:'FK to the component product'"`
+	ComponentVariantID *string   `json:"component_variant_id,omitempty" gorm:"column:component_variant_id;type:varchar(36);comment:'FK to the component variant'"`
+	Quantity           int       `json:"quantity" gorm:"column:quantity;type:int;not null;default:1;comment:'Units of the component per bundle'"`
+	SortOrder          int       `json:"sort_order" gorm:"column:sort_order;type:int;default:0;comment:'Sort order'"`
+	CreatedAt          time.Time `json:"created_at" gorm:"autoCreateTime;column:created_at;comment:'Created at'"`
+
+	// Relations
+	ComponentProduct *Product        `json:"component_product,omitempty" gorm:"foreignKey:ComponentProductID"`
+	ComponentVariant *ProductVariant `json:"component_variant,omitempty" gorm:"foreignKey:ComponentVariantID"`
+}
+
+// TableName specifies the table name for the BundleComponent model
+func (BundleComponent) TableName() string {
+	return "bundleComponents"
+}
+
+// BeforeCreate sets timestamps and performs any pre-creation logic.
+func (b *BundleComponent) BeforeCreate(tx *gorm.DB) (err error) {
+	if b.CreatedAt.IsZero() {
+		b.CreatedAt = time.Now().UTC()
+	}
+	return nil
+}
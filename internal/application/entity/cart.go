@@ -4,16 +4,20 @@ import (
 	"time"
 )
 
-// Cart represents a shopping cart
+// Cart represents a shopping cart. It belongs to a signed-in user or, while
+// UserID is empty, to the guest holding GuestToken.
 type Cart struct {
-	ID         string     `json:"id" gorm:"primaryKey;column:id;type:varchar(36);default:(UUID());comment:'Primary Key'"`
-	UserID     *string    `json:"user_id,omitempty" gorm:"column:user_id;type:varchar(36);comment:'FK to user entity'"`
-	GuestToken string     `json:"guest_token,omitempty" gorm:"column:guest_token;type:varchar(255);comment:'Guest session token'"`
-	CreatedAt  time.Time  `json:"created_at" gorm:"autoCreateTime;column:created_at;comment:'Created at'"`
-	UpdatedAt  time.Time  `json:"updated_at" gorm:"autoUpdateTime;column:updated_at;comment:'Updated at'"`
-	
+	ID         string    `json:"id" gorm:"primaryKey;column:id;type:varchar(36);default:(UUID());comment:'Primary Key'"`
+	UserID     *string   `json:"user_id,omitempty" gorm:"column:user_id;type:varchar(36);index;comment:'FK to user entity'"`
+	GuestToken string    `json:"guest_token,omitempty" gorm:"column:guest_token;type:varchar(255);index;comment:'Guest session token'"`
+	CreatedAt  time.Time `json:"created_at" gorm:"autoCreateTime;column:created_at;comment:'Created at'"`
+	UpdatedAt  time.Time `json:"updated_at" gorm:"autoUpdateTime;column:updated_at;comment:'Updated at'"`
+
 	// Relations
-	Items      []CartItem `json:"items,omitempty" gorm:"foreignKey:CartID"`
+	Items []CartItem `json:"items,omitempty" gorm:"foreignKey:CartID"`
+	// SavedItems are kept in SQL whatever the cart backend, so the cart row
+	// may not exist and no foreign key is created
+	SavedItems []CartSavedItem `json:"saved_items,omitempty" gorm:"foreignKey:CartID;-:migration"`
 }
 
 // TableName specifies the table name for the Cart model
@@ -24,19 +28,52 @@ func (Cart) TableName() string {
 // CartItem represents an item in a shopping cart
 type CartItem struct {
 	ID         string    `json:"id" gorm:"primaryKey;column:id;type:varchar(36);default:(UUID());comment:'Primary Key'"`
-	CartID     string    `json:"cart_id" gorm:"column:cart_id;type:varchar(36);not null;comment:'FK to cart'"`
+	CartID     string    `json:"cart_id" gorm:"column:cart_id;type:varchar(36);not null;index;comment:'FK to cart'"`
 	ProductID  string    `json:"product_id" gorm:"column:product_id;type:varchar(36);not null;comment:'FK to product'"`
+	VariantID  *string   `json:"variant_id,omitempty" gorm:"column:variant_id;type:varchar(36);comment:'FK to product variant'"`
 	Quantity   int       `json:"quantity" gorm:"column:quantity;type:int;not null;default:1;comment:'Item quantity'"`
 	PriceAtAdd float64   `json:"price_at_add" gorm:"column:price_at_add;type:decimal(12,2);not null;comment:'Price when added to cart'"`
 	CreatedAt  time.Time `json:"created_at" gorm:"autoCreateTime;column:created_at;comment:'Created at'"`
 	UpdatedAt  time.Time `json:"updated_at" gorm:"autoUpdateTime;column:updated_at;comment:'Updated at'"`
-	
+
 	// Relations
-	Cart       *Cart     `json:"-" gorm:"foreignKey:CartID"`
-	Product    *Product  `json:"product,omitempty" gorm:"foreignKey:ProductID"`
+	Cart    *Cart    `json:"-" gorm:"foreignKey:CartID"`
+	Product *Product `json:"product,omitempty" gorm:"foreignKey:ProductID"`
 }
 
 // TableName specifies the table name for the CartItem model
 func (CartItem) TableName() string {
 	return "cartItems"
 }
+
+// SameLine reports whether the item is for the given product and variant
+func (i CartItem) SameLine(productID string, variantID *string) bool {
+	if i.ProductID != productID {
+		return false
+	}
+	if i.VariantID == nil || variantID == nil {
+		return i.VariantID == nil && variantID == nil
+	}
+	return *i.VariantID == *variantID
+}
+
+// CartSavedItem is a cart line the shopper saved for later. It is not priced
+// or bought with the cart until it is moved back.
+type CartSavedItem struct {
+	ID        string    `json:"id" gorm:"primaryKey;column:id;type:varchar(36);default:(UUID());comment:'Primary Key'"`
+	CartID    string    `json:"cart_id" gorm:"column:cart_id;type:varchar(36);not null;index;comment:'FK to cart'"`
+	ProductID string    `json:"product_id" gorm:"column:product_id;type:varchar(36);not null;comment:'FK to product'"`
+	VariantID *string   `json:"variant_id,omitempty" gorm:"column:variant_id;type:varchar(36);comment:'FK to product variant'"`
+	Quantity  int       `json:"quantity" gorm:"column:quantity;type:int;not null;default:1;comment:'Item quantity'"`
+	CreatedAt time.Time `json:"created_at" gorm:"autoCreateTime;column:created_at;comment:'Saved at'"`
+}
+
+// TableName specifies the table name for the CartSavedItem model
+func (CartSavedItem) TableName() string {
+	return "cartSavedItems"
+}
+
+// SameLine reports whether the item is for the given product and variant
+func (i CartSavedItem) SameLine(productID string, variantID *string) bool {
+	return CartItem{ProductID: i.ProductID, VariantID: i.VariantID}.SameLine(productID, variantID)
+}
@@ -0,0 +1,45 @@
+package entity
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// CartRecovery tracks the reminders sent for one abandonment of a user's
+// cart. A cart that changes and is abandoned again starts a new recovery, so
+// AbandonedAt is the cart's last change when the reminders started.
+type CartRecovery struct {
+	ID             string     `json:"id" gorm:"primaryKey;column:id;type:varchar(36);default:(UUID());comment:'Primary Key'"`
+	CartID         string     `json:"cart_id" gorm:"column:cart_id;type:varchar(36);not null;uniqueIndex:idx_cart_recovery_abandonment;comment:'FK to cart'"`
+	UserID         string     `json:"user_id" gorm:"column:user_id;type:varchar(36);not null;index;comment:'FK to user entity'"`
+	AbandonedAt    time.Time  `json:"abandoned_at" gorm:"column:abandoned_at;not null;uniqueIndex:idx_cart_recovery_abandonment;comment:'When the cart last changed'"`
+	RemindersSent  int        `json:"reminders_sent" gorm:"column:reminders_sent;type:int;not null;default:0;comment:'Reminders of the sequence sent so far'"`
+	LastRemindedAt *time.Time `json:"last_reminded_at,omitempty" gorm:"column:last_reminded_at;index;comment:'When the last reminder was queued'"`
+	RestoredAt     *time.Time `json:"restored_at,omitempty" gorm:"column:restored_at;comment:'When the recovery link was first used'"`
+	OrderID        *string    `json:"order_id,omitempty" gorm:"column:order_id;type:varchar(36);comment:'FK to the order placed after a reminder'"`
+	ConvertedAt    *time.Time `json:"converted_at,omitempty" gorm:"column:converted_at;comment:'When the user checked out after a reminder'"`
+	CreatedAt      time.Time  `json:"created_at" gorm:"autoCreateTime;column:created_at;index;comment:'Created at'"`
+	UpdatedAt      time.Time  `json:"updated_at" gorm:"autoUpdateTime;column:updated_at;comment:'Updated at'"`
+}
+
+// TableName specifies the table name for the CartRecovery model
+func (CartRecovery) TableName() string {
+	return "cartRecoveries"
+}
+
+// BeforeCreate sets timestamps and performs any pre-creation logic.
+func (r *CartRecovery) BeforeCreate(tx *gorm.DB) (err error) {
+	now := time.Now().UTC()
+	if r.CreatedAt.IsZero() {
+		r.CreatedAt = now
+	}
+	r.UpdatedAt = now
+	return nil
+}
+
+// BeforeUpdate updates the UpdatedAt timestamp before updating an existing record.
+func (r *CartRecovery) BeforeUpdate(tx *gorm.DB) (err error) {
+	r.UpdatedAt = time.Now().UTC()
+	return nil
+}
@@ -0,0 +1,49 @@
+package entity
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// CartSnapshot is a copy of a cart's items taken when the cart was shared.
+// Whoever opens the share link before it expires can copy the items into
+// their own cart; later changes to the shared cart are not seen.
+type CartSnapshot struct {
+	ID        string    `json:"id" gorm:"primaryKey;column:id;type:varchar(36);default:(UUID());comment:'Primary Key'"`
+	CartID    string    `json:"cart_id" gorm:"column:cart_id;type:varchar(36);not null;index;comment:'Cart the snapshot was taken of'"`
+	UserID    *string   `json:"user_id,omitempty" gorm:"column:user_id;type:varchar(36);index;comment:'FK to the user who shared the cart'"`
+	ExpiresAt time.Time `json:"expires_at" gorm:"column:expires_at;not null;index;comment:'When the share link stops working'"`
+	CreatedAt time.Time `json:"created_at" gorm:"autoCreateTime;column:created_at;comment:'Created at'"`
+
+	// Relations
+	Items []CartSnapshotItem `json:"items,omitempty" gorm:"foreignKey:SnapshotID"`
+}
+
+// TableName specifies the table name for the CartSnapshot model
+func (CartSnapshot) TableName() string {
+	return "cartSnapshots"
+}
+
+// BeforeCreate sets timestamps and performs any pre-creation logic.
+func (s *CartSnapshot) BeforeCreate(tx *gorm.DB) (err error) {
+	if s.CreatedAt.IsZero() {
+		s.CreatedAt = time.Now().UTC()
+	}
+	return nil
+}
+
+// CartSnapshotItem is a line of a shared cart
+type CartSnapshotItem struct {
+	ID         string  `json:"id" gorm:"primaryKey;column:id;type:varchar(36);default:(UUID());comment:'Primary Key'"`
+	SnapshotID string  `json:"snapshot_id" gorm:"column:snapshot_id;type:varchar(36);not null;index;comment:'FK to cart snapshot'"`
+	ProductID  string  `json:"product_id" gorm:"column:product_id;type:varchar(36);not null;comment:'FK to product'"`
+	VariantID  *string `json:"variant_id,omitempty" gorm:"column:variant_id;type:varchar(36);comment:'FK to product variant'"`
+	Quantity   int     `json:"quantity" gorm:"column:quantity;type:int;not null;comment:'Item quantity'"`
+	Position   int     `json:"position" gorm:"column:position;type:int;not null;default:0;comment:'Order of the line in the cart'"`
+}
+
+// TableName specifies the table name for the CartSnapshotItem model
+func (CartSnapshotItem) TableName() string {
+	return "cartSnapshotItems"
+}
@@ -0,0 +1,297 @@
+package entity
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// CollectionType represents how the products of a collection are chosen
+type CollectionType string
+
+const (
+	// CollectionTypeManual holds hand-picked products in a fixed order
+	CollectionTypeManual CollectionType = "manual"
+	// CollectionTypeAutomatic holds every product matching its rules
+	CollectionTypeAutomatic CollectionType = "automatic"
+)
+
+// CollectionSort represents the display order of an automatic collection
+type CollectionSort string
+
+const (
+	CollectionSortNewest    CollectionSort = "newest"
+	CollectionSortName      CollectionSort = "name"
+	CollectionSortPriceAsc  CollectionSort = "price_asc"
+	CollectionSortPriceDesc CollectionSort = "price_desc"
+)
+
+// CollectionMatch represents how the conditions of a rule set are combined
+type CollectionMatch string
+
+const (
+	CollectionMatchAll CollectionMatch = "all"
+	CollectionMatchAny CollectionMatch = "any"
+)
+
+// CollectionCondition tests one product field, e.g. {"field": "price", "operator": "lt", "value": "50"}.
+//
+// Supported fields are name, sku, slug, type, category_id, price (the current
+// effective price), tag, attribute.<name>, stock (units available) and
+// in_stock ("true" or "false"). Operators are eq, neq, gt, gte, lt, lte and
+// contains; for tag, eq means the product has the tag and neq that it does not.
+type CollectionCondition struct {
+	Field    string `json:"field"`
+	Operator string `json:"operator"`
+	Value    string `json:"value"`
+}
+
+// CollectionRules decides which products belong to an automatic collection
+type CollectionRules struct {
+	Match      CollectionMatch       `json:"match"`
+	Conditions []CollectionCondition `json:"conditions"`
+}
+
+// ProductFacts are the computed values rules can test besides the product's own fields
+type ProductFacts struct {
+	Price     float64
+	Available int
+	// Unlimited is set for products that are never out of stock, such as digital ones
+	Unlimited bool
+}
+
+// Value implements the driver.Valuer interface
+func (r CollectionRules) Value() (driver.Value, error) {
+	return json.Marshal(r)
+}
+
+// Scan implements the sql.Scanner interface
+func (r *CollectionRules) Scan(value interface{}) error {
+	if value == nil {
+		*r = CollectionRules{}
+		return nil
+	}
+	return json.Unmarshal(value.([]byte), r)
+}
+
+// Validate checks that every condition uses a known field, an operator that
+// applies to it and a value of the right kind
+func (r CollectionRules) Validate() error {
+	if r.Match != CollectionMatchAll && r.Match != CollectionMatchAny {
+		return fmt.Errorf("match must be %q or %q", CollectionMatchAll, CollectionMatchAny)
+	}
+	if len(r.Conditions) == 0 {
+		return fmt.Errorf("at least one condition is required")
+	}
+	for _, condition := range r.Conditions {
+		if err := condition.Validate(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Matches reports whether product, with its computed facts, satisfies the rules
+func (r CollectionRules) Matches(product Product, facts ProductFacts) bool {
+	for _, condition := range r.Conditions {
+		matched := condition.Matches(product, facts)
+		if r.Match == CollectionMatchAny && matched {
+			return true
+		}
+		if r.Match != CollectionMatchAny && !matched {
+			return false
+		}
+	}
+	return r.Match != CollectionMatchAny
+}
+
+// Validate checks a single condition
+func (c CollectionCondition) Validate() error {
+	switch {
+	case c.Field == "price" || c.Field == "stock":
+		if !isComparison(c.Operator) {
+			return fmt.Errorf("operator %q cannot be used with %s", c.Operator, c.Field)
+		}
+		if _, err := strconv.ParseFloat(c.Value, 64); err != nil {
+			return fmt.Errorf("%s must be compared with a number", c.Field)
+		}
+	case c.Field == "in_stock":
+		if c.Operator != "eq" && c.Operator != "neq" {
+			return fmt.Errorf("operator %q cannot be used with in_stock", c.Operator)
+		}
+		if _, err := strconv.ParseBool(c.Value); err != nil {
+			return fmt.Errorf("in_stock must be compared with true or false")
+		}
+	case c.Field == "tag":
+		if c.Operator != "eq" && c.Operator != "neq" {
+			return fmt.Errorf("operator %q cannot be used with tag", c.Operator)
+		}
+	case c.Field == "name" || c.Field == "sku" || c.Field == "slug" || c.Field == "type" || c.Field == "category_id" ||
+		(strings.HasPrefix(c.Field, "attribute.") && len(c.Field) > len("attribute.")):
+		if c.Operator != "eq" && c.Operator != "neq" && c.Operator != "contains" && !isComparison(c.Operator) {
+			return fmt.Errorf("unknown operator %q", c.Operator)
+		}
+	default:
+		return fmt.Errorf("unknown field %q", c.Field)
+	}
+	return nil
+}
+
+// Matches reports whether product satisfies the condition
+func (c CollectionCondition) Matches(product Product, facts ProductFacts) bool {
+	switch c.Field {
+	case "price":
+		return compareNumbers(facts.Price, c.Operator, c.Value)
+	case "stock":
+		if facts.Unlimited {
+			return c.Operator == "gt" || c.Operator == "gte" || c.Operator == "neq"
+		}
+		return compareNumbers(float64(facts.Available), c.Operator, c.Value)
+	case "in_stock":
+		want, _ := strconv.ParseBool(c.Value)
+		inStock := facts.Unlimited || facts.Available > 0
+		return (inStock == want) == (c.Operator == "eq")
+	case "tag":
+		return product.HasTag(c.Value) == (c.Operator == "eq")
+	case "name":
+		return compareStrings(product.Name, c.Operator, c.Value)
+	case "sku":
+		return compareStrings(product.SKU, c.Operator, c.Value)
+	case "slug":
+		return compareStrings(product.Slug, c.Operator, c.Value)
+	case "type":
+		return compareStrings(string(product.Type), c.Operator, c.Value)
+	case "category_id":
+		category := ""
+		if product.CategoryID != nil {
+			category = *product.CategoryID
+		}
+		return compareStrings(category, c.Operator, c.Value)
+	}
+
+	if name, ok := strings.CutPrefix(c.Field, "attribute."); ok {
+		value, exists := product.Attributes[name]
+		if !exists {
+			return c.Operator == "neq"
+		}
+		// Numeric attributes such as weight compare as numbers
+		if number, err := strconv.ParseFloat(value, 64); err == nil && isComparison(c.Operator) {
+			return compareNumbers(number, c.Operator, c.Value)
+		}
+		return compareStrings(value, c.Operator, c.Value)
+	}
+	return false
+}
+
+func isComparison(operator string) bool {
+	switch operator {
+	case "eq", "neq", "gt", "gte", "lt", "lte":
+		return true
+	}
+	return false
+}
+
+func compareNumbers(actual float64, operator, value string) bool {
+	expected, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return false
+	}
+	switch operator {
+	case "eq":
+		return actual == expected
+	case "neq":
+		return actual != expected
+	case "gt":
+		return actual > expected
+	case "gte":
+		return actual >= expected
+	case "lt":
+		return actual < expected
+	case "lte":
+		return actual <= expected
+	}
+	return false
+}
+
+func compareStrings(actual, operator, value string) bool {
+	switch operator {
+	case "eq":
+		return strings.EqualFold(actual, value)
+	case "neq":
+		return !strings.EqualFold(actual, value)
+	case "contains":
+		return strings.Contains(strings.ToLower(actual), strings.ToLower(value))
+	case "gt":
+		return actual > value
+	case "gte":
+		return actual >= value
+	case "lt":
+		return actual < value
+	case "lte":
+		return actual <= value
+	}
+	return false
+}
+
+// Collection is a curated or rule-based group of products shown in the storefront
+type Collection struct {
+	ID          string          `json:"id" gorm:"primaryKey;column:id;type:varchar(36);default:(UUID());comment:'Primary Key'"`
+	Name        string          `json:"name" gorm:"column:name;type:varchar(255);not null;comment:'Collection name'"`
+	Slug        string          `json:"slug" gorm:"column:slug;type:varchar(255);uniqueIndex;not null;comment:'URL-friendly name'"`
+	Description string          `json:"description,omitempty" gorm:"column:description;type:text;comment:'Collection description'"`
+	Type        CollectionType  `json:"type" gorm:"column:type;type:ENUM('manual','automatic');not null;default:'manual';comment:'Collection type'"`
+	Rules       CollectionRules `json:"rules" gorm:"column:rules;type:json;comment:'Membership rules of automatic collections'"`
+	SortBy      CollectionSort  `json:"sort_by" gorm:"column:sort_by;type:varchar(20);not null;default:'newest';comment:'Display order of automatic collections'"`
+	IsActive    bool            `json:"is_active" gorm:"column:is_active;type:boolean;default:true;comment:'Is collection shown in the storefront'"`
+	RefreshedAt *time.Time      `json:"refreshed_at,omitempty" gorm:"column:refreshed_at;comment:'Last full membership refresh'"`
+	CreatedAt   time.Time       `json:"created_at" gorm:"autoCreateTime;column:created_at;comment:'Created at'"`
+	UpdatedAt   time.Time       `json:"updated_at" gorm:"autoUpdateTime;column:updated_at;comment:'Updated at'"`
+}
+
+// TableName specifies the table name for the Collection model
+func (Collection) TableName() string {
+	return "collections"
+}
+
+// BeforeCreate sets timestamps and performs any pre-creation logic.
+func (c *Collection) BeforeCreate(tx *gorm.DB) (err error) {
+	now := time.Now().UTC()
+	if c.CreatedAt.IsZero() {
+		c.CreatedAt = now
+	}
+	c.UpdatedAt = now
+	return nil
+}
+
+// BeforeUpdate updates the UpdatedAt timestamp before updating an existing record.
+func (c *Collection) BeforeUpdate(tx *gorm.DB) (err error) {
+	c.UpdatedAt = time.Now().UTC()
+	return nil
+}
+
+// CollectionProduct is a product's membership of a collection. Manual
+// collections set Position; automatic ones are materialised from their rules.
+type CollectionProduct struct {
+	CollectionID string    `json:"collection_id" gorm:"primaryKey;column:collection_id;type:varchar(36);comment:'FK to collection'"`
+	ProductID    string    `json:"product_id" gorm:"primaryKey;column:product_id;type:varchar(36);index;comment:'FK to product'"`
+	Position     int       `json:"position" gorm:"column:position;type:int;default:0;comment:'Position in manual collections'"`
+	CreatedAt    time.Time `json:"created_at" gorm:"autoCreateTime;column:created_at;comment:'Created at'"`
+}
+
+// TableName specifies the table name for the CollectionProduct model
+func (CollectionProduct) TableName() string {
+	return "collectionProducts"
+}
+
+// BeforeCreate sets timestamps and performs any pre-creation logic.
+func (c *CollectionProduct) BeforeCreate(tx *gorm.DB) (err error) {
+	if c.CreatedAt.IsZero() {
+		c.CreatedAt = time.Now().UTC()
+	}
+	return nil
+}
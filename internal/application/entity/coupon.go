@@ -0,0 +1,173 @@
+package entity
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// CouponType is how a discount code lowers the price
+type CouponType string
+
+const (
+	// CouponPercentage takes Value percent off the eligible lines
+	CouponPercentage CouponType = "percentage"
+	// CouponFixedAmount takes Value off the eligible lines, spread over them
+	// in proportion to their price
+	CouponFixedAmount CouponType = "fixed_amount"
+	// CouponFreeShipping waives the shipping charge
+	CouponFreeShipping CouponType = "free_shipping"
+)
+
+// CouponIDs holds the product or category IDs a coupon is limited to or excludes
+type CouponIDs []string
+
+// Value implements the driver.Valuer interface
+func (ids CouponIDs) Value() (driver.Value, error) {
+	if ids == nil {
+		return nil, nil
+	}
+	return json.Marshal(ids)
+}
+
+// Scan implements the sql.Scanner interface
+func (ids *CouponIDs) Scan(value interface{}) error {
+	if value == nil {
+		*ids = nil
+		return nil
+	}
+	return json.Unmarshal(value.([]byte), ids)
+}
+
+// Contains reports whether id is in the list
+func (ids CouponIDs) Contains(id string) bool {
+	for _, candidate := range ids {
+		if candidate == id {
+			return true
+		}
+	}
+	return false
+}
+
+// Coupon is a discount customers get by entering one of its codes. The
+// limits, window and rules apply across all its codes.
+type Coupon struct {
+	ID          string     `json:"id" gorm:"primaryKey;column:id;type:varchar(36);default:(UUID());comment:'Primary Key'"`
+	Name        string     `json:"name" gorm:"column:name;type:varchar(255);not null;comment:'Name shown on the discount line'"`
+	Description string     `json:"description,omitempty" gorm:"column:description;type:text;comment:'Internal description'"`
+	Type        CouponType `json:"type" gorm:"column:type;type:ENUM('percentage','fixed_amount','free_shipping');not null;comment:'Discount type'"`
+	Value       float64    `json:"value" gorm:"column:value;type:decimal(12,2);not null;default:0;comment:'Percentage or amount off'"`
+	MinSubtotal float64    `json:"min_subtotal" gorm:"column:min_subtotal;type:decimal(12,2);not null;default:0;comment:'Cart subtotal needed for the code to apply'"`
+	// ProductIDs and CategoryIDs limit the discount to the lines of those
+	// products or categories; when both are empty every line is eligible
+	ProductIDs          CouponIDs `json:"product_ids,omitempty" gorm:"column:product_ids;type:json;comment:'Products the discount is limited to'"`
+	CategoryIDs         CouponIDs `json:"category_ids,omitempty" gorm:"column:category_ids;type:json;comment:'Categories the discount is limited to'"`
+	ExcludedProductIDs  CouponIDs `json:"excluded_product_ids,omitempty" gorm:"column:excluded_product_ids;type:json;comment:'Products never discounted'"`
+	ExcludedCategoryIDs CouponIDs `json:"excluded_category_ids,omitempty" gorm:"column:excluded_category_ids;type:json;comment:'Categories never discounted'"`
+	// UsageLimit caps the orders across all codes, and PerCustomerLimit the
+	// orders of one customer; 0 means unlimited
+	UsageLimit       int        `json:"usage_limit" gorm:"column:usage_limit;type:int;not null;default:0;comment:'Total redemptions allowed, 0 for unlimited'"`
+	PerCustomerLimit int        `json:"per_customer_limit" gorm:"column:per_customer_limit;type:int;not null;default:0;comment:'Redemptions allowed per customer, 0 for unlimited'"`
+	TimesUsed        int        `json:"times_used" gorm:"column:times_used;type:int;not null;default:0;comment:'Redemptions so far'"`
+	StartsAt         *time.Time `json:"starts_at,omitempty" gorm:"column:starts_at;comment:'Start of the validity window'"`
+	EndsAt           *time.Time `json:"ends_at,omitempty" gorm:"column:ends_at;comment:'End of the validity window'"`
+	// Combinable codes can be used together; a code that is not must be the
+	// only one on the cart
+	Combinable bool      `json:"combinable" gorm:"column:combinable;not null;default:false;comment:'Can be used with other codes'"`
+	IsActive   bool      `json:"is_active" gorm:"column:is_active;not null;default:true;comment:'Can be redeemed'"`
+	CreatedAt  time.Time `json:"created_at" gorm:"autoCreateTime;column:created_at;comment:'Created at'"`
+	UpdatedAt  time.Time `json:"updated_at" gorm:"autoUpdateTime;column:updated_at;comment:'Updated at'"`
+
+	// Relations
+	Codes []CouponCode `json:"codes,omitempty" gorm:"foreignKey:CouponID"`
+}
+
+// TableName specifies the table name for the Coupon model
+func (Coupon) TableName() string {
+	return "coupons"
+}
+
+// BeforeCreate sets timestamps and performs any pre-creation logic.
+func (c *Coupon) BeforeCreate(tx *gorm.DB) (err error) {
+	now := time.Now().UTC()
+	if c.CreatedAt.IsZero() {
+		c.CreatedAt = now
+	}
+	c.UpdatedAt = now
+	return nil
+}
+
+// BeforeUpdate updates the UpdatedAt timestamp before updating an existing record.
+func (c *Coupon) BeforeUpdate(tx *gorm.DB) (err error) {
+	c.UpdatedAt = time.Now().UTC()
+	return nil
+}
+
+// Eligible reports whether a product in categoryID, which may be nil, can be
+// discounted by the coupon
+func (c Coupon) Eligible(productID string, categoryID *string) bool {
+	if c.ExcludedProductIDs.Contains(productID) {
+		return false
+	}
+	if categoryID != nil && c.ExcludedCategoryIDs.Contains(*categoryID) {
+		return false
+	}
+	if len(c.ProductIDs) == 0 && len(c.CategoryIDs) == 0 {
+		return true
+	}
+	return c.ProductIDs.Contains(productID) || (categoryID != nil && c.CategoryIDs.Contains(*categoryID))
+}
+
+// CouponCode is a code that redeems a coupon. Bulk-generated codes are
+// single use; UsageLimit 0 leaves only the coupon's limits.
+type CouponCode struct {
+	ID         string    `json:"id" gorm:"primaryKey;column:id;type:varchar(36);default:(UUID());comment:'Primary Key'"`
+	CouponID   string    `json:"coupon_id" gorm:"column:coupon_id;type:varchar(36);not null;index;comment:'FK to coupon'"`
+	Code       string    `json:"code" gorm:"column:code;type:varchar(64);uniqueIndex;not null;comment:'Code customers enter, upper case'"`
+	UsageLimit int       `json:"usage_limit" gorm:"column:usage_limit;type:int;not null;default:0;comment:'Redemptions allowed with this code, 0 for unlimited'"`
+	TimesUsed  int       `json:"times_used" gorm:"column:times_used;type:int;not null;default:0;comment:'Redemptions so far'"`
+	CreatedAt  time.Time `json:"created_at" gorm:"autoCreateTime;column:created_at;comment:'Created at'"`
+
+	// Relations
+	Coupon *Coupon `json:"coupon,omitempty" gorm:"foreignKey:CouponID"`
+}
+
+// TableName specifies the table name for the CouponCode model
+func (CouponCode) TableName() string {
+	return "couponCodes"
+}
+
+// CartCoupon is a code applied to a cart. Like saved items, it is kept in
+// SQL whatever the cart backend, so no foreign key to the cart is created.
+type CartCoupon struct {
+	ID           string    `json:"id" gorm:"primaryKey;column:id;type:varchar(36);default:(UUID());comment:'Primary Key'"`
+	CartID       string    `json:"cart_id" gorm:"column:cart_id;type:varchar(36);not null;uniqueIndex:idx_cart_coupon;comment:'Cart the code is applied to'"`
+	CouponCodeID string    `json:"coupon_code_id" gorm:"column:coupon_code_id;type:varchar(36);not null;uniqueIndex:idx_cart_coupon;comment:'FK to coupon code'"`
+	CreatedAt    time.Time `json:"created_at" gorm:"autoCreateTime;column:created_at;comment:'Applied at'"`
+
+	// Relations
+	CouponCode *CouponCode `json:"coupon_code,omitempty" gorm:"foreignKey:CouponCodeID"`
+}
+
+// TableName specifies the table name for the CartCoupon model
+func (CartCoupon) TableName() string {
+	return "cartCoupons"
+}
+
+// CouponRedemption records a code used by an order and the discount it gave
+type CouponRedemption struct {
+	ID           string    `json:"id" gorm:"primaryKey;column:id;type:varchar(36);default:(UUID());comment:'Primary Key'"`
+	CouponID     string    `json:"coupon_id" gorm:"column:coupon_id;type:varchar(36);not null;index:idx_coupon_redemption_customer;comment:'FK to coupon'"`
+	CouponCodeID string    `json:"coupon_code_id" gorm:"column:coupon_code_id;type:varchar(36);not null;index;comment:'FK to coupon code'"`
+	OrderID      string    `json:"order_id" gorm:"column:order_id;type:varchar(36);not null;index;comment:'FK to order'"`
+	UserID       string    `json:"user_id" gorm:"column:user_id;type:varchar(36);not null;index:idx_coupon_redemption_customer;comment:'FK to user entity'"`
+	Amount       float64   `json:"amount" gorm:"column:amount;type:decimal(12,2);not null;comment:'Discount given, including shipping waived'"`
+	CreatedAt    time.Time `json:"created_at" gorm:"autoCreateTime;column:created_at;comment:'Redeemed at'"`
+}
+
+// TableName specifies the table name for the CouponRedemption model
+func (CouponRedemption) TableName() string {
+	return "couponRedemptions"
+}
@@ -0,0 +1,105 @@
+package entity
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// DigitalAsset is a downloadable file attached to a digital product
+type DigitalAsset struct {
+	ID            string    `json:"id" gorm:"primaryKey;column:id;type:varchar(36);default:(UUID());comment:'Primary Key'"`
+	ProductID     string    `json:"product_id" gorm:"column:product_id;type:varchar(36);not null;index;comment:'FK to product'"`
+	FileName      string    `json:"file_name" gorm:"column:file_name;type:varchar(255);not null;comment:'File name shown to the buyer'"`
+	StorageKey    string    `json:"-" gorm:"column:storage_key;type:varchar(500);not null;comment:'Private storage key'"`
+	MimeType      string    `json:"mime_type" gorm:"column:mime_type;type:varchar(100);comment:'MIME type'"`
+	FileSize      int64     `json:"file_size" gorm:"column:file_size;type:bigint;comment:'File size in bytes'"`
+	DownloadLimit int       `json:"download_limit" gorm:"column:download_limit;type:int;not null;default:0;comment:'Downloads allowed per purchased unit, 0 for unlimited'"`
+	AccessDays    int       `json:"access_days" gorm:"column:access_days;type:int;not null;default:0;comment:'Days the download stays available after purchase, 0 for no expiry'"`
+	CreatedAt     time.Time `json:"created_at" gorm:"autoCreateTime;column:created_at;comment:'Created at'"`
+	UpdatedAt     time.Time `json:"updated_at" gorm:"autoUpdateTime;column:updated_at;comment:'Updated at'"`
+}
+
+// TableName specifies the table name for the DigitalAsset model
+func (DigitalAsset) TableName() string {
+	return "digitalAssets"
+}
+
+// BeforeCreate sets timestamps and performs any pre-creation logic.
+func (d *DigitalAsset) BeforeCreate(tx *gorm.DB) (err error) {
+	now := time.Now().UTC()
+	if d.CreatedAt.IsZero() {
+		d.CreatedAt = now
+	}
+	d.UpdatedAt = now
+	return nil
+}
+
+// BeforeUpdate updates the UpdatedAt timestamp before updating an existing record.
+func (d *DigitalAsset) BeforeUpdate(tx *gorm.DB) (err error) {
+	d.UpdatedAt = time.Now().UTC()
+	return nil
+}
+
+// LicenseKey is a licence key in a product's pool. A key is handed out once,
+// to a single purchased unit.
+type LicenseKey struct {
+	ID          string     `json:"id" gorm:"primaryKey;column:id;type:varchar(36);default:(UUID());comment:'Primary Key'"`
+	ProductID   string     `json:"product_id" gorm:"column:product_id;type:varchar(36);not null;uniqueIndex:idx_license_product_key;comment:'FK to product'"`
+	Key         string     `json:"key" gorm:"column:license_key;type:varchar(255);not null;uniqueIndex:idx_license_product_key;comment:'Licence key'"`
+	OrderItemID *string    `json:"order_item_id,omitempty" gorm:"column:order_item_id;type:varchar(36);index;comment:'FK to the order item the key was sold with'"`
+	AssignedAt  *time.Time `json:"assigned_at,omitempty" gorm:"column:assigned_at;comment:'When the key was handed out'"`
+	CreatedAt   time.Time  `json:"created_at" gorm:"autoCreateTime;column:created_at;comment:'Created at'"`
+}
+
+// TableName specifies the table name for the LicenseKey model
+func (LicenseKey) TableName() string {
+	return "licenseKeys"
+}
+
+// BeforeCreate sets timestamps and performs any pre-creation logic.
+func (l *LicenseKey) BeforeCreate(tx *gorm.DB) (err error) {
+	if l.CreatedAt.IsZero() {
+		l.CreatedAt = time.Now().UTC()
+	}
+	return nil
+}
+
+// DownloadEntitlement grants a buyer access to one asset of a purchased
+// digital product
+type DownloadEntitlement struct {
+	ID               string     `json:"id" gorm:"primaryKey;column:id;type:varchar(36);default:(UUID());comment:'Primary Key'"`
+	UserID           string     `json:"user_id" gorm:"column:user_id;type:varchar(36);not null;index;comment:'FK to user'"`
+	OrderID          string     `json:"order_id" gorm:"column:order_id;type:varchar(36);not null;index;comment:'FK to order'"`
+	OrderItemID      string     `json:"order_item_id" gorm:"column:order_item_id;type:varchar(36);not null;uniqueIndex:idx_entitlement_item_asset;comment:'FK to order item'"`
+	AssetID          string     `json:"asset_id" gorm:"column:asset_id;type:varchar(36);not null;uniqueIndex:idx_entitlement_item_asset;comment:'FK to digital asset'"`
+	DownloadLimit    int        `json:"download_limit" gorm:"column:download_limit;type:int;not null;default:0;comment:'Downloads allowed, 0 for unlimited'"`
+	DownloadCount    int        `json:"download_count" gorm:"column:download_count;type:int;not null;default:0;comment:'Downloads used'"`
+	ExpiresAt        *time.Time `json:"expires_at,omitempty" gorm:"column:expires_at;comment:'When access ends, null for no expiry'"`
+	LastDownloadedAt *time.Time `json:"last_downloaded_at,omitempty" gorm:"column:last_downloaded_at;comment:'Last download'"`
+	CreatedAt        time.Time  `json:"created_at" gorm:"autoCreateTime;column:created_at;comment:'Created at'"`
+
+	// Relations
+	Asset *DigitalAsset `json:"asset,omitempty" gorm:"foreignKey:AssetID"`
+}
+
+// TableName specifies the table name for the DownloadEntitlement model
+func (DownloadEntitlement) TableName() string {
+	return "downloadEntitlements"
+}
+
+// BeforeCreate sets timestamps and performs any pre-creation logic.
+func (d *DownloadEntitlement) BeforeCreate(tx *gorm.DB) (err error) {
+	if d.CreatedAt.IsZero() {
+		d.CreatedAt = time.Now().UTC()
+	}
+	return nil
+}
+
+// CanDownload reports whether the entitlement still allows a download at t
+func (d DownloadEntitlement) CanDownload(t time.Time) bool {
+	if d.ExpiresAt != nil && !t.Before(*d.ExpiresAt) {
+		return false
+	}
+	return d.DownloadLimit == 0 || d.DownloadCount < d.DownloadLimit
+}
@@ -0,0 +1,110 @@
+package entity
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// GiftCardSource is how a gift card came to exist
+type GiftCardSource string
+
+const (
+	// GiftCardSourcePurchase cards were bought as a product
+	GiftCardSourcePurchase GiftCardSource = "purchase"
+	// GiftCardSourceAdmin cards were issued by an admin, e.g. as a goodwill gesture
+	GiftCardSourceAdmin GiftCardSource = "admin"
+)
+
+// GiftCardTransactionType classifies a change to a gift card balance
+type GiftCardTransactionType string
+
+const (
+	// GiftCardIssue is the initial balance of a card
+	GiftCardIssue GiftCardTransactionType = "issue"
+	// GiftCardRedeem pays for an order; its amount is negative
+	GiftCardRedeem GiftCardTransactionType = "redeem"
+	// GiftCardRelease gives back what an order took when its payment failed
+	GiftCardRelease GiftCardTransactionType = "release"
+	// GiftCardRefund puts back a refund of an order paid by the card
+	GiftCardRefund GiftCardTransactionType = "refund"
+)
+
+// GiftCard is a stored balance customers spend at checkout by entering its
+// code. Only a hash of the code is kept; the code itself is shown once, when
+// the card is issued, and sent to its holder.
+type GiftCard struct {
+	ID             string         `json:"id" gorm:"primaryKey;column:id;type:varchar(36);default:(UUID());comment:'Primary Key'"`
+	CodeHash       string         `json:"-" gorm:"column:code_hash;type:char(64);uniqueIndex;not null;comment:'SHA-256 of the normalised code'"`
+	Last4          string         `json:"last4" gorm:"column:last4;type:varchar(4);not null;comment:'Last characters of the code, to tell cards apart'"`
+	Currency       string         `json:"currency" gorm:"column:currency;type:varchar(10);not null;comment:'Currency code'"`
+	InitialBalance float64        `json:"initial_balance" gorm:"column:initial_balance;type:decimal(12,2);not null;comment:'Balance when issued'"`
+	Balance        float64        `json:"balance" gorm:"column:balance;type:decimal(12,2);not null;comment:'Balance left to spend'"`
+	ExpiresAt      *time.Time     `json:"expires_at,omitempty" gorm:"column:expires_at;comment:'When the card can no longer be used, null for no expiry'"`
+	IsActive       bool           `json:"is_active" gorm:"column:is_active;not null;default:true;comment:'Can be spent'"`
+	Source         GiftCardSource `json:"source" gorm:"column:source;type:ENUM('purchase','admin');not null;comment:'How the card was issued'"`
+	// OrderItemID is the order line a purchased card was bought with
+	OrderItemID *string   `json:"order_item_id,omitempty" gorm:"column:order_item_id;type:varchar(36);index;comment:'FK to the order item the card was bought with'"`
+	UserID      *string   `json:"user_id,omitempty" gorm:"column:user_id;type:varchar(36);index;comment:'FK to the user the code was sent to'"`
+	Note        string    `json:"note,omitempty" gorm:"column:note;type:varchar(255);comment:'Internal note'"`
+	CreatedAt   time.Time `json:"created_at" gorm:"autoCreateTime;column:created_at;comment:'Created at'"`
+	UpdatedAt   time.Time `json:"updated_at" gorm:"autoUpdateTime;column:updated_at;comment:'Updated at'"`
+
+	// Relations
+	Transactions []GiftCardTransaction `json:"transactions,omitempty" gorm:"foreignKey:GiftCardID"`
+}
+
+// TableName specifies the table name for the GiftCard model
+func (GiftCard) TableName() string {
+	return "giftCards"
+}
+
+// BeforeCreate sets timestamps and performs any pre-creation logic.
+func (g *GiftCard) BeforeCreate(tx *gorm.DB) (err error) {
+	now := time.Now().UTC()
+	if g.CreatedAt.IsZero() {
+		g.CreatedAt = now
+	}
+	g.UpdatedAt = now
+	return nil
+}
+
+// BeforeUpdate updates the UpdatedAt timestamp before updating an existing record.
+func (g *GiftCard) BeforeUpdate(tx *gorm.DB) (err error) {
+	g.UpdatedAt = time.Now().UTC()
+	return nil
+}
+
+// GiftCardTransaction is an entry of a gift card's ledger. Every balance
+// change is recorded, so the balance is always the sum of the entries.
+type GiftCardTransaction struct {
+	ID           string                  `json:"id" gorm:"primaryKey;column:id;type:varchar(36);default:(UUID());comment:'Primary Key'"`
+	GiftCardID   string                  `json:"gift_card_id" gorm:"column:gift_card_id;type:varchar(36);not null;index;comment:'FK to gift card'"`
+	Type         GiftCardTransactionType `json:"type" gorm:"column:type;type:ENUM('issue','redeem','release','refund');not null;comment:'Transaction type'"`
+	Amount       float64                 `json:"amount" gorm:"column:amount;type:decimal(12,2);not null;comment:'Balance change, negative when spent'"`
+	BalanceAfter float64                 `json:"balance_after" gorm:"column:balance_after;type:decimal(12,2);not null;comment:'Balance after the change'"`
+	OrderID      *string                 `json:"order_id,omitempty" gorm:"column:order_id;type:varchar(36);index;comment:'FK to order'"`
+	PaymentID    *string                 `json:"payment_id,omitempty" gorm:"column:payment_id;type:varchar(36);index;comment:'FK to the payment made with the card'"`
+	Note         string                  `json:"note,omitempty" gorm:"column:note;type:varchar(255);comment:'Reason for the change'"`
+	CreatedAt    time.Time               `json:"created_at" gorm:"autoCreateTime;column:created_at;comment:'Created at'"`
+}
+
+// TableName specifies the table name for the GiftCardTransaction model
+func (GiftCardTransaction) TableName() string {
+	return "giftCardTransactions"
+}
+
+// GiftCardProduct makes a digital product a gift card: each unit bought
+// issues a card worth the price paid for it, sent to the buyer by email
+type GiftCardProduct struct {
+	ProductID string `json:"product_id" gorm:"primaryKey;column:product_id;type:varchar(36);comment:'FK to product'"`
+	// ValidityDays is how long cards stay usable after purchase, 0 for no expiry
+	ValidityDays int       `json:"validity_days" gorm:"column:validity_days;type:int;not null;default:0;comment:'Days cards stay usable, 0 for no expiry'"`
+	CreatedAt    time.Time `json:"created_at" gorm:"autoCreateTime;column:created_at;comment:'Created at'"`
+	UpdatedAt    time.Time `json:"updated_at" gorm:"autoUpdateTime;column:updated_at;comment:'Updated at'"`
+}
+
+// TableName specifies the table name for the GiftCardProduct model
+func (GiftCardProduct) TableName() string {
+	return "giftCardProducts"
+}
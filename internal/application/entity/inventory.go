@@ -2,20 +2,34 @@ package entity
 
 import (
 	"time"
+
 	"gorm.io/gorm"
 )
 
-// Inventory tracks product stock levels
+// Inventory tracks the stock level of a product, or of one of its variants,
+// at a stock location
 type Inventory struct {
-	ID            string    `json:"id" gorm:"primaryKey;column:id;type:varchar(36);default:(UUID());comment:'Primary Key'"`
-	ProductID     string    `json:"product_id" gorm:"column:product_id;type:varchar(36);uniqueIndex;not null;comment:'FK to product'"`
-	Quantity      int       `json:"quantity" gorm:"column:quantity;type:int;not null;default:0;comment:'Available quantity'"`
-	Reserved      int       `json:"reserved" gorm:"column:reserved;type:int;not null;default:0;comment:'Reserved quantity'"`
-	CreatedAt     time.Time `json:"created_at" gorm:"autoCreateTime;column:created_at;comment:'Created at'"`
-	UpdatedAt     time.Time `json:"updated_at" gorm:"autoUpdateTime;column:updated_at;comment:'Updated at'"`
-	
-	// Available calculates available stock (quantity - reserved)
-	// Available     int       `json:"available" gorm:"-"`
+	ID         string  `json:"id" gorm:"primaryKey;column:id;type:varchar(36);default:(UUID());comment:'Primary Key'"`
+	LocationID string  `json:"location_id" gorm:"column:location_id;type:varchar(36);not null;uniqueIndex:idx_inventory_stock;comment:'FK to stock location'"`
+	ProductID  string  `json:"product_id" gorm:"column:product_id;type:varchar(36);not null;uniqueIndex:idx_inventory_stock;index;comment:'FK to product'"`
+	VariantID  *string `json:"variant_id,omitempty" gorm:"column:variant_id;type:varchar(36);uniqueIndex:idx_inventory_stock;comment:'FK to product variant, empty for product-level stock'"`
+	Quantity   int     `json:"quantity" gorm:"column:quantity;type:int;not null;default:0;comment:'Available quantity'"`
+	Reserved   int     `json:"reserved" gorm:"column:reserved;type:int;not null;default:0;comment:'Reserved quantity'"`
+	// ReorderPoint is the available quantity at or below which the stock is
+	// reported as low; 0 turns low-stock reporting off
+	ReorderPoint    int `json:"reorder_point" gorm:"column:reorder_point;type:int;not null;default:0;comment:'Available quantity that triggers a reorder'"`
+	ReorderQuantity int `json:"reorder_quantity" gorm:"column:reorder_quantity;type:int;not null;default:0;comment:'Suggested quantity to reorder'"`
+	// AverageCost is the moving average landed cost of the units on hand,
+	// updated whenever goods are received
+	AverageCost float64   `json:"average_cost" gorm:"column:average_cost;type:decimal(12,4);not null;default:0;comment:'Moving average landed cost per unit'"`
+	CreatedAt   time.Time `json:"created_at" gorm:"autoCreateTime;column:created_at;comment:'Created at'"`
+	UpdatedAt   time.Time `json:"updated_at" gorm:"autoUpdateTime;column:updated_at;comment:'Updated at'"`
+
+	// Available is the sellable stock (quantity - reserved), set by CalculateAvailable
+	Available int `json:"available" gorm:"-"`
+
+	// Relations
+	Location *StockLocation `json:"location,omitempty" gorm:"foreignKey:LocationID"`
 }
 
 // TableName specifies the table name for the Inventory model
@@ -23,9 +37,15 @@ func (Inventory) TableName() string {
 	return "inventory"
 }
 
+// IsLow reports whether the stock has fallen to its reorder point. Call
+// CalculateAvailable first.
+func (i Inventory) IsLow() bool {
+	return i.ReorderPoint > 0 && i.Available <= i.ReorderPoint
+}
+
 // CalculateAvailable updates the Available field
 func (i *Inventory) CalculateAvailable() {
-	i.Available = i.Quantity - i.Reserved
+	i.Available = max(0, i.Quantity-i.Reserved)
 }
 
 // BeforeCreate sets timestamps and performs any pre-creation logic.
@@ -42,4 +62,54 @@ func (i *Inventory) BeforeCreate(tx *gorm.DB) (err error) {
 func (u *Inventory) BeforeUpdate(tx *gorm.DB) (err error) {
 	u.UpdatedAt = time.Now().UTC()
 	return nil
-}
\ No newline at end of file
+}
+
+// InventoryReservationStatus represents the state of a stock reservation
+type InventoryReservationStatus string
+
+const (
+	// InventoryReservationActive holds stock for an order awaiting payment
+	InventoryReservationActive InventoryReservationStatus = "active"
+	// InventoryReservationCommitted means the stock left the inventory with a paid order
+	InventoryReservationCommitted InventoryReservationStatus = "committed"
+	// InventoryReservationReleased means the stock went back on sale, e.g. after a failed payment
+	InventoryReservationReleased InventoryReservationStatus = "released"
+	// InventoryReservationExpired means the reservation outlived its TTL and the stock went back on sale
+	InventoryReservationExpired InventoryReservationStatus = "expired"
+)
+
+// InventoryReservation holds stock of a product for an order during checkout.
+// While active, its quantity is counted in Inventory.Reserved.
+type InventoryReservation struct {
+	ID         string                     `json:"id" gorm:"primaryKey;column:id;type:varchar(36);default:(UUID());comment:'Primary Key'"`
+	OrderID    string                     `json:"order_id" gorm:"column:order_id;type:varchar(36);not null;index;comment:'FK to order'"`
+	LocationID string                     `json:"location_id" gorm:"column:location_id;type:varchar(36);not null;comment:'FK to the stock location the order ships from'"`
+	ProductID  string                     `json:"product_id" gorm:"column:product_id;type:varchar(36);not null;index;comment:'FK to product'"`
+	VariantID  *string                    `json:"variant_id,omitempty" gorm:"column:variant_id;type:varchar(36);comment:'FK to product variant'"`
+	Quantity   int                        `json:"quantity" gorm:"column:quantity;type:int;not null;comment:'Reserved quantity'"`
+	Status     InventoryReservationStatus `json:"status" gorm:"column:status;type:ENUM('active','committed','released','expired');not null;default:'active';index:idx_reservation_status_expiry,priority:1;comment:'Reservation status'"`
+	ExpiresAt  time.Time                  `json:"expires_at" gorm:"column:expires_at;not null;index:idx_reservation_status_expiry,priority:2;comment:'When an active reservation is released automatically'"`
+	CreatedAt  time.Time                  `json:"created_at" gorm:"autoCreateTime;column:created_at;comment:'Created at'"`
+	UpdatedAt  time.Time                  `json:"updated_at" gorm:"autoUpdateTime;column:updated_at;comment:'Updated at'"`
+}
+
+// TableName specifies the table name for the InventoryReservation model
+func (InventoryReservation) TableName() string {
+	return "inventoryReservations"
+}
+
+// BeforeCreate sets timestamps and performs any pre-creation logic.
+func (r *InventoryReservation) BeforeCreate(tx *gorm.DB) (err error) {
+	now := time.Now().UTC()
+	if r.CreatedAt.IsZero() {
+		r.CreatedAt = now
+	}
+	r.UpdatedAt = now
+	return nil
+}
+
+// BeforeUpdate updates the UpdatedAt timestamp before updating an existing record.
+func (r *InventoryReservation) BeforeUpdate(tx *gorm.DB) (err error) {
+	r.UpdatedAt = time.Now().UTC()
+	return nil
+}
@@ -0,0 +1,59 @@
+package entity
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// NotificationStatus represents the delivery state of a notification
+type NotificationStatus string
+
+const (
+	NotificationStatusPending NotificationStatus = "pending"
+	NotificationStatusSent    NotificationStatus = "sent"
+	NotificationStatusFailed  NotificationStatus = "failed"
+	// NotificationStatusExpired is set on notifications held back by rate
+	// limits for so long that they are no longer relevant
+	NotificationStatusExpired NotificationStatus = "expired"
+)
+
+// Notification is a queued message to a user. DedupKey is unique, so the
+// same event is never queued twice. The body of a Sensitive notification,
+// e.g. one carrying a gift card code, is wiped once delivery has finished.
+type Notification struct {
+	ID        string             `json:"id" gorm:"primaryKey;column:id;type:varchar(36);default:(UUID());comment:'Primary Key'"`
+	UserID    string             `json:"user_id" gorm:"column:user_id;type:varchar(36);not null;index;comment:'FK to user entity'"`
+	Kind      string             `json:"kind" gorm:"column:kind;type:varchar(50);not null;comment:'What the notification is about, e.g. back_in_stock'"`
+	DedupKey  string             `json:"dedup_key" gorm:"column:dedup_key;type:varchar(255);uniqueIndex;not null;comment:'Identifies the event to avoid duplicates'"`
+	Subject   string             `json:"subject" gorm:"column:subject;type:varchar(255);not null;comment:'Message subject'"`
+	Body      string             `json:"body" gorm:"column:body;type:text;not null;comment:'Message body'"`
+	Sensitive bool               `json:"sensitive" gorm:"column:sensitive;not null;default:false;comment:'Body is wiped once delivery has finished'"`
+	Status    NotificationStatus `json:"status" gorm:"column:status;type:ENUM('pending','sent','failed','expired');not null;default:'pending';index;comment:'Delivery status'"`
+	Attempts  int                `json:"attempts" gorm:"column:attempts;type:int;not null;default:0;comment:'Delivery attempts'"`
+	LastError string             `json:"last_error,omitempty" gorm:"column:last_error;type:varchar(500);comment:'Error of the last failed attempt'"`
+	SentAt    *time.Time         `json:"sent_at,omitempty" gorm:"column:sent_at;index;comment:'When the notification was delivered'"`
+	CreatedAt time.Time          `json:"created_at" gorm:"autoCreateTime;column:created_at;comment:'Created at'"`
+	UpdatedAt time.Time          `json:"updated_at" gorm:"autoUpdateTime;column:updated_at;comment:'Updated at'"`
+}
+
+// TableName specifies the table name for the Notification model
+func (Notification) TableName() string {
+	return "notifications"
+}
+
+// BeforeCreate sets timestamps and performs any pre-creation logic.
+func (n *Notification) BeforeCreate(tx *gorm.DB) (err error) {
+	now := time.Now().UTC()
+	if n.CreatedAt.IsZero() {
+		n.CreatedAt = now
+	}
+	n.UpdatedAt = now
+	return nil
+}
+
+// BeforeUpdate updates the UpdatedAt timestamp before updating an existing record.
+func (n *Notification) BeforeUpdate(tx *gorm.DB) (err error) {
+	n.UpdatedAt = time.Now().UTC()
+	return nil
+}
@@ -10,32 +10,38 @@ import (
 type OrderStatus string
 
 const (
-	OrderStatusPending     OrderStatus = "pending"
-	OrderStatusPaid        OrderStatus = "paid"
-	OrderStatusProcessing  OrderStatus = "processing"
-	OrderStatusShipped     OrderStatus = "shipped"
-	OrderStatusCancelled   OrderStatus = "cancelled"
-	OrderStatusCompleted   OrderStatus = "completed"
+	OrderStatusPending    OrderStatus = "pending"
+	OrderStatusPaid       OrderStatus = "paid"
+	OrderStatusProcessing OrderStatus = "processing"
+	OrderStatusShipped    OrderStatus = "shipped"
+	OrderStatusCancelled  OrderStatus = "cancelled"
+	OrderStatusCompleted  OrderStatus = "completed"
 )
 
 // Order represents a customer order
 type Order struct {
-	ID                string         `json:"id" gorm:"primaryKey;column:id;type:varchar(36);default:(UUID());comment:'Primary Key'"`
-	UserID            *string        `json:"user_id,omitempty" gorm:"column:user_id;type:varchar(36);comment:'FK to user'"`
-	Status            OrderStatus    `json:"status" gorm:"column:status;type:ENUM('pending','paid','processing','shipped','cancelled','completed');default:'pending';comment:'Order status'"`
-	TotalAmount       float64        `json:"total_amount" gorm:"column:total_amount;type:decimal(12,2);not null;comment:'Total order amount'"`
-	Currency          string         `json:"currency" gorm:"column:currency;type:varchar(10);not null;default:'USD';comment:'Currency code'"`
-	ShippingAddressID *string        `json:"shipping_address_id,omitempty" gorm:"column:shipping_address_id;type:varchar(36);comment:'FK to shipping address'"`
-	BillingAddressID  *string        `json:"billing_address_id,omitempty" gorm:"column:billing_address_id;type:varchar(36);comment:'FK to billing address'"`
-	CreatedAt         time.Time      `json:"created_at" gorm:"autoCreateTime;column:created_at;comment:'Created at'"`
-	UpdatedAt         time.Time      `json:"updated_at" gorm:"autoUpdateTime;column:updated_at;comment:'Updated at'"`
-	
+	ID                string      `json:"id" gorm:"primaryKey;column:id;type:varchar(36);default:(UUID());comment:'Primary Key'"`
+	UserID            *string     `json:"user_id,omitempty" gorm:"column:user_id;type:varchar(36);comment:'FK to user'"`
+	Status            OrderStatus `json:"status" gorm:"column:status;type:ENUM('pending','paid','processing','shipped','cancelled','completed');default:'pending';comment:'Order status'"`
+	Subtotal          float64     `json:"subtotal" gorm:"column:subtotal;type:decimal(12,2);not null;default:0;comment:'Sum of line subtotals'"`
+	DiscountTotal     float64     `json:"discount_total" gorm:"column:discount_total;type:decimal(12,2);not null;default:0;comment:'Sum of discounts'"`
+	ShippingTotal     float64     `json:"shipping_total" gorm:"column:shipping_total;type:decimal(12,2);not null;default:0;comment:'Shipping charged'"`
+	TaxTotal          float64     `json:"tax_total" gorm:"column:tax_total;type:decimal(12,2);not null;default:0;comment:'Tax charged'"`
+	GiftCardTotal     float64     `json:"gift_card_total" gorm:"column:gift_card_total;type:decimal(12,2);not null;default:0;comment:'Paid with gift cards, the rest is due through Stripe'"`
+	TotalAmount       float64     `json:"total_amount" gorm:"column:total_amount;type:decimal(12,2);not null;comment:'Total order amount'"`
+	Currency          string      `json:"currency" gorm:"column:currency;type:varchar(10);not null;default:'USD';comment:'Currency code'"`
+	ShippingAddressID *string     `json:"shipping_address_id,omitempty" gorm:"column:shipping_address_id;type:varchar(36);comment:'FK to shipping address'"`
+	BillingAddressID  *string     `json:"billing_address_id,omitempty" gorm:"column:billing_address_id;type:varchar(36);comment:'FK to billing address'"`
+	CreatedAt         time.Time   `json:"created_at" gorm:"autoCreateTime;column:created_at;comment:'Created at'"`
+	UpdatedAt         time.Time   `json:"updated_at" gorm:"autoUpdateTime;column:updated_at;comment:'Updated at'"`
+
 	// Relations
-	User              *User          `json:"user,omitempty" gorm:"foreignKey:UserID"`
-	ShippingAddress   *Address       `json:"shipping_address,omitempty" gorm:"foreignKey:ShippingAddressID"`
-	BillingAddress    *Address       `json:"billing_address,omitempty" gorm:"foreignKey:BillingAddressID"`
-	Items             []OrderItem    `json:"items,omitempty" gorm:"foreignKey:OrderID"`
-	Payment           *Payment       `json:"payment,omitempty" gorm:"foreignKey:OrderID"`
+	User            *User             `json:"user,omitempty" gorm:"foreignKey:UserID"`
+	ShippingAddress *Address          `json:"shipping_address,omitempty" gorm:"foreignKey:ShippingAddressID"`
+	BillingAddress  *Address          `json:"billing_address,omitempty" gorm:"foreignKey:BillingAddressID"`
+	Items           []OrderItem       `json:"items,omitempty" gorm:"foreignKey:OrderID"`
+	Adjustments     []OrderAdjustment `json:"adjustments,omitempty" gorm:"foreignKey:OrderID"`
+	Payment         *Payment          `json:"payment,omitempty" gorm:"foreignKey:OrderID"`
 }
 
 // TableName specifies the table name for the Order model
@@ -59,21 +65,27 @@ func (o *Order) BeforeUpdate(tx *gorm.DB) (err error) {
 	return nil
 }
 
-// OrderItem represents an item in an order
+// OrderItem represents an item in an order. A bundle is recorded as a parent
+// line carrying the price paid plus one component line per bundled product;
+// component lines hold their allocated share of the bundle price and are not
+// added to the order total again.
 type OrderItem struct {
 	ID           string    `json:"id" gorm:"primaryKey;column:id;type:varchar(36);default:(UUID());comment:'Primary Key'"`
 	OrderID      string    `json:"order_id" gorm:"column:order_id;type:varchar(36);not null;comment:'FK to order'"`
 	ProductID    *string   `json:"product_id,omitempty" gorm:"column:product_id;type:varchar(36);comment:'FK to product'"`
+	VariantID    *string   `json:"variant_id,omitempty" gorm:"column:variant_id;type:varchar(36);comment:'FK to product variant'"`
+	ParentItemID *string   `json:"parent_item_id,omitempty" gorm:"column:parent_item_id;type:varchar(36);index;comment:'FK to the bundle line this component belongs to'"`
 	ProductName  string    `json:"product_name" gorm:"column:product_name;type:varchar(255);not null;comment:'Product name at time of order'"`
 	SKU          string    `json:"sku,omitempty" gorm:"column:sku;type:varchar(100);comment:'Product SKU'"`
 	Quantity     int       `json:"quantity" gorm:"column:quantity;type:int;not null;comment:'Item quantity'"`
 	UnitPrice    float64   `json:"unit_price" gorm:"column:unit_price;type:decimal(12,2);not null;comment:'Price per unit'"`
 	TotalPrice   float64   `json:"total_price" gorm:"column:total_price;type:decimal(12,2);not null;comment:'Total price (quantity * unit_price)'"`
 	CreatedAt    time.Time `json:"created_at" gorm:"autoCreateTime;column:created_at;comment:'Created at'"`
-	
+
 	// Relations
-	Order        *Order     `json:"-" gorm:"foreignKey:OrderID"`
-	Product      *Product   `json:"product,omitempty" gorm:"foreignKey:ProductID"`
+	Order      *Order      `json:"-" gorm:"foreignKey:OrderID"`
+	Product    *Product    `json:"product,omitempty" gorm:"foreignKey:ProductID"`
+	Components []OrderItem `json:"components,omitempty" gorm:"foreignKey:ParentItemID"`
 }
 
 // TableName specifies the table name for the OrderItem model
@@ -89,3 +101,21 @@ func (oi *OrderItem) BeforeCreate(tx *gorm.DB) (err error) {
 	}
 	return nil
 }
+
+// OrderAdjustment records a discount, shipping or tax adjustment of an order
+// as it was quoted when the order was created
+type OrderAdjustment struct {
+	ID          string         `json:"id" gorm:"primaryKey;column:id;type:varchar(36);default:(UUID());comment:'Primary Key'"`
+	OrderID     string         `json:"order_id" gorm:"column:order_id;type:varchar(36);not null;index;comment:'FK to order'"`
+	OrderItemID *string        `json:"order_item_id,omitempty" gorm:"column:order_item_id;type:varchar(36);comment:'FK to the order line adjusted, empty for the whole order'"`
+	Type        AdjustmentType `json:"type" gorm:"column:type;type:ENUM('discount','shipping','tax');not null;comment:'Adjustment type'"`
+	Source      string         `json:"source" gorm:"column:source;type:varchar(100);not null;comment:'Rule, code or rate the adjustment came from'"`
+	Label       string         `json:"label" gorm:"column:label;type:varchar(255);comment:'Label shown to the customer'"`
+	Amount      float64        `json:"amount" gorm:"column:amount;type:decimal(12,2);not null;comment:'Amount, negative for discounts'"`
+	CreatedAt   time.Time      `json:"created_at" gorm:"autoCreateTime;column:created_at;comment:'Created at'"`
+}
+
+// TableName specifies the table name for the OrderAdjustment model
+func (OrderAdjustment) TableName() string {
+	return "orderAdjustments"
+}
@@ -16,8 +16,19 @@ const (
 	PaymentStatusSucceeded PaymentStatus = "succeeded"
 	PaymentStatusFailed    PaymentStatus = "failed"
 	PaymentStatusRefunded  PaymentStatus = "refunded"
+
+	// PaymentStatusPartiallyRefunded is set until the whole amount is refunded
+	PaymentStatusPartiallyRefunded PaymentStatus = "partially_refunded"
 )
 
+// PaymentProviderStripe is the provider of card payments; their
+// ProviderPaymentID is the Stripe payment intent's ID
+const PaymentProviderStripe = "stripe"
+
+// PaymentProviderGiftCard is the provider of payments made with a gift card;
+// their ProviderPaymentID is the card's ID
+const PaymentProviderGiftCard = "gift_card"
+
 // RawResponse is a custom type to handle JSON data in the database
 type RawResponse map[string]interface{}
 
@@ -46,7 +57,7 @@ type Payment struct {
 	Status            PaymentStatus     `json:"status" gorm:"column:status;type:ENUM('initiated','succeeded','failed','refunded','partially_refunded');default:'initiated';comment:'Payment status'"`
 	TransactionID     string            `json:"transaction_id,omitempty" gorm:"column:transaction_id;type:varchar(255);comment:'Transaction ID from payment provider'"`
 	ClientSecret      string            `json:"client_secret,omitempty" gorm:"-"` // Not stored in DB
-	Metadata          map[string]string `json:"metadata,omitempty" gorm:"type:json;column:metadata;comment:'Additional payment metadata'"`
+	Metadata          map[string]string `json:"metadata,omitempty" gorm:"type:json;serializer:json;column:metadata;comment:'Additional payment metadata'"`
 	RawResponse       *RawResponse      `json:"raw_response,omitempty" gorm:"type:json;column:raw_response;comment:'Raw response from payment provider'"`
 	CreatedAt         time.Time         `json:"created_at" gorm:"autoCreateTime;column:created_at;comment:'Created at'"`
 	UpdatedAt         time.Time         `json:"updated_at" gorm:"autoUpdateTime;column:updated_at;comment:'Updated at'"`
@@ -0,0 +1,108 @@
+package entity
+
+// AdjustmentType classifies a price adjustment
+type AdjustmentType string
+
+const (
+	// AdjustmentDiscount lowers the price; its amount is negative
+	AdjustmentDiscount AdjustmentType = "discount"
+	// AdjustmentShipping is a shipping charge
+	AdjustmentShipping AdjustmentType = "shipping"
+	// AdjustmentTax is a tax charge
+	AdjustmentTax AdjustmentType = "tax"
+)
+
+// PriceAdjustment is an itemised change to the price of a cart or order,
+// attributed to the rule, code or rate it came from
+type PriceAdjustment struct {
+	Type AdjustmentType `json:"type"`
+	// Source identifies what produced the adjustment, e.g. "shipping:flat_rate" or "tax:us-ca"
+	Source string `json:"source"`
+	Label  string `json:"label"`
+	// LineID is the cart item the adjustment applies to, or "" for the whole cart
+	LineID string  `json:"line_id,omitempty"`
+	Amount float64 `json:"amount"`
+}
+
+// QuoteLine is a priced cart line
+type QuoteLine struct {
+	LineID    string  `json:"line_id"`
+	ProductID string  `json:"product_id"`
+	VariantID *string `json:"variant_id,omitempty"`
+	Name      string  `json:"name"`
+	SKU       string  `json:"sku,omitempty"`
+	// Quantity is what can be bought: the quantity Requested, capped at the
+	// stock available
+	Quantity  int     `json:"quantity"`
+	Requested int     `json:"requested"`
+	UnitPrice float64 `json:"unit_price"`
+	// Subtotal is UnitPrice * Quantity; Discount and Tax are the line's share
+	// of the discount and tax adjustments, and Total what the line costs
+	Subtotal float64 `json:"subtotal"`
+	Discount float64 `json:"discount"`
+	Tax      float64 `json:"tax"`
+	Total    float64 `json:"total"`
+	// Offered is false when the product or variant is no longer on sale; such
+	// lines are left out of the totals
+	Offered bool `json:"offered"`
+	// Available is the stock the line is sold from, or -1 when unlimited
+	Available        int  `json:"available"`
+	RequiresShipping bool `json:"requires_shipping"`
+	// Promotions explains which automatic promotions discounted the line
+	Promotions []LinePromotion `json:"promotions,omitempty"`
+}
+
+// LinePromotion is an automatic promotion's discount on one line
+type LinePromotion struct {
+	PromotionID string `json:"promotion_id"`
+	Name        string `json:"name"`
+	// Explanation says what the line earned, e.g. "1 of 3 free"
+	Explanation string  `json:"explanation"`
+	Amount      float64 `json:"amount"`
+}
+
+// PriceQuote is the full price breakdown of a cart, used both to show the
+// cart and to create the order, so both always agree.
+// GrandTotal = Subtotal - DiscountTotal + ShippingTotal + TaxTotal.
+type PriceQuote struct {
+	Currency      string            `json:"currency"`
+	Lines         []QuoteLine       `json:"lines"`
+	Adjustments   []PriceAdjustment `json:"adjustments"`
+	Coupons       []QuoteCoupon     `json:"coupons"`
+	Promotions    []QuotePromotion  `json:"promotions"`
+	Subtotal      float64           `json:"subtotal"`
+	DiscountTotal float64           `json:"discount_total"`
+	ShippingTotal float64           `json:"shipping_total"`
+	TaxTotal      float64           `json:"tax_total"`
+	GrandTotal    float64           `json:"grand_total"`
+}
+
+// QuoteCoupon is a discount code applied to a cart and whether it took
+// effect. Its discounts are the adjustments with Source "coupon:" + Code.
+type QuoteCoupon struct {
+	CouponID string     `json:"coupon_id"`
+	CodeID   string     `json:"code_id"`
+	Code     string     `json:"code"`
+	Name     string     `json:"name"`
+	Type     CouponType `json:"type"`
+	// Applied is false when the code does not apply to the cart as it is;
+	// Reason says why
+	Applied bool   `json:"applied"`
+	Reason  string `json:"reason,omitempty"`
+	// Amount is the discount the code gave, including shipping waived
+	Amount float64 `json:"amount"`
+}
+
+// QuotePromotion is an automatic promotion evaluated against a cart and
+// whether it took effect. Quotes list them in priority order; the discounts
+// are the adjustments with Source "promotion:" + PromotionID.
+type QuotePromotion struct {
+	PromotionID string        `json:"promotion_id"`
+	Name        string        `json:"name"`
+	Type        PromotionType `json:"type"`
+	// Applied is false when the cart does not qualify, or an exclusive
+	// promotion prevented it; Reason says why
+	Applied bool    `json:"applied"`
+	Reason  string  `json:"reason,omitempty"`
+	Amount  float64 `json:"amount"`
+}
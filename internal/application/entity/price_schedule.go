@@ -0,0 +1,101 @@
+package entity
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// PriceScheduleType represents the kind of scheduled price
+type PriceScheduleType string
+
+const (
+	// PriceScheduleSale sets a temporary price between StartsAt and EndsAt
+	PriceScheduleSale PriceScheduleType = "sale"
+	// PriceSchedulePermanent replaces the regular price from StartsAt onwards
+	PriceSchedulePermanent PriceScheduleType = "permanent"
+)
+
+// PriceSchedule is a future or time-boxed price for a product or one of its variants
+type PriceSchedule struct {
+	ID        string            `json:"id" gorm:"primaryKey;column:id;type:varchar(36);default:(UUID());comment:'Primary Key'"`
+	ProductID string            `json:"product_id" gorm:"column:product_id;type:varchar(36);not null;index;comment:'FK to product'"`
+	VariantID *string           `json:"variant_id,omitempty" gorm:"column:variant_id;type:varchar(36);index;comment:'FK to product variant, null for the product itself'"`
+	Type      PriceScheduleType `json:"type" gorm:"column:type;type:ENUM('sale','permanent');not null;comment:'Schedule type'"`
+	Price     float64           `json:"price" gorm:"column:price;type:decimal(12,2);not null;comment:'Scheduled price'"`
+	StartsAt  time.Time         `json:"starts_at" gorm:"column:starts_at;not null;index;comment:'Price takes effect at'"`
+	EndsAt    *time.Time        `json:"ends_at,omitempty" gorm:"column:ends_at;index;comment:'Sale ends at, null for open-ended'"`
+	AppliedAt *time.Time        `json:"applied_at,omitempty" gorm:"column:applied_at;comment:'When a permanent price was written to the product'"`
+	CreatedAt time.Time         `json:"created_at" gorm:"autoCreateTime;column:created_at;comment:'Created at'"`
+	UpdatedAt time.Time         `json:"updated_at" gorm:"autoUpdateTime;column:updated_at;comment:'Updated at'"`
+}
+
+// TableName specifies the table name for the PriceSchedule model
+func (PriceSchedule) TableName() string {
+	return "priceSchedules"
+}
+
+// BeforeCreate sets timestamps and performs any pre-creation logic.
+func (p *PriceSchedule) BeforeCreate(tx *gorm.DB) (err error) {
+	now := time.Now().UTC()
+	if p.CreatedAt.IsZero() {
+		p.CreatedAt = now
+	}
+	p.UpdatedAt = now
+	return nil
+}
+
+// BeforeUpdate updates the UpdatedAt timestamp before updating an existing record.
+func (p *PriceSchedule) BeforeUpdate(tx *gorm.DB) (err error) {
+	p.UpdatedAt = time.Now().UTC()
+	return nil
+}
+
+// IsActiveAt reports whether the schedule is in effect at t
+func (p *PriceSchedule) IsActiveAt(t time.Time) bool {
+	if t.Before(p.StartsAt) {
+		return false
+	}
+	if p.Type == PriceScheduleSale && p.EndsAt != nil && !t.Before(*p.EndsAt) {
+		return false
+	}
+	return true
+}
+
+// EffectivePrice is what a product or variant sells for at a point in time
+type EffectivePrice struct {
+	Price          float64
+	CompareAtPrice *float64 // regular price while a sale is running
+	SaleEndsAt     *time.Time
+}
+
+// ResolvePrice works out the effective price at t from a regular price and
+// the schedules that target the same product or variant. A permanent change
+// that is due but not yet applied replaces the regular price; the lowest
+// running sale below the regular price then wins.
+func ResolvePrice(regular float64, schedules []PriceSchedule, t time.Time) EffectivePrice {
+	var latestPermanent *PriceSchedule
+	for i := range schedules {
+		s := &schedules[i]
+		if s.Type != PriceSchedulePermanent || s.AppliedAt != nil || !s.IsActiveAt(t) {
+			continue
+		}
+		if latestPermanent == nil || s.StartsAt.After(latestPermanent.StartsAt) {
+			latestPermanent = s
+		}
+	}
+	if latestPermanent != nil {
+		regular = latestPermanent.Price
+	}
+
+	result := EffectivePrice{Price: regular}
+	for i := range schedules {
+		s := &schedules[i]
+		if s.Type != PriceScheduleSale || !s.IsActiveAt(t) || s.Price >= result.Price {
+			continue
+		}
+		compareAt := regular
+		result = EffectivePrice{Price: s.Price, CompareAtPrice: &compareAt, SaleEndsAt: s.EndsAt}
+	}
+	return result
+}
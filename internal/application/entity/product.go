@@ -1,28 +1,124 @@
 package entity
 
 import (
+	"database/sql/driver"
+	"encoding/json"
+	"strings"
 	"time"
 
 	"gorm.io/gorm"
 )
 
+// ProductType represents how a product is sold and fulfilled
+type ProductType string
+
+const (
+	ProductTypeSimple ProductType = "simple"
+	ProductTypeBundle ProductType = "bundle"
+	// ProductTypeDigital is delivered as downloadable files and never ships
+	ProductTypeDigital ProductType = "digital"
+)
+
+// BundlePricing represents how the price of a bundle is determined
+type BundlePricing string
+
+const (
+	// BundlePricingFixed sells the bundle at the product's own price
+	BundlePricingFixed BundlePricing = "fixed"
+	// BundlePricingComputed sums the component prices and applies BundleDiscount
+	BundlePricingComputed BundlePricing = "computed"
+)
+
+// ProductStatus represents where a product is in the publishing workflow.
+// Only published products are visible in the storefront. The column defaults
+// to published so products that predate the workflow stay visible.
+type ProductStatus string
+
+const (
+	ProductStatusDraft     ProductStatus = "draft"
+	ProductStatusInReview  ProductStatus = "in_review"
+	ProductStatusPublished ProductStatus = "published"
+)
+
+// ProductAttributes holds free-form product specifications (material, size, ...)
+type ProductAttributes map[string]string
+
+// Value implements the driver.Valuer interface
+func (a ProductAttributes) Value() (driver.Value, error) {
+	if a == nil {
+		return nil, nil
+	}
+	return json.Marshal(a)
+}
+
+// Scan implements the sql.Scanner interface
+func (a *ProductAttributes) Scan(value interface{}) error {
+	if value == nil {
+		*a = nil
+		return nil
+	}
+	return json.Unmarshal(value.([]byte), a)
+}
+
+// ProductTags holds the labels a product is tagged with, used by collection rules
+type ProductTags []string
+
+// Value implements the driver.Valuer interface
+func (t ProductTags) Value() (driver.Value, error) {
+	if t == nil {
+		return nil, nil
+	}
+	return json.Marshal(t)
+}
+
+// Scan implements the sql.Scanner interface
+func (t *ProductTags) Scan(value interface{}) error {
+	if value == nil {
+		*t = nil
+		return nil
+	}
+	return json.Unmarshal(value.([]byte), t)
+}
+
+// HasTag reports whether the product is tagged with tag, ignoring case
+func (p Product) HasTag(tag string) bool {
+	for _, t := range p.Tags {
+		if strings.EqualFold(t, tag) {
+			return true
+		}
+	}
+	return false
+}
+
 // Product represents an item for sale
 type Product struct {
-	ID           string         `json:"id" gorm:"primaryKey;column:id;type:varchar(36);default:(UUID());comment:'Primary Key'"`
-	SKU          string         `json:"sku,omitempty" gorm:"column:sku;type:varchar(100);uniqueIndex;comment:'Stock Keeping Unit'"`
-	Name         string         `json:"name" gorm:"column:name;type:varchar(255);not null;comment:'Product name'"`
-	Slug         string         `json:"slug" gorm:"column:slug;type:varchar(255);uniqueIndex;not null;comment:'URL-friendly name'"`
-	Description  string         `json:"description,omitempty" gorm:"column:description;type:text;comment:'Product description'"`
-	Price        float64        `json:"price" gorm:"column:price;type:decimal(12,2);not null;comment:'Product price'"`
-	Currency     string         `json:"currency" gorm:"column:currency;type:varchar(10);not null;default:'USD';comment:'Currency code'"`
-	CategoryID   *string        `json:"category_id,omitempty" gorm:"column:category_id;type:varchar(36);comment:'FK to category'"`
-	IsActive     bool           `json:"is_active" gorm:"column:is_active;type:boolean;default:true;comment:'Is product active'"`
-	CreatedAt    time.Time      `json:"created_at" gorm:"autoCreateTime;column:created_at;comment:'Created at'"`
-	UpdatedAt    time.Time      `json:"updated_at" gorm:"autoUpdateTime;column:updated_at;comment:'Updated at'"`
-	
+	ID             string            `json:"id" gorm:"primaryKey;column:id;type:varchar(36);default:(UUID());comment:'Primary Key'"`
+	SKU            string            `json:"sku,omitempty" gorm:"column:sku;type:varchar(100);uniqueIndex;comment:'Stock Keeping Unit'"`
+	Name           string            `json:"name" gorm:"column:name;type:varchar(255);not null;comment:'Product name'"`
+	Slug           string            `json:"slug" gorm:"column:slug;type:varchar(255);uniqueIndex;not null;comment:'URL-friendly name'"`
+	Description    string            `json:"description,omitempty" gorm:"column:description;type:text;comment:'Product description'"`
+	Price          float64           `json:"price" gorm:"column:price;type:decimal(12,2);not null;comment:'Product price'"`
+	Currency       string            `json:"currency" gorm:"column:currency;type:varchar(10);not null;default:'USD';comment:'Currency code'"`
+	CategoryID     *string           `json:"category_id,omitempty" gorm:"column:category_id;type:varchar(36);comment:'FK to category'"`
+	IsActive       bool              `json:"is_active" gorm:"column:is_active;type:boolean;default:true;comment:'Is product active'"`
+	Type           ProductType       `json:"type" gorm:"column:type;type:ENUM('simple','bundle','digital');not null;default:'simple';comment:'Product type'"`
+	BundlePricing  BundlePricing     `json:"bundle_pricing,omitempty" gorm:"column:bundle_pricing;type:varchar(20);comment:'Bundle pricing mode (fixed, computed)'"`
+	BundleDiscount float64           `json:"bundle_discount,omitempty" gorm:"column:bundle_discount;type:decimal(5,2);default:0;comment:'Percentage discount for computed bundles'"`
+	Attributes     ProductAttributes `json:"attributes,omitempty" gorm:"column:attributes;type:json;comment:'Product attributes'"`
+	Tags           ProductTags       `json:"tags,omitempty" gorm:"column:tags;type:json;comment:'Product tags'"`
+	Status         ProductStatus     `json:"status" gorm:"column:status;type:ENUM('draft','in_review','published');not null;default:'published';index;comment:'Publishing status'"`
+	PublishAt      *time.Time        `json:"publish_at,omitempty" gorm:"column:publish_at;index;comment:'Scheduled publish time'"`
+	UnpublishAt    *time.Time        `json:"unpublish_at,omitempty" gorm:"column:unpublish_at;index;comment:'Scheduled unpublish time'"`
+	PublishedAt    *time.Time        `json:"published_at,omitempty" gorm:"column:published_at;comment:'Last published at'"`
+	CreatedAt      time.Time         `json:"created_at" gorm:"autoCreateTime;column:created_at;comment:'Created at'"`
+	UpdatedAt      time.Time         `json:"updated_at" gorm:"autoUpdateTime;column:updated_at;comment:'Updated at'"`
+
 	// Relations
 	// Category     *Category      `json:"category,omitempty" gorm:"foreignKey:CategoryID"`
-	Images       []ProductImage `json:"images,omitempty" gorm:"foreignKey:ProductID"`
+	Images           []ProductImage    `json:"images,omitempty" gorm:"foreignKey:ProductID"`
+	Variants         []ProductVariant  `json:"variants,omitempty" gorm:"foreignKey:ProductID"`
+	BundleComponents []BundleComponent `json:"bundle_components,omitempty" gorm:"foreignKey:BundleID"`
+	DigitalAssets    []DigitalAsset    `json:"digital_assets,omitempty" gorm:"foreignKey:ProductID"`
 	// Inventory    *Inventory     `json:"inventory,omitempty" gorm:"foreignKey:ProductID"`
 }
 
@@ -31,6 +127,11 @@ func (Product) TableName() string {
 	return "products"
 }
 
+// RequiresShipping reports whether the product has to be shipped physically
+func (p Product) RequiresShipping() bool {
+	return p.Type != ProductTypeDigital
+}
+
 // BeforeCreate sets timestamps and performs any pre-creation logic.
 func (p *Product) BeforeCreate(tx *gorm.DB) (err error) {
 	now := time.Now().UTC()
@@ -46,5 +147,3 @@ func (p *Product) BeforeUpdate(tx *gorm.DB) (err error) {
 	p.UpdatedAt = time.Now().UTC()
 	return nil
 }
-
-
@@ -0,0 +1,77 @@
+package entity
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ProductAlertType represents what a customer wants to be told about
+type ProductAlertType string
+
+const (
+	// ProductAlertBackInStock fires when an out-of-stock product becomes available
+	ProductAlertBackInStock ProductAlertType = "back_in_stock"
+	// ProductAlertPriceDrop fires when the effective price falls below the target price
+	ProductAlertPriceDrop ProductAlertType = "price_drop"
+)
+
+// ProductAlertStatus represents the lifecycle of an alert
+type ProductAlertStatus string
+
+const (
+	ProductAlertStatusActive    ProductAlertStatus = "active"
+	ProductAlertStatusTriggered ProductAlertStatus = "triggered"
+	ProductAlertStatusCancelled ProductAlertStatus = "cancelled"
+)
+
+// ProductAlert is a customer's subscription to a stock or price change of a
+// product or one of its variants. It fires once and is then marked triggered.
+type ProductAlert struct {
+	ID             string             `json:"id" gorm:"primaryKey;column:id;type:varchar(36);default:(UUID());comment:'Primary Key'"`
+	UserID         string             `json:"user_id" gorm:"column:user_id;type:varchar(36);not null;index;comment:'FK to user entity'"`
+	Type           ProductAlertType   `json:"type" gorm:"column:type;type:ENUM('back_in_stock','price_drop');not null;comment:'Alert type'"`
+	ProductID      string             `json:"product_id" gorm:"column:product_id;type:varchar(36);not null;index:idx_product_alert_target;comment:'FK to product'"`
+	VariantID      *string            `json:"variant_id,omitempty" gorm:"column:variant_id;type:varchar(36);index:idx_product_alert_target;comment:'FK to product variant'"`
+	WishlistItemID *string            `json:"wishlist_item_id,omitempty" gorm:"column:wishlist_item_id;type:varchar(36);comment:'FK to the wishlist item the alert was created from'"`
+	TargetPrice    *float64           `json:"target_price,omitempty" gorm:"column:target_price;type:decimal(12,2);comment:'Price drop alerts fire below this price'"`
+	PriceAtSignup  float64            `json:"price_at_signup" gorm:"column:price_at_signup;type:decimal(12,2);not null;default:0;comment:'Effective price when the alert was created'"`
+	Status         ProductAlertStatus `json:"status" gorm:"column:status;type:ENUM('active','triggered','cancelled');not null;default:'active';index;comment:'Alert status'"`
+	TriggeredAt    *time.Time         `json:"triggered_at,omitempty" gorm:"column:triggered_at;comment:'When the alert fired'"`
+	CreatedAt      time.Time          `json:"created_at" gorm:"autoCreateTime;column:created_at;comment:'Created at'"`
+	UpdatedAt      time.Time          `json:"updated_at" gorm:"autoUpdateTime;column:updated_at;comment:'Updated at'"`
+
+	// Relations
+	Product *Product        `json:"product,omitempty" gorm:"foreignKey:ProductID"`
+	Variant *ProductVariant `json:"variant,omitempty" gorm:"foreignKey:VariantID"`
+}
+
+// TableName specifies the table name for the ProductAlert model
+func (ProductAlert) TableName() string {
+	return "productAlerts"
+}
+
+// BeforeCreate sets timestamps and performs any pre-creation logic.
+func (a *ProductAlert) BeforeCreate(tx *gorm.DB) (err error) {
+	now := time.Now().UTC()
+	if a.CreatedAt.IsZero() {
+		a.CreatedAt = now
+	}
+	a.UpdatedAt = now
+	return nil
+}
+
+// BeforeUpdate updates the UpdatedAt timestamp before updating an existing record.
+func (a *ProductAlert) BeforeUpdate(tx *gorm.DB) (err error) {
+	a.UpdatedAt = time.Now().UTC()
+	return nil
+}
+
+// Threshold returns the price below which a price drop alert fires: the
+// target price if one was given, otherwise the price at signup
+func (a ProductAlert) Threshold() float64 {
+	if a.TargetPrice != nil {
+		return *a.TargetPrice
+	}
+	return a.PriceAtSignup
+}
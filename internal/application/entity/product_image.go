@@ -8,16 +8,27 @@ import (
 
 // ProductImage represents an image for a product
 type ProductImage struct {
-	ID        string    `json:"id" gorm:"primaryKey;column:id;type:varchar(36);default:(UUID());comment:'Primary Key'"`
-	ProductID string    `json:"product_id" gorm:"column:product_id;type:varchar(36);not null;comment:'FK to product'"`
-	URL       string    `json:"url" gorm:"column:url;type:varchar(1000);not null;comment:'Image URL'"`
-	AltText   string    `json:"alt_text,omitempty" gorm:"column:alt_text;type:varchar(255);comment:'Alternative text'"`
-	SortOrder int       `json:"sort_order" gorm:"column:sort_order;type:int;default:0;comment:'Sort order'"`
-	CreatedAt time.Time `json:"created_at" gorm:"autoCreateTime;column:created_at;comment:'Created at'"`
-	UpdatedAt time.Time `json:"updated_at" gorm:"autoUpdateTime;column:updated_at;comment:'Updated at'"`
-	
+	ID         string    `json:"id" gorm:"primaryKey;column:id;type:varchar(36);default:(UUID());comment:'Primary Key'"`
+	ProductID  string    `json:"product_id" gorm:"column:product_id;type:varchar(36);not null;comment:'FK to product'"`
+	URL        string    `json:"url" gorm:"column:url;type:varchar(1000);not null;comment:'Image URL'"`
+	AltText    string    `json:"alt_text,omitempty" gorm:"column:alt_text;type:varchar(255);comment:'Alternative text'"`
+	SortOrder  int       `json:"sort_order" gorm:"column:sort_order;type:int;default:0;comment:'Sort order'"`
+	StorageKey string    `json:"-" gorm:"column:storage_key;type:varchar(500);comment:'Storage key of the original file'"`
+	MimeType   string    `json:"mime_type,omitempty" gorm:"column:mime_type;type:varchar(50);comment:'Detected MIME type'"`
+	Width      int       `json:"width" gorm:"column:width;type:int;default:0;comment:'Original width in pixels'"`
+	Height     int       `json:"height" gorm:"column:height;type:int;default:0;comment:'Original height in pixels'"`
+	FileSize   int64     `json:"file_size" gorm:"column:file_size;type:bigint;default:0;comment:'Original file size in bytes'"`
+	CreatedAt  time.Time `json:"created_at" gorm:"autoCreateTime;column:created_at;comment:'Created at'"`
+	UpdatedAt  time.Time `json:"updated_at" gorm:"autoUpdateTime;column:updated_at;comment:'Updated at'"`
+
+	// DetachedAt hides an image that a restored revision did not have. The
+	// row, its renditions and files are kept so a later restore can bring
+	// it back.
+	DetachedAt gorm.DeletedAt `json:"-" gorm:"column:detached_at;index;comment:'When a restored revision hid the image'"`
+
 	// Relation
-	Product    *Product    `json:"-" gorm:"foreignKey:ProductID"`
+	Product    *Product                `json:"-" gorm:"foreignKey:ProductID"`
+	Renditions []ProductImageRendition `json:"renditions,omitempty" gorm:"foreignKey:ImageID"`
 }
 
 // TableName specifies the table name for the ProductImage model
@@ -39,4 +50,31 @@ func (u *ProductImage) BeforeCreate(tx *gorm.DB) (err error) {
 func (u *ProductImage) BeforeUpdate(tx *gorm.DB) (err error) {
 	u.UpdatedAt = time.Now().UTC()
 	return nil
-}
\ No newline at end of file
+}
+
+// ProductImageRendition is a resized or re-encoded copy of a product image
+type ProductImageRendition struct {
+	ID         string    `json:"id" gorm:"primaryKey;column:id;type:varchar(36);default:(UUID());comment:'Primary Key'"`
+	ImageID    string    `json:"image_id" gorm:"column:image_id;type:varchar(36);not null;index;comment:'FK to product image'"`
+	Name       string    `json:"name" gorm:"column:name;type:varchar(50);not null;comment:'Rendition name (thumbnail, medium, large)'"`
+	Format     string    `json:"format" gorm:"column:format;type:varchar(20);not null;comment:'Encoded format (jpeg, png, webp)'"`
+	URL        string    `json:"url" gorm:"column:url;type:varchar(1000);not null;comment:'Rendition URL'"`
+	StorageKey string    `json:"-" gorm:"column:storage_key;type:varchar(500);comment:'Storage key of the rendition file'"`
+	Width      int       `json:"width" gorm:"column:width;type:int;not null;comment:'Width in pixels'"`
+	Height     int       `json:"height" gorm:"column:height;type:int;not null;comment:'Height in pixels'"`
+	FileSize   int64     `json:"file_size" gorm:"column:file_size;type:bigint;default:0;comment:'File size in bytes'"`
+	CreatedAt  time.Time `json:"created_at" gorm:"autoCreateTime;column:created_at;comment:'Created at'"`
+}
+
+// TableName specifies the table name for the ProductImageRendition model
+func (ProductImageRendition) TableName() string {
+	return "productImageRenditions"
+}
+
+// BeforeCreate sets timestamps and performs any pre-creation logic.
+func (r *ProductImageRendition) BeforeCreate(tx *gorm.DB) (err error) {
+	if r.CreatedAt.IsZero() {
+		r.CreatedAt = time.Now().UTC()
+	}
+	return nil
+}
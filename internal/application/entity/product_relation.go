@@ -0,0 +1,67 @@
+package entity
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ProductRelationType represents why a product is suggested alongside another
+type ProductRelationType string
+
+const (
+	// ProductRelationRelated suggests similar products
+	ProductRelationRelated ProductRelationType = "related"
+	// ProductRelationUpsell suggests a more expensive alternative
+	ProductRelationUpsell ProductRelationType = "upsell"
+	// ProductRelationCrossSell suggests a complementary product
+	ProductRelationCrossSell ProductRelationType = "cross_sell"
+	// ProductRelationAccessory suggests an add-on for the product
+	ProductRelationAccessory ProductRelationType = "accessory"
+)
+
+// ProductRelation is a hand-curated link from one product to another
+type ProductRelation struct {
+	ID               string              `json:"id" gorm:"primaryKey;column:id;type:varchar(36);default:(UUID());comment:'Primary Key'"`
+	ProductID        string              `json:"product_id" gorm:"column:product_id;type:varchar(36);not null;uniqueIndex:idx_product_relation;comment:'FK to product'"`
+	RelatedProductID string              `json:"related_product_id" gorm:"column:related_product_id;type:varchar(36);not null;uniqueIndex:idx_product_relation;index;comment:'FK to the suggested product'"`
+	Type             ProductRelationType `json:"type" gorm:"column:type;type:ENUM('related','upsell','cross_sell','accessory');not null;uniqueIndex:idx_product_relation;comment:'Relation type'"`
+	Position         int                 `json:"position" gorm:"column:position;type:int;default:0;comment:'Display order within the type'"`
+	CreatedAt        time.Time           `json:"created_at" gorm:"autoCreateTime;column:created_at;comment:'Created at'"`
+
+	// Relations
+	RelatedProduct *Product `json:"related_product,omitempty" gorm:"foreignKey:RelatedProductID"`
+}
+
+// TableName specifies the table name for the ProductRelation model
+func (ProductRelation) TableName() string {
+	return "productRelations"
+}
+
+// BeforeCreate sets timestamps and performs any pre-creation logic.
+func (r *ProductRelation) BeforeCreate(tx *gorm.DB) (err error) {
+	if r.CreatedAt.IsZero() {
+		r.CreatedAt = time.Now().UTC()
+	}
+	return nil
+}
+
+// FrequentlyBoughtTogether is a computed association between two products
+// ordered together. Support is the share of all orders containing both,
+// Confidence the share of orders containing ProductID that also contain
+// RelatedProductID, and Lift how much more often they are bought together
+// than they would be by chance.
+type FrequentlyBoughtTogether struct {
+	ProductID        string    `json:"product_id" gorm:"primaryKey;column:product_id;type:varchar(36);comment:'FK to product'"`
+	RelatedProductID string    `json:"related_product_id" gorm:"primaryKey;column:related_product_id;type:varchar(36);comment:'FK to the product bought with it'"`
+	OrderCount       int       `json:"order_count" gorm:"column:order_count;type:int;not null;comment:'Orders containing both products'"`
+	Support          float64   `json:"support" gorm:"column:support;type:decimal(10,6);not null;comment:'Share of orders containing both products'"`
+	Confidence       float64   `json:"confidence" gorm:"column:confidence;type:decimal(10,6);not null;index;comment:'Share of orders with the product that also contain the related one'"`
+	Lift             float64   `json:"lift" gorm:"column:lift;type:decimal(12,6);not null;comment:'Confidence divided by the popularity of the related product'"`
+	ComputedAt       time.Time `json:"computed_at" gorm:"column:computed_at;not null;comment:'When the association was computed'"`
+}
+
+// TableName specifies the table name for the FrequentlyBoughtTogether model
+func (FrequentlyBoughtTogether) TableName() string {
+	return "frequentlyBoughtTogether"
+}
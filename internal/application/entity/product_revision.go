@@ -0,0 +1,77 @@
+package entity
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ProductSnapshot is the full editable state of a product at one point in time
+type ProductSnapshot struct {
+	SKU              string                 `json:"sku"`
+	Name             string                 `json:"name"`
+	Slug             string                 `json:"slug"`
+	Description      string                 `json:"description"`
+	Price            float64                `json:"price"`
+	Currency         string                 `json:"currency"`
+	CategoryID       *string                `json:"category_id"`
+	IsActive         bool                   `json:"is_active"`
+	Type             ProductType            `json:"type"`
+	BundlePricing    BundlePricing          `json:"bundle_pricing"`
+	BundleDiscount   float64                `json:"bundle_discount"`
+	Attributes       ProductAttributes      `json:"attributes"`
+	Tags             ProductTags            `json:"tags"`
+	Variants         []ProductVariant       `json:"variants"`
+	PriceSchedules   []PriceSchedule        `json:"price_schedules"`
+	Images           []ProductImageSnapshot `json:"images"`
+	BundleComponents []BundleComponent      `json:"bundle_components"`
+}
+
+// ProductImageSnapshot is the editable state of a product image. The files
+// themselves are not versioned.
+type ProductImageSnapshot struct {
+	ID        string `json:"id"`
+	URL       string `json:"url"`
+	AltText   string `json:"alt_text"`
+	SortOrder int    `json:"sort_order"`
+}
+
+// Value implements the driver.Valuer interface
+func (s ProductSnapshot) Value() (driver.Value, error) {
+	return json.Marshal(s)
+}
+
+// Scan implements the sql.Scanner interface
+func (s *ProductSnapshot) Scan(value interface{}) error {
+	if value == nil {
+		*s = ProductSnapshot{}
+		return nil
+	}
+	return json.Unmarshal(value.([]byte), s)
+}
+
+// ProductRevision is a numbered snapshot of a product taken after each change
+type ProductRevision struct {
+	ID        string          `json:"id" gorm:"primaryKey;column:id;type:varchar(36);default:(UUID());comment:'Primary Key'"`
+	ProductID string          `json:"product_id" gorm:"column:product_id;type:varchar(36);not null;uniqueIndex:idx_revision_product_version;comment:'FK to product'"`
+	Version   int             `json:"version" gorm:"column:version;type:int;not null;uniqueIndex:idx_revision_product_version;comment:'Revision number, starting at 1'"`
+	Summary   string          `json:"summary" gorm:"column:summary;type:varchar(255);comment:'What changed'"`
+	ChangedBy *string         `json:"changed_by,omitempty" gorm:"column:changed_by;type:varchar(36);comment:'FK to the user who made the change, null for system changes'"`
+	Snapshot  ProductSnapshot `json:"snapshot" gorm:"column:snapshot;type:json;not null;comment:'Product state after the change'"`
+	CreatedAt time.Time       `json:"created_at" gorm:"autoCreateTime;column:created_at;comment:'Created at'"`
+}
+
+// TableName specifies the table name for the ProductRevision model
+func (ProductRevision) TableName() string {
+	return "productRevisions"
+}
+
+// BeforeCreate sets timestamps and performs any pre-creation logic.
+func (r *ProductRevision) BeforeCreate(tx *gorm.DB) (err error) {
+	if r.CreatedAt.IsZero() {
+		r.CreatedAt = time.Now().UTC()
+	}
+	return nil
+}
@@ -0,0 +1,44 @@
+package entity
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ProductVariant represents a purchasable variation of a product (size, colour, ...)
+type ProductVariant struct {
+	ID        string    `json:"id" gorm:"primaryKey;column:id;type:varchar(36);default:(UUID());comment:'Primary Key'"`
+	ProductID string    `json:"product_id" gorm:"column:product_id;type:varchar(36);not null;index;comment:'FK to product'"`
+	SKU       string    `json:"sku,omitempty" gorm:"column:sku;type:varchar(100);uniqueIndex;comment:'Stock Keeping Unit'"`
+	Name      string    `json:"name" gorm:"column:name;type:varchar(255);not null;comment:'Variant name'"`
+	Price     float64   `json:"price" gorm:"column:price;type:decimal(12,2);not null;comment:'Variant price'"`
+	IsActive  bool      `json:"is_active" gorm:"column:is_active;type:boolean;default:true;comment:'Is variant active'"`
+	SortOrder int       `json:"sort_order" gorm:"column:sort_order;type:int;default:0;comment:'Sort order'"`
+	CreatedAt time.Time `json:"created_at" gorm:"autoCreateTime;column:created_at;comment:'Created at'"`
+	UpdatedAt time.Time `json:"updated_at" gorm:"autoUpdateTime;column:updated_at;comment:'Updated at'"`
+
+	// Relation
+	Product *Product `json:"-" gorm:"foreignKey:ProductID"`
+}
+
+// TableName specifies the table name for the ProductVariant model
+func (ProductVariant) TableName() string {
+	return "productVariants"
+}
+
+// BeforeCreate sets timestamps and performs any pre-creation logic.
+func (v *ProductVariant) BeforeCreate(tx *gorm.DB) (err error) {
+	now := time.Now().UTC()
+	if v.CreatedAt.IsZero() {
+		v.CreatedAt = now
+	}
+	v.UpdatedAt = now
+	return nil
+}
+
+// BeforeUpdate updates the UpdatedAt timestamp before updating an existing record.
+func (v *ProductVariant) BeforeUpdate(tx *gorm.DB) (err error) {
+	v.UpdatedAt = time.Now().UTC()
+	return nil
+}
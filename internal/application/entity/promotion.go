@@ -0,0 +1,131 @@
+package entity
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// PromotionType is how an automatic promotion is earned and what it gives
+type PromotionType string
+
+const (
+	// PromotionBuyXGetY gives GetQuantity reward units at Percent off for
+	// every BuyQuantity qualifying units bought
+	PromotionBuyXGetY PromotionType = "buy_x_get_y"
+	// PromotionSpendThreshold takes a percentage off the qualifying lines
+	// once their subtotal reaches a tier, e.g. spend 100 and save 10%
+	PromotionSpendThreshold PromotionType = "spend_threshold"
+	// PromotionMultiBuy makes GetQuantity of every BuyQuantity qualifying
+	// units free, e.g. 3 for 2 within a category
+	PromotionMultiBuy PromotionType = "multi_buy"
+	// PromotionBundle discounts products bought together, once per complete set
+	PromotionBundle PromotionType = "bundle"
+)
+
+// PromotionTier is a step of a spend threshold promotion
+type PromotionTier struct {
+	MinSubtotal float64 `json:"min_subtotal"`
+	Percent     float64 `json:"percent"`
+}
+
+// PromotionRules are the conditions and reward of a promotion; which fields
+// are used depends on its type
+type PromotionRules struct {
+	// ProductIDs and CategoryIDs select the qualifying lines; when both are
+	// empty every line qualifies. A bundle needs every product in ProductIDs.
+	ProductIDs  CouponIDs `json:"product_ids,omitempty"`
+	CategoryIDs CouponIDs `json:"category_ids,omitempty"`
+	// BuyQuantity and GetQuantity are X and Y of buy X get Y, or the group
+	// size and free units of a multi-buy: 3 for 2 is BuyQuantity 3 and
+	// GetQuantity 1
+	BuyQuantity int `json:"buy_quantity,omitempty"`
+	GetQuantity int `json:"get_quantity,omitempty"`
+	// RewardProductIDs and RewardCategoryIDs select the units given by buy X
+	// get Y; when both are empty they come from the qualifying lines
+	RewardProductIDs  CouponIDs `json:"reward_product_ids,omitempty"`
+	RewardCategoryIDs CouponIDs `json:"reward_category_ids,omitempty"`
+	// Percent is taken off reward units and bundles; 100 makes them free
+	Percent float64 `json:"percent,omitempty"`
+	// Amount is taken off each complete bundle instead of Percent
+	Amount float64 `json:"amount,omitempty"`
+	// Tiers of a spend threshold promotion; the highest tier reached applies
+	Tiers []PromotionTier `json:"tiers,omitempty"`
+}
+
+// Value implements the driver.Valuer interface
+func (r PromotionRules) Value() (driver.Value, error) {
+	return json.Marshal(r)
+}
+
+// Scan implements the sql.Scanner interface
+func (r *PromotionRules) Scan(value interface{}) error {
+	if value == nil {
+		*r = PromotionRules{}
+		return nil
+	}
+	return json.Unmarshal(value.([]byte), r)
+}
+
+// Qualifies reports whether a product in categoryID, which may be nil,
+// counts towards the promotion
+func (r PromotionRules) Qualifies(productID string, categoryID *string) bool {
+	return matchesIDs(r.ProductIDs, r.CategoryIDs, productID, categoryID)
+}
+
+// Rewards reports whether a product in categoryID, which may be nil, can be
+// given by a buy X get Y promotion
+func (r PromotionRules) Rewards(productID string, categoryID *string) bool {
+	if len(r.RewardProductIDs) == 0 && len(r.RewardCategoryIDs) == 0 {
+		return r.Qualifies(productID, categoryID)
+	}
+	return matchesIDs(r.RewardProductIDs, r.RewardCategoryIDs, productID, categoryID)
+}
+
+func matchesIDs(productIDs, categoryIDs CouponIDs, productID string, categoryID *string) bool {
+	if len(productIDs) == 0 && len(categoryIDs) == 0 {
+		return true
+	}
+	return productIDs.Contains(productID) || (categoryID != nil && categoryIDs.Contains(*categoryID))
+}
+
+// Promotion is a discount applied automatically, without a code, to carts
+// that meet its rules. Promotions are evaluated by descending Priority; an
+// exclusive promotion is never combined with other promotions.
+type Promotion struct {
+	ID          string         `json:"id" gorm:"primaryKey;column:id;type:varchar(36);default:(UUID());comment:'Primary Key'"`
+	Name        string         `json:"name" gorm:"column:name;type:varchar(255);not null;comment:'Name shown on the discount line'"`
+	Description string         `json:"description,omitempty" gorm:"column:description;type:text;comment:'Internal description'"`
+	Type        PromotionType  `json:"type" gorm:"column:type;type:ENUM('buy_x_get_y','spend_threshold','multi_buy','bundle');not null;comment:'Promotion type'"`
+	Rules       PromotionRules `json:"rules" gorm:"column:rules;type:json;not null;comment:'Conditions and reward'"`
+	Priority    int            `json:"priority" gorm:"column:priority;type:int;not null;default:0;index;comment:'Higher priorities are evaluated first'"`
+	Exclusive   bool           `json:"exclusive" gorm:"column:exclusive;not null;default:false;comment:'Never combined with other promotions'"`
+	StartsAt    *time.Time     `json:"starts_at,omitempty" gorm:"column:starts_at;comment:'Start of the validity window'"`
+	EndsAt      *time.Time     `json:"ends_at,omitempty" gorm:"column:ends_at;comment:'End of the validity window'"`
+	IsActive    bool           `json:"is_active" gorm:"column:is_active;not null;default:true;comment:'Applied to carts'"`
+	CreatedAt   time.Time      `json:"created_at" gorm:"autoCreateTime;column:created_at;comment:'Created at'"`
+	UpdatedAt   time.Time      `json:"updated_at" gorm:"autoUpdateTime;column:updated_at;comment:'Updated at'"`
+}
+
+// TableName specifies the table name for the Promotion model
+func (Promotion) TableName() string {
+	return "promotions"
+}
+
+// BeforeCreate sets timestamps and performs any pre-creation logic.
+func (p *Promotion) BeforeCreate(tx *gorm.DB) (err error) {
+	now := time.Now().UTC()
+	if p.CreatedAt.IsZero() {
+		p.CreatedAt = now
+	}
+	p.UpdatedAt = now
+	return nil
+}
+
+// BeforeUpdate updates the UpdatedAt timestamp before updating an existing record.
+func (p *Promotion) BeforeUpdate(tx *gorm.DB) (err error) {
+	p.UpdatedAt = time.Now().UTC()
+	return nil
+}
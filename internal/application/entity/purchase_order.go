@@ -0,0 +1,127 @@
+package entity
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// PurchaseOrderStatus represents the stage of a purchase order
+type PurchaseOrderStatus string
+
+const (
+	// PurchaseOrderDraft can still be edited or deleted
+	PurchaseOrderDraft             PurchaseOrderStatus = "draft"
+	PurchaseOrderSent              PurchaseOrderStatus = "sent"
+	PurchaseOrderPartiallyReceived PurchaseOrderStatus = "partially_received"
+	PurchaseOrderReceived          PurchaseOrderStatus = "received"
+	// PurchaseOrderClosed accepts no more deliveries, whether or not everything arrived
+	PurchaseOrderClosed PurchaseOrderStatus = "closed"
+)
+
+// CanReceive reports whether goods can be received against an order in the status
+func (s PurchaseOrderStatus) CanReceive() bool {
+	return s == PurchaseOrderSent || s == PurchaseOrderPartiallyReceived
+}
+
+// PurchaseOrder is an order for stock placed with a supplier. Shipping and
+// other costs such as duties are spread over the lines to work out the
+// landed cost of each unit.
+type PurchaseOrder struct {
+	ID           string              `json:"id" gorm:"primaryKey;column:id;type:varchar(36);default:(UUID());comment:'Primary Key'"`
+	Number       string              `json:"number" gorm:"column:number;type:varchar(50);uniqueIndex;not null;comment:'Purchase order number'"`
+	SupplierID   string              `json:"supplier_id" gorm:"column:supplier_id;type:varchar(36);not null;index;comment:'FK to supplier'"`
+	LocationID   string              `json:"location_id" gorm:"column:location_id;type:varchar(36);not null;comment:'FK to the stock location goods are delivered to'"`
+	Status       PurchaseOrderStatus `json:"status" gorm:"column:status;type:ENUM('draft','sent','partially_received','received','closed');not null;default:'draft';index;comment:'Purchase order status'"`
+	Currency     string              `json:"currency" gorm:"column:currency;type:varchar(10);not null;default:'USD';comment:'Currency code'"`
+	ShippingCost float64             `json:"shipping_cost" gorm:"column:shipping_cost;type:decimal(12,2);not null;default:0;comment:'Freight charged for the order'"`
+	OtherCosts   float64             `json:"other_costs" gorm:"column:other_costs;type:decimal(12,2);not null;default:0;comment:'Duties, fees and other costs of landing the goods'"`
+	Notes        string              `json:"notes" gorm:"column:notes;type:text;comment:'Notes for the supplier'"`
+	ExpectedAt   *time.Time          `json:"expected_at,omitempty" gorm:"column:expected_at;comment:'Expected delivery date'"`
+	SentAt       *time.Time          `json:"sent_at,omitempty" gorm:"column:sent_at;comment:'When the order was sent to the supplier'"`
+	ReceivedAt   *time.Time          `json:"received_at,omitempty" gorm:"column:received_at;comment:'When the last goods were received'"`
+	ClosedAt     *time.Time          `json:"closed_at,omitempty" gorm:"column:closed_at;comment:'When the order was closed'"`
+	CreatedBy    string              `json:"created_by" gorm:"column:created_by;type:varchar(36);comment:'FK to the user who raised the order'"`
+	CreatedAt    time.Time           `json:"created_at" gorm:"autoCreateTime;column:created_at;comment:'Created at'"`
+	UpdatedAt    time.Time           `json:"updated_at" gorm:"autoUpdateTime;column:updated_at;comment:'Updated at'"`
+
+	// Relations
+	Supplier *Supplier           `json:"supplier,omitempty" gorm:"foreignKey:SupplierID"`
+	Location *StockLocation      `json:"location,omitempty" gorm:"foreignKey:LocationID"`
+	Lines    []PurchaseOrderLine `json:"lines,omitempty" gorm:"foreignKey:PurchaseOrderID"`
+}
+
+// TableName specifies the table name for the PurchaseOrder model
+func (PurchaseOrder) TableName() string {
+	return "purchaseOrders"
+}
+
+// Subtotal is the cost of the goods before shipping and other costs
+func (o PurchaseOrder) Subtotal() float64 {
+	var subtotal float64
+	for _, line := range o.Lines {
+		subtotal += line.UnitCost * float64(line.Quantity)
+	}
+	return subtotal
+}
+
+// LandedUnitCost is the cost of one unit of a line once the order's shipping
+// and other costs are spread over the lines by value
+func (o PurchaseOrder) LandedUnitCost(line PurchaseOrderLine) float64 {
+	extra := o.ShippingCost + o.OtherCosts
+	subtotal := o.Subtotal()
+	if extra == 0 || subtotal == 0 || line.Quantity == 0 {
+		return line.UnitCost
+	}
+	share := extra * line.UnitCost * float64(line.Quantity) / subtotal
+	return line.UnitCost + share/float64(line.Quantity)
+}
+
+// BeforeCreate sets timestamps and performs any pre-creation logic.
+func (o *PurchaseOrder) BeforeCreate(tx *gorm.DB) (err error) {
+	now := time.Now().UTC()
+	if o.CreatedAt.IsZero() {
+		o.CreatedAt = now
+	}
+	o.UpdatedAt = now
+	return nil
+}
+
+// BeforeUpdate updates the UpdatedAt timestamp before updating an existing record.
+func (o *PurchaseOrder) BeforeUpdate(tx *gorm.DB) (err error) {
+	o.UpdatedAt = time.Now().UTC()
+	return nil
+}
+
+// PurchaseOrderLine is a SKU ordered from a supplier
+type PurchaseOrderLine struct {
+	ID               string    `json:"id" gorm:"primaryKey;column:id;type:varchar(36);default:(UUID());comment:'Primary Key'"`
+	PurchaseOrderID  string    `json:"purchase_order_id" gorm:"column:purchase_order_id;type:varchar(36);not null;index;comment:'FK to purchase order'"`
+	ProductID        string    `json:"product_id" gorm:"column:product_id;type:varchar(36);not null;index;comment:'FK to product'"`
+	VariantID        *string   `json:"variant_id,omitempty" gorm:"column:variant_id;type:varchar(36);comment:'FK to product variant'"`
+	SKU              string    `json:"sku" gorm:"column:sku;type:varchar(100);comment:'SKU at the time of ordering'"`
+	Quantity         int       `json:"quantity" gorm:"column:quantity;type:int;not null;comment:'Ordered quantity'"`
+	ReceivedQuantity int       `json:"received_quantity" gorm:"column:received_quantity;type:int;not null;default:0;comment:'Quantity received so far'"`
+	UnitCost         float64   `json:"unit_cost" gorm:"column:unit_cost;type:decimal(12,4);not null;comment:'Supplier price per unit'"`
+	LandedUnitCost   float64   `json:"landed_unit_cost" gorm:"column:landed_unit_cost;type:decimal(12,4);not null;default:0;comment:'Unit cost including a share of shipping and other costs, set on receipt'"`
+	SortOrder        int       `json:"sort_order" gorm:"column:sort_order;type:int;not null;default:0;comment:'Line order'"`
+	CreatedAt        time.Time `json:"created_at" gorm:"autoCreateTime;column:created_at;comment:'Created at'"`
+}
+
+// TableName specifies the table name for the PurchaseOrderLine model
+func (PurchaseOrderLine) TableName() string {
+	return "purchaseOrderLines"
+}
+
+// Outstanding is the quantity still to be received
+func (l PurchaseOrderLine) Outstanding() int {
+	return max(0, l.Quantity-l.ReceivedQuantity)
+}
+
+// BeforeCreate sets timestamps and performs any pre-creation logic.
+func (l *PurchaseOrderLine) BeforeCreate(tx *gorm.DB) (err error) {
+	if l.CreatedAt.IsZero() {
+		l.CreatedAt = time.Now().UTC()
+	}
+	return nil
+}
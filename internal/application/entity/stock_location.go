@@ -0,0 +1,71 @@
+package entity
+
+import (
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// StockLocationType represents what kind of place stock is kept at
+type StockLocationType string
+
+const (
+	StockLocationWarehouse StockLocationType = "warehouse"
+	StockLocationStore     StockLocationType = "store"
+)
+
+// StockLocation is a warehouse or store that holds inventory. Orders are
+// allocated to active locations, lowest Priority first.
+type StockLocation struct {
+	ID         string            `json:"id" gorm:"primaryKey;column:id;type:varchar(36);default:(UUID());comment:'Primary Key'"`
+	Code       string            `json:"code" gorm:"column:code;type:varchar(50);uniqueIndex;not null;comment:'Short unique location code'"`
+	Name       string            `json:"name" gorm:"column:name;type:varchar(255);not null;comment:'Location name'"`
+	Type       StockLocationType `json:"type" gorm:"column:type;type:ENUM('warehouse','store');not null;default:'warehouse';comment:'Location type'"`
+	Street     string            `json:"street" gorm:"column:street;type:varchar(255);comment:'Street address'"`
+	City       string            `json:"city" gorm:"column:city;type:varchar(100);comment:'City'"`
+	State      string            `json:"state" gorm:"column:state;type:varchar(100);comment:'State/Province'"`
+	PostalCode string            `json:"postal_code" gorm:"column:postal_code;type:varchar(50);comment:'Postal/ZIP code'"`
+	Country    string            `json:"country" gorm:"column:country;type:varchar(100);comment:'Country'"`
+	Priority   int               `json:"priority" gorm:"column:priority;type:int;not null;default:0;comment:'Allocation order, lowest first'"`
+	IsActive   bool              `json:"is_active" gorm:"column:is_active;type:boolean;default:true;comment:'Whether orders can be allocated here'"`
+	CreatedAt  time.Time         `json:"created_at" gorm:"autoCreateTime;column:created_at;comment:'Created at'"`
+	UpdatedAt  time.Time         `json:"updated_at" gorm:"autoUpdateTime;column:updated_at;comment:'Updated at'"`
+}
+
+// TableName specifies the table name for the StockLocation model
+func (StockLocation) TableName() string {
+	return "stockLocations"
+}
+
+// Proximity rates how close the location is to an address without geocoding:
+// 3 for the same city, 2 for the same state, 1 for the same country and 0
+// otherwise.
+func (l StockLocation) Proximity(address Address) int {
+	if !strings.EqualFold(l.Country, address.Country) {
+		return 0
+	}
+	if !strings.EqualFold(l.State, address.State) {
+		return 1
+	}
+	if !strings.EqualFold(l.City, address.City) {
+		return 2
+	}
+	return 3
+}
+
+// BeforeCreate sets timestamps and performs any pre-creation logic.
+func (l *StockLocation) BeforeCreate(tx *gorm.DB) (err error) {
+	now := time.Now().UTC()
+	if l.CreatedAt.IsZero() {
+		l.CreatedAt = now
+	}
+	l.UpdatedAt = now
+	return nil
+}
+
+// BeforeUpdate updates the UpdatedAt timestamp before updating an existing record.
+func (l *StockLocation) BeforeUpdate(tx *gorm.DB) (err error) {
+	l.UpdatedAt = time.Now().UTC()
+	return nil
+}
@@ -0,0 +1,79 @@
+package entity
+
+import (
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// StockMovementReason represents why stock moved
+type StockMovementReason string
+
+const (
+	StockMovementSale       StockMovementReason = "sale"
+	StockMovementReturn     StockMovementReason = "return"
+	StockMovementRestock    StockMovementReason = "restock"
+	StockMovementAdjustment StockMovementReason = "adjustment"
+	StockMovementTransfer   StockMovementReason = "transfer"
+	StockMovementDamage     StockMovementReason = "damage"
+)
+
+// StockMovementReference represents what a stock movement was recorded for
+type StockMovementReference string
+
+const (
+	StockReferenceOrder         StockMovementReference = "order"
+	StockReferencePurchaseOrder StockMovementReference = "purchase_order"
+	// StockReferenceTransfer links the two movements of a transfer between locations
+	StockReferenceTransfer StockMovementReference = "transfer"
+)
+
+// ErrStockMovementImmutable is returned when a ledger entry would be changed
+var ErrStockMovementImmutable = errors.New("stock movements cannot be changed")
+
+// StockMovement is an entry of the append-only stock ledger. Quantity is the
+// signed change of the quantity on hand of a product or variant at a
+// location, so the current quantity is the sum of its movements.
+type StockMovement struct {
+	ID         string  `json:"id" gorm:"primaryKey;column:id;type:varchar(36);default:(UUID());comment:'Primary Key'"`
+	LocationID string  `json:"location_id" gorm:"column:location_id;type:varchar(36);not null;index:idx_stock_movement_stock;comment:'FK to stock location'"`
+	ProductID  string  `json:"product_id" gorm:"column:product_id;type:varchar(36);not null;index:idx_stock_movement_stock;comment:'FK to product'"`
+	VariantID  *string `json:"variant_id,omitempty" gorm:"column:variant_id;type:varchar(36);index:idx_stock_movement_stock;comment:'FK to product variant'"`
+	SKU        string  `json:"sku" gorm:"column:sku;type:varchar(100);index:idx_stock_movement_sku;comment:'SKU at the time of the movement'"`
+	Quantity   int     `json:"quantity" gorm:"column:quantity;type:int;not null;comment:'Signed change of the quantity on hand'"`
+	// UnitCost is the landed cost of received units, or the average cost of sold units
+	UnitCost      *float64               `json:"unit_cost,omitempty" gorm:"column:unit_cost;type:decimal(12,4);comment:'Cost per unit of the moved stock'"`
+	Reason        StockMovementReason    `json:"reason" gorm:"column:reason;type:ENUM('sale','return','restock','adjustment','transfer','damage');not null;index;comment:'Why the stock moved'"`
+	ReferenceType StockMovementReference `json:"reference_type,omitempty" gorm:"column:reference_type;type:varchar(20);comment:'What the reference points to'"`
+	ReferenceID   *string                `json:"reference_id,omitempty" gorm:"column:reference_id;type:varchar(36);index;comment:'ID of the order, purchase order or transfer'"`
+	ActorID       *string                `json:"actor_id,omitempty" gorm:"column:actor_id;type:varchar(36);comment:'FK to the user who moved the stock, empty for the system'"`
+	Note          string                 `json:"note,omitempty" gorm:"column:note;type:varchar(500);comment:'Free text explanation'"`
+	CreatedAt     time.Time              `json:"created_at" gorm:"autoCreateTime;column:created_at;index:idx_stock_movement_sku;comment:'When the stock moved'"`
+
+	// Relations
+	Location *StockLocation `json:"location,omitempty" gorm:"foreignKey:LocationID"`
+}
+
+// TableName specifies the table name for the StockMovement model
+func (StockMovement) TableName() string {
+	return "stockMovements"
+}
+
+// BeforeCreate sets timestamps and performs any pre-creation logic.
+func (m *StockMovement) BeforeCreate(tx *gorm.DB) (err error) {
+	if m.CreatedAt.IsZero() {
+		m.CreatedAt = time.Now().UTC()
+	}
+	return nil
+}
+
+// BeforeUpdate keeps the ledger append-only
+func (m *StockMovement) BeforeUpdate(tx *gorm.DB) (err error) {
+	return ErrStockMovementImmutable
+}
+
+// BeforeDelete keeps the ledger append-only
+func (m *StockMovement) BeforeDelete(tx *gorm.DB) (err error) {
+	return ErrStockMovementImmutable
+}
@@ -0,0 +1,48 @@
+package entity
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Supplier is a vendor that stock is purchased from
+type Supplier struct {
+	ID           string    `json:"id" gorm:"primaryKey;column:id;type:varchar(36);default:(UUID());comment:'Primary Key'"`
+	Name         string    `json:"name" gorm:"column:name;type:varchar(255);not null;uniqueIndex;comment:'Supplier name'"`
+	ContactName  string    `json:"contact_name" gorm:"column:contact_name;type:varchar(255);comment:'Contact person'"`
+	Email        string    `json:"email" gorm:"column:email;type:varchar(255);comment:'Email address for purchase orders'"`
+	Phone        string    `json:"phone" gorm:"column:phone;type:varchar(50);comment:'Contact phone number'"`
+	Street       string    `json:"street" gorm:"column:street;type:varchar(255);comment:'Street address'"`
+	City         string    `json:"city" gorm:"column:city;type:varchar(100);comment:'City'"`
+	State        string    `json:"state" gorm:"column:state;type:varchar(100);comment:'State/Province'"`
+	PostalCode   string    `json:"postal_code" gorm:"column:postal_code;type:varchar(50);comment:'Postal/ZIP code'"`
+	Country      string    `json:"country" gorm:"column:country;type:varchar(100);comment:'Country'"`
+	Currency     string    `json:"currency" gorm:"column:currency;type:varchar(10);not null;default:'USD';comment:'Currency the supplier invoices in'"`
+	LeadTimeDays int       `json:"lead_time_days" gorm:"column:lead_time_days;type:int;not null;default:0;comment:'Usual days from order to delivery'"`
+	IsActive     bool      `json:"is_active" gorm:"column:is_active;type:boolean;default:true;comment:'Whether new purchase orders can be raised'"`
+	Notes        string    `json:"notes" gorm:"column:notes;type:text;comment:'Internal notes'"`
+	CreatedAt    time.Time `json:"created_at" gorm:"autoCreateTime;column:created_at;comment:'Created at'"`
+	UpdatedAt    time.Time `json:"updated_at" gorm:"autoUpdateTime;column:updated_at;comment:'Updated at'"`
+}
+
+// TableName specifies the table name for the Supplier model
+func (Supplier) TableName() string {
+	return "suppliers"
+}
+
+// BeforeCreate sets timestamps and performs any pre-creation logic.
+func (s *Supplier) BeforeCreate(tx *gorm.DB) (err error) {
+	now := time.Now().UTC()
+	if s.CreatedAt.IsZero() {
+		s.CreatedAt = now
+	}
+	s.UpdatedAt = now
+	return nil
+}
+
+// BeforeUpdate updates the UpdatedAt timestamp before updating an existing record.
+func (s *Supplier) BeforeUpdate(tx *gorm.DB) (err error) {
+	s.UpdatedAt = time.Now().UTC()
+	return nil
+}
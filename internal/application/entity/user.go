@@ -19,6 +19,10 @@ type User struct {
 	CreatedAt time.Time      `json:"created_at" gorm:"column:created_at;type:timestamp;comment:'created at'"`
 	UpdatedAt time.Time      `json:"updated_at" gorm:"column:updated_at;type:timestamp;comment:'updated at'"`
 	DeletedAt gorm.DeletedAt `json:"deleted_at" gorm:"column:deleted_at;type:timestamp;comment:'deleted at'"`
+
+	// MarketingOptOutAt is set when the user unsubscribes from marketing
+	// emails such as abandoned cart reminders
+	MarketingOptOutAt *time.Time `json:"marketing_opt_out_at,omitempty" gorm:"column:marketing_opt_out_at;type:timestamp;comment:'when the user unsubscribed from marketing emails'"`
 }
 
 // TableName specifies the table name for the User model
@@ -0,0 +1,86 @@
+package entity
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// RecentlyViewed records the last time a shopper looked at a product. Like
+// Cart, rows belong to a signed-in user or to a guest token.
+type RecentlyViewed struct {
+	ID         string    `json:"id" gorm:"primaryKey;column:id;type:varchar(36);default:(UUID());comment:'Primary Key'"`
+	UserID     *string   `json:"user_id,omitempty" gorm:"column:user_id;type:varchar(36);index:idx_recently_viewed_user;comment:'FK to user entity'"`
+	GuestToken string    `json:"guest_token,omitempty" gorm:"column:guest_token;type:varchar(255);index:idx_recently_viewed_guest;comment:'Guest session token'"`
+	ProductID  string    `json:"product_id" gorm:"column:product_id;type:varchar(36);not null;comment:'FK to product'"`
+	ViewedAt   time.Time `json:"viewed_at" gorm:"column:viewed_at;not null;comment:'Last viewed at'"`
+
+	// Relations
+	Product *Product `json:"product,omitempty" gorm:"foreignKey:ProductID"`
+}
+
+// TableName specifies the table name for the RecentlyViewed model
+func (RecentlyViewed) TableName() string {
+	return "recentlyViewed"
+}
+
+// Wishlist is a named list of products saved by a signed-in user or a guest
+type Wishlist struct {
+	ID         string    `json:"id" gorm:"primaryKey;column:id;type:varchar(36);default:(UUID());comment:'Primary Key'"`
+	UserID     *string   `json:"user_id,omitempty" gorm:"column:user_id;type:varchar(36);index;comment:'FK to user entity'"`
+	GuestToken string    `json:"guest_token,omitempty" gorm:"column:guest_token;type:varchar(255);index;comment:'Guest session token'"`
+	Name       string    `json:"name" gorm:"column:name;type:varchar(100);not null;comment:'Wishlist name'"`
+	ShareToken *string   `json:"share_token,omitempty" gorm:"column:share_token;type:varchar(64);uniqueIndex;comment:'Token of the public link, null when not shared'"`
+	CreatedAt  time.Time `json:"created_at" gorm:"autoCreateTime;column:created_at;comment:'Created at'"`
+	UpdatedAt  time.Time `json:"updated_at" gorm:"autoUpdateTime;column:updated_at;comment:'Updated at'"`
+
+	// Relations
+	Items []WishlistItem `json:"items,omitempty" gorm:"foreignKey:WishlistID"`
+}
+
+// TableName specifies the table name for the Wishlist model
+func (Wishlist) TableName() string {
+	return "wishlists"
+}
+
+// BeforeCreate sets timestamps and performs any pre-creation logic.
+func (w *Wishlist) BeforeCreate(tx *gorm.DB) (err error) {
+	now := time.Now().UTC()
+	if w.CreatedAt.IsZero() {
+		w.CreatedAt = now
+	}
+	w.UpdatedAt = now
+	return nil
+}
+
+// BeforeUpdate updates the UpdatedAt timestamp before updating an existing record.
+func (w *Wishlist) BeforeUpdate(tx *gorm.DB) (err error) {
+	w.UpdatedAt = time.Now().UTC()
+	return nil
+}
+
+// WishlistItem is a product saved to a wishlist
+type WishlistItem struct {
+	ID         string    `json:"id" gorm:"primaryKey;column:id;type:varchar(36);default:(UUID());comment:'Primary Key'"`
+	WishlistID string    `json:"wishlist_id" gorm:"column:wishlist_id;type:varchar(36);not null;uniqueIndex:idx_wishlist_product;comment:'FK to wishlist'"`
+	ProductID  string    `json:"product_id" gorm:"column:product_id;type:varchar(36);not null;uniqueIndex:idx_wishlist_product;comment:'FK to product'"`
+	Note       string    `json:"note,omitempty" gorm:"column:note;type:varchar(255);comment:'Shopper note'"`
+	CreatedAt  time.Time `json:"created_at" gorm:"autoCreateTime;column:created_at;comment:'Created at'"`
+
+	// Relations
+	Wishlist *Wishlist `json:"-" gorm:"foreignKey:WishlistID"`
+	Product  *Product  `json:"product,omitempty" gorm:"foreignKey:ProductID"`
+}
+
+// TableName specifies the table name for the WishlistItem model
+func (WishlistItem) TableName() string {
+	return "wishlistItems"
+}
+
+// BeforeCreate sets timestamps and performs any pre-creation logic.
+func (wi *WishlistItem) BeforeCreate(tx *gorm.DB) (err error) {
+	if wi.CreatedAt.IsZero() {
+		wi.CreatedAt = time.Now().UTC()
+	}
+	return nil
+}
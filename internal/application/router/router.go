@@ -3,6 +3,9 @@ package router
 import (
 	"github.com/gin-gonic/gin"
 	"gorm.io/gorm"
+
+	"backend-ecommerce/internal/application/controller"
+	"backend-ecommerce/internal/infrastructure/config"
 )
 
 // Register registers all HTTP routes on the given engine.
@@ -23,7 +26,7 @@ func Register(router *gin.Engine, db *gorm.DB) {
 	// TODO: Uncomment when auth controller is implemented
 	// authCtrl := controller.NewAuthController(authService)
 	// api.POST("/auth/register", authCtrl.Register)
-	// api.POST("/auth/login", authCtrl.Login)
+	// api.POST("/auth/login", authCtrl.Login) // must merge guest data as POST /users/me/merge-guest does
 	// api.POST("/auth/refresh", authCtrl.RefreshToken)
 	// api.POST("/auth/password-reset/request", authCtrl.RequestPasswordReset)
 	// api.POST("/auth/password-reset/confirm", authCtrl.ConfirmPasswordReset)
@@ -52,10 +55,14 @@ func Register(router *gin.Engine, db *gorm.DB) {
 	})
 
 	// Product endpoints
+	api.GET("/products", controller.ProductCtrl.GetProducts)
+	api.GET("/products/:id", controller.ProductCtrl.GetProduct)
+	api.GET("/products/:id/preview", controller.ProductPublishingCtrl.GetPreview)
+
+	// Collection endpoints
+	api.GET("/collections", controller.CollectionCtrl.GetCollections)
+	api.GET("/collections/:slug", controller.CollectionCtrl.GetCollection)
 	// TODO: Uncomment when product controller is implemented
-	// productCtrl := controller.NewProductController(productService)
-	// api.GET("/products", productCtrl.GetProducts)
-	// api.GET("/products/:id", productCtrl.GetProduct)
 	// api.POST("/products", productCtrl.CreateProduct)
 	// api.PUT("/products/:id", productCtrl.UpdateProduct)
 	// api.DELETE("/products/:id", productCtrl.DeleteProduct)
@@ -69,42 +76,92 @@ func Register(router *gin.Engine, db *gorm.DB) {
 	// api.PUT("/categories/:id", categoryCtrl.UpdateCategory)
 	// api.DELETE("/categories/:id", categoryCtrl.DeleteCategory)
 
-	// Cart endpoints
-	// TODO: Uncomment when cart controller is implemented
-	// cartCtrl := controller.NewCartController(cartService)
-	// api.GET("/cart", cartCtrl.GetCart)
-	// api.POST("/cart/items", cartCtrl.AddToCart)
-	// api.PUT("/cart/items/:id", cartCtrl.UpdateCartItem)
-	// api.DELETE("/cart/items/:id", cartCtrl.RemoveFromCart
-
-	// Order endpoints
-	// TODO: Uncomment when order controller is implemented
-	// orderCtrl := controller.NewOrderController(orderService)
-	// api.POST("/orders", orderCtrl.CreateOrder)
-	// api.GET("/orders", orderCtrl.GetUserOrders)
-	// api.GET("/orders/:id", orderCtrl.GetOrderByID)
-
 	// Payment endpoints
 	// TODO: Uncomment when payment controller is implemented
 	// paymentCtrl := controller.NewPaymentController(paymentService)
 	// api.POST("/payments/create-payment-intent", paymentCtrl.CreatePaymentIntent)
-	// api.POST("/payments/webhook", paymentCtrl.HandleWebhook)
+	api.POST("/payments/webhook", controller.PaymentCtrl.HandleWebhook)
 
 	// File uploads
-	api.Static("/files", "./uploads")
+	api.GET("/files/*key", controller.FileCtrl.ServeFile)
+	api.GET("/signed-files/*key", controller.FileCtrl.ServeSignedFile)
 	api.POST("/upload", func(c *gin.Context) {
 		// TODO: Implement file upload handler
 		c.JSON(200, gin.H{"message": "File upload endpoint"})
 	})
 
+	// Shared wishlists
+	api.GET("/shared-wishlists/:token", controller.WishlistCtrl.GetSharedWishlist)
+	api.GET("/shared-carts/:id", controller.CartShareCtrl.GetSharedCart)
+
+	// Marketing email unsubscribe links
+	api.POST("/marketing/unsubscribe", controller.UserCtrl.Unsubscribe)
+
+	// Shopper routes (signed-in users or guests identified by X-Guest-Token)
+	shopper := api.Group("")
+	shopper.Use(config.ShopperMiddleware())
+
+	// Gift cards
+	shopper.POST("/gift-cards/balance", controller.GiftCardCtrl.CheckBalance)
+
+	// Cart
+	shopper.GET("/carts", controller.CartCtrl.GetOrCreateCart)
+	shopper.DELETE("/carts", controller.CartCtrl.ClearCart)
+	shopper.POST("/carts/items", controller.CartCtrl.AddCartItem)
+	shopper.PUT("/carts/items/:item_id", controller.CartCtrl.UpdateCartItem)
+	shopper.DELETE("/carts/items/:item_id", controller.CartCtrl.RemoveCartItem)
+	shopper.POST("/carts/acknowledge", controller.CartCtrl.AcknowledgeChanges)
+	shopper.GET("/carts/recommendations", controller.CartCtrl.GetRecommendations)
+	shopper.POST("/carts/restore", controller.CartRecoveryCtrl.RestoreCart)
+	shopper.POST("/carts/items/:item_id/save-for-later", controller.CartCtrl.SaveForLater)
+	shopper.POST("/carts/saved-items/:saved_id/move-to-cart", controller.CartCtrl.MoveSavedToCart)
+	shopper.DELETE("/carts/saved-items/:saved_id", controller.CartCtrl.RemoveSavedItem)
+	shopper.POST("/carts/share", controller.CartShareCtrl.ShareCart)
+	shopper.POST("/carts/coupons", controller.CouponCtrl.ApplyCoupon)
+	shopper.DELETE("/carts/coupons/:code", controller.CouponCtrl.RemoveCoupon)
+	shopper.POST("/shared-carts/:id/copy", controller.CartShareCtrl.CopySharedCart)
+
+	// Recently viewed products
+	shopper.GET("/recently-viewed", controller.RecentlyViewedCtrl.GetRecentlyViewed)
+	shopper.POST("/recently-viewed", controller.RecentlyViewedCtrl.RecordView)
+	shopper.DELETE("/recently-viewed", controller.RecentlyViewedCtrl.ClearRecentlyViewed)
+
+	// Wishlists
+	shopper.GET("/wishlists", controller.WishlistCtrl.GetWishlists)
+	shopper.POST("/wishlists", controller.WishlistCtrl.CreateWishlist)
+	shopper.PUT("/wishlists/:id", controller.WishlistCtrl.RenameWishlist)
+	shopper.DELETE("/wishlists/:id", controller.WishlistCtrl.DeleteWishlist)
+	shopper.POST("/wishlists/:id/items", controller.WishlistCtrl.AddItem)
+	shopper.DELETE("/wishlists/:id/items/:item_id", controller.WishlistCtrl.RemoveItem)
+	shopper.POST("/wishlists/:id/items/:item_id/move-to-cart", controller.WishlistCtrl.MoveItemToCart)
+	shopper.POST("/wishlists/:id/share", controller.WishlistCtrl.ShareWishlist)
+	shopper.DELETE("/wishlists/:id/share", controller.WishlistCtrl.UnshareWishlist)
+
+	// Customer routes (require a signed-in user)
+	account := api.Group("")
+	account.Use(config.AuthMiddleware())
+
+	// Guest data merge; clients call it after every sign-in, nothing else merges guest carts
+	account.POST("/users/me/merge-guest", controller.UserCtrl.MergeGuestData)
+	account.PUT("/users/me/marketing-preferences", controller.UserCtrl.UpdateMarketingPreferences)
+
+	// Orders
+	account.POST("/orders", controller.OrderCtrl.CreateOrder)
+	account.GET("/orders", controller.OrderCtrl.GetUserOrders)
+	account.GET("/orders/:id", controller.OrderCtrl.GetOrder)
+
+	// Stock and price alerts
+	account.GET("/alerts", controller.ProductAlertCtrl.GetAlerts)
+	account.POST("/alerts", controller.ProductAlertCtrl.CreateAlert)
+	account.DELETE("/alerts/:id", controller.ProductAlertCtrl.CancelAlert)
+
+	// Digital downloads
+	account.GET("/downloads", controller.DigitalProductCtrl.GetDownloads)
+	account.POST("/downloads/:id/link", controller.DigitalProductCtrl.CreateDownloadLink)
+
 	// Admin routes (protected by admin middleware)
 	admin := api.Group("/admin")
-	admin.Use(func() gin.HandlerFunc {
-		return func(c *gin.Context) {
-			// TODO: Implement admin middleware
-			c.Next()
-		}
-	}())
+	admin.Use(config.AuthMiddleware(), config.AdminMiddleware())
 
 	// Admin user management
 	// TODO: Uncomment when user controller is implemented
@@ -121,6 +178,114 @@ func Register(router *gin.Engine, db *gorm.DB) {
 		c.JSON(200, gin.H{"message": "Product export endpoint (not implemented)"})
 	})
 
+	admin.PUT("/products/:id", controller.ProductCtrl.UpdateProduct)
+
+	// Admin product publishing
+	admin.PUT("/products/:id/status", controller.ProductPublishingCtrl.UpdateStatus)
+	admin.PUT("/products/:id/publication-schedule", controller.ProductPublishingCtrl.SchedulePublication)
+	admin.POST("/products/:id/preview-token", controller.ProductPublishingCtrl.CreatePreviewToken)
+
+	// Admin product revisions
+	admin.GET("/products/:id/revisions", controller.ProductRevisionCtrl.GetRevisions)
+	admin.GET("/products/:id/revisions/diff", controller.ProductRevisionCtrl.DiffRevisions)
+	admin.GET("/products/:id/revisions/:version", controller.ProductRevisionCtrl.GetRevision)
+	admin.POST("/products/:id/revisions/:version/restore", controller.ProductRevisionCtrl.RestoreRevision)
+
+	// Admin collections
+	admin.GET("/collections", controller.CollectionCtrl.GetAdminCollections)
+	admin.POST("/collections", controller.CollectionCtrl.CreateCollection)
+	admin.PUT("/collections/:id", controller.CollectionCtrl.UpdateCollection)
+	admin.DELETE("/collections/:id", controller.CollectionCtrl.DeleteCollection)
+	admin.PUT("/collections/:id/products", controller.CollectionCtrl.SetCollectionProducts)
+	admin.POST("/collections/:id/refresh", controller.CollectionCtrl.RefreshCollection)
+
+	// Admin product relations
+	admin.GET("/products/:id/relations", controller.ProductRelationCtrl.GetRelations)
+	admin.PUT("/products/:id/relations/:type", controller.ProductRelationCtrl.SetRelations)
+
+	// Admin inventory
+	admin.GET("/stock-locations", controller.StockLocationCtrl.GetStockLocations)
+	admin.POST("/stock-locations", controller.StockLocationCtrl.CreateStockLocation)
+	admin.PUT("/stock-locations/:id", controller.StockLocationCtrl.UpdateStockLocation)
+	admin.DELETE("/stock-locations/:id", controller.StockLocationCtrl.DeleteStockLocation)
+	admin.GET("/products/:id/stock", controller.InventoryCtrl.GetProductStock)
+	admin.PUT("/inventory", controller.InventoryCtrl.SetStockLevel)
+	admin.POST("/inventory/adjustments", controller.InventoryCtrl.AdjustStock)
+	admin.POST("/inventory/transfers", controller.InventoryCtrl.TransferStock)
+	admin.GET("/inventory/movements", controller.InventoryCtrl.GetMovements)
+	admin.GET("/inventory/reconciliation", controller.InventoryCtrl.ReconcileStock)
+	admin.PUT("/inventory/reorder-settings", controller.InventoryCtrl.SetReorderSettings)
+	admin.GET("/inventory/low-stock", controller.InventoryCtrl.GetLowStockReport)
+
+	// Admin purchasing
+	admin.GET("/suppliers", controller.SupplierCtrl.GetSuppliers)
+	admin.POST("/suppliers", controller.SupplierCtrl.CreateSupplier)
+	admin.PUT("/suppliers/:id", controller.SupplierCtrl.UpdateSupplier)
+	admin.DELETE("/suppliers/:id", controller.SupplierCtrl.DeleteSupplier)
+	admin.GET("/purchase-orders", controller.PurchaseOrderCtrl.GetPurchaseOrders)
+	admin.POST("/purchase-orders", controller.PurchaseOrderCtrl.CreatePurchaseOrder)
+	admin.GET("/purchase-orders/:id", controller.PurchaseOrderCtrl.GetPurchaseOrder)
+	admin.PUT("/purchase-orders/:id", controller.PurchaseOrderCtrl.UpdatePurchaseOrder)
+	admin.DELETE("/purchase-orders/:id", controller.PurchaseOrderCtrl.DeletePurchaseOrder)
+	admin.POST("/purchase-orders/:id/send", controller.PurchaseOrderCtrl.SendPurchaseOrder)
+	admin.POST("/purchase-orders/:id/receipts", controller.PurchaseOrderCtrl.ReceiveGoods)
+	admin.POST("/purchase-orders/:id/close", controller.PurchaseOrderCtrl.ClosePurchaseOrder)
+
+	// Admin coupons
+	admin.GET("/coupons", controller.CouponCtrl.GetCoupons)
+	admin.POST("/coupons", controller.CouponCtrl.CreateCoupon)
+	admin.GET("/coupons/:id", controller.CouponCtrl.GetCoupon)
+	admin.PUT("/coupons/:id", controller.CouponCtrl.UpdateCoupon)
+	admin.DELETE("/coupons/:id", controller.CouponCtrl.DeleteCoupon)
+	admin.GET("/coupons/:id/codes", controller.CouponCtrl.GetCouponCodes)
+	admin.POST("/coupons/:id/codes", controller.CouponCtrl.GenerateCodes)
+
+	// Admin promotions
+	admin.GET("/promotions", controller.PromotionCtrl.GetPromotions)
+	admin.POST("/promotions", controller.PromotionCtrl.CreatePromotion)
+	admin.POST("/promotions/simulate", controller.PromotionCtrl.SimulatePromotion)
+	admin.GET("/promotions/:id", controller.PromotionCtrl.GetPromotion)
+	admin.PUT("/promotions/:id", controller.PromotionCtrl.UpdatePromotion)
+	admin.DELETE("/promotions/:id", controller.PromotionCtrl.DeletePromotion)
+
+	// Admin gift cards
+	admin.GET("/gift-cards", controller.GiftCardCtrl.GetGiftCards)
+	admin.POST("/gift-cards", controller.GiftCardCtrl.IssueGiftCard)
+	admin.GET("/gift-cards/:id", controller.GiftCardCtrl.GetGiftCard)
+	admin.PUT("/gift-cards/:id", controller.GiftCardCtrl.UpdateGiftCard)
+	admin.PUT("/products/:id/gift-card", controller.GiftCardCtrl.SetGiftCardProduct)
+	admin.DELETE("/products/:id/gift-card", controller.GiftCardCtrl.RemoveGiftCardProduct)
+	admin.POST("/payments/:id/refund", controller.PaymentCtrl.RefundPayment)
+
+	// Admin abandoned cart recovery
+	admin.GET("/carts/recovery-report", controller.CartRecoveryCtrl.GetRecoveryReport)
+
+	// Admin alert demand
+	admin.GET("/alerts/demand", controller.ProductAlertCtrl.GetDemandReport)
+
+	// Admin product images
+	admin.GET("/products/:id/images", controller.ProductImageCtrl.GetProductImages)
+	admin.POST("/products/:id/images", controller.ProductImageCtrl.UploadProductImage)
+	admin.PUT("/products/:id/images/order", controller.ProductImageCtrl.ReorderProductImages)
+	admin.DELETE("/products/:id/images/:image_id", controller.ProductImageCtrl.DeleteProductImage)
+
+	// Admin price schedules
+	admin.GET("/products/:id/price-schedules", controller.PriceScheduleCtrl.GetPriceSchedules)
+	admin.POST("/products/:id/price-schedules", controller.PriceScheduleCtrl.CreatePriceSchedule)
+	admin.DELETE("/price-schedules/:id", controller.PriceScheduleCtrl.DeletePriceSchedule)
+
+	// Admin bundles
+	admin.PUT("/products/:id/bundle", controller.BundleCtrl.UpdateBundle)
+	admin.DELETE("/products/:id/bundle", controller.BundleCtrl.RemoveBundle)
+
+	// Admin digital products
+	admin.GET("/products/:id/digital-assets", controller.DigitalProductCtrl.GetDigitalAssets)
+	admin.POST("/products/:id/digital-assets", controller.DigitalProductCtrl.UploadDigitalAsset)
+	admin.PUT("/digital-assets/:id", controller.DigitalProductCtrl.UpdateDigitalAsset)
+	admin.DELETE("/digital-assets/:id", controller.DigitalProductCtrl.DeleteDigitalAsset)
+	admin.GET("/products/:id/license-keys", controller.DigitalProductCtrl.GetLicenseKeyStock)
+	admin.POST("/products/:id/license-keys", controller.DigitalProductCtrl.AddLicenseKeys)
+
 	// Admin order management
 	admin.GET("/all-orders", func(c *gin.Context) {
 		c.JSON(200, gin.H{"message": "All orders endpoint (not implemented)"})
@@ -0,0 +1,343 @@
+package service
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+
+	"backend-ecommerce/internal/application/dto"
+	"backend-ecommerce/internal/application/entity"
+	"backend-ecommerce/internal/application/tools"
+	"backend-ecommerce/internal/infrastructure/cachemanager"
+	"backend-ecommerce/internal/infrastructure/dbmanager"
+	"backend-ecommerce/internal/infrastructure/logger"
+)
+
+type bundleService struct {
+}
+
+// bundleDetails is a bundle resolved at a point in time
+type bundleDetails struct {
+	Price      entity.EffectivePrice
+	Response   dto.BundleResponse
+	Components []entity.BundleComponent
+	// ComponentPrices holds the effective unit price of each component, in
+	// Components order; both are built together from the sellable components
+	ComponentPrices []float64
+}
+
+// UpdateBundle turns a product into a bundle and replaces its components
+func (s *bundleService) UpdateBundle(productID string, req dto.BundleUpdateRequest, changedBy string) dto.ResponseDto {
+	db := dbmanager.GetDB()
+
+	var product entity.Product
+	if err := db.Where("id = ?", productID).First(&product).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return *dto.Fail("Product not found")
+		}
+		logger.Error("Error fetching product: %v", err)
+		return *dto.Fail("Error fetching product")
+	}
+
+	components := make([]entity.BundleComponent, len(req.Components))
+	for i, item := range req.Components {
+		if item.ProductID == productID {
+			return *dto.Fail("A bundle cannot contain itself")
+		}
+
+		var component entity.Product
+		if err := db.Where("id = ?", item.ProductID).First(&component).Error; err != nil {
+			if err == gorm.ErrRecordNotFound {
+				return *dto.Fail("Component product not found")
+			}
+			logger.Error("Error fetching component product: %v", err)
+			return *dto.Fail("Error fetching component product")
+		}
+		if component.Type == entity.ProductTypeBundle {
+			return *dto.Fail("Bundles cannot be nested")
+		}
+
+		if item.VariantID != nil {
+			var count int64
+			if err := db.Model(&entity.ProductVariant{}).Where("id = ? AND product_id = ?", *item.VariantID, item.ProductID).Count(&count).Error; err != nil {
+				logger.Error("Error fetching component variant: %v", err)
+				return *dto.Fail("Error fetching component variant")
+			}
+			if count == 0 {
+				return *dto.Fail("Component variant not found")
+			}
+		}
+
+		components[i] = entity.BundleComponent{
+			ID:                 tools.NewUuid(),
+			BundleID:           productID,
+			ComponentProductID: item.ProductID,
+			ComponentVariantID: item.VariantID,
+			Quantity:           item.Quantity,
+			SortOrder:          i,
+		}
+	}
+
+	if product.Type != entity.ProductTypeBundle {
+		var count int64
+		if err := db.Model(&entity.BundleComponent{}).Where("component_product_id = ?", productID).Count(&count).Error; err != nil {
+			logger.Error("Error fetching bundle components: %v", err)
+			return *dto.Fail("Error fetching bundle components")
+		}
+		if count > 0 {
+			return *dto.Fail("Bundles cannot be nested")
+		}
+	}
+
+	err := IProductRevisionService.Track(db, productID, changedBy, "Bundle updated", func(tx *gorm.DB) error {
+		if err := tx.Model(&entity.Product{}).Where("id = ?", productID).Updates(map[string]interface{}{
+			"type":            entity.ProductTypeBundle,
+			"bundle_pricing":  entity.BundlePricing(req.Pricing),
+			"bundle_discount": req.DiscountPercent,
+		}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("bundle_id = ?", productID).Delete(&entity.BundleComponent{}).Error; err != nil {
+			return err
+		}
+		return tx.Create(&components).Error
+	})
+	if err != nil {
+		logger.Error("Error updating bundle: %v", err)
+		return *dto.Fail("Error updating bundle")
+	}
+
+	ICollectionService.RefreshProductMemberships([]string{productID})
+	cachemanager.DeletePrefix(productCachePrefix)
+
+	return IProductService.GetProductForAdmin(productID)
+}
+
+// RemoveBundle turns a bundle back into a simple product
+func (s *bundleService) RemoveBundle(productID, changedBy string) dto.ResponseDto {
+	db := dbmanager.GetDB()
+
+	err := IProductRevisionService.Track(db, productID, changedBy, "Bundle removed", func(tx *gorm.DB) error {
+		if err := tx.Where("bundle_id = ?", productID).Delete(&entity.BundleComponent{}).Error; err != nil {
+			return err
+		}
+		return tx.Model(&entity.Product{}).Where("id = ?", productID).Updates(map[string]interface{}{
+			"type":            entity.ProductTypeSimple,
+			"bundle_pricing":  "",
+			"bundle_discount": 0,
+		}).Error
+	})
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return *dto.Fail("Product not found")
+		}
+		logger.Error("Error removing bundle: %v", err)
+		return *dto.Fail("Error removing bundle")
+	}
+
+	ICollectionService.RefreshProductMemberships([]string{productID})
+	cachemanager.DeletePrefix(productCachePrefix)
+
+	return *dto.Success("Bundle removed successfully")
+}
+
+// ResolveBundles prices every bundle among products at t and works out how
+// many can be sold from component stock. ownPrices holds the bundles' own
+// resolved prices, used for fixed pricing. Results are keyed by bundle ID.
+func (s *bundleService) ResolveBundles(db *gorm.DB, products []entity.Product, ownPrices map[string]entity.EffectivePrice, t time.Time) (map[string]bundleDetails, error) {
+	details := make(map[string]bundleDetails)
+
+	var bundleIDs []string
+	for _, product := range products {
+		if product.Type == entity.ProductTypeBundle {
+			bundleIDs = append(bundleIDs, product.ID)
+		}
+	}
+	if len(bundleIDs) == 0 {
+		return details, nil
+	}
+
+	var components []entity.BundleComponent
+	if err := db.Preload("ComponentProduct").Preload("ComponentVariant").
+		Where("bundle_id IN ?", bundleIDs).
+		Order("sort_order ASC").
+		Find(&components).Error; err != nil {
+		return nil, err
+	}
+
+	// Price the components the same way the catalog prices them
+	componentProducts := make(map[string]*entity.Product)
+	var componentIDs []string
+	for _, component := range components {
+		if component.ComponentProduct == nil {
+			continue
+		}
+		product, ok := componentProducts[component.ComponentProductID]
+		if !ok {
+			copied := *component.ComponentProduct
+			copied.Variants = nil
+			product = &copied
+			componentProducts[component.ComponentProductID] = product
+			componentIDs = append(componentIDs, product.ID)
+		}
+		if component.ComponentVariant != nil {
+			product.Variants = append(product.Variants, *component.ComponentVariant)
+		}
+	}
+	pricedProducts := make([]entity.Product, 0, len(componentProducts))
+	for _, id := range componentIDs {
+		pricedProducts = append(pricedProducts, *componentProducts[id])
+	}
+	productPrices, variantPrices, err := IPriceScheduleService.ResolvePrices(db, pricedProducts, t)
+	if err != nil {
+		return nil, err
+	}
+
+	availability, variantAvailability, err := IInventoryService.GetStockLevels(db, componentIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	componentsByBundle := make(map[string][]entity.BundleComponent)
+	for _, component := range components {
+		componentsByBundle[component.BundleID] = append(componentsByBundle[component.BundleID], component)
+	}
+
+	for _, product := range products {
+		if product.Type != entity.ProductTypeBundle {
+			continue
+		}
+
+		bundle := bundleDetails{
+			Response: dto.BundleResponse{
+				Pricing:         string(product.BundlePricing),
+				DiscountPercent: product.BundleDiscount,
+				Components:      []dto.BundleComponentResponse{},
+			},
+		}
+
+		available := -1
+		var sum, regularSum float64
+		for _, component := range componentsByBundle[product.ID] {
+			// A component that was deleted, or has since become a bundle
+			// itself, leaves the bundle unavailable rather than sold short
+			if component.ComponentProduct == nil || component.ComponentProduct.Type == entity.ProductTypeBundle {
+				available = 0
+				continue
+			}
+
+			price := productPrices[component.ComponentProductID]
+			name, sku := component.ComponentProduct.Name, component.ComponentProduct.SKU
+			if component.ComponentVariant != nil {
+				price = variantPrices[component.ComponentVariant.ID]
+				name = name + " - " + component.ComponentVariant.Name
+				sku = component.ComponentVariant.SKU
+			}
+			regular := price.Price
+			if price.CompareAtPrice != nil {
+				regular = *price.CompareAtPrice
+			}
+			sum += price.Price * float64(component.Quantity)
+			regularSum += regular * float64(component.Quantity)
+			bundle.Components = append(bundle.Components, component)
+			bundle.ComponentPrices = append(bundle.ComponentPrices, price.Price)
+
+			// A bundle is only as available as its scarcest component. A
+			// variant stocked on its own is sold from its own stock.
+			componentAvailable := availability[component.ComponentProductID]
+			if component.ComponentVariantID != nil {
+				if variantAvailable, ok := variantAvailability[*component.ComponentVariantID]; ok {
+					componentAvailable = variantAvailable
+				}
+			}
+			if !component.ComponentProduct.IsActive {
+				componentAvailable = 0
+			}
+			sets := componentAvailable / component.Quantity
+			if available < 0 || sets < available {
+				available = sets
+			}
+
+			bundle.Response.Components = append(bundle.Response.Components, dto.BundleComponentResponse{
+				ProductID:   component.ComponentProductID,
+				VariantID:   component.ComponentVariantID,
+				Name:        name,
+				SKU:         sku,
+				Quantity:    component.Quantity,
+				UnitPrice:   price.Price,
+				Available:   componentAvailable,
+				IsAvailable: componentAvailable >= component.Quantity,
+			})
+		}
+		bundle.Response.Available = max(0, available)
+
+		if product.BundlePricing == entity.BundlePricingComputed {
+			discounted := tools.RoundPrice(sum * (1 - product.BundleDiscount/100))
+			bundle.Price = entity.EffectivePrice{Price: discounted}
+			if regularSum := tools.RoundPrice(regularSum); regularSum > discounted {
+				bundle.Price.CompareAtPrice = &regularSum
+			}
+		} else {
+			bundle.Price = ownPrices[product.ID]
+		}
+
+		details[product.ID] = bundle
+	}
+
+	return details, nil
+}
+
+// ExpandBundleLine builds the component order lines of a bundle line. The
+// parent's total is split across components in proportion to their current
+// prices so each component can be fulfilled and refunded on its own; the
+// last component absorbs any rounding difference.
+func (s *bundleService) ExpandBundleLine(parent entity.OrderItem, bundle bundleDetails) []entity.OrderItem {
+	var weightTotal float64
+	for i, component := range bundle.Components {
+		weightTotal += bundle.ComponentPrices[i] * float64(component.Quantity)
+	}
+
+	items := make([]entity.OrderItem, 0, len(bundle.Components))
+	remaining := parent.TotalPrice
+	for i, component := range bundle.Components {
+		quantity := component.Quantity * parent.Quantity
+
+		var total float64
+		switch {
+		case i == len(bundle.Components)-1:
+			total = tools.RoundPrice(remaining)
+		case weightTotal > 0:
+			share := bundle.ComponentPrices[i] * float64(component.Quantity) / weightTotal
+			total = tools.RoundPrice(parent.TotalPrice * share)
+		default:
+			total = tools.RoundPrice(parent.TotalPrice / float64(len(bundle.Components)))
+		}
+		remaining -= total
+
+		name, sku := "", ""
+		if component.ComponentProduct != nil {
+			name, sku = component.ComponentProduct.Name, component.ComponentProduct.SKU
+		}
+		if component.ComponentVariant != nil {
+			name = name + " - " + component.ComponentVariant.Name
+			sku = component.ComponentVariant.SKU
+		}
+
+		productID := component.ComponentProductID
+		parentID := parent.ID
+		items = append(items, entity.OrderItem{
+			ID:           tools.NewUuid(),
+			OrderID:      parent.OrderID,
+			ProductID:    &productID,
+			VariantID:    component.ComponentVariantID,
+			ParentItemID: &parentID,
+			ProductName:  name,
+			SKU:          sku,
+			Quantity:     quantity,
+			UnitPrice:    tools.RoundPrice(total / float64(quantity)),
+			TotalPrice:   total,
+		})
+	}
+
+	return items
+}
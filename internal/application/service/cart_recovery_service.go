@@ -0,0 +1,414 @@
+package service
+
+import (
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+
+	"backend-ecommerce/internal/application/dto"
+	"backend-ecommerce/internal/application/entity"
+	"backend-ecommerce/internal/application/tools"
+	"backend-ecommerce/internal/infrastructure/config"
+	"backend-ecommerce/internal/infrastructure/dbmanager"
+	"backend-ecommerce/internal/infrastructure/logger"
+)
+
+const (
+	cartRecoveryAudience = "cart-recovery"
+	cartRecoveryKind     = "abandoned_cart"
+	// cartRecoveryMaxIdle bounds, as a multiple of the last reminder window,
+	// how long a cart may have been left before reminders are no longer worth
+	// starting, e.g. for carts abandoned before the job was turned on
+	cartRecoveryMaxIdle = 2
+)
+
+type cartRecoveryService struct {
+	mu sync.Mutex
+}
+
+// SendReminders is run by cronmanager. It queues a reminder for every user
+// cart that has sat unchanged for the next of the configured windows, with a
+// link that restores the cart on any device. Users who unsubscribed from
+// marketing emails, have no email address or checked out since are skipped.
+// A cart whose link was used gets no further reminders.
+func (s *cartRecoveryService) SendReminders() {
+	db := dbmanager.GetDB()
+	if db == nil {
+		return
+	}
+	if !s.mu.TryLock() {
+		return
+	}
+	defer s.mu.Unlock()
+
+	windows := recoveryWindows()
+	now := time.Now().UTC()
+	from, to := now.Add(-cartRecoveryMaxIdle*windows[len(windows)-1]), now.Add(-windows[0])
+
+	// Carts held outside SQL are written back so the scan below sees them
+	if err := cartStore.PersistIdle(db, from, to); err != nil {
+		logger.Error("Error persisting idle carts: %v", err)
+	}
+
+	var carts []struct {
+		ID        string
+		UserID    string
+		UpdatedAt time.Time
+		FullName  string
+		Username  string
+	}
+	if err := db.Model(&entity.Cart{}).
+		Select("carts.id, carts.user_id, carts.updated_at, users.full_name, users.username").
+		Joins("JOIN users ON users.id = carts.user_id AND users.deleted_at IS NULL").
+		Where("users.email <> '' AND users.is_active = ? AND users.marketing_opt_out_at IS NULL", true).
+		Where("carts.updated_at <= ? AND carts.updated_at > ?", to, from).
+		Where("EXISTS (?)", db.Model(&entity.CartItem{}).Select("1").Where("cartItems.cart_id = carts.id")).
+		Where("NOT EXISTS (?)", db.Model(&entity.Order{}).Select("1").Where("orders.user_id = carts.user_id AND orders.created_at >= carts.updated_at")).
+		Order("carts.updated_at ASC").Scan(&carts).Error; err != nil {
+		logger.Error("Error fetching abandoned carts: %v", err)
+		return
+	}
+	if len(carts) == 0 {
+		return
+	}
+
+	cartIDs := make([]string, len(carts))
+	for i, cart := range carts {
+		cartIDs[i] = cart.ID
+	}
+	var recoveries []entity.CartRecovery
+	if err := db.Where("cart_id IN ?", cartIDs).Find(&recoveries).Error; err != nil {
+		logger.Error("Error fetching cart recoveries: %v", err)
+		return
+	}
+	byCart := make(map[string]entity.CartRecovery, len(recoveries))
+	for _, recovery := range recoveries {
+		byCart[recovery.CartID+recovery.AbandonedAt.UTC().Format(time.RFC3339Nano)] = recovery
+	}
+
+	queued := 0
+	for _, cart := range carts {
+		abandonedAt := cart.UpdatedAt.UTC()
+		recovery, ok := byCart[cart.ID+abandonedAt.Format(time.RFC3339Nano)]
+		if !ok {
+			recovery = entity.CartRecovery{CartID: cart.ID, UserID: cart.UserID, AbandonedAt: abandonedAt}
+		}
+		if recovery.RestoredAt != nil {
+			continue
+		}
+
+		// Reminders missed, e.g. while the job was not running, are skipped
+		// rather than sent all at once
+		idle := now.Sub(abandonedAt)
+		step := -1
+		for i := recovery.RemindersSent; i < len(windows); i++ {
+			if idle >= windows[i] {
+				step = i
+			}
+		}
+		if step < 0 {
+			continue
+		}
+
+		name := cart.FullName
+		if name == "" {
+			name = cart.Username
+		}
+		notification, err := s.reminder(db, cart.ID, cart.UserID, name, step, len(windows))
+		if err != nil {
+			logger.Error("Error building reminder for cart %s: %v", cart.ID, err)
+			continue
+		}
+		if notification == nil {
+			continue
+		}
+		notification.DedupKey = fmt.Sprintf("%s:%s:%d:%d", cartRecoveryKind, cart.ID, abandonedAt.UnixMilli(), step)
+
+		err = db.Transaction(func(tx *gorm.DB) error {
+			remindedAt := time.Now().UTC()
+			if recovery.ID == "" {
+				recovery.ID = tools.NewUuid()
+				recovery.RemindersSent = step + 1
+				recovery.LastRemindedAt = &remindedAt
+				if err := tx.Create(&recovery).Error; err != nil {
+					return err
+				}
+			} else if err := tx.Model(&entity.CartRecovery{}).Where("id = ?", recovery.ID).Updates(map[string]interface{}{
+				"reminders_sent":   step + 1,
+				"last_reminded_at": remindedAt,
+			}).Error; err != nil {
+				return err
+			}
+			_, err := INotificationService.Enqueue(tx, *notification)
+			return err
+		})
+		if err != nil {
+			logger.Error("Error queueing reminder for cart %s: %v", cart.ID, err)
+			continue
+		}
+		queued++
+	}
+
+	if queued > 0 {
+		logger.Info("Abandoned cart reminders queued: %d", queued)
+	}
+}
+
+// RestoreCart copies the items of the cart in a reminder's restore link into
+// the shopper's own cart, so the link works on any device and whether or not
+// the shopper is signed in. Lines already in the cart keep the larger
+// quantity; items that can no longer be bought are left out.
+func (s *cartRecoveryService) RestoreCart(userID, guestToken string, req dto.CartRestoreRequest) dto.ResponseDto {
+	if userID == "" && guestToken == "" {
+		return *dto.Fail("Shopper not identified")
+	}
+	if err := config.JWT.VerifyScoped(req.Token, req.CartID, cartRecoveryAudience); err != nil {
+		return *dto.Fail("Invalid or expired recovery link")
+	}
+	db := dbmanager.GetDB()
+
+	source, err := cartStore.Get(db, req.CartID)
+	if err != nil {
+		logger.Error("Error fetching cart: %v", err)
+		return *dto.Fail("Error restoring cart")
+	}
+	if source == nil {
+		return *dto.Fail("This cart no longer exists")
+	}
+	if len(source.Items) == 0 {
+		return *dto.Fail("This cart is empty; it may have been checked out already")
+	}
+
+	var cartID string
+	skipped := 0
+	err = db.Transaction(func(tx *gorm.DB) error {
+		cart, err := ICartService.findOrCreateCart(tx, userID, guestToken)
+		if err != nil {
+			return err
+		}
+		cartID = cart.ID
+
+		if cart.ID != source.ID {
+			if skipped, err = ICartService.copyItems(tx, cart, source.Items); err != nil {
+				return err
+			}
+		}
+
+		return tx.Model(&entity.CartRecovery{}).Where("cart_id = ? AND restored_at IS NULL", source.ID).
+			Update("restored_at", time.Now().UTC()).Error
+	})
+	if err != nil {
+		logger.Error("Error restoring cart %s: %v", source.ID, err)
+		return *dto.Fail("Error restoring cart")
+	}
+
+	response := ICartService.getCart(db, cartID, nil)
+	if response.Code == 0 && skipped > 0 {
+		response.Msg = fmt.Sprintf("%d items could not be restored because they are no longer available in that quantity", skipped)
+	}
+	return response
+}
+
+// GetRecoveryReport reports the carts first reminded between from and to
+// (YYYY-MM-DD, the last 30 days by default) and how many were recovered
+func (s *cartRecoveryService) GetRecoveryReport(from, to string) dto.ResponseDto {
+	db := dbmanager.GetDB()
+
+	end := time.Now().UTC()
+	if to != "" {
+		parsed, err := time.Parse("2006-01-02", to)
+		if err != nil {
+			return *dto.Fail("Invalid to date, expected YYYY-MM-DD")
+		}
+		end = parsed.AddDate(0, 0, 1)
+	}
+	start := end.AddDate(0, 0, -30)
+	if from != "" {
+		parsed, err := time.Parse("2006-01-02", from)
+		if err != nil {
+			return *dto.Fail("Invalid from date, expected YYYY-MM-DD")
+		}
+		start = parsed
+	}
+
+	period := db.Model(&entity.CartRecovery{}).
+		Where("cartRecoveries.created_at >= ? AND cartRecoveries.created_at < ?", start, end)
+
+	var steps []struct {
+		RemindersSent int
+		Carts         int64
+		Restored      int64
+		Recovered     int64
+	}
+	if err := period.Session(&gorm.Session{}).
+		Select("reminders_sent, COUNT(*) AS carts, SUM(restored_at IS NOT NULL) AS restored, SUM(converted_at IS NOT NULL) AS recovered").
+		Group("reminders_sent").Scan(&steps).Error; err != nil {
+		logger.Error("Error reporting cart recoveries: %v", err)
+		return *dto.Fail("Error fetching recovery report")
+	}
+
+	var revenue []struct {
+		Currency string
+		Total    float64
+	}
+	if err := period.Session(&gorm.Session{}).
+		Select("orders.currency, SUM(orders.total_amount) AS total").
+		Joins("JOIN orders ON orders.id = cartRecoveries.order_id").
+		Where("cartRecoveries.converted_at IS NOT NULL AND orders.status <> ?", entity.OrderStatusCancelled).
+		Group("orders.currency").Scan(&revenue).Error; err != nil {
+		logger.Error("Error reporting recovered revenue: %v", err)
+		return *dto.Fail("Error fetching recovery report")
+	}
+
+	windows := recoveryWindows()
+	report := dto.CartRecoveryReportResponse{
+		From:             start.Format("2006-01-02"),
+		To:               end.AddDate(0, 0, -1).Format("2006-01-02"),
+		RecoveredRevenue: make(map[string]float64, len(revenue)),
+		Reminders:        make([]dto.CartRecoveryStepResponse, len(windows)),
+	}
+	for i, window := range windows {
+		report.Reminders[i] = dto.CartRecoveryStepResponse{Reminder: i + 1, Window: window.String()}
+	}
+	for _, row := range steps {
+		report.Reminded += row.Carts
+		report.RemindersSent += int64(row.RemindersSent) * row.Carts
+		report.Restored += row.Restored
+		report.Recovered += row.Recovered
+		for i := 0; i < row.RemindersSent && i < len(report.Reminders); i++ {
+			report.Reminders[i].Carts += row.Carts
+		}
+		if last := row.RemindersSent - 1; last >= 0 && last < len(report.Reminders) {
+			report.Reminders[last].Recovered += row.Recovered
+		}
+	}
+	if report.Reminded > 0 {
+		report.RecoveryRate = tools.RoundPrice(float64(report.Recovered) / float64(report.Reminded) * 100)
+	}
+	for _, row := range revenue {
+		report.RecoveredRevenue[row.Currency] = tools.RoundPrice(row.Total)
+	}
+
+	return *dto.Success(report)
+}
+
+// checkedOut is called when a user places an order. It credits the order to
+// the user's most recent reminder within the restore link's lifetime and
+// drops reminders still waiting to be delivered.
+func (s *cartRecoveryService) checkedOut(tx *gorm.DB, userID, orderID string) error {
+	now := time.Now().UTC()
+
+	var recovery entity.CartRecovery
+	err := tx.Where("user_id = ? AND converted_at IS NULL AND last_reminded_at >= ?", userID, now.Add(-config.Get().Cart.RecoveryTTL)).
+		Order("last_reminded_at DESC").First(&recovery).Error
+	switch {
+	case err == nil:
+		if err := tx.Model(&recovery).Updates(map[string]interface{}{"order_id": orderID, "converted_at": now}).Error; err != nil {
+			return err
+		}
+	case err != gorm.ErrRecordNotFound:
+		return err
+	}
+
+	return cancelPendingReminders(tx, userID)
+}
+
+// reminder builds the reminder for step of a sequence of steps reminders
+// about a cart, or returns nil if nothing in the cart can be bought any more
+func (s *cartRecoveryService) reminder(db *gorm.DB, cartID, userID, name string, step, steps int) (*entity.Notification, error) {
+	cart, err := cartStore.Get(db, cartID)
+	if err != nil || cart == nil {
+		return nil, err
+	}
+	quote, _, err := IPricingService.quote(db, ICartService.pricingCart(*cart), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var lines strings.Builder
+	for _, line := range quote.Lines {
+		if !line.Offered || line.Quantity == 0 {
+			continue
+		}
+		fmt.Fprintf(&lines, "- %d x %s: %.2f %s\n", line.Quantity, line.Name, line.Subtotal, quote.Currency)
+	}
+	if lines.Len() == 0 {
+		return nil, nil
+	}
+
+	ttl := config.Get().Cart.RecoveryTTL
+	restoreToken, err := config.JWT.SignScoped(cartID, cartRecoveryAudience, ttl)
+	if err != nil {
+		return nil, err
+	}
+	unsubscribeToken, err := config.JWT.SignScoped(userID, marketingUnsubscribeAudience, marketingUnsubscribeTTL)
+	if err != nil {
+		return nil, err
+	}
+	restoreURL := linkWithQuery(config.Get().Cart.RecoveryURL, url.Values{"cart": {cartID}, "token": {restoreToken}})
+	unsubscribeURL := linkWithQuery(config.Get().Notification.UnsubscribeURL, url.Values{"user": {userID}, "token": {unsubscribeToken}})
+
+	subject := "You left something in your cart"
+	switch {
+	case step > 0 && step == steps-1:
+		subject = "Last reminder: your cart is still waiting"
+	case step > 0:
+		subject = "Your cart is still waiting"
+	}
+
+	var body strings.Builder
+	if name != "" {
+		fmt.Fprintf(&body, "Hi %s,\n\n", name)
+	}
+	body.WriteString("You still have these items in your cart:\n\n")
+	body.WriteString(lines.String())
+	fmt.Fprintf(&body, "\nSubtotal: %.2f %s\n\n", quote.Subtotal, quote.Currency)
+	fmt.Fprintf(&body, "Pick up where you left off on any device: %s\n", restoreURL)
+	fmt.Fprintf(&body, "The link works until %s. Prices and stock may have changed since you added the items.\n\n", time.Now().UTC().Add(ttl).Format("January 2, 2006"))
+	fmt.Fprintf(&body, "Don't want these emails? Unsubscribe: %s\n", unsubscribeURL)
+
+	return &entity.Notification{
+		UserID:  userID,
+		Kind:    cartRecoveryKind,
+		Subject: subject,
+		Body:    body.String(),
+	}, nil
+}
+
+// cancelPendingReminders drops a user's abandoned cart reminders that have
+// not been delivered yet
+func cancelPendingReminders(tx *gorm.DB, userID string) error {
+	return tx.Model(&entity.Notification{}).
+		Where("user_id = ? AND kind = ? AND status = ?", userID, cartRecoveryKind, entity.NotificationStatusPending).
+		Update("status", entity.NotificationStatusExpired).Error
+}
+
+// recoveryWindows returns the configured reminder windows in ascending order
+func recoveryWindows() []time.Duration {
+	var windows []time.Duration
+	for _, window := range config.Get().Cart.RecoveryWindows {
+		if window > 0 {
+			windows = append(windows, window)
+		}
+	}
+	if len(windows) == 0 {
+		windows = []time.Duration{time.Hour, 24 * time.Hour, 72 * time.Hour}
+	}
+	sort.Slice(windows, func(i, j int) bool { return windows[i] < windows[j] })
+	return windows
+}
+
+// linkWithQuery appends query parameters to a configured link, which may
+// already have some
+func linkWithQuery(link string, query url.Values) string {
+	separator := "?"
+	if strings.Contains(link, "?") {
+		separator = "&"
+	}
+	return link + separator + query.Encode()
+}
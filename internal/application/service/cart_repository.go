@@ -0,0 +1,86 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+
+	"backend-ecommerce/internal/application/entity"
+	"backend-ecommerce/internal/infrastructure/config"
+	"backend-ecommerce/internal/infrastructure/logger"
+	"backend-ecommerce/internal/infrastructure/redismanager"
+)
+
+// errCartLineLimit is returned by cartRepository.AddItem when a line would
+// go over its limit
+var errCartLineLimit = errors.New("cart line limit exceeded")
+
+// cartRepository stores carts and their items. Methods take the database
+// handle of the surrounding transaction: the SQL repository works within it,
+// other repositories use it to keep the SQL copy of a cart in step. Carts are
+// returned with their items in the order they were added.
+type cartRepository interface {
+	// Find returns the most recent cart of a signed-in user or, when userID
+	// is empty, of a guest, or nil if there is none
+	Find(db *gorm.DB, userID, guestToken string) (*entity.Cart, error)
+	// Create creates an empty cart for a signed-in user or, when userID is
+	// empty, for a guest
+	Create(db *gorm.DB, userID, guestToken string) (*entity.Cart, error)
+	// Get returns a cart by ID, or nil if it does not exist
+	Get(db *gorm.DB, cartID string) (*entity.Cart, error)
+	// FindItem returns an item of a shopper's cart, or nil if the shopper has
+	// no such item
+	FindItem(db *gorm.DB, userID, guestToken, itemID string) (*entity.CartItem, error)
+	// AddItem adds quantity units of a product or variant to the cart's line
+	// for it, creating the line if needed, and sets the line's price. The new
+	// quantity is checked against limit atomically with the update; if it
+	// would be exceeded nothing changes and errCartLineLimit is returned.
+	AddItem(db *gorm.DB, cartID, productID string, variantID *string, quantity int, price float64, limit int) (int, error)
+	// UpdateItem sets the quantity of an item and, unless price is nil, its price
+	UpdateItem(db *gorm.DB, cartID, itemID string, quantity int, price *float64) error
+	// RemoveItems removes items from a cart, or every item when none are given
+	RemoveItems(db *gorm.DB, cartID string, itemIDs ...string) error
+	// Assign hands a guest's cart over to a signed-in user
+	Assign(db *gorm.DB, cartID, userID string) error
+	// Delete deletes a cart and its items
+	Delete(db *gorm.DB, cartID string) error
+	// CheckoutCart returns a user's most recent cart, or nil if there is
+	// none, after writing it to SQL. The SQL row stays locked for the rest of
+	// db's transaction so a cart cannot be checked out twice.
+	CheckoutCart(db *gorm.DB, userID string) (*entity.Cart, error)
+	// PersistIdle writes to SQL the carts of signed-in users that last changed
+	// between from and to, so jobs reading carts from SQL see them as they are
+	PersistIdle(db *gorm.DB, from, to time.Time) error
+}
+
+// cartStore is the repository the cart services use, set by InitCartRepository
+var cartStore cartRepository = &sqlCartRepository{}
+
+// InitCartRepository selects the cart repository configured in cart.backend:
+// sql, the default, or redis for high traffic. cachemanager.Init must run
+// first, as it connects to Redis.
+func InitCartRepository() error {
+	backend := config.Get().Cart.Backend
+	switch backend {
+	case "sql":
+		cartStore = &sqlCartRepository{}
+	case "redis":
+		if redismanager.Redis == nil {
+			return fmt.Errorf("cart: redis backend selected but Redis is not configured")
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+		defer cancel()
+		if err := redismanager.Redis.Ping(ctx).Err(); err != nil {
+			return fmt.Errorf("cart: redis backend selected but Redis is unreachable: %w", err)
+		}
+		cartStore = newRedisCartRepository(redismanager.Redis.Client, config.Get().Cart.GuestTTL)
+	default:
+		return fmt.Errorf("cart: unknown backend %q", backend)
+	}
+
+	logger.Info("cart: using %s backend", backend)
+	return nil
+}
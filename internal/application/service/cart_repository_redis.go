@@ -0,0 +1,619 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"backend-ecommerce/internal/application/entity"
+	"backend-ecommerce/internal/application/tools"
+)
+
+const (
+	// redisCartPrefix prefixes the hash holding a cart. Besides the cart's own
+	// fields it holds, per item, "item:<id>" with the item's fixed data,
+	// "qty:<id>" and "price:<id>", and "line:<product>:<variant>" pointing a
+	// line at its item.
+	redisCartPrefix = "cart:"
+	// redisUserCartPrefix and redisGuestCartPrefix map shoppers to their cart
+	redisUserCartPrefix  = "cart-user:"
+	redisGuestCartPrefix = "cart-guest:"
+	// redisCartActivity is a sorted set of users' carts scored by their last
+	// change, in Unix milliseconds
+	redisCartActivity = "cart-activity"
+)
+
+// errRedisCartGone is returned when a cart disappears while it is changed,
+// e.g. when a guest cart expires
+var errRedisCartGone = errors.New("cart no longer exists")
+
+// redisAddItemScript adds to the quantity of a cart line, creating the line
+// if needed, unless the result would go over the limit.
+// KEYS: cart hash. ARGV: line field, new item ID, item data, quantity,
+// limit, price. Returns the new quantity, -1 over the limit or -2 if the
+// cart does not exist.
+var redisAddItemScript = redis.NewScript(`
+if redis.call('EXISTS', KEYS[1]) == 0 then
+	return -2
+end
+local id = redis.call('HGET', KEYS[1], ARGV[1]) or ARGV[2]
+local quantity = tonumber(redis.call('HGET', KEYS[1], 'qty:' .. id) or '0') + tonumber(ARGV[4])
+if quantity > tonumber(ARGV[5]) then
+	return -1
+end
+redis.call('HSETNX', KEYS[1], 'item:' .. id, ARGV[3])
+redis.call('HSET', KEYS[1], ARGV[1], id, 'line-of:' .. id, ARGV[1], 'qty:' .. id, quantity, 'price:' .. id, ARGV[6])
+return quantity
+`)
+
+// redisUpdateItemScript sets the quantity and, unless empty, the price of an
+// existing item. KEYS: cart hash. ARGV: item ID, quantity, price.
+var redisUpdateItemScript = redis.NewScript(`
+if redis.call('HEXISTS', KEYS[1], 'item:' .. ARGV[1]) == 0 then
+	return 0
+end
+redis.call('HSET', KEYS[1], 'qty:' .. ARGV[1], ARGV[2])
+if ARGV[3] ~= '' then
+	redis.call('HSET', KEYS[1], 'price:' .. ARGV[1], ARGV[3])
+end
+return 1
+`)
+
+// redisRemoveItemsScript removes items and their lines. KEYS: cart hash.
+// ARGV: item IDs.
+var redisRemoveItemsScript = redis.NewScript(`
+for _, id in ipairs(ARGV) do
+	local line = redis.call('HGET', KEYS[1], 'line-of:' .. id)
+	if line and redis.call('HGET', KEYS[1], line) == id then
+		redis.call('HDEL', KEYS[1], line)
+	end
+	redis.call('HDEL', KEYS[1], 'item:' .. id, 'qty:' .. id, 'price:' .. id, 'line-of:' .. id)
+end
+return 1
+`)
+
+// redisMarkInSQLScript records that a cart has a copy in SQL, unless the
+// cart is gone. KEYS: cart hash.
+var redisMarkInSQLScript = redis.NewScript(`
+if redis.call('EXISTS', KEYS[1]) == 0 then
+	return 0
+end
+redis.call('HSET', KEYS[1], 'in_sql', '1')
+return 1
+`)
+
+// redisCartItem is the fixed data of a cart item stored in a cart hash
+type redisCartItem struct {
+	ProductID string  `json:"product_id"`
+	VariantID *string `json:"variant_id,omitempty"`
+	CreatedAt int64   `json:"created_at"`
+}
+
+// redisCartRepository keeps carts in Redis, one hash per cart, so cart
+// writes do not reach MySQL during traffic peaks. Guest carts expire after
+// guestTTL without changes. Carts reach SQL when checkout starts and, for
+// users, once idle, so abandoned cart reminders can find them; a cart
+// missing from Redis is read back from its SQL copy. Every change to a cart
+// with a SQL copy is applied to the copy as well, so it never brings back
+// stale lines or items and carts that are gone.
+type redisCartRepository struct {
+	client   *redis.Client
+	guestTTL time.Duration
+}
+
+func newRedisCartRepository(client *redis.Client, guestTTL time.Duration) *redisCartRepository {
+	return &redisCartRepository{client: client, guestTTL: guestTTL}
+}
+
+func (r *redisCartRepository) Find(db *gorm.DB, userID, guestToken string) (*entity.Cart, error) {
+	ctx := context.Background()
+
+	cartID, err := r.client.Get(ctx, r.ownerKey(userID, guestToken)).Result()
+	if err != nil && err != redis.Nil {
+		return nil, err
+	}
+	if err == nil {
+		cart, err := r.get(ctx, cartID)
+		if err != nil || cart != nil {
+			return cart, err
+		}
+	}
+
+	// Not in Redis: fall back to a cart kept in SQL, e.g. from before Redis
+	// was used or persisted at checkout
+	cart, err := (&sqlCartRepository{}).Find(db, userID, guestToken)
+	if err != nil || cart == nil {
+		return nil, err
+	}
+	if err := r.store(ctx, cart, true); err != nil {
+		return nil, err
+	}
+	return cart, nil
+}
+
+func (r *redisCartRepository) Create(db *gorm.DB, userID, guestToken string) (*entity.Cart, error) {
+	now := redisCartNow()
+	cart := entity.Cart{ID: tools.NewUuid(), CreatedAt: now, UpdatedAt: now}
+	if userID != "" {
+		cart.UserID = &userID
+	} else {
+		cart.GuestToken = guestToken
+	}
+	if err := r.store(context.Background(), &cart, false); err != nil {
+		return nil, err
+	}
+	return &cart, nil
+}
+
+func (r *redisCartRepository) Get(db *gorm.DB, cartID string) (*entity.Cart, error) {
+	cart, err := r.get(context.Background(), cartID)
+	if err != nil || cart != nil {
+		return cart, err
+	}
+	return (&sqlCartRepository{}).Get(db, cartID)
+}
+
+func (r *redisCartRepository) FindItem(db *gorm.DB, userID, guestToken, itemID string) (*entity.CartItem, error) {
+	cart, err := r.Find(db, userID, guestToken)
+	if err != nil || cart == nil {
+		return nil, err
+	}
+	for _, item := range cart.Items {
+		if item.ID == itemID {
+			return &item, nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *redisCartRepository) AddItem(db *gorm.DB, cartID, productID string, variantID *string, quantity int, price float64, limit int) (int, error) {
+	ctx := context.Background()
+
+	data, err := json.Marshal(redisCartItem{ProductID: productID, VariantID: variantID, CreatedAt: redisCartNow().UnixMilli()})
+	if err != nil {
+		return 0, err
+	}
+	result, err := redisAddItemScript.Run(ctx, r.client, []string{redisCartPrefix + cartID},
+		redisCartLine(productID, variantID), tools.NewUuid(), string(data), quantity, limit, formatRedisPrice(price)).Int()
+	if err != nil {
+		return 0, err
+	}
+	switch result {
+	case -1:
+		return 0, errCartLineLimit
+	case -2:
+		return 0, errRedisCartGone
+	}
+	if r.inSQL(ctx, cartID) {
+		itemID, err := r.client.HGet(ctx, redisCartPrefix+cartID, redisCartLine(productID, variantID)).Result()
+		if err != nil {
+			return 0, err
+		}
+		if err := r.saveItemCopy(ctx, db, cartID, itemID); err != nil {
+			return 0, err
+		}
+	}
+	return result, r.touch(ctx, cartID)
+}
+
+func (r *redisCartRepository) UpdateItem(db *gorm.DB, cartID, itemID string, quantity int, price *float64) error {
+	ctx := context.Background()
+
+	priceArg := ""
+	if price != nil {
+		priceArg = formatRedisPrice(*price)
+	}
+	if err := redisUpdateItemScript.Run(ctx, r.client, []string{redisCartPrefix + cartID}, itemID, quantity, priceArg).Err(); err != nil {
+		return err
+	}
+	if r.inSQL(ctx, cartID) {
+		if err := r.saveItemCopy(ctx, db, cartID, itemID); err != nil {
+			return err
+		}
+	}
+	return r.touch(ctx, cartID)
+}
+
+func (r *redisCartRepository) RemoveItems(db *gorm.DB, cartID string, itemIDs ...string) error {
+	ctx := context.Background()
+
+	if len(itemIDs) == 0 {
+		cart, err := r.get(ctx, cartID)
+		if err != nil || cart == nil {
+			return err
+		}
+		for _, item := range cart.Items {
+			itemIDs = append(itemIDs, item.ID)
+		}
+		if len(itemIDs) == 0 {
+			return nil
+		}
+	}
+
+	args := make([]interface{}, len(itemIDs))
+	for i, itemID := range itemIDs {
+		args[i] = itemID
+	}
+	if err := redisRemoveItemsScript.Run(ctx, r.client, []string{redisCartPrefix + cartID}, args...).Err(); err != nil {
+		return err
+	}
+	if r.inSQL(ctx, cartID) {
+		if err := db.Where("cart_id = ? AND id IN ?", cartID, itemIDs).Delete(&entity.CartItem{}).Error; err != nil {
+			return err
+		}
+	}
+	return r.touch(ctx, cartID)
+}
+
+func (r *redisCartRepository) Assign(db *gorm.DB, cartID, userID string) error {
+	ctx := context.Background()
+	key := redisCartPrefix + cartID
+
+	guestToken, err := r.client.HGet(ctx, key, "guest_token").Result()
+	if err == redis.Nil {
+		return errRedisCartGone
+	}
+	if err != nil {
+		return err
+	}
+	inSQL := r.inSQL(ctx, cartID)
+
+	now := redisCartNow()
+	if _, err := r.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.HSet(ctx, key, "user_id", userID, "guest_token", "", "updated_at", now.UnixMilli())
+		pipe.Persist(ctx, key)
+		if guestToken != "" {
+			pipe.Del(ctx, redisGuestCartPrefix+guestToken)
+		}
+		pipe.Set(ctx, redisUserCartPrefix+userID, cartID, 0)
+		pipe.ZAdd(ctx, redisCartActivity, redis.Z{Score: float64(now.UnixMilli()), Member: cartID})
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	if !inSQL {
+		return nil
+	}
+	return db.Model(&entity.Cart{}).Where("id = ?", cartID).
+		UpdateColumns(map[string]interface{}{"user_id": userID, "guest_token": "", "updated_at": now}).Error
+}
+
+func (r *redisCartRepository) Delete(db *gorm.DB, cartID string) error {
+	ctx := context.Background()
+
+	cart, err := r.get(ctx, cartID)
+	if err != nil {
+		return err
+	}
+	// A cart missing from Redis may still have a copy in SQL
+	inSQL := cart == nil || r.inSQL(ctx, cartID)
+	if cart != nil {
+		ownerKey := r.ownerKey(stringValue(cart.UserID), cart.GuestToken)
+		if _, err := r.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+			pipe.Del(ctx, redisCartPrefix+cartID)
+			pipe.ZRem(ctx, redisCartActivity, cartID)
+			return nil
+		}); err != nil {
+			return err
+		}
+		if owned, err := r.client.Get(ctx, ownerKey).Result(); err == nil && owned == cartID {
+			if err := r.client.Del(ctx, ownerKey).Err(); err != nil {
+				return err
+			}
+		}
+	}
+
+	if !inSQL {
+		return nil
+	}
+	return (&sqlCartRepository{}).Delete(db, cartID)
+}
+
+// CheckoutCart locks the SQL copy of the cart before reading the cart from
+// Redis, so a checkout waiting for the lock sees the items the previous one
+// left
+func (r *redisCartRepository) CheckoutCart(db *gorm.DB, userID string) (*entity.Cart, error) {
+	ctx := context.Background()
+
+	cart, err := r.Find(db, userID, "")
+	if err != nil || cart == nil {
+		return nil, err
+	}
+
+	var copies []entity.Cart
+	if err := db.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", cart.ID).Find(&copies).Error; err != nil {
+		return nil, err
+	}
+	cart, err = r.get(ctx, cart.ID)
+	if err != nil || cart == nil {
+		return nil, err
+	}
+	if err := r.persist(db, cart, len(copies) > 0); err != nil {
+		return nil, err
+	}
+	return cart, nil
+}
+
+// PersistIdle writes the idle carts whose SQL copy is missing or older, and
+// refreshes the SQL copies last changed between from and to whose cart has
+// changed since, so they no longer look idle
+func (r *redisCartRepository) PersistIdle(db *gorm.DB, from, to time.Time) error {
+	ctx := context.Background()
+
+	cartIDs, err := r.client.ZRangeByScore(ctx, redisCartActivity, &redis.ZRangeBy{
+		Min: strconv.FormatInt(from.UnixMilli(), 10),
+		Max: strconv.FormatInt(to.UnixMilli(), 10),
+	}).Result()
+	if err != nil {
+		return err
+	}
+
+	var stale []string
+	if err := db.Model(&entity.Cart{}).
+		Where("user_id IS NOT NULL AND updated_at >= ? AND updated_at <= ?", from, to).
+		Where("EXISTS (?)", db.Model(&entity.CartItem{}).Select("1").Where("cartItems.cart_id = carts.id")).
+		Pluck("id", &stale).Error; err != nil {
+		return err
+	}
+	cartIDs = append(cartIDs, stale...)
+	if len(cartIDs) == 0 {
+		return nil
+	}
+
+	var copies []entity.Cart
+	if err := db.Select("id", "updated_at").Where("id IN ?", cartIDs).Find(&copies).Error; err != nil {
+		return err
+	}
+	persisted := make(map[string]int64, len(copies))
+	for _, row := range copies {
+		persisted[row.ID] = row.UpdatedAt.UnixMilli()
+	}
+
+	seen := make(map[string]bool, len(cartIDs))
+	for _, cartID := range cartIDs {
+		if seen[cartID] {
+			continue
+		}
+		seen[cartID] = true
+
+		// A cart missing from Redis keeps its SQL copy, which it is read back from
+		cart, err := r.get(ctx, cartID)
+		if err != nil {
+			return err
+		}
+		if cart == nil || cart.UserID == nil {
+			continue
+		}
+		updatedAt, exists := persisted[cartID]
+		if exists && updatedAt == cart.UpdatedAt.UnixMilli() {
+			continue
+		}
+		if err := db.Transaction(func(tx *gorm.DB) error {
+			return r.persist(tx, cart, exists)
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// persist replaces the SQL copy of a cart
+func (r *redisCartRepository) persist(db *gorm.DB, cart *entity.Cart, exists bool) error {
+	// The flag is set first: a copy the flag does not know of could outlive
+	// the items it holds
+	if err := redisMarkInSQLScript.Run(context.Background(), r.client, []string{redisCartPrefix + cart.ID}).Err(); err != nil {
+		return err
+	}
+	if err := db.Where("cart_id = ?", cart.ID).Delete(&entity.CartItem{}).Error; err != nil {
+		return err
+	}
+	if exists {
+		if err := db.Model(&entity.Cart{}).Where("id = ?", cart.ID).UpdateColumns(map[string]interface{}{
+			"user_id":     cart.UserID,
+			"guest_token": cart.GuestToken,
+			"updated_at":  cart.UpdatedAt,
+		}).Error; err != nil {
+			return err
+		}
+	} else {
+		row := entity.Cart{ID: cart.ID, UserID: cart.UserID, GuestToken: cart.GuestToken, CreatedAt: cart.CreatedAt, UpdatedAt: cart.UpdatedAt}
+		if err := db.Create(&row).Error; err != nil {
+			return err
+		}
+	}
+	if len(cart.Items) == 0 {
+		return nil
+	}
+	items := make([]entity.CartItem, len(cart.Items))
+	copy(items, cart.Items)
+	return db.Create(&items).Error
+}
+
+// saveItemCopy writes an item as it is in Redis to the SQL copy of its cart
+func (r *redisCartRepository) saveItemCopy(ctx context.Context, db *gorm.DB, cartID, itemID string) error {
+	cart, err := r.get(ctx, cartID)
+	if err != nil || cart == nil {
+		return err
+	}
+	for _, item := range cart.Items {
+		if item.ID == itemID {
+			return db.Save(&item).Error
+		}
+	}
+	return nil
+}
+
+// get reads a cart from its hash, or returns nil if it is not in Redis
+func (r *redisCartRepository) get(ctx context.Context, cartID string) (*entity.Cart, error) {
+	fields, err := r.client.HGetAll(ctx, redisCartPrefix+cartID).Result()
+	if err != nil {
+		return nil, err
+	}
+	if len(fields) == 0 {
+		return nil, nil
+	}
+
+	cart := entity.Cart{
+		ID:         cartID,
+		GuestToken: fields["guest_token"],
+		CreatedAt:  parseRedisMillis(fields["created_at"]),
+		UpdatedAt:  parseRedisMillis(fields["updated_at"]),
+		Items:      []entity.CartItem{},
+	}
+	if userID := fields["user_id"]; userID != "" {
+		cart.UserID = &userID
+	}
+	for field, value := range fields {
+		itemID, ok := strings.CutPrefix(field, "item:")
+		if !ok {
+			continue
+		}
+		var data redisCartItem
+		if err := json.Unmarshal([]byte(value), &data); err != nil {
+			return nil, err
+		}
+		quantity, _ := strconv.Atoi(fields["qty:"+itemID])
+		price, _ := strconv.ParseFloat(fields["price:"+itemID], 64)
+		cart.Items = append(cart.Items, entity.CartItem{
+			ID:         itemID,
+			CartID:     cartID,
+			ProductID:  data.ProductID,
+			VariantID:  data.VariantID,
+			Quantity:   quantity,
+			PriceAtAdd: price,
+			CreatedAt:  time.UnixMilli(data.CreatedAt).UTC(),
+			UpdatedAt:  cart.UpdatedAt,
+		})
+	}
+	sort.Slice(cart.Items, func(i, j int) bool {
+		a, b := cart.Items[i], cart.Items[j]
+		if !a.CreatedAt.Equal(b.CreatedAt) {
+			return a.CreatedAt.Before(b.CreatedAt)
+		}
+		return a.ID < b.ID
+	})
+	return &cart, nil
+}
+
+// store writes a whole cart to Redis and points its owner at it. Timestamps
+// are kept to the millisecond, as in SQL, so the copies can be compared.
+func (r *redisCartRepository) store(ctx context.Context, cart *entity.Cart, loaded bool) error {
+	key := redisCartPrefix + cart.ID
+	values := []interface{}{
+		"in_sql", "",
+		"user_id", stringValue(cart.UserID),
+		"guest_token", cart.GuestToken,
+		"created_at", cart.CreatedAt.UnixMilli(),
+		"updated_at", cart.UpdatedAt.UnixMilli(),
+	}
+	if loaded {
+		values[1] = "1"
+	}
+	for _, item := range cart.Items {
+		data, err := json.Marshal(redisCartItem{ProductID: item.ProductID, VariantID: item.VariantID, CreatedAt: item.CreatedAt.UnixMilli()})
+		if err != nil {
+			return err
+		}
+		line := redisCartLine(item.ProductID, item.VariantID)
+		values = append(values,
+			"item:"+item.ID, string(data),
+			"qty:"+item.ID, item.Quantity,
+			"price:"+item.ID, formatRedisPrice(item.PriceAtAdd),
+			"line-of:"+item.ID, line,
+			line, item.ID,
+		)
+	}
+
+	_, err := r.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		if loaded {
+			pipe.Del(ctx, key)
+		}
+		pipe.HSet(ctx, key, values...)
+		if cart.UserID != nil {
+			pipe.Set(ctx, redisUserCartPrefix+*cart.UserID, cart.ID, 0)
+			pipe.ZAdd(ctx, redisCartActivity, redis.Z{Score: float64(cart.UpdatedAt.UnixMilli()), Member: cart.ID})
+		} else {
+			pipe.Expire(ctx, key, r.guestTTL)
+			pipe.Set(ctx, redisGuestCartPrefix+cart.GuestToken, cart.ID, r.guestTTL)
+		}
+		return nil
+	})
+	return err
+}
+
+// touch marks a cart as changed. Guest carts start a new time to live.
+func (r *redisCartRepository) touch(ctx context.Context, cartID string) error {
+	key := redisCartPrefix + cartID
+	owner, err := r.client.HMGet(ctx, key, "user_id", "guest_token").Result()
+	if err != nil {
+		return err
+	}
+	if owner[0] == nil && owner[1] == nil {
+		return errRedisCartGone
+	}
+	userID, _ := owner[0].(string)
+	guestToken, _ := owner[1].(string)
+
+	now := redisCartNow().UnixMilli()
+	_, err = r.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.HSet(ctx, key, "updated_at", now)
+		if userID != "" {
+			pipe.ZAdd(ctx, redisCartActivity, redis.Z{Score: float64(now), Member: cartID})
+		} else if guestToken != "" {
+			pipe.Expire(ctx, key, r.guestTTL)
+			pipe.Expire(ctx, redisGuestCartPrefix+guestToken, r.guestTTL)
+		}
+		return nil
+	})
+	return err
+}
+
+// inSQL reports whether a cart may have a copy in SQL
+func (r *redisCartRepository) inSQL(ctx context.Context, cartID string) bool {
+	flag, err := r.client.HGet(ctx, redisCartPrefix+cartID, "in_sql").Result()
+	return err != nil || flag == "1"
+}
+
+// ownerKey returns the key pointing a signed-in user or, when userID is
+// empty, a guest at their cart
+func (r *redisCartRepository) ownerKey(userID, guestToken string) string {
+	if userID != "" {
+		return redisUserCartPrefix + userID
+	}
+	return redisGuestCartPrefix + guestToken
+}
+
+// redisCartLine returns the hash field identifying the line of a product or variant
+func redisCartLine(productID string, variantID *string) string {
+	return "line:" + productID + ":" + stringValue(variantID)
+}
+
+// redisCartNow returns the current time to the millisecond
+func redisCartNow() time.Time {
+	return time.Now().UTC().Truncate(time.Millisecond)
+}
+
+func stringValue(value *string) string {
+	if value == nil {
+		return ""
+	}
+	return *value
+}
+
+func parseRedisMillis(value string) time.Time {
+	millis, _ := strconv.ParseInt(value, 10, 64)
+	return time.UnixMilli(millis).UTC()
+}
+
+func formatRedisPrice(price float64) string {
+	return strconv.FormatFloat(price, 'f', -1, 64)
+}
@@ -0,0 +1,178 @@
+package service
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"backend-ecommerce/internal/application/entity"
+	"backend-ecommerce/internal/application/tools"
+)
+
+// sqlCartRepository keeps carts in the carts and cartItems tables
+type sqlCartRepository struct {
+}
+
+func (r *sqlCartRepository) Find(db *gorm.DB, userID, guestToken string) (*entity.Cart, error) {
+	var cart entity.Cart
+	err := db.Scopes(shopperScope(userID, guestToken)).Preload("Items", orderCartItems).
+		Order("updated_at DESC").First(&cart).Error
+	if err == gorm.ErrRecordNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &cart, nil
+}
+
+func (r *sqlCartRepository) Create(db *gorm.DB, userID, guestToken string) (*entity.Cart, error) {
+	cart := entity.Cart{ID: tools.NewUuid()}
+	if userID != "" {
+		cart.UserID = &userID
+	} else {
+		cart.GuestToken = guestToken
+	}
+	if err := db.Create(&cart).Error; err != nil {
+		return nil, err
+	}
+	return &cart, nil
+}
+
+func (r *sqlCartRepository) Get(db *gorm.DB, cartID string) (*entity.Cart, error) {
+	var cart entity.Cart
+	err := db.Preload("Items", orderCartItems).Where("id = ?", cartID).First(&cart).Error
+	if err == gorm.ErrRecordNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &cart, nil
+}
+
+func (r *sqlCartRepository) FindItem(db *gorm.DB, userID, guestToken, itemID string) (*entity.CartItem, error) {
+	var item entity.CartItem
+	err := db.Joins("JOIN carts ON carts.id = cartItems.cart_id").
+		Where("cartItems.id = ?", itemID).
+		Scopes(shopperScope(userID, guestToken)).
+		First(&item).Error
+	if err == gorm.ErrRecordNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &item, nil
+}
+
+// AddItem locks the cart row, so adds to the same cart in concurrent
+// transactions are serialised
+func (r *sqlCartRepository) AddItem(db *gorm.DB, cartID, productID string, variantID *string, quantity int, price float64, limit int) (int, error) {
+	if err := db.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", cartID).First(&entity.Cart{}).Error; err != nil {
+		return 0, err
+	}
+
+	var items []entity.CartItem
+	if err := db.Where("cart_id = ? AND product_id = ?", cartID, productID).Find(&items).Error; err != nil {
+		return 0, err
+	}
+	var item entity.CartItem
+	for _, existing := range items {
+		if existing.SameLine(productID, variantID) {
+			item = existing
+		}
+	}
+	if item.Quantity+quantity > limit {
+		return 0, errCartLineLimit
+	}
+
+	if item.ID != "" {
+		item.Quantity += quantity
+		if err := db.Model(&item).Updates(map[string]interface{}{
+			"quantity":     item.Quantity,
+			"price_at_add": price,
+		}).Error; err != nil {
+			return 0, err
+		}
+	} else {
+		item = entity.CartItem{
+			ID:         tools.NewUuid(),
+			CartID:     cartID,
+			ProductID:  productID,
+			VariantID:  variantID,
+			Quantity:   quantity,
+			PriceAtAdd: price,
+		}
+		if err := db.Create(&item).Error; err != nil {
+			return 0, err
+		}
+	}
+	return item.Quantity, r.touch(db, cartID)
+}
+
+func (r *sqlCartRepository) UpdateItem(db *gorm.DB, cartID, itemID string, quantity int, price *float64) error {
+	updates := map[string]interface{}{"quantity": quantity}
+	if price != nil {
+		updates["price_at_add"] = *price
+	}
+	if err := db.Model(&entity.CartItem{}).Where("id = ? AND cart_id = ?", itemID, cartID).Updates(updates).Error; err != nil {
+		return err
+	}
+	return r.touch(db, cartID)
+}
+
+func (r *sqlCartRepository) RemoveItems(db *gorm.DB, cartID string, itemIDs ...string) error {
+	query := db.Where("cart_id = ?", cartID)
+	if len(itemIDs) > 0 {
+		query = query.Where("id IN ?", itemIDs)
+	}
+	if err := query.Delete(&entity.CartItem{}).Error; err != nil {
+		return err
+	}
+	return r.touch(db, cartID)
+}
+
+func (r *sqlCartRepository) Assign(db *gorm.DB, cartID, userID string) error {
+	return db.Model(&entity.Cart{}).Where("id = ?", cartID).
+		Updates(map[string]interface{}{"user_id": userID, "guest_token": ""}).Error
+}
+
+func (r *sqlCartRepository) Delete(db *gorm.DB, cartID string) error {
+	if err := db.Where("cart_id = ?", cartID).Delete(&entity.CartItem{}).Error; err != nil {
+		return err
+	}
+	return db.Where("id = ?", cartID).Delete(&entity.Cart{}).Error
+}
+
+func (r *sqlCartRepository) CheckoutCart(db *gorm.DB, userID string) (*entity.Cart, error) {
+	var cart entity.Cart
+	err := db.Clauses(clause.Locking{Strength: "UPDATE"}).Scopes(shopperScope(userID, "")).
+		Order("updated_at DESC").First(&cart).Error
+	if err == gorm.ErrRecordNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := db.Where("cart_id = ?", cart.ID).Scopes(orderCartItems).Find(&cart.Items).Error; err != nil {
+		return nil, err
+	}
+	return &cart, nil
+}
+
+// PersistIdle has nothing to do: carts are always in SQL
+func (r *sqlCartRepository) PersistIdle(db *gorm.DB, from, to time.Time) error {
+	return nil
+}
+
+// touch marks a cart as changed
+func (r *sqlCartRepository) touch(db *gorm.DB, cartID string) error {
+	return db.Model(&entity.Cart{}).Where("id = ?", cartID).Update("updated_at", time.Now().UTC()).Error
+}
+
+// orderCartItems sorts cart items in the order they were added
+func orderCartItems(db *gorm.DB) *gorm.DB {
+	return db.Order("created_at ASC").Order("id ASC")
+}
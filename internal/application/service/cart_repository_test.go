@@ -0,0 +1,347 @@
+package service
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/glebarez/sqlite"
+	"github.com/redis/go-redis/v9"
+	"gorm.io/gorm"
+	gormlogger "gorm.io/gorm/logger"
+
+	"backend-ecommerce/internal/application/entity"
+)
+
+// forEachCartRepository runs test against every cart repository, each on
+// fresh stores: an in-memory SQLite database standing in for MySQL and, for
+// the Redis repository, an in-process Redis server. Both repositories must
+// pass the same cases.
+func forEachCartRepository(t *testing.T, test func(t *testing.T, repo cartRepository, db *gorm.DB)) {
+	backends := []struct {
+		name string
+		open func(t *testing.T) cartRepository
+	}{
+		{"sql", func(t *testing.T) cartRepository {
+			return &sqlCartRepository{}
+		}},
+		{"redis", func(t *testing.T) cartRepository {
+			server := miniredis.RunT(t)
+			client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+			t.Cleanup(func() { client.Close() })
+			return newRedisCartRepository(client, time.Hour)
+		}},
+	}
+	for _, backend := range backends {
+		t.Run(backend.name, func(t *testing.T) {
+			test(t, backend.open(t), newCartTestDB(t))
+		})
+	}
+}
+
+// newCartTestDB opens a test database with the cart tables
+func newCartTestDB(t *testing.T) *gorm.DB {
+	return newTestDB(t,
+		`CREATE TABLE carts (
+			id varchar(36) PRIMARY KEY,
+			user_id varchar(36),
+			guest_token varchar(255),
+			created_at datetime,
+			updated_at datetime
+		)`,
+		`CREATE TABLE cartItems (
+			id varchar(36) PRIMARY KEY,
+			cart_id varchar(36) NOT NULL,
+			product_id varchar(36) NOT NULL,
+			variant_id varchar(36),
+			quantity int NOT NULL DEFAULT 1,
+			price_at_add decimal(12,2) NOT NULL,
+			created_at datetime,
+			updated_at datetime
+		)`,
+	)
+}
+
+// newTestDB opens an in-memory database with the given tables. A single
+// connection is kept so every query sees the same database. Tables are
+// created by hand, as the entities' MySQL column types do not migrate to
+// SQLite.
+func newTestDB(t *testing.T, tables ...string) *gorm.DB {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{
+		Logger: gormlogger.Default.LogMode(gormlogger.Silent),
+	})
+	if err != nil {
+		t.Fatalf("open database: %v", err)
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatalf("open database: %v", err)
+	}
+	sqlDB.SetMaxOpenConns(1)
+	t.Cleanup(func() { sqlDB.Close() })
+
+	for _, ddl := range tables {
+		if err := db.Exec(ddl).Error; err != nil {
+			t.Fatalf("create tables: %v", err)
+		}
+	}
+	return db
+}
+
+// addCartItem adds a line and fails the test on error. It waits a moment
+// first so lines added one after the other have distinct creation times.
+func addCartItem(t *testing.T, repo cartRepository, db *gorm.DB, cartID, productID string, variantID *string, quantity int, price float64) {
+	t.Helper()
+	time.Sleep(2 * time.Millisecond)
+	if _, err := repo.AddItem(db, cartID, productID, variantID, quantity, price, 99); err != nil {
+		t.Fatalf("add %s: %v", productID, err)
+	}
+}
+
+// getCart loads a cart that must exist
+func getCart(t *testing.T, repo cartRepository, db *gorm.DB, cartID string) *entity.Cart {
+	t.Helper()
+	cart, err := repo.Get(db, cartID)
+	if err != nil {
+		t.Fatalf("get cart: %v", err)
+	}
+	if cart == nil {
+		t.Fatalf("cart %s not found", cartID)
+	}
+	return cart
+}
+
+func TestCartRepositoryAddItem(t *testing.T) {
+	forEachCartRepository(t, func(t *testing.T, repo cartRepository, db *gorm.DB) {
+		cart, err := repo.Create(db, "user-1", "")
+		if err != nil {
+			t.Fatalf("create cart: %v", err)
+		}
+
+		quantity, err := repo.AddItem(db, cart.ID, "product-1", nil, 2, 10, 5)
+		if err != nil || quantity != 2 {
+			t.Fatalf("first add = %d, %v; want 2", quantity, err)
+		}
+		quantity, err = repo.AddItem(db, cart.ID, "product-1", nil, 2, 12, 5)
+		if err != nil || quantity != 4 {
+			t.Fatalf("second add = %d, %v; want 4", quantity, err)
+		}
+		variantID := "variant-1"
+		addCartItem(t, repo, db, cart.ID, "product-1", &variantID, 1, 15)
+
+		if _, err := repo.AddItem(db, cart.ID, "product-1", nil, 2, 12, 5); !errors.Is(err, errCartLineLimit) {
+			t.Fatalf("add over the limit: err = %v, want errCartLineLimit", err)
+		}
+
+		items := getCart(t, repo, db, cart.ID).Items
+		if len(items) != 2 {
+			t.Fatalf("got %d lines, want 2", len(items))
+		}
+		if items[0].VariantID != nil || items[0].Quantity != 4 || items[0].PriceAtAdd != 12 {
+			t.Errorf("product line = %+v, want quantity 4 at the latest price 12", items[0])
+		}
+		if items[1].VariantID == nil || *items[1].VariantID != variantID || items[1].Quantity != 1 {
+			t.Errorf("variant line = %+v, want variant-1 with quantity 1", items[1])
+		}
+	})
+}
+
+func TestCartRepositoryUpdateItem(t *testing.T) {
+	forEachCartRepository(t, func(t *testing.T, repo cartRepository, db *gorm.DB) {
+		cart, err := repo.Create(db, "user-1", "")
+		if err != nil {
+			t.Fatalf("create cart: %v", err)
+		}
+		addCartItem(t, repo, db, cart.ID, "product-1", nil, 1, 10)
+		itemID := getCart(t, repo, db, cart.ID).Items[0].ID
+
+		if err := repo.UpdateItem(db, cart.ID, itemID, 3, nil); err != nil {
+			t.Fatalf("update quantity: %v", err)
+		}
+		item := getCart(t, repo, db, cart.ID).Items[0]
+		if item.Quantity != 3 || item.PriceAtAdd != 10 {
+			t.Errorf("after quantity update = %+v, want quantity 3 at price 10", item)
+		}
+
+		price := 8.5
+		if err := repo.UpdateItem(db, cart.ID, itemID, 1, &price); err != nil {
+			t.Fatalf("update quantity and price: %v", err)
+		}
+		item = getCart(t, repo, db, cart.ID).Items[0]
+		if item.Quantity != 1 || item.PriceAtAdd != 8.5 {
+			t.Errorf("after price update = %+v, want quantity 1 at price 8.5", item)
+		}
+	})
+}
+
+func TestCartRepositoryRemoveItems(t *testing.T) {
+	forEachCartRepository(t, func(t *testing.T, repo cartRepository, db *gorm.DB) {
+		cart, err := repo.Create(db, "", "guest-1")
+		if err != nil {
+			t.Fatalf("create cart: %v", err)
+		}
+		for _, productID := range []string{"product-1", "product-2", "product-3"} {
+			addCartItem(t, repo, db, cart.ID, productID, nil, 1, 10)
+		}
+		items := getCart(t, repo, db, cart.ID).Items
+
+		if err := repo.RemoveItems(db, cart.ID, items[1].ID); err != nil {
+			t.Fatalf("remove one item: %v", err)
+		}
+		left := getCart(t, repo, db, cart.ID).Items
+		if len(left) != 2 || left[0].ProductID != "product-1" || left[1].ProductID != "product-3" {
+			t.Fatalf("after removing product-2 got %+v", left)
+		}
+
+		// The removed line can be added again as a new line
+		addCartItem(t, repo, db, cart.ID, "product-2", nil, 2, 10)
+		if left := getCart(t, repo, db, cart.ID).Items; len(left) != 3 || left[2].Quantity != 2 {
+			t.Fatalf("after adding product-2 again got %+v", left)
+		}
+
+		if err := repo.RemoveItems(db, cart.ID); err != nil {
+			t.Fatalf("remove every item: %v", err)
+		}
+		if left := getCart(t, repo, db, cart.ID).Items; len(left) != 0 {
+			t.Fatalf("after clearing got %d items, want none", len(left))
+		}
+	})
+}
+
+func TestCartRepositoryCheckout(t *testing.T) {
+	forEachCartRepository(t, func(t *testing.T, repo cartRepository, db *gorm.DB) {
+		cart, err := repo.Create(db, "user-1", "")
+		if err != nil {
+			t.Fatalf("create cart: %v", err)
+		}
+		addCartItem(t, repo, db, cart.ID, "product-1", nil, 1, 10)
+		addCartItem(t, repo, db, cart.ID, "product-2", nil, 2, 20)
+
+		// Checkout reads the cart with its SQL copy locked and removes the
+		// lines bought in the same transaction
+		err = db.Transaction(func(tx *gorm.DB) error {
+			checkout, err := repo.CheckoutCart(tx, "user-1")
+			if err != nil {
+				return err
+			}
+			if checkout == nil || checkout.ID != cart.ID || len(checkout.Items) != 2 {
+				t.Fatalf("checkout cart = %+v, want cart %s with 2 items", checkout, cart.ID)
+			}
+			return repo.RemoveItems(tx, checkout.ID, checkout.Items[0].ID)
+		})
+		if err != nil {
+			t.Fatalf("checkout: %v", err)
+		}
+
+		found, err := repo.Find(db, "user-1", "")
+		if err != nil || found == nil {
+			t.Fatalf("find after checkout = %v, %v", found, err)
+		}
+		if len(found.Items) != 1 || found.Items[0].ProductID != "product-2" {
+			t.Errorf("after checkout the cart holds %+v, want only product-2", found.Items)
+		}
+
+		// The SQL copy written at checkout does not bring back the items bought
+		var copied []entity.CartItem
+		if err := db.Where("cart_id = ?", cart.ID).Find(&copied).Error; err != nil {
+			t.Fatalf("read SQL copy: %v", err)
+		}
+		if len(copied) != 1 || copied[0].ProductID != "product-2" {
+			t.Errorf("SQL copy holds %+v, want only product-2", copied)
+		}
+
+		none, err := repo.CheckoutCart(db, "user-2")
+		if err != nil || none != nil {
+			t.Errorf("checkout without a cart = %v, %v; want nil", none, err)
+		}
+	})
+}
+
+func TestCartRepositorySQLCopyFollowsChanges(t *testing.T) {
+	forEachCartRepository(t, func(t *testing.T, repo cartRepository, db *gorm.DB) {
+		cart, err := repo.Create(db, "user-1", "")
+		if err != nil {
+			t.Fatalf("create cart: %v", err)
+		}
+		addCartItem(t, repo, db, cart.ID, "product-1", nil, 1, 10)
+		if _, err := repo.CheckoutCart(db, "user-1"); err != nil {
+			t.Fatalf("checkout: %v", err)
+		}
+
+		// Changes after the SQL copy was written reach it too
+		itemID := getCart(t, repo, db, cart.ID).Items[0].ID
+		price := 9.5
+		if err := repo.UpdateItem(db, cart.ID, itemID, 3, &price); err != nil {
+			t.Fatalf("update item: %v", err)
+		}
+		addCartItem(t, repo, db, cart.ID, "product-1", nil, 1, 9.5)
+		addCartItem(t, repo, db, cart.ID, "product-2", nil, 2, 20)
+
+		var copied []entity.CartItem
+		if err := db.Where("cart_id = ?", cart.ID).Order("created_at").Find(&copied).Error; err != nil {
+			t.Fatalf("read SQL copy: %v", err)
+		}
+		if len(copied) != 2 {
+			t.Fatalf("SQL copy holds %+v, want 2 lines", copied)
+		}
+		if copied[0].ID != itemID || copied[0].Quantity != 4 || copied[0].PriceAtAdd != 9.5 {
+			t.Errorf("SQL copy of product-1 = %+v, want quantity 4 at price 9.5", copied[0])
+		}
+		if copied[1].ProductID != "product-2" || copied[1].Quantity != 2 {
+			t.Errorf("SQL copy of product-2 = %+v, want quantity 2", copied[1])
+		}
+	})
+}
+
+func TestCartRepositoryAssign(t *testing.T) {
+	forEachCartRepository(t, func(t *testing.T, repo cartRepository, db *gorm.DB) {
+		cart, err := repo.Create(db, "", "guest-1")
+		if err != nil {
+			t.Fatalf("create cart: %v", err)
+		}
+		addCartItem(t, repo, db, cart.ID, "product-1", nil, 1, 10)
+		itemID := getCart(t, repo, db, cart.ID).Items[0].ID
+
+		if found, err := repo.Find(db, "", "guest-1"); err != nil || found == nil || found.ID != cart.ID {
+			t.Fatalf("find guest cart = %v, %v", found, err)
+		}
+		if item, err := repo.FindItem(db, "user-2", "", itemID); err != nil || item != nil {
+			t.Fatalf("another shopper found the item: %v, %v", item, err)
+		}
+
+		if err := repo.Assign(db, cart.ID, "user-2"); err != nil {
+			t.Fatalf("assign: %v", err)
+		}
+		found, err := repo.Find(db, "user-2", "")
+		if err != nil || found == nil || found.ID != cart.ID || len(found.Items) != 1 {
+			t.Fatalf("find assigned cart = %+v, %v", found, err)
+		}
+		if item, err := repo.FindItem(db, "user-2", "", itemID); err != nil || item == nil {
+			t.Errorf("find item of assigned cart = %v, %v", item, err)
+		}
+		if guest, err := repo.Find(db, "", "guest-1"); err != nil || guest != nil {
+			t.Errorf("guest still finds the cart: %v, %v", guest, err)
+		}
+	})
+}
+
+func TestCartRepositoryDelete(t *testing.T) {
+	forEachCartRepository(t, func(t *testing.T, repo cartRepository, db *gorm.DB) {
+		cart, err := repo.Create(db, "user-1", "")
+		if err != nil {
+			t.Fatalf("create cart: %v", err)
+		}
+		addCartItem(t, repo, db, cart.ID, "product-1", nil, 1, 10)
+
+		if err := repo.Delete(db, cart.ID); err != nil {
+			t.Fatalf("delete: %v", err)
+		}
+		if deleted, err := repo.Get(db, cart.ID); err != nil || deleted != nil {
+			t.Errorf("get deleted cart = %v, %v", deleted, err)
+		}
+		if found, err := repo.Find(db, "user-1", ""); err != nil || found != nil {
+			t.Errorf("find deleted cart = %v, %v", found, err)
+		}
+	})
+}
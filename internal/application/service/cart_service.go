@@ -1,33 +1,736 @@
 package service
 
 import (
+	"errors"
+	"fmt"
+
 	"backend-ecommerce/internal/application/dto"
 	"backend-ecommerce/internal/application/entity"
+	"backend-ecommerce/internal/application/tools"
+	"backend-ecommerce/internal/infrastructure/config"
+	"backend-ecommerce/internal/infrastructure/dbmanager"
+	"backend-ecommerce/internal/infrastructure/logger"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
+// errCartFailure carries a message for the client out of a transaction
+type errCartFailure struct {
+	message string
+}
+
+func (e errCartFailure) Error() string {
+	return e.message
+}
+
 type cartService struct {
 }
 
-func (s *cartService) GetOrCreateCart(userID *string, guestToken string) dto.ResponseDto {
-	return dto.ResponseDto{}
+// GetCart returns the cart of a signed-in user or guest, creating an empty
+// one if needed. Signed-in users can pass one of their addresses to have
+// shipping and tax quoted for it; otherwise they are estimated.
+func (s *cartService) GetCart(userID, guestToken, shippingAddressID string) dto.ResponseDto {
+	if userID == "" && guestToken == "" {
+		return *dto.Fail("Shopper not identified")
+	}
+	db := dbmanager.GetDB()
+
+	var shipTo *entity.Address
+	if shippingAddressID != "" {
+		address, err := findUserAddress(db, userID, shippingAddressID)
+		if err != nil {
+			if err == gorm.ErrRecordNotFound {
+				return *dto.Fail("Shipping address not found")
+			}
+			logger.Error("Error fetching address: %v", err)
+			return *dto.Fail("Error fetching cart")
+		}
+		shipTo = address
+	}
+
+	cart, err := s.findOrCreateCart(db, userID, guestToken)
+	if err != nil {
+		logger.Error("Error fetching cart: %v", err)
+		return *dto.Fail("Error fetching cart")
+	}
+
+	return s.getCart(db, cart.ID, shipTo)
+}
+
+// AddCartItem adds a product, or one of its variants, to the shopper's cart.
+// The product must be on sale and in stock for the resulting quantity.
+func (s *cartService) AddCartItem(userID, guestToken string, req dto.CartItemAddRequest) dto.ResponseDto {
+	if userID == "" && guestToken == "" {
+		return *dto.Fail("Shopper not identified")
+	}
+	db := dbmanager.GetDB()
+
+	var cartID string
+	err := db.Transaction(func(tx *gorm.DB) error {
+		cart, err := s.findOrCreateCart(tx, userID, guestToken)
+		if err != nil {
+			return err
+		}
+		cartID = cart.ID
+		return s.addItem(tx, cart, req.ProductID, req.VariantID, req.Quantity)
+	})
+	if err != nil {
+		var failure errCartFailure
+		if errors.As(err, &failure) {
+			return *dto.Fail(failure.message)
+		}
+		logger.Error("Error adding item to cart: %v", err)
+		return *dto.Fail("Error adding item to cart")
+	}
+
+	return s.getCart(db, cartID, nil)
+}
+
+// UpdateCartItem changes the quantity of a cart item; 0 removes it
+func (s *cartService) UpdateCartItem(userID, guestToken, itemID string, req dto.CartItemUpdateRequest) dto.ResponseDto {
+	if req.Quantity == 0 {
+		return s.RemoveCartItem(userID, guestToken, itemID)
+	}
+	db := dbmanager.GetDB()
+
+	item, response := s.findItem(db, userID, guestToken, itemID)
+	if item == nil {
+		return response
+	}
+
+	product, err := s.offeredProduct(db, item.ProductID)
+	if err != nil {
+		logger.Error("Error fetching product: %v", err)
+		return *dto.Fail("Error updating cart item")
+	}
+	if failure := s.checkQuantity(product, item.VariantID, req.Quantity); failure != "" {
+		return *dto.Fail(failure)
+	}
+
+	if err := cartStore.UpdateItem(db, item.CartID, item.ID, req.Quantity, nil); err != nil {
+		logger.Error("Error updating cart item: %v", err)
+		return *dto.Fail("Error updating cart item")
+	}
+
+	return s.getCart(db, item.CartID, nil)
+}
+
+// RemoveCartItem removes an item from the shopper's cart
+func (s *cartService) RemoveCartItem(userID, guestToken, itemID string) dto.ResponseDto {
+	db := dbmanager.GetDB()
+
+	item, response := s.findItem(db, userID, guestToken, itemID)
+	if item == nil {
+		return response
+	}
+
+	if err := cartStore.RemoveItems(db, item.CartID, item.ID); err != nil {
+		logger.Error("Error removing cart item: %v", err)
+		return *dto.Fail("Error removing cart item")
+	}
+
+	return s.getCart(db, item.CartID, nil)
+}
+
+// ClearCart removes every item from the shopper's cart
+func (s *cartService) ClearCart(userID, guestToken string) dto.ResponseDto {
+	if userID == "" && guestToken == "" {
+		return *dto.Fail("Shopper not identified")
+	}
+	db := dbmanager.GetDB()
+
+	cart, err := cartStore.Find(db, userID, guestToken)
+	if err != nil {
+		logger.Error("Error fetching cart: %v", err)
+		return *dto.Fail("Error clearing cart")
+	}
+	if cart != nil {
+		if err := cartStore.RemoveItems(db, cart.ID); err != nil {
+			logger.Error("Error clearing cart: %v", err)
+			return *dto.Fail("Error clearing cart")
+		}
+	}
+
+	return *dto.Success("Cart cleared successfully")
 }
 
-func (s *cartService) GetCartByID(id string) (*entity.Cart, error) {
-	return nil, nil
+// SaveForLater moves a cart item to the cart's saved for later list, adding
+// to the saved line for the same item if there is one
+func (s *cartService) SaveForLater(userID, guestToken, itemID string) dto.ResponseDto {
+	db := dbmanager.GetDB()
+
+	item, response := s.findItem(db, userID, guestToken, itemID)
+	if item == nil {
+		return response
+	}
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := s.saveItem(tx, item.CartID, item.ProductID, item.VariantID, item.Quantity); err != nil {
+			return err
+		}
+		// The cart may not roll back with the transaction, so it changes last
+		return cartStore.RemoveItems(tx, item.CartID, item.ID)
+	})
+	if err != nil {
+		logger.Error("Error saving cart item for later: %v", err)
+		return *dto.Fail("Error saving item for later")
+	}
+
+	return s.getCart(db, item.CartID, nil)
 }
 
-func (s *cartService) AddCartItem(cartID string, item entity.CartItem) (*entity.Cart, error) {
-	return nil, nil
+// MoveSavedToCart moves a saved item back into the shopper's cart. As when
+// adding it, the product must be on sale and in stock for the resulting
+// quantity.
+func (s *cartService) MoveSavedToCart(userID, guestToken, savedID string) dto.ResponseDto {
+	if userID == "" && guestToken == "" {
+		return *dto.Fail("Shopper not identified")
+	}
+	db := dbmanager.GetDB()
+
+	var cartID string
+	err := db.Transaction(func(tx *gorm.DB) error {
+		cart, err := cartStore.Find(tx, userID, guestToken)
+		if err != nil {
+			return err
+		}
+		if cart == nil {
+			return errCartFailure{"Saved item not found"}
+		}
+		cartID = cart.ID
+
+		var saved entity.CartSavedItem
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id = ? AND cart_id = ?", savedID, cart.ID).First(&saved).Error; err != nil {
+			if err == gorm.ErrRecordNotFound {
+				return errCartFailure{"Saved item not found"}
+			}
+			return err
+		}
+		if err := tx.Delete(&saved).Error; err != nil {
+			return err
+		}
+		return s.addItem(tx, cart, saved.ProductID, saved.VariantID, saved.Quantity)
+	})
+	if err != nil {
+		var failure errCartFailure
+		if errors.As(err, &failure) {
+			return *dto.Fail(failure.message)
+		}
+		logger.Error("Error moving saved item to cart: %v", err)
+		return *dto.Fail("Error moving item to cart")
+	}
+
+	return s.getCart(db, cartID, nil)
 }
 
-func (s *cartService) UpdateCartItem(cartID, itemID string, quantity int) (*entity.Cart, error) {
-	return nil, nil
+// RemoveSavedItem deletes an item from the shopper's saved for later list
+func (s *cartService) RemoveSavedItem(userID, guestToken, savedID string) dto.ResponseDto {
+	if userID == "" && guestToken == "" {
+		return *dto.Fail("Shopper not identified")
+	}
+	db := dbmanager.GetDB()
+
+	cart, err := cartStore.Find(db, userID, guestToken)
+	if err != nil {
+		logger.Error("Error fetching cart: %v", err)
+		return *dto.Fail("Error removing saved item")
+	}
+	if cart == nil {
+		return *dto.Fail("Saved item not found")
+	}
+
+	result := db.Where("id = ? AND cart_id = ?", savedID, cart.ID).Delete(&entity.CartSavedItem{})
+	if result.Error != nil {
+		logger.Error("Error removing saved item: %v", result.Error)
+		return *dto.Fail("Error removing saved item")
+	}
+	if result.RowsAffected == 0 {
+		return *dto.Fail("Saved item not found")
+	}
+
+	return s.getCart(db, cart.ID, nil)
 }
 
-func (s *cartService) RemoveCartItem(cartID, itemID string) error {
+// AcknowledgeChanges accepts the changes reported in the cart's warnings:
+// lines take their current price, quantities are reduced to the stock
+// available and lines that can no longer be bought are removed
+func (s *cartService) AcknowledgeChanges(userID, guestToken string) dto.ResponseDto {
+	if userID == "" && guestToken == "" {
+		return *dto.Fail("Shopper not identified")
+	}
+	db := dbmanager.GetDB()
+
+	var cartID string
+	err := db.Transaction(func(tx *gorm.DB) error {
+		cart, err := s.findOrCreateCart(tx, userID, guestToken)
+		if err != nil {
+			return err
+		}
+		cartID = cart.ID
+
+		quote, _, err := IPricingService.quote(tx, s.pricingCart(*cart), nil)
+		if err != nil {
+			return err
+		}
+		var removed []string
+		for _, line := range quote.Lines {
+			if !line.Offered || line.Quantity == 0 {
+				removed = append(removed, line.LineID)
+				continue
+			}
+			price := line.UnitPrice
+			if err := cartStore.UpdateItem(tx, cart.ID, line.LineID, line.Quantity, &price); err != nil {
+				return err
+			}
+		}
+		if len(removed) == 0 {
+			return nil
+		}
+		return cartStore.RemoveItems(tx, cart.ID, removed...)
+	})
+	if err != nil {
+		logger.Error("Error acknowledging cart changes: %v", err)
+		return *dto.Fail("Error updating cart")
+	}
+
+	return s.getCart(db, cartID, nil)
+}
+
+// GetCartRecommendations suggests products to add to the cart of a user or guest
+func (s *cartService) GetCartRecommendations(userID, guestToken string) dto.ResponseDto {
+	if userID == "" && guestToken == "" {
+		return *dto.Success(dto.ProductRecommendationsResponse{})
+	}
+
+	db := dbmanager.GetDB()
+
+	cart, err := cartStore.Find(db, userID, guestToken)
+	if err != nil {
+		logger.Error("Error fetching cart: %v", err)
+		return *dto.Fail("Error fetching cart")
+	}
+
+	var productIDs []string
+	if cart != nil {
+		for _, item := range cart.Items {
+			productIDs = append(productIDs, item.ProductID)
+		}
+	}
+
+	recommendations, err := IProductRelationService.GetCartRecommendations(db, productIDs)
+	if err != nil {
+		logger.Error("Error fetching cart recommendations: %v", err)
+		return *dto.Fail("Error fetching recommendations")
+	}
+
+	return *dto.Success(recommendations)
+}
+
+// mergeGuest moves a guest's cart into a user's cart after signing in. A
+// line in both carts keeps the larger of the two quantities rather than
+// their sum, since shoppers often add the same item on both sides; every
+// merged line is capped at the stock available. Lines that can no longer be
+// bought are dropped.
+func (s *cartService) mergeGuest(tx *gorm.DB, userID, guestToken string) error {
+	for {
+		guestCart, err := cartStore.Find(tx, "", guestToken)
+		if err != nil || guestCart == nil {
+			return err
+		}
+		userCart, err := cartStore.Find(tx, userID, "")
+		if err != nil {
+			return err
+		}
+		if userCart == nil {
+			// Nothing to merge into: the guest cart becomes the user's
+			if err := cartStore.Assign(tx, guestCart.ID, userID); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if err := s.mergeInto(tx, userCart, guestCart); err != nil {
+			return err
+		}
+		if err := s.mergeSaved(tx, userCart.ID, guestCart.ID); err != nil {
+			return err
+		}
+		if err := ICouponService.moveCart(tx, userCart.ID, guestCart.ID); err != nil {
+			return err
+		}
+		if err := cartStore.Delete(tx, guestCart.ID); err != nil {
+			return err
+		}
+	}
+}
+
+// mergeInto merges the lines of one cart into another, as in mergeGuest
+func (s *cartService) mergeInto(tx *gorm.DB, cart, from *entity.Cart) error {
+	productIDs := make([]string, len(from.Items))
+	for i, item := range from.Items {
+		productIDs[i] = item.ProductID
+	}
+	products, err := IProductService.publishedProductResponses(tx, productIDs)
+	if err != nil {
+		return err
+	}
+
+	for _, item := range from.Items {
+		product, ok := products[item.ProductID]
+		if !ok {
+			continue
+		}
+		_, inventory, ok := dto.CartLineOffer(product, item.VariantID)
+		if !ok {
+			continue
+		}
+		limit := s.lineLimit(inventory)
+
+		merged := false
+		for _, existing := range cart.Items {
+			if !existing.SameLine(item.ProductID, item.VariantID) {
+				continue
+			}
+			quantity := min(max(existing.Quantity, item.Quantity), max(limit, existing.Quantity))
+			if quantity != existing.Quantity {
+				if err := cartStore.UpdateItem(tx, cart.ID, existing.ID, quantity, nil); err != nil {
+					return err
+				}
+			}
+			merged = true
+			break
+		}
+		if merged || limit < 1 {
+			continue
+		}
+
+		// The line keeps the price it was added at, so price changes since
+		// are still reported
+		if _, err := cartStore.AddItem(tx, cart.ID, item.ProductID, item.VariantID, min(item.Quantity, limit), item.PriceAtAdd, limit); err != nil && err != errCartLineLimit {
+			return err
+		}
+	}
 	return nil
 }
 
-func (s *cartService) ClearCart(cartID string) error {
+// mergeSaved moves the saved items of one cart to another. As with cart
+// lines, an item saved in both keeps the larger quantity.
+func (s *cartService) mergeSaved(tx *gorm.DB, cartID, fromID string) error {
+	var saved, from []entity.CartSavedItem
+	if err := tx.Where("cart_id = ?", cartID).Find(&saved).Error; err != nil {
+		return err
+	}
+	if err := tx.Where("cart_id = ?", fromID).Find(&from).Error; err != nil {
+		return err
+	}
+
+	for _, item := range from {
+		merged := false
+		for _, existing := range saved {
+			if !existing.SameLine(item.ProductID, item.VariantID) {
+				continue
+			}
+			if item.Quantity > existing.Quantity {
+				if err := tx.Model(&existing).Update("quantity", item.Quantity).Error; err != nil {
+					return err
+				}
+			}
+			if err := tx.Delete(&item).Error; err != nil {
+				return err
+			}
+			merged = true
+			break
+		}
+		if merged {
+			continue
+		}
+		if err := tx.Model(&item).Update("cart_id", cartID).Error; err != nil {
+			return err
+		}
+	}
 	return nil
-}
\ No newline at end of file
+}
+
+// saveItem adds quantity units of a product, or of one of its variants, to
+// a cart's saved items, merging with the saved line for the same item
+func (s *cartService) saveItem(tx *gorm.DB, cartID, productID string, variantID *string, quantity int) error {
+	var saved []entity.CartSavedItem
+	if err := tx.Where("cart_id = ? AND product_id = ?", cartID, productID).Find(&saved).Error; err != nil {
+		return err
+	}
+	for _, existing := range saved {
+		if existing.SameLine(productID, variantID) {
+			quantity = min(existing.Quantity+quantity, config.Get().Cart.MaxQuantity)
+			return tx.Model(&existing).Update("quantity", quantity).Error
+		}
+	}
+	return tx.Create(&entity.CartSavedItem{
+		ID:        tools.NewUuid(),
+		CartID:    cartID,
+		ProductID: productID,
+		VariantID: variantID,
+		Quantity:  quantity,
+	}).Error
+}
+
+// copyItems tops up a cart so it holds at least the quantity of each of
+// items, as when restoring or copying a cart. Items that can no longer be
+// bought in that quantity are skipped; it returns how many were.
+func (s *cartService) copyItems(tx *gorm.DB, cart *entity.Cart, items []entity.CartItem) (int, error) {
+	skipped := 0
+	for _, item := range items {
+		quantity := item.Quantity
+		for _, existing := range cart.Items {
+			if existing.SameLine(item.ProductID, item.VariantID) {
+				quantity -= existing.Quantity
+			}
+		}
+		if quantity < 1 {
+			continue
+		}
+		if err := s.addItem(tx, cart, item.ProductID, item.VariantID, quantity); err != nil {
+			var failure errCartFailure
+			if errors.As(err, &failure) {
+				skipped++
+				continue
+			}
+			return skipped, err
+		}
+	}
+	return skipped, nil
+}
+
+// findOrCreateCart returns the most recent cart of a signed-in user or, when
+// userID is empty, of a guest, with its items, creating one if needed
+func (s *cartService) findOrCreateCart(tx *gorm.DB, userID, guestToken string) (*entity.Cart, error) {
+	cart, err := cartStore.Find(tx, userID, guestToken)
+	if err != nil || cart != nil {
+		return cart, err
+	}
+	return cartStore.Create(tx, userID, guestToken)
+}
+
+// addItem adds quantity units of a product, or of one of its variants, to
+// cart at its current price, merging with an existing line for the same
+// item. A product that cannot be bought in that quantity is reported as an
+// errCartFailure.
+func (s *cartService) addItem(tx *gorm.DB, cart *entity.Cart, productID string, variantID *string, quantity int) error {
+	product, err := s.offeredProduct(tx, productID)
+	if err != nil {
+		return err
+	}
+
+	existing := 0
+	for _, item := range cart.Items {
+		if item.SameLine(productID, variantID) {
+			existing = item.Quantity
+		}
+	}
+	if failure := s.checkQuantity(product, variantID, existing+quantity); failure != "" {
+		return errCartFailure{failure}
+	}
+	price, inventory, _ := dto.CartLineOffer(*product, variantID)
+
+	// The repository enforces the limit again, against concurrent adds
+	limit := s.lineLimit(inventory)
+	if _, err := cartStore.AddItem(tx, cart.ID, productID, variantID, quantity, price, limit); err != nil {
+		if err == errCartLineLimit {
+			return errCartFailure{s.checkQuantity(product, variantID, limit+1)}
+		}
+		return err
+	}
+	return nil
+}
+
+// lineLimit returns the most units of an item a cart line can hold, given
+// its stock; nil means unlimited stock
+func (s *cartService) lineLimit(inventory *dto.InventoryResponse) int {
+	limit := config.Get().Cart.MaxQuantity
+	if inventory != nil {
+		limit = min(limit, inventory.Available)
+	}
+	return limit
+}
+
+// offeredProduct returns a product as currently offered in the storefront,
+// priced and with its stock, or nil if it is not on sale
+func (s *cartService) offeredProduct(db *gorm.DB, productID string) (*dto.ProductResponse, error) {
+	products, err := IProductService.publishedProductResponses(db, []string{productID})
+	if err != nil {
+		return nil, err
+	}
+	product, ok := products[productID]
+	if !ok {
+		return nil, nil
+	}
+	return &product, nil
+}
+
+// checkQuantity explains why quantity units of a product cannot be in a
+// cart, or returns "" if they can
+func (s *cartService) checkQuantity(product *dto.ProductResponse, variantID *string, quantity int) string {
+	if product == nil {
+		return "This product is not available"
+	}
+	_, inventory, ok := dto.CartLineOffer(*product, variantID)
+	if !ok {
+		if variantID == nil {
+			return "Please choose a variant of this product"
+		}
+		return "This variant is not available"
+	}
+	if limit := config.Get().Cart.MaxQuantity; quantity > limit {
+		return fmt.Sprintf("You can buy at most %d of this item", limit)
+	}
+	if inventory != nil && quantity > inventory.Available {
+		if inventory.Available == 0 {
+			return "This item is out of stock"
+		}
+		return fmt.Sprintf("Only %d of this item left in stock", inventory.Available)
+	}
+	return ""
+}
+
+// findItem loads a cart item owned by the shopper. On failure it returns nil
+// and the response to send.
+func (s *cartService) findItem(db *gorm.DB, userID, guestToken, itemID string) (*entity.CartItem, dto.ResponseDto) {
+	if userID == "" && guestToken == "" {
+		return nil, *dto.Fail("Shopper not identified")
+	}
+
+	item, err := cartStore.FindItem(db, userID, guestToken, itemID)
+	if err != nil {
+		logger.Error("Error fetching cart item: %v", err)
+		return nil, *dto.Fail("Error fetching cart item")
+	}
+	if item == nil {
+		return nil, *dto.Fail("Cart item not found")
+	}
+	return item, dto.ResponseDto{}
+}
+
+// getCart returns a cart with its items priced now for delivery to shipTo
+func (s *cartService) getCart(db *gorm.DB, cartID string, shipTo *entity.Address) dto.ResponseDto {
+	cart, err := cartStore.Get(db, cartID)
+	if err != nil {
+		logger.Error("Error fetching cart: %v", err)
+		return *dto.Fail("Error fetching cart")
+	}
+	if cart == nil {
+		return *dto.Fail("Cart not found")
+	}
+	if err := db.Where("cart_id = ?", cart.ID).Order("created_at ASC").Find(&cart.SavedItems).Error; err != nil {
+		logger.Error("Error fetching saved items: %v", err)
+		return *dto.Fail("Error fetching cart")
+	}
+
+	quote, products, err := IPricingService.quote(db, s.pricingCart(*cart), shipTo)
+	if err != nil {
+		logger.Error("Error pricing cart: %v", err)
+		return *dto.Fail("Error fetching cart")
+	}
+
+	// Saved items are not priced, but are shown with their products
+	var savedIDs []string
+	for _, item := range cart.SavedItems {
+		if _, ok := products[item.ProductID]; !ok {
+			savedIDs = append(savedIDs, item.ProductID)
+		}
+	}
+	saved, err := IProductService.publishedProductResponses(db, savedIDs)
+	if err != nil {
+		logger.Error("Error fetching saved products: %v", err)
+		return *dto.Fail("Error fetching cart")
+	}
+	for id, product := range saved {
+		products[id] = product
+	}
+
+	return *dto.Success(dto.GetCartResponse(*cart, products, *quote, s.warnings(*cart, *quote)))
+}
+
+// warnings compares each cart line with its current quote: the price it was
+// added at, whether it is still on sale and whether there is enough stock
+func (s *cartService) warnings(cart entity.Cart, quote entity.PriceQuote) []dto.CartWarningResponse {
+	lines := make(map[string]entity.QuoteLine, len(quote.Lines))
+	for _, line := range quote.Lines {
+		lines[line.LineID] = line
+	}
+
+	var warnings []dto.CartWarningResponse
+	for _, item := range cart.Items {
+		line := lines[item.ID]
+		warning := dto.CartWarningResponse{ItemID: item.ID, ProductID: item.ProductID, RequiresAcknowledgement: true}
+
+		switch {
+		case !line.Offered:
+			warning.Code = dto.CartWarningUnavailable
+			warning.Message = "An item in your cart is no longer available"
+			warnings = append(warnings, warning)
+			continue
+		case line.Quantity == 0:
+			warning.Code = dto.CartWarningUnavailable
+			warning.Message = fmt.Sprintf("%s is out of stock", line.Name)
+			warnings = append(warnings, warning)
+			continue
+		case line.Quantity < item.Quantity:
+			oldQuantity, newQuantity := item.Quantity, line.Quantity
+			warning.Code = dto.CartWarningQuantityReduced
+			warning.Message = fmt.Sprintf("Only %d of %s left in stock; the quantity was reduced from %d", newQuantity, line.Name, oldQuantity)
+			warning.OldQuantity, warning.NewQuantity = &oldQuantity, &newQuantity
+			warnings = append(warnings, warning)
+		}
+
+		oldPrice, newPrice := item.PriceAtAdd, line.UnitPrice
+		if toCents(oldPrice) == toCents(newPrice) {
+			continue
+		}
+		warning = dto.CartWarningResponse{
+			Code:                    dto.CartWarningPriceIncreased,
+			ItemID:                  item.ID,
+			ProductID:               item.ProductID,
+			Message:                 fmt.Sprintf("The price of %s went up from %.2f to %.2f", line.Name, oldPrice, newPrice),
+			OldPrice:                &oldPrice,
+			NewPrice:                &newPrice,
+			RequiresAcknowledgement: true,
+		}
+		if newPrice < oldPrice {
+			warning.Code = dto.CartWarningPriceDecreased
+			warning.Message = fmt.Sprintf("The price of %s dropped from %.2f to %.2f", line.Name, oldPrice, newPrice)
+			warning.RequiresAcknowledgement = false
+		}
+		warnings = append(warnings, warning)
+	}
+	return warnings
+}
+
+// pricingCart returns a cart as the pricing engine takes it
+func (s *cartService) pricingCart(cart entity.Cart) pricingCart {
+	priced := pricingCart{CartID: cart.ID, Lines: make([]pricingLine, len(cart.Items))}
+	if cart.UserID != nil {
+		priced.UserID = *cart.UserID
+	}
+	for i, item := range cart.Items {
+		priced.Lines[i] = pricingLine{
+			LineID:    item.ID,
+			ProductID: item.ProductID,
+			VariantID: item.VariantID,
+			Quantity:  item.Quantity,
+		}
+	}
+	return priced
+}
+
+// shopperScope restricts a query to the rows of a signed-in user or, when
+// userID is empty, of a guest. Rows are owned by one or the other, as in
+// entity.Cart.
+func shopperScope(userID, guestToken string) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		if userID != "" {
+			return db.Where("user_id = ?", userID)
+		}
+		return db.Where("user_id IS NULL AND guest_token = ?", guestToken)
+	}
+}
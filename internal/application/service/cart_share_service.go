@@ -0,0 +1,164 @@
+package service
+
+import (
+	"fmt"
+	"net/url"
+	"time"
+
+	"gorm.io/gorm"
+
+	"backend-ecommerce/internal/application/dto"
+	"backend-ecommerce/internal/application/entity"
+	"backend-ecommerce/internal/application/tools"
+	"backend-ecommerce/internal/infrastructure/config"
+	"backend-ecommerce/internal/infrastructure/dbmanager"
+	"backend-ecommerce/internal/infrastructure/logger"
+)
+
+// cartShareAudience scopes the signed tokens of shared cart links
+const cartShareAudience = "cart-share"
+
+type cartShareService struct {
+}
+
+// ShareCart takes a snapshot of the shopper's cart and returns a signed link
+// to it, e.g. for a sales rep to send a prepared cart to a customer. The link
+// expires after the configured time; changes to the cart after sharing are
+// not seen through it.
+func (s *cartShareService) ShareCart(userID, guestToken string) dto.ResponseDto {
+	if userID == "" && guestToken == "" {
+		return *dto.Fail("Shopper not identified")
+	}
+	db := dbmanager.GetDB()
+
+	cart, err := cartStore.Find(db, userID, guestToken)
+	if err != nil {
+		logger.Error("Error fetching cart: %v", err)
+		return *dto.Fail("Error sharing cart")
+	}
+	if cart == nil || len(cart.Items) == 0 {
+		return *dto.Fail("Your cart is empty")
+	}
+
+	ttl := config.Get().Cart.ShareTTL
+	snapshot := entity.CartSnapshot{
+		ID:        tools.NewUuid(),
+		CartID:    cart.ID,
+		ExpiresAt: time.Now().UTC().Add(ttl),
+	}
+	if userID != "" {
+		snapshot.UserID = &userID
+	}
+	for i, item := range cart.Items {
+		snapshot.Items = append(snapshot.Items, entity.CartSnapshotItem{
+			ID:         tools.NewUuid(),
+			SnapshotID: snapshot.ID,
+			ProductID:  item.ProductID,
+			VariantID:  item.VariantID,
+			Quantity:   item.Quantity,
+			Position:   i,
+		})
+	}
+
+	token, err := config.JWT.SignScoped(snapshot.ID, cartShareAudience, ttl)
+	if err != nil {
+		logger.Error("Error signing cart share link: %v", err)
+		return *dto.Fail("Error sharing cart")
+	}
+	if err := db.Create(&snapshot).Error; err != nil {
+		logger.Error("Error creating cart snapshot: %v", err)
+		return *dto.Fail("Error sharing cart")
+	}
+
+	return *dto.Success(dto.CartShareResponse{
+		ID:        snapshot.ID,
+		Token:     token,
+		URL:       linkWithQuery(config.Get().Cart.ShareURL, url.Values{"share": {snapshot.ID}, "token": {token}}),
+		ExpiresAt: snapshot.ExpiresAt.Format(time.RFC3339),
+	})
+}
+
+// GetSharedCart returns a shared cart at current prices, so the recipient
+// can review it before copying it
+func (s *cartShareService) GetSharedCart(id, token string) dto.ResponseDto {
+	db := dbmanager.GetDB()
+
+	snapshot, response := s.findSnapshot(db, id, token)
+	if snapshot == nil {
+		return response
+	}
+
+	productIDs := make([]string, len(snapshot.Items))
+	for i, item := range snapshot.Items {
+		productIDs[i] = item.ProductID
+	}
+	products, err := IProductService.publishedProductResponses(db, productIDs)
+	if err != nil {
+		logger.Error("Error fetching products: %v", err)
+		return *dto.Fail("Error fetching shared cart")
+	}
+
+	return *dto.Success(dto.GetCartSnapshotResponse(*snapshot, products))
+}
+
+// CopySharedCart copies the items of a shared cart into the shopper's own
+// cart at current prices. As when restoring a cart, lines already in the
+// cart keep the larger quantity and items that can no longer be bought are
+// left out.
+func (s *cartShareService) CopySharedCart(userID, guestToken, id string, req dto.CartSharedCopyRequest) dto.ResponseDto {
+	if userID == "" && guestToken == "" {
+		return *dto.Fail("Shopper not identified")
+	}
+	db := dbmanager.GetDB()
+
+	snapshot, response := s.findSnapshot(db, id, req.Token)
+	if snapshot == nil {
+		return response
+	}
+
+	items := make([]entity.CartItem, len(snapshot.Items))
+	for i, item := range snapshot.Items {
+		items[i] = entity.CartItem{ProductID: item.ProductID, VariantID: item.VariantID, Quantity: item.Quantity}
+	}
+
+	var cartID string
+	skipped := 0
+	err := db.Transaction(func(tx *gorm.DB) error {
+		cart, err := ICartService.findOrCreateCart(tx, userID, guestToken)
+		if err != nil {
+			return err
+		}
+		cartID = cart.ID
+		skipped, err = ICartService.copyItems(tx, cart, items)
+		return err
+	})
+	if err != nil {
+		logger.Error("Error copying shared cart %s: %v", snapshot.ID, err)
+		return *dto.Fail("Error copying shared cart")
+	}
+
+	response = ICartService.getCart(db, cartID, nil)
+	if response.Code == 0 && skipped > 0 {
+		response.Msg = fmt.Sprintf("%d items could not be added because they are no longer available in that quantity", skipped)
+	}
+	return response
+}
+
+// findSnapshot loads a shared cart after checking the token of its link. On
+// failure it returns nil and the response to send.
+func (s *cartShareService) findSnapshot(db *gorm.DB, id, token string) (*entity.CartSnapshot, dto.ResponseDto) {
+	if err := config.JWT.VerifyScoped(token, id, cartShareAudience); err != nil {
+		return nil, *dto.Fail("Invalid or expired share link")
+	}
+
+	var snapshot entity.CartSnapshot
+	if err := db.Preload("Items", func(db *gorm.DB) *gorm.DB { return db.Order("position ASC") }).
+		Where("id = ?", id).First(&snapshot).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, *dto.Fail("This shared cart no longer exists")
+		}
+		logger.Error("Error fetching cart snapshot: %v", err)
+		return nil, *dto.Fail("Error fetching shared cart")
+	}
+	return &snapshot, dto.ResponseDto{}
+}
@@ -0,0 +1,576 @@
+package service
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"backend-ecommerce/internal/application/dto"
+	"backend-ecommerce/internal/application/entity"
+	"backend-ecommerce/internal/application/tools"
+	"backend-ecommerce/internal/infrastructure/cachemanager"
+	"backend-ecommerce/internal/infrastructure/dbmanager"
+	"backend-ecommerce/internal/infrastructure/logger"
+)
+
+// collectionRefreshBatch is how many products are evaluated at a time during a full refresh
+const collectionRefreshBatch = 500
+
+type collectionService struct {
+	mu sync.Mutex
+}
+
+type cachedCollection struct {
+	Collection dto.CollectionDetailResponse `json:"collection"`
+	Total      int64                        `json:"total"`
+}
+
+// GetCollections lists every collection for admins
+func (s *collectionService) GetCollections() dto.ResponseDto {
+	return s.listCollections(false)
+}
+
+// GetStorefrontCollections lists the active collections
+func (s *collectionService) GetStorefrontCollections() dto.ResponseDto {
+	return s.listCollections(true)
+}
+
+func (s *collectionService) listCollections(activeOnly bool) dto.ResponseDto {
+	db := dbmanager.GetDB()
+
+	var collections []entity.Collection
+	query := db.Order("name ASC")
+	if activeOnly {
+		query = query.Where("is_active = ?", true)
+	}
+	if err := query.Find(&collections).Error; err != nil {
+		logger.Error("Error fetching collections: %v", err)
+		return *dto.Fail("Error fetching collections")
+	}
+
+	counts, err := s.countProducts(db, collections, activeOnly)
+	if err != nil {
+		logger.Error("Error counting collection products: %v", err)
+		return *dto.Fail("Error fetching collections")
+	}
+
+	collectionDtos := make([]dto.CollectionResponse, len(collections))
+	for i, collection := range collections {
+		collectionDtos[i] = dto.GetCollectionResponse(collection, counts[collection.ID])
+	}
+
+	return *dto.SuccessCount(collectionDtos, int64(len(collectionDtos)))
+}
+
+// GetCollectionBySlug returns an active collection with one page of its
+// published products, priced at the current time
+func (s *collectionService) GetCollectionBySlug(slug string, page, pageSize int) dto.ResponseDto {
+	cacheKey := fmt.Sprintf("%scollection:%s:%d:%d", productCachePrefix, slug, page, pageSize)
+
+	var cached cachedCollection
+	if cachemanager.GetJSON(cacheKey, &cached) {
+		return *dto.SuccessCount(cached.Collection, cached.Total)
+	}
+
+	db := dbmanager.GetDB()
+
+	var collection entity.Collection
+	if err := db.Where("slug = ? AND is_active = ?", slug, true).First(&collection).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return *dto.Fail("Collection not found")
+		}
+		logger.Error("Error fetching collection: %v", err)
+		return *dto.Fail("Error fetching collection")
+	}
+
+	query := publishedProducts(db.Model(&entity.Product{})).
+		Joins("JOIN collectionProducts ON collectionProducts.product_id = products.id").
+		Where("collectionProducts.collection_id = ?", collection.ID)
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		logger.Error("Error counting collection products: %v", err)
+		return *dto.Fail("Error fetching collection")
+	}
+
+	switch {
+	case collection.Type == entity.CollectionTypeManual:
+		query = query.Order("collectionProducts.position ASC")
+	case collection.SortBy == entity.CollectionSortName:
+		query = query.Order("products.name ASC")
+	case collection.SortBy == entity.CollectionSortPriceAsc:
+		query = query.Order("products.price ASC")
+	case collection.SortBy == entity.CollectionSortPriceDesc:
+		query = query.Order("products.price DESC")
+	default:
+		query = query.Order("products.created_at DESC")
+	}
+	if pageSize > 0 {
+		query = query.Limit(pageSize).Offset((page - 1) * pageSize)
+	}
+
+	var products []entity.Product
+	if err := preloadProductDetails(query).Find(&products).Error; err != nil {
+		logger.Error("Error fetching collection products: %v", err)
+		return *dto.Fail("Error fetching collection")
+	}
+
+	productDtos, err := IProductService.toProductResponses(db, products, time.Now().UTC())
+	if err != nil {
+		logger.Error("Error resolving product prices: %v", err)
+		return *dto.Fail("Error fetching collection")
+	}
+
+	detail := dto.CollectionDetailResponse{
+		CollectionResponse: dto.GetCollectionResponse(collection, total),
+		Products:           productDtos,
+	}
+	cachemanager.SetJSON(cacheKey, cachedCollection{Collection: detail, Total: total}, productCacheTTL)
+
+	return *dto.SuccessCount(detail, total)
+}
+
+// CreateCollection creates a collection. Automatic collections are filled
+// from their rules straight away.
+func (s *collectionService) CreateCollection(req dto.CollectionCreateRequest) dto.ResponseDto {
+	db := dbmanager.GetDB()
+
+	collection := entity.Collection{
+		ID:          tools.NewUuid(),
+		Name:        req.Name,
+		Slug:        req.Slug,
+		Description: req.Description,
+		Type:        entity.CollectionType(req.Type),
+		SortBy:      entity.CollectionSortNewest,
+		IsActive:    true,
+	}
+	if req.IsActive != nil {
+		collection.IsActive = *req.IsActive
+	}
+	if req.SortBy != "" {
+		collection.SortBy = entity.CollectionSort(req.SortBy)
+	}
+	if collection.Type == entity.CollectionTypeAutomatic {
+		if req.Rules == nil {
+			return *dto.Fail("Automatic collections need rules")
+		}
+		if err := req.Rules.Validate(); err != nil {
+			return *dto.Fail("Invalid rules: " + err.Error())
+		}
+		collection.Rules = *req.Rules
+	}
+
+	var count int64
+	if err := db.Model(&entity.Collection{}).Where("slug = ?", collection.Slug).Count(&count).Error; err != nil {
+		logger.Error("Error checking collection slug: %v", err)
+		return *dto.Fail("Error creating collection")
+	}
+	if count > 0 {
+		return *dto.Fail("A collection with this slug already exists")
+	}
+
+	if err := db.Create(&collection).Error; err != nil {
+		logger.Error("Error creating collection: %v", err)
+		return *dto.Fail("Error creating collection")
+	}
+
+	if collection.Type == entity.CollectionTypeAutomatic {
+		if err := s.refreshCollection(db, &collection); err != nil {
+			logger.Error("Error refreshing collection %s: %v", collection.ID, err)
+		}
+	}
+
+	return s.GetCollection(collection.ID)
+}
+
+// GetCollection returns a collection for admins
+func (s *collectionService) GetCollection(id string) dto.ResponseDto {
+	db := dbmanager.GetDB()
+
+	var collection entity.Collection
+	if err := db.Where("id = ?", id).First(&collection).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return *dto.Fail("Collection not found")
+		}
+		logger.Error("Error fetching collection: %v", err)
+		return *dto.Fail("Error fetching collection")
+	}
+
+	counts, err := s.countProducts(db, []entity.Collection{collection}, false)
+	if err != nil {
+		logger.Error("Error counting collection products: %v", err)
+		return *dto.Fail("Error fetching collection")
+	}
+
+	return *dto.Success(dto.GetCollectionResponse(collection, counts[collection.ID]))
+}
+
+// UpdateCollection updates a collection and re-evaluates it when its rules change
+func (s *collectionService) UpdateCollection(id string, req dto.CollectionUpdateRequest) dto.ResponseDto {
+	db := dbmanager.GetDB()
+
+	var collection entity.Collection
+	if err := db.Where("id = ?", id).First(&collection).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return *dto.Fail("Collection not found")
+		}
+		logger.Error("Error fetching collection: %v", err)
+		return *dto.Fail("Error fetching collection")
+	}
+
+	if req.Name != nil {
+		collection.Name = *req.Name
+	}
+	if req.Slug != nil && *req.Slug != collection.Slug {
+		var count int64
+		if err := db.Model(&entity.Collection{}).Where("slug = ? AND id <> ?", *req.Slug, id).Count(&count).Error; err != nil {
+			logger.Error("Error checking collection slug: %v", err)
+			return *dto.Fail("Error updating collection")
+		}
+		if count > 0 {
+			return *dto.Fail("A collection with this slug already exists")
+		}
+		collection.Slug = *req.Slug
+	}
+	if req.Description != nil {
+		collection.Description = *req.Description
+	}
+	if req.SortBy != nil {
+		collection.SortBy = entity.CollectionSort(*req.SortBy)
+	}
+	if req.IsActive != nil {
+		collection.IsActive = *req.IsActive
+	}
+	if req.Rules != nil {
+		if collection.Type != entity.CollectionTypeAutomatic {
+			return *dto.Fail("Only automatic collections have rules")
+		}
+		if err := req.Rules.Validate(); err != nil {
+			return *dto.Fail("Invalid rules: " + err.Error())
+		}
+		collection.Rules = *req.Rules
+	}
+
+	if err := db.Save(&collection).Error; err != nil {
+		logger.Error("Error updating collection: %v", err)
+		return *dto.Fail("Error updating collection")
+	}
+
+	if req.Rules != nil {
+		if err := s.refreshCollection(db, &collection); err != nil {
+			logger.Error("Error refreshing collection %s: %v", collection.ID, err)
+		}
+	}
+	cachemanager.DeletePrefix(productCachePrefix)
+
+	return s.GetCollection(collection.ID)
+}
+
+// DeleteCollection removes a collection and its memberships
+func (s *collectionService) DeleteCollection(id string) dto.ResponseDto {
+	db := dbmanager.GetDB()
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("collection_id = ?", id).Delete(&entity.CollectionProduct{}).Error; err != nil {
+			return err
+		}
+		result := tx.Where("id = ?", id).Delete(&entity.Collection{})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		return nil
+	})
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return *dto.Fail("Collection not found")
+		}
+		logger.Error("Error deleting collection: %v", err)
+		return *dto.Fail("Error deleting collection")
+	}
+
+	cachemanager.DeletePrefix(productCachePrefix)
+
+	return *dto.Success("Collection deleted successfully")
+}
+
+// SetCollectionProducts replaces the products of a manual collection, in display order
+func (s *collectionService) SetCollectionProducts(id string, productIDs []string) dto.ResponseDto {
+	db := dbmanager.GetDB()
+
+	var collection entity.Collection
+	if err := db.Where("id = ?", id).First(&collection).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return *dto.Fail("Collection not found")
+		}
+		logger.Error("Error fetching collection: %v", err)
+		return *dto.Fail("Error fetching collection")
+	}
+	if collection.Type != entity.CollectionTypeManual {
+		return *dto.Fail("Products of automatic collections are chosen by their rules")
+	}
+
+	seen := make(map[string]bool, len(productIDs))
+	memberships := make([]entity.CollectionProduct, 0, len(productIDs))
+	for _, productID := range productIDs {
+		if seen[productID] {
+			return *dto.Fail("Each product can appear only once")
+		}
+		seen[productID] = true
+		memberships = append(memberships, entity.CollectionProduct{
+			CollectionID: id,
+			ProductID:    productID,
+			Position:     len(memberships),
+		})
+	}
+
+	if len(productIDs) > 0 {
+		var count int64
+		if err := db.Model(&entity.Product{}).Where("id IN ?", productIDs).Count(&count).Error; err != nil {
+			logger.Error("Error fetching products: %v", err)
+			return *dto.Fail("Error fetching products")
+		}
+		if int(count) != len(productIDs) {
+			return *dto.Fail("One or more products were not found")
+		}
+	}
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("collection_id = ?", id).Delete(&entity.CollectionProduct{}).Error; err != nil {
+			return err
+		}
+		if len(memberships) == 0 {
+			return nil
+		}
+		return tx.Create(&memberships).Error
+	})
+	if err != nil {
+		logger.Error("Error updating collection products: %v", err)
+		return *dto.Fail("Error updating collection products")
+	}
+
+	cachemanager.DeletePrefix(productCachePrefix)
+
+	return s.GetCollection(id)
+}
+
+// RefreshCollection re-evaluates the rules of an automatic collection against every product
+func (s *collectionService) RefreshCollection(id string) dto.ResponseDto {
+	db := dbmanager.GetDB()
+
+	var collection entity.Collection
+	if err := db.Where("id = ?", id).First(&collection).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return *dto.Fail("Collection not found")
+		}
+		logger.Error("Error fetching collection: %v", err)
+		return *dto.Fail("Error fetching collection")
+	}
+	if collection.Type != entity.CollectionTypeAutomatic {
+		return *dto.Fail("Only automatic collections can be refreshed")
+	}
+
+	if err := s.refreshCollection(db, &collection); err != nil {
+		logger.Error("Error refreshing collection %s: %v", collection.ID, err)
+		return *dto.Fail("Error refreshing collection")
+	}
+	cachemanager.DeletePrefix(productCachePrefix)
+
+	return s.GetCollection(id)
+}
+
+// RefreshAllCollections is run by cronmanager. It re-evaluates every
+// automatic collection so changes that happen with time, such as sale prices
+// and stock levels, are picked up.
+func (s *collectionService) RefreshAllCollections() {
+	db := dbmanager.GetDB()
+	if db == nil {
+		return
+	}
+
+	var collections []entity.Collection
+	if err := db.Where("type = ?", entity.CollectionTypeAutomatic).Find(&collections).Error; err != nil {
+		logger.Error("Error fetching collections: %v", err)
+		return
+	}
+	for i := range collections {
+		if err := s.refreshCollection(db, &collections[i]); err != nil {
+			logger.Error("Error refreshing collection %s: %v", collections[i].ID, err)
+		}
+	}
+	if len(collections) > 0 {
+		cachemanager.DeletePrefix(productCachePrefix)
+	}
+}
+
+// RefreshProductMemberships re-evaluates the given products against every
+// automatic collection. It is called after products change so membership
+// stays current without a full refresh. Errors are logged, not returned, as
+// the product change itself has already been saved.
+func (s *collectionService) RefreshProductMemberships(productIDs []string) {
+	if len(productIDs) == 0 {
+		return
+	}
+	db := dbmanager.GetDB()
+
+	var collections []entity.Collection
+	if err := db.Where("type = ?", entity.CollectionTypeAutomatic).Find(&collections).Error; err != nil {
+		logger.Error("Error fetching collections: %v", err)
+		return
+	}
+	if len(collections) == 0 {
+		return
+	}
+
+	var products []entity.Product
+	if err := db.Where("id IN ?", productIDs).Find(&products).Error; err != nil {
+		logger.Error("Error fetching products: %v", err)
+		return
+	}
+	facts, err := s.productFacts(db, products, time.Now().UTC())
+	if err != nil {
+		logger.Error("Error computing product facts: %v", err)
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, collection := range collections {
+		err := db.Transaction(func(tx *gorm.DB) error {
+			// Deleted products drop out of every collection
+			if err := tx.Where("collection_id = ? AND product_id IN ?", collection.ID, productIDs).
+				Delete(&entity.CollectionProduct{}).Error; err != nil {
+				return err
+			}
+			var members []entity.CollectionProduct
+			for _, product := range products {
+				if collection.Rules.Matches(product, facts[product.ID]) {
+					members = append(members, entity.CollectionProduct{CollectionID: collection.ID, ProductID: product.ID})
+				}
+			}
+			if len(members) == 0 {
+				return nil
+			}
+			return tx.Create(&members).Error
+		})
+		if err != nil {
+			logger.Error("Error updating collection %s: %v", collection.ID, err)
+		}
+	}
+	cachemanager.DeletePrefix(productCachePrefix)
+}
+
+// refreshCollection materialises the membership of an automatic collection
+// by evaluating its rules against every product
+func (s *collectionService) refreshCollection(db *gorm.DB, collection *entity.Collection) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now().UTC()
+	var matched []entity.CollectionProduct
+	var products []entity.Product
+	err := db.Model(&entity.Product{}).FindInBatches(&products, collectionRefreshBatch, func(batch *gorm.DB, _ int) error {
+		facts, err := s.productFacts(db, products, now)
+		if err != nil {
+			return err
+		}
+		for _, product := range products {
+			if collection.Rules.Matches(product, facts[product.ID]) {
+				matched = append(matched, entity.CollectionProduct{CollectionID: collection.ID, ProductID: product.ID})
+			}
+		}
+		return nil
+	}).Error
+	if err != nil {
+		return err
+	}
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("collection_id = ?", collection.ID).Delete(&entity.CollectionProduct{}).Error; err != nil {
+			return err
+		}
+		if len(matched) > 0 {
+			if err := tx.Clauses(clause.OnConflict{DoNothing: true}).CreateInBatches(&matched, collectionRefreshBatch).Error; err != nil {
+				return err
+			}
+		}
+		collection.RefreshedAt = &now
+		return tx.Model(&entity.Collection{}).Where("id = ?", collection.ID).Update("refreshed_at", now).Error
+	})
+}
+
+// productFacts works out the current price and stock of each product, the
+// way the storefront shows them
+func (s *collectionService) productFacts(db *gorm.DB, products []entity.Product, t time.Time) (map[string]entity.ProductFacts, error) {
+	productPrices, _, err := IPriceScheduleService.ResolvePrices(db, products, t)
+	if err != nil {
+		return nil, err
+	}
+	bundles, err := IBundleService.ResolveBundles(db, products, productPrices, t)
+	if err != nil {
+		return nil, err
+	}
+
+	productIDs := make([]string, len(products))
+	for i, product := range products {
+		productIDs[i] = product.ID
+	}
+	availability, err := IInventoryService.GetAvailability(db, productIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	facts := make(map[string]entity.ProductFacts, len(products))
+	for _, product := range products {
+		fact := entity.ProductFacts{
+			Price:     productPrices[product.ID].Price,
+			Available: availability[product.ID],
+			Unlimited: product.Type == entity.ProductTypeDigital,
+		}
+		if bundle, ok := bundles[product.ID]; ok {
+			fact.Price = bundle.Price.Price
+			fact.Available = bundle.Response.Available
+		}
+		facts[product.ID] = fact
+	}
+	return facts, nil
+}
+
+// countProducts counts the members of each collection, optionally only the published ones
+func (s *collectionService) countProducts(db *gorm.DB, collections []entity.Collection, publishedOnly bool) (map[string]int64, error) {
+	counts := make(map[string]int64, len(collections))
+	if len(collections) == 0 {
+		return counts, nil
+	}
+
+	ids := make([]string, len(collections))
+	for i, collection := range collections {
+		ids[i] = collection.ID
+	}
+
+	var rows []struct {
+		CollectionID string
+		Count        int64
+	}
+	query := db.Table("collectionProducts").
+		Select("collectionProducts.collection_id, COUNT(*) AS count").
+		Where("collectionProducts.collection_id IN ?", ids).
+		Group("collectionProducts.collection_id")
+	if publishedOnly {
+		query = query.Joins("JOIN products ON products.id = collectionProducts.product_id").
+			Where("products.is_active = ? AND products.status = ?", true, entity.ProductStatusPublished)
+	}
+	if err := query.Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+	for _, row := range rows {
+		counts[row.CollectionID] = row.Count
+	}
+	return counts, nil
+}
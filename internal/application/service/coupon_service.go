@@ -0,0 +1,587 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"backend-ecommerce/internal/application/dto"
+	"backend-ecommerce/internal/application/entity"
+	"backend-ecommerce/internal/application/tools"
+	"backend-ecommerce/internal/infrastructure/dbmanager"
+	"backend-ecommerce/internal/infrastructure/logger"
+)
+
+// couponCodeLength is the length of the random part of generated codes
+const couponCodeLength = 10
+
+type couponService struct {
+}
+
+// GetCoupons returns every coupon, newest first
+func (s *couponService) GetCoupons() dto.ResponseDto {
+	db := dbmanager.GetDB()
+
+	var coupons []entity.Coupon
+	if err := db.Order("created_at DESC").Find(&coupons).Error; err != nil {
+		logger.Error("Error fetching coupons: %v", err)
+		return *dto.Fail("Error fetching coupons")
+	}
+
+	var counts []struct {
+		CouponID string
+		Count    int64
+	}
+	if err := db.Model(&entity.CouponCode{}).Select("coupon_id, COUNT(*) AS count").
+		Group("coupon_id").Scan(&counts).Error; err != nil {
+		logger.Error("Error counting coupon codes: %v", err)
+		return *dto.Fail("Error fetching coupons")
+	}
+	codeCounts := make(map[string]int64, len(counts))
+	for _, count := range counts {
+		codeCounts[count.CouponID] = count.Count
+	}
+
+	couponDtos := make([]dto.CouponResponse, len(coupons))
+	for i, coupon := range coupons {
+		couponDtos[i] = dto.GetCouponResponse(coupon, codeCounts[coupon.ID])
+	}
+
+	return *dto.SuccessCount(couponDtos, int64(len(couponDtos)))
+}
+
+// GetCoupon returns a coupon
+func (s *couponService) GetCoupon(id string) dto.ResponseDto {
+	db := dbmanager.GetDB()
+
+	coupon, response := s.findCoupon(db, id)
+	if coupon == nil {
+		return response
+	}
+	return s.couponResponse(db, *coupon)
+}
+
+// CreateCoupon creates a coupon and, if a code is given, a code for it that
+// any number of customers can use within the coupon's limits
+func (s *couponService) CreateCoupon(req dto.CouponCreateRequest) dto.ResponseDto {
+	db := dbmanager.GetDB()
+
+	coupon := entity.Coupon{
+		ID:                  tools.NewUuid(),
+		Name:                strings.TrimSpace(req.Name),
+		Description:         req.Description,
+		Type:                entity.CouponType(req.Type),
+		Value:               tools.RoundPrice(req.Value),
+		MinSubtotal:         tools.RoundPrice(req.MinSubtotal),
+		ProductIDs:          req.ProductIDs,
+		CategoryIDs:         req.CategoryIDs,
+		ExcludedProductIDs:  req.ExcludedProductIDs,
+		ExcludedCategoryIDs: req.ExcludedCategoryIDs,
+		UsageLimit:          req.UsageLimit,
+		PerCustomerLimit:    req.PerCustomerLimit,
+		StartsAt:            req.StartsAt,
+		EndsAt:              req.EndsAt,
+		Combinable:          req.Combinable,
+		IsActive:            true,
+	}
+	if failure := s.validate(coupon); failure != "" {
+		return *dto.Fail(failure)
+	}
+
+	code := normalizeCouponCode(req.Code)
+	if code != "" {
+		var count int64
+		if err := db.Model(&entity.CouponCode{}).Where("code = ?", code).Count(&count).Error; err != nil {
+			logger.Error("Error checking coupon code: %v", err)
+			return *dto.Fail("Error creating coupon")
+		}
+		if count > 0 {
+			return *dto.Fail("This code is already in use")
+		}
+	}
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&coupon).Error; err != nil {
+			return err
+		}
+		if code == "" {
+			return nil
+		}
+		return tx.Create(&entity.CouponCode{ID: tools.NewUuid(), CouponID: coupon.ID, Code: code}).Error
+	})
+	if err != nil {
+		logger.Error("Error creating coupon: %v", err)
+		return *dto.Fail("Error creating coupon")
+	}
+
+	return s.couponResponse(db, coupon)
+}
+
+// UpdateCoupon updates a coupon. Changes apply to every code of the coupon,
+// including codes already applied to carts.
+func (s *couponService) UpdateCoupon(id string, req dto.CouponUpdateRequest) dto.ResponseDto {
+	db := dbmanager.GetDB()
+
+	coupon, response := s.findCoupon(db, id)
+	if coupon == nil {
+		return response
+	}
+
+	if req.Name != nil {
+		coupon.Name = strings.TrimSpace(*req.Name)
+	}
+	if req.Description != nil {
+		coupon.Description = *req.Description
+	}
+	if req.Value != nil {
+		coupon.Value = tools.RoundPrice(*req.Value)
+	}
+	if req.MinSubtotal != nil {
+		coupon.MinSubtotal = tools.RoundPrice(*req.MinSubtotal)
+	}
+	if req.ProductIDs != nil {
+		coupon.ProductIDs = *req.ProductIDs
+	}
+	if req.CategoryIDs != nil {
+		coupon.CategoryIDs = *req.CategoryIDs
+	}
+	if req.ExcludedProductIDs != nil {
+		coupon.ExcludedProductIDs = *req.ExcludedProductIDs
+	}
+	if req.ExcludedCategoryIDs != nil {
+		coupon.ExcludedCategoryIDs = *req.ExcludedCategoryIDs
+	}
+	if req.UsageLimit != nil {
+		coupon.UsageLimit = *req.UsageLimit
+	}
+	if req.PerCustomerLimit != nil {
+		coupon.PerCustomerLimit = *req.PerCustomerLimit
+	}
+	if req.StartsAt != nil {
+		coupon.StartsAt = req.StartsAt
+	}
+	if req.EndsAt != nil {
+		coupon.EndsAt = req.EndsAt
+	}
+	if req.Combinable != nil {
+		coupon.Combinable = *req.Combinable
+	}
+	if req.IsActive != nil {
+		coupon.IsActive = *req.IsActive
+	}
+	if failure := s.validate(*coupon); failure != "" {
+		return *dto.Fail(failure)
+	}
+
+	// times_used is left out: it only changes with redemptions
+	if err := db.Omit("times_used").Save(coupon).Error; err != nil {
+		logger.Error("Error updating coupon: %v", err)
+		return *dto.Fail("Error updating coupon")
+	}
+
+	return s.couponResponse(db, *coupon)
+}
+
+// DeleteCoupon removes a coupon that was never redeemed, with its codes.
+// Redeemed coupons can be deactivated instead.
+func (s *couponService) DeleteCoupon(id string) dto.ResponseDto {
+	db := dbmanager.GetDB()
+
+	var count int64
+	if err := db.Model(&entity.CouponRedemption{}).Where("coupon_id = ?", id).Count(&count).Error; err != nil {
+		logger.Error("Error checking coupon redemptions: %v", err)
+		return *dto.Fail("Error deleting coupon")
+	}
+	if count > 0 {
+		return *dto.Fail("The coupon has been redeemed; deactivate it instead")
+	}
+
+	var deleted int64
+	err := db.Transaction(func(tx *gorm.DB) error {
+		codeIDs := tx.Model(&entity.CouponCode{}).Select("id").Where("coupon_id = ?", id)
+		if err := tx.Where("coupon_code_id IN (?)", codeIDs).Delete(&entity.CartCoupon{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("coupon_id = ?", id).Delete(&entity.CouponCode{}).Error; err != nil {
+			return err
+		}
+		result := tx.Where("id = ?", id).Delete(&entity.Coupon{})
+		deleted = result.RowsAffected
+		return result.Error
+	})
+	if err != nil {
+		logger.Error("Error deleting coupon: %v", err)
+		return *dto.Fail("Error deleting coupon")
+	}
+	if deleted == 0 {
+		return *dto.Fail("Coupon not found")
+	}
+
+	return *dto.Success("Coupon deleted successfully")
+}
+
+// GetCouponCodes returns the codes of a coupon, oldest first
+func (s *couponService) GetCouponCodes(id string, page, pageSize int) dto.ResponseDto {
+	db := dbmanager.GetDB()
+
+	coupon, response := s.findCoupon(db, id)
+	if coupon == nil {
+		return response
+	}
+
+	var total int64
+	if err := db.Model(&entity.CouponCode{}).Where("coupon_id = ?", coupon.ID).Count(&total).Error; err != nil {
+		logger.Error("Error counting coupon codes: %v", err)
+		return *dto.Fail("Error fetching coupon codes")
+	}
+
+	var codes []entity.CouponCode
+	if err := db.Where("coupon_id = ?", coupon.ID).Order("created_at ASC").Order("code ASC").
+		Offset((page - 1) * pageSize).Limit(pageSize).Find(&codes).Error; err != nil {
+		logger.Error("Error fetching coupon codes: %v", err)
+		return *dto.Fail("Error fetching coupon codes")
+	}
+
+	codeDtos := make([]dto.CouponCodeResponse, len(codes))
+	for i, code := range codes {
+		codeDtos[i] = dto.GetCouponCodeResponse(code)
+	}
+
+	return *dto.SuccessCount(codeDtos, total)
+}
+
+// GenerateCodes creates unique single-use codes for a coupon, e.g. to hand
+// out one per customer. Codes are a prefix followed by random characters
+// that are hard to confuse when typed.
+func (s *couponService) GenerateCodes(id string, req dto.CouponCodeGenerateRequest) dto.ResponseDto {
+	db := dbmanager.GetDB()
+
+	coupon, response := s.findCoupon(db, id)
+	if coupon == nil {
+		return response
+	}
+
+	length := req.Length
+	if length == 0 {
+		length = couponCodeLength
+	}
+	prefix := normalizeCouponCode(req.Prefix)
+
+	codes := make([]entity.CouponCode, 0, req.Count)
+	generated := make(map[string]bool, req.Count)
+	// Collisions are rare, so a few rounds always suffice unless the prefix
+	// and length leave too few codes
+	for round := 0; round < 5 && len(codes) < req.Count; round++ {
+		candidates := make([]string, 0, req.Count-len(codes))
+		for len(candidates) < cap(candidates) {
+			code := prefix + tools.NewRandomCode(length)
+			if !generated[code] {
+				generated[code] = true
+				candidates = append(candidates, code)
+			}
+		}
+
+		var taken []string
+		if err := db.Model(&entity.CouponCode{}).Where("code IN ?", candidates).Pluck("code", &taken).Error; err != nil {
+			logger.Error("Error checking coupon codes: %v", err)
+			return *dto.Fail("Error generating codes")
+		}
+		existing := make(map[string]bool, len(taken))
+		for _, code := range taken {
+			existing[code] = true
+		}
+		for _, code := range candidates {
+			if !existing[code] {
+				codes = append(codes, entity.CouponCode{ID: tools.NewUuid(), CouponID: coupon.ID, Code: code, UsageLimit: 1})
+			}
+		}
+	}
+	if len(codes) < req.Count {
+		return *dto.Fail("Could not generate enough unique codes; use a longer code")
+	}
+
+	if err := db.CreateInBatches(&codes, 500).Error; err != nil {
+		logger.Error("Error creating coupon codes: %v", err)
+		return *dto.Fail("Error generating codes")
+	}
+
+	codeDtos := make([]dto.CouponCodeResponse, len(codes))
+	for i, code := range codes {
+		codeDtos[i] = dto.GetCouponCodeResponse(code)
+	}
+
+	return *dto.SuccessCount(codeDtos, int64(len(codeDtos)))
+}
+
+// ApplyCoupon applies a discount code to the shopper's cart. The code must
+// be usable and apply to the cart as it is; it is redeemed when an order is
+// placed.
+func (s *couponService) ApplyCoupon(userID, guestToken string, req dto.CouponApplyRequest) dto.ResponseDto {
+	if userID == "" && guestToken == "" {
+		return *dto.Fail("Shopper not identified")
+	}
+	db := dbmanager.GetDB()
+
+	var code entity.CouponCode
+	if err := db.Where("code = ?", normalizeCouponCode(req.Code)).First(&code).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return *dto.Fail("Invalid discount code")
+		}
+		logger.Error("Error fetching coupon code: %v", err)
+		return *dto.Fail("Error applying discount code")
+	}
+
+	var cartID string
+	err := db.Transaction(func(tx *gorm.DB) error {
+		cart, err := ICartService.findOrCreateCart(tx, userID, guestToken)
+		if err != nil {
+			return err
+		}
+		cartID = cart.ID
+
+		var count int64
+		if err := tx.Model(&entity.CartCoupon{}).Where("cart_id = ? AND coupon_code_id = ?", cart.ID, code.ID).
+			Count(&count).Error; err != nil {
+			return err
+		}
+		if count > 0 {
+			return errCartFailure{"This code is already applied"}
+		}
+		if err := tx.Create(&entity.CartCoupon{ID: tools.NewUuid(), CartID: cart.ID, CouponCodeID: code.ID}).Error; err != nil {
+			return err
+		}
+
+		// The code is kept only if it takes effect on the cart as it is
+		quote, _, err := IPricingService.quote(tx, ICartService.pricingCart(*cart), nil)
+		if err != nil {
+			return err
+		}
+		for _, coupon := range quote.Coupons {
+			if coupon.CodeID == code.ID && !coupon.Applied {
+				return errCartFailure{coupon.Reason}
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		var failure errCartFailure
+		if errors.As(err, &failure) {
+			return *dto.Fail(failure.message)
+		}
+		logger.Error("Error applying discount code: %v", err)
+		return *dto.Fail("Error applying discount code")
+	}
+
+	return ICartService.getCart(db, cartID, nil)
+}
+
+// RemoveCoupon takes a discount code off the shopper's cart
+func (s *couponService) RemoveCoupon(userID, guestToken, code string) dto.ResponseDto {
+	if userID == "" && guestToken == "" {
+		return *dto.Fail("Shopper not identified")
+	}
+	db := dbmanager.GetDB()
+
+	cart, err := cartStore.Find(db, userID, guestToken)
+	if err != nil {
+		logger.Error("Error fetching cart: %v", err)
+		return *dto.Fail("Error removing discount code")
+	}
+	if cart == nil {
+		return *dto.Fail("Discount code not applied")
+	}
+
+	codeIDs := db.Model(&entity.CouponCode{}).Select("id").Where("code = ?", normalizeCouponCode(code))
+	result := db.Where("cart_id = ? AND coupon_code_id IN (?)", cart.ID, codeIDs).Delete(&entity.CartCoupon{})
+	if result.Error != nil {
+		logger.Error("Error removing discount code: %v", result.Error)
+		return *dto.Fail("Error removing discount code")
+	}
+	if result.RowsAffected == 0 {
+		return *dto.Fail("Discount code not applied")
+	}
+
+	return ICartService.getCart(db, cart.ID, nil)
+}
+
+// unusable returns why a code cannot be redeemed by a user now, or "" if it
+// can. Whether it applies to a cart is up to the pricing engine. Per-customer
+// limits are only checked for signed-in users. code.Coupon must be loaded.
+func (s *couponService) unusable(db *gorm.DB, code *entity.CouponCode, userID string, now time.Time) (string, error) {
+	coupon := code.Coupon
+	switch {
+	case !coupon.IsActive:
+		return "This code is no longer active", nil
+	case coupon.StartsAt != nil && now.Before(*coupon.StartsAt):
+		return "This code is not valid yet", nil
+	case coupon.EndsAt != nil && !now.Before(*coupon.EndsAt):
+		return "This code has expired", nil
+	case coupon.UsageLimit > 0 && coupon.TimesUsed >= coupon.UsageLimit:
+		return "This code has been fully redeemed", nil
+	case code.UsageLimit > 0 && code.TimesUsed >= code.UsageLimit:
+		return "This code has already been used", nil
+	}
+
+	if coupon.PerCustomerLimit > 0 && userID != "" {
+		var count int64
+		if err := db.Model(&entity.CouponRedemption{}).Where("coupon_id = ? AND user_id = ?", coupon.ID, userID).
+			Count(&count).Error; err != nil {
+			return "", err
+		}
+		if count >= int64(coupon.PerCustomerLimit) {
+			return "You have already used this offer", nil
+		}
+	}
+	return "", nil
+}
+
+// redeem records the codes that applied to an order's cart as used and takes
+// every code off the cart. The coupon and code rows are locked and their
+// limits checked again, so concurrent orders cannot redeem a code more often
+// than it allows.
+func (s *couponService) redeem(tx *gorm.DB, cartID, userID, orderID string, coupons []entity.QuoteCoupon) error {
+	var applied []entity.QuoteCoupon
+	for _, coupon := range coupons {
+		if coupon.Applied {
+			applied = append(applied, coupon)
+		}
+	}
+	// Rows are locked in the same order by every order, so they cannot deadlock
+	sort.Slice(applied, func(i, j int) bool {
+		if applied[i].CouponID != applied[j].CouponID {
+			return applied[i].CouponID < applied[j].CouponID
+		}
+		return applied[i].CodeID < applied[j].CodeID
+	})
+
+	now := time.Now().UTC()
+	for _, quoted := range applied {
+		var coupon entity.Coupon
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", quoted.CouponID).First(&coupon).Error; err != nil {
+			return err
+		}
+		var code entity.CouponCode
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", quoted.CodeID).First(&code).Error; err != nil {
+			return err
+		}
+		code.Coupon = &coupon
+
+		// A locking read sees the redemptions of orders committed meanwhile
+		reason, err := s.unusable(tx.Clauses(clause.Locking{Strength: "SHARE"}), &code, userID, now)
+		if err != nil {
+			return err
+		}
+		if reason != "" {
+			return errOrderFailure{message: fmt.Sprintf("Discount code %s: %s", code.Code, reason)}
+		}
+
+		if err := tx.Create(&entity.CouponRedemption{
+			ID:           tools.NewUuid(),
+			CouponID:     coupon.ID,
+			CouponCodeID: code.ID,
+			OrderID:      orderID,
+			UserID:       userID,
+			Amount:       quoted.Amount,
+		}).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&entity.Coupon{}).Where("id = ?", coupon.ID).
+			UpdateColumn("times_used", gorm.Expr("times_used + 1")).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&entity.CouponCode{}).Where("id = ?", code.ID).
+			UpdateColumn("times_used", gorm.Expr("times_used + 1")).Error; err != nil {
+			return err
+		}
+	}
+
+	return tx.Where("cart_id = ?", cartID).Delete(&entity.CartCoupon{}).Error
+}
+
+// releaseOrder gives back the codes an order redeemed, when its payment fails
+// or it expires unpaid
+func (s *couponService) releaseOrder(tx *gorm.DB, orderID string) error {
+	var redemptions []entity.CouponRedemption
+	if err := tx.Where("order_id = ?", orderID).Find(&redemptions).Error; err != nil {
+		return err
+	}
+	for _, redemption := range redemptions {
+		if err := tx.Model(&entity.Coupon{}).Where("id = ? AND times_used > 0", redemption.CouponID).
+			UpdateColumn("times_used", gorm.Expr("times_used - 1")).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&entity.CouponCode{}).Where("id = ? AND times_used > 0", redemption.CouponCodeID).
+			UpdateColumn("times_used", gorm.Expr("times_used - 1")).Error; err != nil {
+			return err
+		}
+	}
+	return tx.Where("order_id = ?", orderID).Delete(&entity.CouponRedemption{}).Error
+}
+
+// moveCart moves the codes applied to one cart to another, e.g. when a
+// guest's cart is merged on sign in. Codes that no longer combine are
+// reported by the pricing engine.
+func (s *couponService) moveCart(tx *gorm.DB, cartID, fromID string) error {
+	var applied []string
+	if err := tx.Model(&entity.CartCoupon{}).Where("cart_id = ?", cartID).Pluck("coupon_code_id", &applied).Error; err != nil {
+		return err
+	}
+	if len(applied) > 0 {
+		if err := tx.Where("cart_id = ? AND coupon_code_id IN ?", fromID, applied).Delete(&entity.CartCoupon{}).Error; err != nil {
+			return err
+		}
+	}
+	return tx.Model(&entity.CartCoupon{}).Where("cart_id = ?", fromID).Update("cart_id", cartID).Error
+}
+
+// findCoupon loads a coupon. On failure it returns nil and the response to send.
+func (s *couponService) findCoupon(db *gorm.DB, id string) (*entity.Coupon, dto.ResponseDto) {
+	var coupon entity.Coupon
+	if err := db.Where("id = ?", id).First(&coupon).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, *dto.Fail("Coupon not found")
+		}
+		logger.Error("Error fetching coupon: %v", err)
+		return nil, *dto.Fail("Error fetching coupon")
+	}
+	return &coupon, dto.ResponseDto{}
+}
+
+// couponResponse returns a coupon with the number of its codes
+func (s *couponService) couponResponse(db *gorm.DB, coupon entity.Coupon) dto.ResponseDto {
+	var count int64
+	if err := db.Model(&entity.CouponCode{}).Where("coupon_id = ?", coupon.ID).Count(&count).Error; err != nil {
+		logger.Error("Error counting coupon codes: %v", err)
+		return *dto.Fail("Error fetching coupon")
+	}
+	return *dto.Success(dto.GetCouponResponse(coupon, count))
+}
+
+// validate returns why a coupon's settings are invalid, or "" if they are valid
+func (s *couponService) validate(coupon entity.Coupon) string {
+	switch coupon.Type {
+	case entity.CouponPercentage:
+		if coupon.Value <= 0 || coupon.Value > 100 {
+			return "A percentage discount must be more than 0 and at most 100"
+		}
+	case entity.CouponFixedAmount:
+		if coupon.Value <= 0 {
+			return "A fixed amount discount must be more than 0"
+		}
+	}
+	if coupon.StartsAt != nil && coupon.EndsAt != nil && !coupon.EndsAt.After(*coupon.StartsAt) {
+		return "The validity window must end after it starts"
+	}
+	return ""
+}
+
+// normalizeCouponCode returns a code as stored: trimmed and in upper case,
+// so customers can type it in any case
+func normalizeCouponCode(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
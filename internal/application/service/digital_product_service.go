@@ -0,0 +1,409 @@
+package service
+
+import (
+	"context"
+	"mime/multipart"
+	"path"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"backend-ecommerce/internal/application/dto"
+	"backend-ecommerce/internal/application/entity"
+	"backend-ecommerce/internal/application/tools"
+	"backend-ecommerce/internal/infrastructure/dbmanager"
+	"backend-ecommerce/internal/infrastructure/logger"
+	"backend-ecommerce/internal/infrastructure/storage"
+)
+
+const (
+	// maxDigitalAssetSize is the largest accepted digital asset upload (2 GB)
+	maxDigitalAssetSize = 2 << 30
+	digitalAssetFolder  = "digital"
+	// downloadLinkTTL is how long a generated download URL stays valid
+	downloadLinkTTL = 5 * time.Minute
+)
+
+type digitalProductService struct {
+}
+
+// GetDigitalAssets returns the files attached to a digital product
+func (s *digitalProductService) GetDigitalAssets(productID string) dto.ResponseDto {
+	var assets []entity.DigitalAsset
+
+	db := dbmanager.GetDB()
+	if err := db.Where("product_id = ?", productID).Order("created_at ASC").Find(&assets).Error; err != nil {
+		logger.Error("Error fetching digital assets: %v", err)
+		return *dto.Fail("Error fetching digital assets")
+	}
+
+	assetDtos := make([]dto.DigitalAssetResponse, len(assets))
+	for i, asset := range assets {
+		assetDtos[i] = dto.GetDigitalAssetResponse(asset)
+	}
+
+	return *dto.SuccessCount(assetDtos, int64(len(assetDtos)))
+}
+
+// UploadDigitalAsset stores a file in private storage and attaches it to a
+// digital product. Only digital products can carry downloadable files.
+func (s *digitalProductService) UploadDigitalAsset(ctx context.Context, productID string, file multipart.File, header *multipart.FileHeader, req dto.DigitalAssetUploadRequest) dto.ResponseDto {
+	db := dbmanager.GetDB()
+
+	var product entity.Product
+	if err := db.Select("id", "type").Where("id = ?", productID).First(&product).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return *dto.Fail("Product not found")
+		}
+		logger.Error("Error fetching product: %v", err)
+		return *dto.Fail("Error fetching product")
+	}
+	if product.Type != entity.ProductTypeDigital {
+		return *dto.Fail("Files can only be attached to digital products")
+	}
+
+	if header.Size > maxDigitalAssetSize {
+		return *dto.Fail("File exceeds the maximum size of 2 GB")
+	}
+
+	fileName := path.Base(strings.ReplaceAll(header.Filename, "\\", "/"))
+	if fileName == "." || fileName == "/" {
+		return *dto.Fail("Invalid file name")
+	}
+	mimeType := header.Header.Get("Content-Type")
+	if mimeType == "" {
+		mimeType = "application/octet-stream"
+	}
+
+	assetID := tools.NewUuid()
+	key := path.Join(storage.PrivatePrefix, digitalAssetFolder, productID, assetID, fileName)
+
+	object, err := storage.GetStorage().Put(ctx, key, file, header.Size, mimeType)
+	if err != nil {
+		logger.Error("Error storing digital asset: %v", err)
+		return *dto.Fail("Error storing file")
+	}
+
+	asset := entity.DigitalAsset{
+		ID:            assetID,
+		ProductID:     productID,
+		FileName:      fileName,
+		StorageKey:    key,
+		MimeType:      mimeType,
+		FileSize:      object.Size,
+		DownloadLimit: req.DownloadLimit,
+		AccessDays:    req.AccessDays,
+	}
+	if err := db.Create(&asset).Error; err != nil {
+		logger.Error("Error creating digital asset: %v", err)
+		if err := storage.GetStorage().Delete(ctx, key); err != nil {
+			logger.Error("Error deleting stored file %s: %v", key, err)
+		}
+		return *dto.Fail("Error creating digital asset")
+	}
+
+	return *dto.Success(dto.GetDigitalAssetResponse(asset))
+}
+
+// UpdateDigitalAsset changes the download limits of an asset. Existing
+// entitlements keep the limits they were granted with.
+func (s *digitalProductService) UpdateDigitalAsset(assetID string, req dto.DigitalAssetUpdateRequest) dto.ResponseDto {
+	db := dbmanager.GetDB()
+
+	var asset entity.DigitalAsset
+	if err := db.Where("id = ?", assetID).First(&asset).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return *dto.Fail("Digital asset not found")
+		}
+		logger.Error("Error fetching digital asset: %v", err)
+		return *dto.Fail("Error fetching digital asset")
+	}
+
+	asset.DownloadLimit = req.DownloadLimit
+	asset.AccessDays = req.AccessDays
+	if err := db.Save(&asset).Error; err != nil {
+		logger.Error("Error updating digital asset: %v", err)
+		return *dto.Fail("Error updating digital asset")
+	}
+
+	return *dto.Success(dto.GetDigitalAssetResponse(asset))
+}
+
+// DeleteDigitalAsset removes an asset that nobody has bought yet
+func (s *digitalProductService) DeleteDigitalAsset(ctx context.Context, assetID string) dto.ResponseDto {
+	db := dbmanager.GetDB()
+
+	var asset entity.DigitalAsset
+	if err := db.Where("id = ?", assetID).First(&asset).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return *dto.Fail("Digital asset not found")
+		}
+		logger.Error("Error fetching digital asset: %v", err)
+		return *dto.Fail("Error fetching digital asset")
+	}
+
+	var entitlements int64
+	if err := db.Model(&entity.DownloadEntitlement{}).Where("asset_id = ?", assetID).Count(&entitlements).Error; err != nil {
+		logger.Error("Error counting download entitlements: %v", err)
+		return *dto.Fail("Error deleting digital asset")
+	}
+	if entitlements > 0 {
+		return *dto.Fail("The file has already been sold and cannot be deleted")
+	}
+
+	if err := db.Delete(&asset).Error; err != nil {
+		logger.Error("Error deleting digital asset: %v", err)
+		return *dto.Fail("Error deleting digital asset")
+	}
+	if err := storage.GetStorage().Delete(ctx, asset.StorageKey); err != nil {
+		logger.Error("Error deleting stored file %s: %v", asset.StorageKey, err)
+	}
+
+	return *dto.Success("Digital asset deleted successfully")
+}
+
+// AddLicenseKeys adds keys to a product's licence key pool, skipping any
+// that are already in it
+func (s *digitalProductService) AddLicenseKeys(productID string, keys []string) dto.ResponseDto {
+	db := dbmanager.GetDB()
+
+	var count int64
+	if err := db.Model(&entity.Product{}).Where("id = ?", productID).Count(&count).Error; err != nil {
+		logger.Error("Error fetching product: %v", err)
+		return *dto.Fail("Error fetching product")
+	}
+	if count == 0 {
+		return *dto.Fail("Product not found")
+	}
+
+	seen := make(map[string]bool, len(keys))
+	licenseKeys := make([]entity.LicenseKey, 0, len(keys))
+	for _, key := range keys {
+		key = strings.TrimSpace(key)
+		if key == "" || seen[key] {
+			continue
+		}
+		seen[key] = true
+		licenseKeys = append(licenseKeys, entity.LicenseKey{
+			ID:        tools.NewUuid(),
+			ProductID: productID,
+			Key:       key,
+		})
+	}
+	if len(licenseKeys) == 0 {
+		return *dto.Fail("No licence keys provided")
+	}
+
+	if err := db.Clauses(clause.OnConflict{DoNothing: true}).Create(&licenseKeys).Error; err != nil {
+		logger.Error("Error adding licence keys: %v", err)
+		return *dto.Fail("Error adding licence keys")
+	}
+
+	return s.GetLicenseKeyStock(productID)
+}
+
+// GetLicenseKeyStock reports how many keys of a product are still unassigned
+func (s *digitalProductService) GetLicenseKeyStock(productID string) dto.ResponseDto {
+	db := dbmanager.GetDB()
+
+	stock := dto.LicenseKeyStockResponse{ProductID: productID}
+	if err := db.Model(&entity.LicenseKey{}).Where("product_id = ?", productID).Count(&stock.Total).Error; err != nil {
+		logger.Error("Error counting licence keys: %v", err)
+		return *dto.Fail("Error fetching licence keys")
+	}
+	if err := db.Model(&entity.LicenseKey{}).Where("product_id = ? AND order_item_id IS NOT NULL", productID).Count(&stock.Assigned).Error; err != nil {
+		logger.Error("Error counting licence keys: %v", err)
+		return *dto.Fail("Error fetching licence keys")
+	}
+	stock.Available = stock.Total - stock.Assigned
+
+	return *dto.Success(stock)
+}
+
+// GrantDownloads gives the buyer of a paid order access to the files of
+// every digital product in it and hands out one licence key per unit from
+// the product's pool, if it has one. It is safe to call again: existing
+// entitlements are kept and only missing keys are assigned, so it can be
+// re-run after an exhausted key pool has been topped up.
+func (s *digitalProductService) GrantDownloads(tx *gorm.DB, orderID string) error {
+	var order entity.Order
+	if err := tx.Preload("Items.Product").Where("id = ?", orderID).First(&order).Error; err != nil {
+		return err
+	}
+	if order.UserID == nil {
+		return nil
+	}
+
+	now := time.Now().UTC()
+	for _, item := range order.Items {
+		if item.Product == nil || item.Product.Type != entity.ProductTypeDigital {
+			continue
+		}
+
+		var assets []entity.DigitalAsset
+		if err := tx.Where("product_id = ?", item.Product.ID).Find(&assets).Error; err != nil {
+			return err
+		}
+		for _, asset := range assets {
+			entitlement := entity.DownloadEntitlement{
+				ID:            tools.NewUuid(),
+				UserID:        *order.UserID,
+				OrderID:       order.ID,
+				OrderItemID:   item.ID,
+				AssetID:       asset.ID,
+				DownloadLimit: asset.DownloadLimit * item.Quantity,
+			}
+			if asset.AccessDays > 0 {
+				expiresAt := now.AddDate(0, 0, asset.AccessDays)
+				entitlement.ExpiresAt = &expiresAt
+			}
+			if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&entitlement).Error; err != nil {
+				return err
+			}
+		}
+
+		if err := s.assignLicenseKeys(tx, item, now); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// assignLicenseKeys hands out unassigned keys from the product's pool until
+// the order item holds one key per unit
+func (s *digitalProductService) assignLicenseKeys(tx *gorm.DB, item entity.OrderItem, t time.Time) error {
+	var assigned int64
+	if err := tx.Model(&entity.LicenseKey{}).Where("order_item_id = ?", item.ID).Count(&assigned).Error; err != nil {
+		return err
+	}
+	missing := item.Quantity - int(assigned)
+	if missing <= 0 {
+		return nil
+	}
+
+	var keys []entity.LicenseKey
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("product_id = ? AND order_item_id IS NULL", item.Product.ID).
+		Order("created_at ASC").
+		Limit(missing).
+		Find(&keys).Error; err != nil {
+		return err
+	}
+	if len(keys) == 0 {
+		var poolSize int64
+		if err := tx.Model(&entity.LicenseKey{}).Where("product_id = ?", item.Product.ID).Count(&poolSize).Error; err != nil {
+			return err
+		}
+		if poolSize == 0 {
+			// The product is not sold with licence keys
+			return nil
+		}
+	}
+	if len(keys) < missing {
+		logger.Warn("Licence key pool of product %s is exhausted, order item %s is missing %d keys", item.Product.ID, item.ID, missing-len(keys))
+	}
+
+	ids := make([]string, len(keys))
+	for i, key := range keys {
+		ids[i] = key.ID
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+	return tx.Model(&entity.LicenseKey{}).Where("id IN ?", ids).Updates(map[string]interface{}{
+		"order_item_id": item.ID,
+		"assigned_at":   t,
+	}).Error
+}
+
+// GetUserDownloads lists the downloads and licence keys a user has bought
+func (s *digitalProductService) GetUserDownloads(userID string) dto.ResponseDto {
+	db := dbmanager.GetDB()
+
+	var entitlements []entity.DownloadEntitlement
+	if err := db.Preload("Asset").
+		Where("user_id = ?", userID).
+		Order("created_at DESC").
+		Find(&entitlements).Error; err != nil {
+		logger.Error("Error fetching downloads: %v", err)
+		return *dto.Fail("Error fetching downloads")
+	}
+
+	itemIDs := make([]string, 0, len(entitlements))
+	for _, entitlement := range entitlements {
+		itemIDs = append(itemIDs, entitlement.OrderItemID)
+	}
+	var keys []entity.LicenseKey
+	if len(itemIDs) > 0 {
+		if err := db.Where("order_item_id IN ?", itemIDs).Order("assigned_at ASC").Find(&keys).Error; err != nil {
+			logger.Error("Error fetching licence keys: %v", err)
+			return *dto.Fail("Error fetching downloads")
+		}
+	}
+	keysByItem := make(map[string][]string)
+	for _, key := range keys {
+		keysByItem[*key.OrderItemID] = append(keysByItem[*key.OrderItemID], key.Key)
+	}
+
+	now := time.Now().UTC()
+	downloadDtos := make([]dto.DownloadResponse, len(entitlements))
+	for i, entitlement := range entitlements {
+		downloadDtos[i] = dto.GetDownloadResponse(entitlement, keysByItem[entitlement.OrderItemID], now)
+	}
+
+	return *dto.SuccessCount(downloadDtos, int64(len(downloadDtos)))
+}
+
+// CreateDownloadLink uses up one download of an entitlement and returns a
+// short-lived signed URL to the file
+func (s *digitalProductService) CreateDownloadLink(ctx context.Context, userID, entitlementID string) dto.ResponseDto {
+	db := dbmanager.GetDB()
+
+	var entitlement entity.DownloadEntitlement
+	if err := db.Preload("Asset").Where("id = ? AND user_id = ?", entitlementID, userID).First(&entitlement).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return *dto.Fail("Download not found")
+		}
+		logger.Error("Error fetching download: %v", err)
+		return *dto.Fail("Error fetching download")
+	}
+	if entitlement.Asset == nil {
+		return *dto.Fail("The file is no longer available")
+	}
+
+	now := time.Now().UTC()
+	if entitlement.ExpiresAt != nil && !now.Before(*entitlement.ExpiresAt) {
+		return *dto.Fail("Download has expired")
+	}
+
+	// Count the download atomically so concurrent requests cannot exceed the limit
+	result := db.Model(&entity.DownloadEntitlement{}).
+		Where("id = ? AND (download_limit = 0 OR download_count < download_limit)", entitlement.ID).
+		Updates(map[string]interface{}{
+			"download_count":     gorm.Expr("download_count + 1"),
+			"last_downloaded_at": now,
+		})
+	if result.Error != nil {
+		logger.Error("Error recording download: %v", result.Error)
+		return *dto.Fail("Error creating download link")
+	}
+	if result.RowsAffected == 0 {
+		return *dto.Fail("Download limit reached")
+	}
+
+	url, err := storage.GetStorage().SignedURL(ctx, entitlement.Asset.StorageKey, downloadLinkTTL)
+	if err != nil {
+		logger.Error("Error signing download URL: %v", err)
+		db.Model(&entity.DownloadEntitlement{}).Where("id = ?", entitlement.ID).
+			Update("download_count", gorm.Expr("download_count - 1"))
+		return *dto.Fail("Error creating download link")
+	}
+
+	return *dto.Success(dto.DownloadLinkResponse{
+		URL:       url,
+		ExpiresAt: now.Add(downloadLinkTTL).Format(time.RFC3339),
+	})
+}
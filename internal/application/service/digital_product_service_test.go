@@ -0,0 +1,233 @@
+package service
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	stripeapi "github.com/stripe/stripe-go/v76"
+	"github.com/stripe/stripe-go/v76/webhook"
+	"gorm.io/gorm"
+
+	"backend-ecommerce/internal/application/entity"
+	"backend-ecommerce/internal/infrastructure/dbmanager"
+	"backend-ecommerce/internal/infrastructure/stripe"
+)
+
+const testWebhookSecret = "whsec_test"
+
+// newOrderTestDB opens a test database with the tables paying for an order
+// touches, and makes it the application's database
+func newOrderTestDB(t *testing.T) *gorm.DB {
+	db := newTestDB(t,
+		`CREATE TABLE products (
+			id varchar(36) PRIMARY KEY,
+			name varchar(255) NOT NULL,
+			type varchar(20) NOT NULL DEFAULT 'simple'
+		)`,
+		`CREATE TABLE orders (
+			id varchar(36) PRIMARY KEY,
+			user_id varchar(36),
+			status varchar(20) DEFAULT 'pending',
+			subtotal decimal(12,2) NOT NULL DEFAULT 0,
+			discount_total decimal(12,2) NOT NULL DEFAULT 0,
+			shipping_total decimal(12,2) NOT NULL DEFAULT 0,
+			tax_total decimal(12,2) NOT NULL DEFAULT 0,
+			gift_card_total decimal(12,2) NOT NULL DEFAULT 0,
+			total_amount decimal(12,2) NOT NULL,
+			currency varchar(10) NOT NULL DEFAULT 'USD',
+			shipping_address_id varchar(36),
+			billing_address_id varchar(36),
+			created_at datetime,
+			updated_at datetime
+		)`,
+		`CREATE TABLE order_items (
+			id varchar(36) PRIMARY KEY,
+			order_id varchar(36) NOT NULL,
+			product_id varchar(36),
+			variant_id varchar(36),
+			parent_item_id varchar(36),
+			product_name varchar(255) NOT NULL,
+			sku varchar(100),
+			quantity int NOT NULL,
+			unit_price decimal(12,2) NOT NULL,
+			total_price decimal(12,2) NOT NULL,
+			created_at datetime
+		)`,
+		`CREATE TABLE orderAdjustments (
+			id varchar(36) PRIMARY KEY,
+			order_id varchar(36) NOT NULL,
+			order_item_id varchar(36),
+			type varchar(20),
+			source varchar(255),
+			label varchar(255),
+			amount decimal(12,2),
+			created_at datetime
+		)`,
+		`CREATE TABLE payments (
+			id varchar(36) PRIMARY KEY,
+			order_id varchar(36) NOT NULL,
+			provider varchar(50) DEFAULT 'stripe',
+			provider_payment_id varchar(255),
+			amount decimal(12,2) NOT NULL,
+			currency varchar(10) NOT NULL DEFAULT 'USD',
+			status varchar(20) DEFAULT 'initiated',
+			transaction_id varchar(255),
+			metadata text,
+			raw_response text,
+			created_at datetime,
+			updated_at datetime
+		)`,
+		`CREATE TABLE inventoryReservations (
+			id varchar(36) PRIMARY KEY,
+			order_id varchar(36) NOT NULL,
+			location_id varchar(36),
+			product_id varchar(36) NOT NULL,
+			variant_id varchar(36),
+			quantity int NOT NULL,
+			status varchar(20),
+			expires_at datetime NOT NULL,
+			created_at datetime,
+			updated_at datetime
+		)`,
+		`CREATE TABLE digitalAssets (
+			id varchar(36) PRIMARY KEY,
+			product_id varchar(36) NOT NULL,
+			file_name varchar(255) NOT NULL,
+			storage_key varchar(500) NOT NULL,
+			mime_type varchar(100),
+			file_size bigint,
+			download_limit int NOT NULL DEFAULT 0,
+			access_days int NOT NULL DEFAULT 0,
+			created_at datetime,
+			updated_at datetime
+		)`,
+		`CREATE TABLE downloadEntitlements (
+			id varchar(36) PRIMARY KEY,
+			user_id varchar(36) NOT NULL,
+			order_id varchar(36) NOT NULL,
+			order_item_id varchar(36) NOT NULL,
+			asset_id varchar(36) NOT NULL,
+			download_limit int NOT NULL DEFAULT 0,
+			download_count int NOT NULL DEFAULT 0,
+			expires_at datetime,
+			last_downloaded_at datetime,
+			created_at datetime,
+			UNIQUE (order_item_id, asset_id)
+		)`,
+		`CREATE TABLE licenseKeys (
+			id varchar(36) PRIMARY KEY,
+			product_id varchar(36) NOT NULL,
+			license_key varchar(255) NOT NULL,
+			order_item_id varchar(36),
+			assigned_at datetime,
+			created_at datetime
+		)`,
+		`CREATE TABLE giftCardProducts (
+			product_id varchar(36) PRIMARY KEY,
+			validity_days int,
+			created_at datetime,
+			updated_at datetime
+		)`,
+	)
+	dbmanager.SetDB(db)
+	t.Cleanup(func() { dbmanager.SetDB(nil) })
+	return db
+}
+
+// sendStripeEvent signs a payment intent event as Stripe would and hands it
+// to the webhook
+func sendStripeEvent(t *testing.T, eventType, intentID string, amountReceived int64) error {
+	t.Helper()
+	payload, err := json.Marshal(map[string]interface{}{
+		"id":          "evt_" + intentID,
+		"object":      "event",
+		"api_version": stripeapi.APIVersion,
+		"type":        eventType,
+		"data": map[string]interface{}{
+			"object": map[string]interface{}{
+				"id":              intentID,
+				"object":          "payment_intent",
+				"amount":          amountReceived,
+				"amount_received": amountReceived,
+				"currency":        "usd",
+			},
+		},
+	})
+	if err != nil {
+		t.Fatalf("encode event: %v", err)
+	}
+	signed := webhook.GenerateTestSignedPayload(&webhook.UnsignedPayload{Payload: payload, Secret: testWebhookSecret})
+	return IPaymentService.HandleWebhook(signed.Payload, signed.Header)
+}
+
+func TestStripeCompletionGrantsDownloads(t *testing.T) {
+	stripe.NewStripeManager("sk_test", testWebhookSecret)
+	db := newOrderTestDB(t)
+
+	userID := "user-1"
+	productID := "product-1"
+	seed := []interface{}{
+		&entity.Order{ID: "order-1", UserID: &userID, Status: entity.OrderStatusPending, TotalAmount: 25, Currency: "USD"},
+		&entity.OrderItem{ID: "item-1", OrderID: "order-1", ProductID: &productID, ProductName: "E-book", Quantity: 2, UnitPrice: 12.5, TotalPrice: 25},
+		&entity.DigitalAsset{ID: "asset-1", ProductID: productID, FileName: "book.pdf", StorageKey: "private/book.pdf", DownloadLimit: 3},
+		&entity.LicenseKey{ID: "key-1", ProductID: productID, Key: "AAAA-1111"},
+		&entity.LicenseKey{ID: "key-2", ProductID: productID, Key: "BBBB-2222"},
+		&entity.LicenseKey{ID: "key-3", ProductID: productID, Key: "CCCC-3333"},
+		&entity.Payment{ID: "payment-1", OrderID: "order-1", Provider: entity.PaymentProviderStripe, ProviderPaymentID: "pi_1",
+			Amount: 25, Currency: "USD", Status: entity.PaymentStatusInitiated},
+	}
+	if err := db.Exec("INSERT INTO products (id, name, type) VALUES (?, ?, ?)", productID, "E-book", entity.ProductTypeDigital).Error; err != nil {
+		t.Fatalf("seed product: %v", err)
+	}
+	for _, row := range seed {
+		if err := db.Create(row).Error; err != nil {
+			t.Fatalf("seed %T: %v", row, err)
+		}
+	}
+
+	if err := IPaymentService.HandleWebhook([]byte(`{"type":"payment_intent.succeeded"}`), "t=1,v1=bad"); !errors.Is(err, ErrInvalidWebhook) {
+		t.Fatalf("unsigned event: err = %v, want ErrInvalidWebhook", err)
+	}
+
+	// Stripe may deliver an event more than once; the replay changes nothing
+	for i := 0; i < 2; i++ {
+		if err := sendStripeEvent(t, "payment_intent.succeeded", "pi_1", 2500); err != nil {
+			t.Fatalf("delivery %d: %v", i+1, err)
+		}
+	}
+
+	var order entity.Order
+	if err := db.Where("id = ?", "order-1").First(&order).Error; err != nil {
+		t.Fatalf("read order: %v", err)
+	}
+	if order.Status != entity.OrderStatusPaid {
+		t.Errorf("order status = %s, want paid", order.Status)
+	}
+	var payment entity.Payment
+	if err := db.Where("id = ?", "payment-1").First(&payment).Error; err != nil {
+		t.Fatalf("read payment: %v", err)
+	}
+	if payment.Status != entity.PaymentStatusSucceeded {
+		t.Errorf("payment status = %s, want succeeded", payment.Status)
+	}
+
+	var entitlements []entity.DownloadEntitlement
+	if err := db.Where("order_id = ?", "order-1").Find(&entitlements).Error; err != nil {
+		t.Fatalf("read entitlements: %v", err)
+	}
+	if len(entitlements) != 1 {
+		t.Fatalf("got %d entitlements, want 1", len(entitlements))
+	}
+	if got := entitlements[0]; got.UserID != userID || got.AssetID != "asset-1" || got.DownloadLimit != 6 {
+		t.Errorf("entitlement = %+v, want asset-1 for user-1 with 6 downloads", got)
+	}
+
+	var keys int64
+	if err := db.Model(&entity.LicenseKey{}).Where("order_item_id = ?", "item-1").Count(&keys).Error; err != nil {
+		t.Fatalf("count licence keys: %v", err)
+	}
+	if keys != 2 {
+		t.Errorf("order item holds %d licence keys, want 2", keys)
+	}
+}
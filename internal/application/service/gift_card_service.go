@@ -0,0 +1,570 @@
+package service
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"backend-ecommerce/internal/application/dto"
+	"backend-ecommerce/internal/application/entity"
+	"backend-ecommerce/internal/application/tools"
+	"backend-ecommerce/internal/infrastructure/cachemanager"
+	"backend-ecommerce/internal/infrastructure/config"
+	"backend-ecommerce/internal/infrastructure/dbmanager"
+	"backend-ecommerce/internal/infrastructure/logger"
+)
+
+const (
+	// giftCardCodeLength is the length of a gift card code, without dashes
+	giftCardCodeLength = 16
+	giftCardKind       = "gift_card"
+	// Cache keys of the balance check counters, per client IP
+	giftCardChecksPrefix   = "gift_card_checks:"
+	giftCardFailuresPrefix = "gift_card_failures:"
+)
+
+type giftCardService struct {
+}
+
+// GetGiftCards returns the gift cards, newest first
+func (s *giftCardService) GetGiftCards(page, pageSize int) dto.ResponseDto {
+	db := dbmanager.GetDB()
+
+	var total int64
+	if err := db.Model(&entity.GiftCard{}).Count(&total).Error; err != nil {
+		logger.Error("Error counting gift cards: %v", err)
+		return *dto.Fail("Error fetching gift cards")
+	}
+
+	var cards []entity.GiftCard
+	if err := db.Order("created_at DESC").Offset((page - 1) * pageSize).Limit(pageSize).Find(&cards).Error; err != nil {
+		logger.Error("Error fetching gift cards: %v", err)
+		return *dto.Fail("Error fetching gift cards")
+	}
+
+	cardDtos := make([]dto.GiftCardResponse, len(cards))
+	for i, card := range cards {
+		cardDtos[i] = dto.GetGiftCardResponse(card)
+	}
+
+	return *dto.SuccessCount(cardDtos, total)
+}
+
+// GetGiftCard returns a gift card with its ledger
+func (s *giftCardService) GetGiftCard(id string) dto.ResponseDto {
+	db := dbmanager.GetDB()
+
+	var card entity.GiftCard
+	if err := db.Preload("Transactions", func(db *gorm.DB) *gorm.DB { return db.Order("created_at ASC") }).
+		Where("id = ?", id).First(&card).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return *dto.Fail("Gift card not found")
+		}
+		logger.Error("Error fetching gift card: %v", err)
+		return *dto.Fail("Error fetching gift card")
+	}
+
+	return *dto.Success(dto.GetGiftCardResponse(card))
+}
+
+// IssueGiftCard issues a gift card, e.g. as a goodwill gesture. The code is
+// returned only in this response; when a customer is given it is also
+// emailed to them.
+func (s *giftCardService) IssueGiftCard(req dto.GiftCardIssueRequest) dto.ResponseDto {
+	db := dbmanager.GetDB()
+
+	currency := strings.ToUpper(req.Currency)
+	if currency == "" {
+		currency = strings.ToUpper(config.Get().Pricing.Currency)
+	}
+	if req.ExpiresAt != nil && !req.ExpiresAt.After(time.Now().UTC()) {
+		return *dto.Fail("The expiry must be in the future")
+	}
+
+	card := entity.GiftCard{
+		Currency:  currency,
+		Balance:   tools.RoundPrice(req.Amount),
+		ExpiresAt: req.ExpiresAt,
+		Source:    entity.GiftCardSourceAdmin,
+		Note:      req.Note,
+	}
+	if req.UserID != "" {
+		var count int64
+		if err := db.Model(&entity.User{}).Where("id = ?", req.UserID).Count(&count).Error; err != nil {
+			logger.Error("Error checking user: %v", err)
+			return *dto.Fail("Error issuing gift card")
+		}
+		if count == 0 {
+			return *dto.Fail("User not found")
+		}
+		card.UserID = &req.UserID
+	}
+
+	var code string
+	err := db.Transaction(func(tx *gorm.DB) error {
+		var err error
+		code, err = s.issue(tx, &card, nil)
+		return err
+	})
+	if err != nil {
+		logger.Error("Error issuing gift card: %v", err)
+		return *dto.Fail("Error issuing gift card")
+	}
+
+	return *dto.Success(dto.GiftCardIssuedResponse{GiftCardResponse: dto.GetGiftCardResponse(card), Code: code})
+}
+
+// UpdateGiftCard disables or re-enables a gift card, or changes its expiry
+// or note
+func (s *giftCardService) UpdateGiftCard(id string, req dto.GiftCardUpdateRequest) dto.ResponseDto {
+	db := dbmanager.GetDB()
+
+	var card entity.GiftCard
+	if err := db.Where("id = ?", id).First(&card).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return *dto.Fail("Gift card not found")
+		}
+		logger.Error("Error fetching gift card: %v", err)
+		return *dto.Fail("Error updating gift card")
+	}
+
+	updates := map[string]interface{}{}
+	if req.IsActive != nil {
+		updates["is_active"] = *req.IsActive
+	}
+	if req.ExpiresAt != nil {
+		updates["expires_at"] = *req.ExpiresAt
+	}
+	if req.Note != nil {
+		updates["note"] = *req.Note
+	}
+	// The balance is left alone: it only changes with ledger entries
+	if len(updates) > 0 {
+		if err := db.Model(&card).Updates(updates).Error; err != nil {
+			logger.Error("Error updating gift card: %v", err)
+			return *dto.Fail("Error updating gift card")
+		}
+	}
+
+	return s.GetGiftCard(card.ID)
+}
+
+// CheckBalance returns the balance of a gift card and whether it can be used.
+// Checks are limited per client IP, and an IP that has tried too many unknown
+// codes is refused until its failures expire, so codes cannot be guessed.
+func (s *giftCardService) CheckBalance(clientIP string, req dto.GiftCardBalanceRequest) dto.ResponseDto {
+	db := dbmanager.GetDB()
+
+	limits := config.Get().GiftCard
+	failures, _ := cachemanager.Get(giftCardFailuresPrefix + clientIP)
+	if failed, _ := strconv.Atoi(failures); failed >= limits.MaxFailedChecks ||
+		cachemanager.Incr(giftCardChecksPrefix+clientIP, time.Minute) > int64(limits.BalanceChecksPerMinute) {
+		return *dto.Fail("Too many gift card checks, please try again later")
+	}
+
+	var card entity.GiftCard
+	if err := db.Where("code_hash = ?", hashGiftCardCode(req.Code)).First(&card).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			cachemanager.Incr(giftCardFailuresPrefix+clientIP, limits.FailedCheckWindow)
+			return *dto.Fail("Gift card not found")
+		}
+		logger.Error("Error fetching gift card: %v", err)
+		return *dto.Fail("Error checking gift card")
+	}
+
+	currency := strings.ToUpper(config.Get().Pricing.Currency)
+	reason := s.unusable(card, currency, time.Now().UTC())
+	response := dto.GiftCardBalanceResponse{
+		Last4:    card.Last4,
+		Currency: card.Currency,
+		Balance:  card.Balance,
+		Usable:   reason == "",
+		Reason:   reason,
+	}
+	if card.ExpiresAt != nil {
+		expiresAt := card.ExpiresAt.Format(time.RFC3339)
+		response.ExpiresAt = &expiresAt
+	}
+	return *dto.Success(response)
+}
+
+// SetGiftCardProduct sells a digital product as a gift card. Each unit bought
+// issues a card worth the unit price, emailed to the buyer once the order
+// is paid.
+func (s *giftCardService) SetGiftCardProduct(productID string, req dto.GiftCardProductRequest) dto.ResponseDto {
+	db := dbmanager.GetDB()
+
+	var product entity.Product
+	if err := db.Select("id", "type").Where("id = ?", productID).First(&product).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return *dto.Fail("Product not found")
+		}
+		logger.Error("Error fetching product: %v", err)
+		return *dto.Fail("Error updating gift card product")
+	}
+	if product.Type != entity.ProductTypeDigital {
+		return *dto.Fail("Only digital products can be sold as gift cards")
+	}
+
+	giftCardProduct := entity.GiftCardProduct{ProductID: productID, ValidityDays: req.ValidityDays}
+	if err := db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "product_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"validity_days", "updated_at"}),
+	}).Create(&giftCardProduct).Error; err != nil {
+		logger.Error("Error saving gift card product: %v", err)
+		return *dto.Fail("Error updating gift card product")
+	}
+
+	return *dto.Success(dto.GiftCardProductResponse{ProductID: productID, ValidityDays: req.ValidityDays})
+}
+
+// RemoveGiftCardProduct stops issuing gift cards for a product. Cards
+// already issued are kept.
+func (s *giftCardService) RemoveGiftCardProduct(productID string) dto.ResponseDto {
+	result := dbmanager.GetDB().Where("product_id = ?", productID).Delete(&entity.GiftCardProduct{})
+	if result.Error != nil {
+		logger.Error("Error removing gift card product: %v", result.Error)
+		return *dto.Fail("Error updating gift card product")
+	}
+	if result.RowsAffected == 0 {
+		return *dto.Fail("The product is not sold as a gift card")
+	}
+
+	return *dto.Success("Gift card product removed successfully")
+}
+
+// redeem pays for a new order with gift cards, in the order the codes were
+// given, until the order is paid. Each card used gets a payment and a ledger
+// entry; the cards are locked so a balance is never spent twice. It returns
+// the amount paid, and errOrderFailure for codes that cannot be used.
+func (s *giftCardService) redeem(tx *gorm.DB, order *entity.Order, codes []string) (float64, error) {
+	if len(codes) == 0 {
+		return 0, nil
+	}
+	hashes := make([]string, 0, len(codes))
+	seen := make(map[string]bool, len(codes))
+	for _, code := range codes {
+		hash := hashGiftCardCode(code)
+		if !seen[hash] {
+			seen[hash] = true
+			hashes = append(hashes, hash)
+		}
+	}
+
+	// Cards are locked in ID order to avoid deadlocks between checkouts
+	var cards []entity.GiftCard
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("code_hash IN ?", hashes).
+		Order("id ASC").Find(&cards).Error; err != nil {
+		return 0, err
+	}
+	byHash := make(map[string]*entity.GiftCard, len(cards))
+	for i := range cards {
+		byHash[cards[i].CodeHash] = &cards[i]
+	}
+
+	now := time.Now().UTC()
+	total := toCents(order.TotalAmount)
+	remaining := total
+	for _, hash := range hashes {
+		card, ok := byHash[hash]
+		if !ok {
+			return 0, errOrderFailure{message: "Gift card not found; please check the code"}
+		}
+		if reason := s.unusable(*card, order.Currency, now); reason != "" {
+			return 0, errOrderFailure{message: fmt.Sprintf("Gift card ending in %s: %s", card.Last4, reason)}
+		}
+		if remaining == 0 {
+			break
+		}
+
+		amount := min(toCents(card.Balance), remaining)
+		remaining -= amount
+		payment := entity.Payment{
+			ID:                tools.NewUuid(),
+			OrderID:           order.ID,
+			Provider:          entity.PaymentProviderGiftCard,
+			ProviderPaymentID: card.ID,
+			Amount:            fromCents(amount),
+			Currency:          order.Currency,
+			Status:            entity.PaymentStatusSucceeded,
+		}
+		if err := tx.Create(&payment).Error; err != nil {
+			return 0, err
+		}
+		if err := s.post(tx, card, entity.GiftCardRedeem, -amount, &order.ID, &payment.ID, ""); err != nil {
+			return 0, err
+		}
+	}
+	return fromCents(total - remaining), nil
+}
+
+// release gives back to the cards what an order took when the rest of its
+// payment failed or the order expired unpaid
+func (s *giftCardService) release(tx *gorm.DB, orderID, note string) error {
+	var payments []entity.Payment
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("order_id = ? AND provider = ? AND status IN ?", orderID, entity.PaymentProviderGiftCard,
+			[]entity.PaymentStatus{entity.PaymentStatusSucceeded, entity.PaymentStatusPartiallyRefunded}).
+		Order("id ASC").Find(&payments).Error; err != nil {
+		return err
+	}
+
+	for _, payment := range payments {
+		refunded, err := s.refunded(tx, payment.ID)
+		if err != nil {
+			return err
+		}
+		if amount := toCents(payment.Amount) - refunded; amount > 0 {
+			if err := s.credit(tx, payment, entity.GiftCardRelease, amount, note); err != nil {
+				return err
+			}
+		}
+		if err := tx.Model(&entity.Payment{}).Where("id = ?", payment.ID).
+			Update("status", entity.PaymentStatusRefunded).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// credit puts amount cents paid with a gift card back on the card
+func (s *giftCardService) credit(tx *gorm.DB, payment entity.Payment, transactionType entity.GiftCardTransactionType, amount int64, note string) error {
+	var card entity.GiftCard
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", payment.ProviderPaymentID).
+		First(&card).Error; err != nil {
+		return err
+	}
+	return s.post(tx, &card, transactionType, amount, &payment.OrderID, &payment.ID, note)
+}
+
+// refunded returns the cents of a gift card payment already put back on the card
+func (s *giftCardService) refunded(tx *gorm.DB, paymentID string) (int64, error) {
+	var amount float64
+	err := tx.Model(&entity.GiftCardTransaction{}).Select("COALESCE(SUM(amount), 0)").
+		Where("payment_id = ? AND type IN ?", paymentID, []entity.GiftCardTransactionType{entity.GiftCardRefund, entity.GiftCardRelease}).
+		Scan(&amount).Error
+	return toCents(amount), err
+}
+
+// issueOrder issues the gift cards bought with a paid order, one per unit,
+// each worth the price paid for it, and emails each code to the buyer. It is safe to call again: only the
+// cards still missing are issued.
+func (s *giftCardService) issueOrder(tx *gorm.DB, orderID string) error {
+	var order entity.Order
+	if err := tx.Preload("Items", "parent_item_id IS NULL").Where("id = ?", orderID).First(&order).Error; err != nil {
+		return err
+	}
+	if order.UserID == nil {
+		return nil
+	}
+
+	productIDs := make([]string, 0, len(order.Items))
+	for _, item := range order.Items {
+		if item.ProductID != nil {
+			productIDs = append(productIDs, *item.ProductID)
+		}
+	}
+	var giftCardProducts []entity.GiftCardProduct
+	if err := tx.Where("product_id IN ?", productIDs).Find(&giftCardProducts).Error; err != nil {
+		return err
+	}
+	validity := make(map[string]int, len(giftCardProducts))
+	for _, giftCardProduct := range giftCardProducts {
+		validity[giftCardProduct.ProductID] = giftCardProduct.ValidityDays
+	}
+
+	// Cards are worth what was paid for them. Pricing never discounts gift
+	// cards, but a product made a gift card while an order was pending may
+	// have been.
+	var discounts []struct {
+		OrderItemID string
+		Amount      float64
+	}
+	if err := tx.Model(&entity.OrderAdjustment{}).Select("order_item_id, SUM(amount) AS amount").
+		Where("order_id = ? AND type = ? AND order_item_id IS NOT NULL", orderID, entity.AdjustmentDiscount).
+		Group("order_item_id").Scan(&discounts).Error; err != nil {
+		return err
+	}
+	discounted := make(map[string]int64, len(discounts))
+	for _, discount := range discounts {
+		discounted[discount.OrderItemID] = -toCents(discount.Amount)
+	}
+
+	now := time.Now().UTC()
+	for _, item := range order.Items {
+		if item.ProductID == nil {
+			continue
+		}
+		days, ok := validity[*item.ProductID]
+		paid := toCents(item.TotalPrice) - discounted[item.ID]
+		if !ok || item.Quantity <= 0 || paid <= 0 {
+			continue
+		}
+
+		var issued int64
+		if err := tx.Model(&entity.GiftCard{}).Where("order_item_id = ?", item.ID).Count(&issued).Error; err != nil {
+			return err
+		}
+		for n := int(issued); n < item.Quantity; n++ {
+			// The cents that do not divide evenly go to the first cards
+			balance := paid / int64(item.Quantity)
+			if int64(n) < paid%int64(item.Quantity) {
+				balance++
+			}
+			card := entity.GiftCard{
+				Currency:    order.Currency,
+				Balance:     fromCents(balance),
+				Source:      entity.GiftCardSourcePurchase,
+				OrderItemID: &item.ID,
+				UserID:      order.UserID,
+			}
+			if days > 0 {
+				expiresAt := now.AddDate(0, 0, days)
+				card.ExpiresAt = &expiresAt
+			}
+			if _, err := s.issue(tx, &card, &item.ProductName); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// issue creates a card with a new code and records its balance in the
+// ledger. When the card has a holder the code is emailed to them; name is
+// the product it was bought as, if any. It returns the code, which is not
+// stored.
+func (s *giftCardService) issue(tx *gorm.DB, card *entity.GiftCard, name *string) (string, error) {
+	var code string
+	for attempt := 0; ; attempt++ {
+		code = formatGiftCardCode(tools.NewRandomCode(giftCardCodeLength))
+		var count int64
+		if err := tx.Model(&entity.GiftCard{}).Where("code_hash = ?", hashGiftCardCode(code)).Count(&count).Error; err != nil {
+			return "", err
+		}
+		if count == 0 {
+			break
+		}
+		if attempt == 4 {
+			return "", fmt.Errorf("could not generate a unique gift card code")
+		}
+	}
+
+	balance := toCents(card.Balance)
+	card.ID = tools.NewUuid()
+	card.CodeHash = hashGiftCardCode(code)
+	card.Last4 = code[len(code)-4:]
+	card.InitialBalance = fromCents(balance)
+	card.Balance = 0
+	card.IsActive = true
+	if err := tx.Create(card).Error; err != nil {
+		return "", err
+	}
+	if err := s.post(tx, card, entity.GiftCardIssue, balance, nil, nil, card.Note); err != nil {
+		return "", err
+	}
+
+	if card.UserID == nil {
+		return code, nil
+	}
+	title := "gift card"
+	if name != nil {
+		title = *name
+	}
+	var body strings.Builder
+	fmt.Fprintf(&body, "Here is your %s worth %.2f %s.\n\n", title, card.InitialBalance, card.Currency)
+	fmt.Fprintf(&body, "Code: %s\n\n", code)
+	if card.ExpiresAt != nil {
+		fmt.Fprintf(&body, "It can be used until %s. ", card.ExpiresAt.Format("January 2, 2006"))
+	}
+	body.WriteString("Enter the code at checkout to pay for all or part of an order. Keep it safe: anyone with the code can spend the balance.\n")
+	_, err := INotificationService.Enqueue(tx, entity.Notification{
+		UserID:   *card.UserID,
+		Kind:     giftCardKind,
+		DedupKey: giftCardKind + ":" + card.ID,
+		Subject:  "Your gift card",
+		Body:     body.String(),
+		// The code is only kept until it has been sent
+		Sensitive: true,
+	})
+	return code, err
+}
+
+// post changes the balance of a locked card by amount cents and records the
+// change in its ledger
+func (s *giftCardService) post(tx *gorm.DB, card *entity.GiftCard, transactionType entity.GiftCardTransactionType, amount int64, orderID, paymentID *string, note string) error {
+	balance := toCents(card.Balance) + amount
+	if err := tx.Model(&entity.GiftCard{}).Where("id = ?", card.ID).Update("balance", fromCents(balance)).Error; err != nil {
+		return err
+	}
+	card.Balance = fromCents(balance)
+	return tx.Create(&entity.GiftCardTransaction{
+		ID:           tools.NewUuid(),
+		GiftCardID:   card.ID,
+		Type:         transactionType,
+		Amount:       fromCents(amount),
+		BalanceAfter: card.Balance,
+		OrderID:      orderID,
+		PaymentID:    paymentID,
+		Note:         note,
+	}).Error
+}
+
+// giftCardProducts reports which of the products are sold as gift cards
+func (s *giftCardService) giftCardProducts(db *gorm.DB, productIDs []string) (map[string]bool, error) {
+	giftCards := make(map[string]bool)
+	if len(productIDs) == 0 {
+		return giftCards, nil
+	}
+	var ids []string
+	if err := db.Model(&entity.GiftCardProduct{}).Where("product_id IN ?", productIDs).
+		Pluck("product_id", &ids).Error; err != nil {
+		return nil, err
+	}
+	for _, id := range ids {
+		giftCards[id] = true
+	}
+	return giftCards, nil
+}
+
+// unusable returns why a card cannot pay for an order in currency at now,
+// or "" if it can
+func (s *giftCardService) unusable(card entity.GiftCard, currency string, now time.Time) string {
+	switch {
+	case !card.IsActive:
+		return "This gift card has been disabled"
+	case card.ExpiresAt != nil && !now.Before(*card.ExpiresAt):
+		return "This gift card has expired"
+	case card.Currency != currency:
+		return fmt.Sprintf("This gift card is in %s and cannot pay in %s", card.Currency, currency)
+	case card.Balance <= 0:
+		return "This gift card has no balance left"
+	}
+	return ""
+}
+
+// hashGiftCardCode returns the hash a code is stored as. Codes are compared
+// in upper case without dashes or spaces, so customers can type them either
+// way. Codes are long and random, so an unsalted hash is enough to keep them
+// out of the database.
+func hashGiftCardCode(code string) string {
+	normalized := strings.NewReplacer("-", "", " ", "").Replace(strings.ToUpper(strings.TrimSpace(code)))
+	sum := sha256.Sum256([]byte(normalized))
+	return hex.EncodeToString(sum[:])
+}
+
+// formatGiftCardCode splits a code into groups of four, e.g. ABCD-EFGH-JKLM-NPQR
+func formatGiftCardCode(code string) string {
+	var groups []string
+	for len(code) > 4 {
+		groups = append(groups, code[:4])
+		code = code[4:]
+	}
+	return strings.Join(append(groups, code), "-")
+}
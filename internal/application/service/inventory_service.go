@@ -0,0 +1,603 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"backend-ecommerce/internal/application/dto"
+	"backend-ecommerce/internal/application/entity"
+	"backend-ecommerce/internal/application/tools"
+	"backend-ecommerce/internal/infrastructure/cachemanager"
+	"backend-ecommerce/internal/infrastructure/config"
+	"backend-ecommerce/internal/infrastructure/dbmanager"
+	"backend-ecommerce/internal/infrastructure/logger"
+)
+
+// ErrInsufficientStock is returned when a reservation asks for more than is available
+var ErrInsufficientStock = errors.New("insufficient stock")
+
+// reservationExpiryBatch is how many expired reservations are released at a time
+const reservationExpiryBatch = 200
+
+type inventoryService struct {
+}
+
+// stockKey identifies the stock of a product, or of one of its variants
+type stockKey struct {
+	ProductID string
+	VariantID string // empty for product-level stock
+}
+
+func newStockKey(productID string, variantID *string) stockKey {
+	key := stockKey{ProductID: productID}
+	if variantID != nil {
+		key.VariantID = *variantID
+	}
+	return key
+}
+
+func (k stockKey) variantID() *string {
+	if k.VariantID == "" {
+		return nil
+	}
+	variantID := k.VariantID
+	return &variantID
+}
+
+// stockAllocation is stock of one key taken from one location
+type stockAllocation struct {
+	LocationID string
+	Key        stockKey
+	Quantity   int
+}
+
+// stockRow restricts a query to the inventory row of a key at a location
+func stockRow(db *gorm.DB, locationID string, key stockKey) *gorm.DB {
+	db = db.Where("location_id = ? AND product_id = ?", locationID, key.ProductID)
+	if key.VariantID == "" {
+		return db.Where("variant_id IS NULL")
+	}
+	return db.Where("variant_id = ?", key.VariantID)
+}
+
+// activeStock loads the inventory rows of the given products at active locations
+func activeStock(db *gorm.DB, productIDs []string) *gorm.DB {
+	return db.Joins("Location").
+		Where("Location.is_active = ?", true).
+		Where("inventory.product_id IN ?", productIDs)
+}
+
+// GetAvailability returns the sellable quantity (on hand minus reserved) of
+// each product, summed over all active locations and variants. Products
+// without inventory records are reported as 0.
+func (s *inventoryService) GetAvailability(db *gorm.DB, productIDs []string) (map[string]int, error) {
+	availability, _, err := s.GetStockLevels(db, productIDs)
+	return availability, err
+}
+
+// GetStockLevels returns the sellable quantity of each product and of each
+// variant stocked on its own, summed over all active locations
+func (s *inventoryService) GetStockLevels(db *gorm.DB, productIDs []string) (map[string]int, map[string]int, error) {
+	products := make(map[string]int, len(productIDs))
+	variants := make(map[string]int)
+	if len(productIDs) == 0 {
+		return products, variants, nil
+	}
+
+	var rows []entity.Inventory
+	if err := activeStock(db, productIDs).Find(&rows).Error; err != nil {
+		return nil, nil, err
+	}
+
+	for _, row := range rows {
+		row.CalculateAvailable()
+		products[row.ProductID] += row.Available
+		if row.VariantID != nil {
+			variants[*row.VariantID] += row.Available
+		}
+	}
+	return products, variants, nil
+}
+
+// GetProductStock returns the stock of a product at every location
+func (s *inventoryService) GetProductStock(productID string) dto.ResponseDto {
+	db := dbmanager.GetDB()
+
+	if err := db.Select("id").Where("id = ?", productID).First(&entity.Product{}).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return *dto.Fail("Product not found")
+		}
+		logger.Error("Error fetching product: %v", err)
+		return *dto.Fail("Error fetching stock")
+	}
+
+	var rows []entity.Inventory
+	if err := db.Joins("Location").Where("inventory.product_id = ?", productID).
+		Order("Location.priority ASC").Order("Location.code ASC").Order("inventory.variant_id ASC").
+		Find(&rows).Error; err != nil {
+		logger.Error("Error fetching stock: %v", err)
+		return *dto.Fail("Error fetching stock")
+	}
+
+	response := dto.ProductStockResponse{ProductID: productID, Levels: make([]dto.StockLevelResponse, len(rows))}
+	for i, row := range rows {
+		response.Levels[i] = dto.GetStockLevelResponse(row)
+		if row.Location != nil && row.Location.IsActive {
+			response.Available += response.Levels[i].Available
+		}
+	}
+
+	return *dto.Success(response)
+}
+
+// SetStockLevel sets the quantity on hand of a product or variant at a
+// location, e.g. after a stock count, and records the difference in the
+// ledger. The quantity cannot drop below what is reserved for open orders.
+func (s *inventoryService) SetStockLevel(actorID string, req dto.StockLevelUpdateRequest) dto.ResponseDto {
+	db := dbmanager.GetDB()
+
+	failure, err := checkStockTarget(db, req.LocationID, req.ProductID, req.VariantID)
+	if err != nil {
+		logger.Error("Error checking stock target: %v", err)
+		return *dto.Fail("Error updating stock")
+	}
+	if failure != "" {
+		return *dto.Fail(failure)
+	}
+
+	key := newStockKey(req.ProductID, req.VariantID)
+	var row entity.Inventory
+	err = db.Transaction(func(tx *gorm.DB) error {
+		if err := stockRow(tx.Clauses(clause.Locking{Strength: "UPDATE"}), req.LocationID, key).
+			Limit(1).Find(&row).Error; err != nil {
+			return err
+		}
+		if row.ID == "" {
+			row = entity.Inventory{LocationID: req.LocationID, ProductID: req.ProductID, VariantID: key.variantID()}
+		}
+		if req.Quantity < row.Reserved {
+			failure = fmt.Sprintf("Quantity cannot be lower than the %d units reserved for open orders", row.Reserved)
+			return nil
+		}
+		if req.Quantity == row.Quantity {
+			return nil
+		}
+
+		movement := entity.StockMovement{
+			LocationID: req.LocationID,
+			ProductID:  req.ProductID,
+			VariantID:  req.VariantID,
+			Quantity:   req.Quantity - row.Quantity,
+			Reason:     entity.StockMovementReason(req.Reason),
+			ActorID:    &actorID,
+			Note:       req.Note,
+		}
+		if err := IStockMovementService.apply(tx, &movement); err != nil {
+			return err
+		}
+		return stockRow(tx, req.LocationID, key).First(&row).Error
+	})
+	if err != nil {
+		logger.Error("Error updating stock: %v", err)
+		return *dto.Fail("Error updating stock")
+	}
+	if failure != "" {
+		return *dto.Fail(failure)
+	}
+
+	s.stockChanged([]string{req.ProductID})
+
+	return *dto.Success(dto.GetStockLevelResponse(row))
+}
+
+// checkStockTarget verifies that stock can be kept for the product or variant
+// at the location. The returned message explains why it cannot.
+func checkStockTarget(db *gorm.DB, locationID, productID string, variantID *string) (string, error) {
+	if err := db.Where("id = ?", locationID).First(&entity.StockLocation{}).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return "Stock location not found", nil
+		}
+		return "", err
+	}
+
+	var product entity.Product
+	if err := db.Preload("Variants").Where("id = ?", productID).First(&product).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return "Product not found", nil
+		}
+		return "", err
+	}
+	if product.Type == entity.ProductTypeBundle || product.Type == entity.ProductTypeDigital {
+		return "Bundles and digital products are not stocked", nil
+	}
+	if variantID != nil {
+		found := false
+		for _, variant := range product.Variants {
+			found = found || variant.ID == *variantID
+		}
+		if !found {
+			return "Variant not found", nil
+		}
+	}
+	return "", nil
+}
+
+// stockChanged refreshes what depends on the availability of the products
+// after their stock changed outside of checkout
+func (s *inventoryService) stockChanged(productIDs []string) {
+	cachemanager.DeletePrefix(productCachePrefix)
+	IProductAlertService.CheckProducts(productIDs)
+	ICollectionService.RefreshProductMemberships(productIDs)
+}
+
+// ReserveOrder reserves the stock of an order's lines when checkout starts
+// and returns the reservations, one per location and product or variant.
+// Bundle lines are reserved through their component lines and digital
+// products are not stocked. Either every line is reserved or, with
+// ErrInsufficientStock, none is; the reservations expire after the
+// configured TTL unless the order is paid first.
+func (s *inventoryService) ReserveOrder(tx *gorm.DB, orderID string, items []entity.OrderItem, shipTo *entity.Address) ([]entity.InventoryReservation, error) {
+	bundleLines := make(map[string]bool)
+	for _, item := range items {
+		if item.ParentItemID != nil {
+			bundleLines[*item.ParentItemID] = true
+		}
+	}
+
+	demand := make(map[stockKey]int)
+	for _, item := range items {
+		if item.ProductID == nil || bundleLines[item.ID] || item.Quantity <= 0 {
+			continue
+		}
+		demand[newStockKey(*item.ProductID, item.VariantID)] += item.Quantity
+	}
+	if len(demand) == 0 {
+		return nil, nil
+	}
+
+	var reservations []entity.InventoryReservation
+	expiresAt := time.Now().UTC().Add(config.Get().Inventory.ReservationTTL)
+	err := tx.Transaction(func(tx *gorm.DB) error {
+		allocations, err := s.allocate(tx, demand, shipTo)
+		if err != nil {
+			return err
+		}
+
+		for _, allocation := range allocations {
+			// The condition makes the check and the reservation one atomic step,
+			// so two buyers can never both take the last unit
+			result := stockRow(tx.Model(&entity.Inventory{}), allocation.LocationID, allocation.Key).
+				Where("quantity - reserved >= ?", allocation.Quantity).
+				Update("reserved", gorm.Expr("reserved + ?", allocation.Quantity))
+			if result.Error != nil {
+				return result.Error
+			}
+			if result.RowsAffected == 0 {
+				return ErrInsufficientStock
+			}
+
+			reservation := entity.InventoryReservation{
+				ID:         tools.NewUuid(),
+				OrderID:    orderID,
+				LocationID: allocation.LocationID,
+				ProductID:  allocation.Key.ProductID,
+				VariantID:  allocation.Key.variantID(),
+				Quantity:   allocation.Quantity,
+				Status:     entity.InventoryReservationActive,
+				ExpiresAt:  expiresAt,
+			}
+			if err := tx.Create(&reservation).Error; err != nil {
+				return err
+			}
+			reservations = append(reservations, reservation)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return reservations, nil
+}
+
+// allocate decides which locations the demand is taken from. Locations are
+// ranked by priority, or by proximity to the shipping address first when so
+// configured. The best ranked location that can ship everything is used;
+// otherwise each line is split over the ranked locations. The inventory rows
+// involved stay locked until the transaction ends.
+func (s *inventoryService) allocate(tx *gorm.DB, demand map[stockKey]int, shipTo *entity.Address) ([]stockAllocation, error) {
+	productIDs := make([]string, 0, len(demand))
+	seen := make(map[string]bool)
+	for key := range demand {
+		if !seen[key.ProductID] {
+			seen[key.ProductID] = true
+			productIDs = append(productIDs, key.ProductID)
+		}
+	}
+
+	var digital []string
+	if err := tx.Model(&entity.Product{}).Where("id IN ? AND type = ?", productIDs, entity.ProductTypeDigital).
+		Pluck("id", &digital).Error; err != nil {
+		return nil, err
+	}
+	for _, id := range digital {
+		for key := range demand {
+			if key.ProductID == id {
+				delete(demand, key)
+			}
+		}
+	}
+
+	// Rows are locked in a fixed order so concurrent checkouts cannot deadlock.
+	// Only inventory rows are locked; checkouts share the location rows.
+	var rows []entity.Inventory
+	locking := clause.Locking{Strength: "UPDATE", Table: clause.Table{Name: entity.Inventory{}.TableName()}}
+	if err := activeStock(tx.Clauses(locking), productIDs).
+		Order("inventory.id").Find(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	stock := make(map[string]map[stockKey]int)
+	locations := make(map[string]entity.StockLocation)
+	variantStocked := make(map[string]bool)
+	for _, row := range rows {
+		row.CalculateAvailable()
+		key := newStockKey(row.ProductID, row.VariantID)
+		if stock[row.LocationID] == nil {
+			stock[row.LocationID] = make(map[stockKey]int)
+		}
+		stock[row.LocationID][key] = row.Available
+		if row.Location != nil {
+			locations[row.LocationID] = *row.Location
+		}
+		if row.VariantID != nil {
+			variantStocked[*row.VariantID] = true
+		}
+	}
+
+	// Variants without stock of their own are sold from their product's stock
+	for key, quantity := range demand {
+		if key.VariantID != "" && !variantStocked[key.VariantID] {
+			delete(demand, key)
+			demand[stockKey{ProductID: key.ProductID}] += quantity
+		}
+	}
+
+	keys := make([]stockKey, 0, len(demand))
+	for key := range demand {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].ProductID != keys[j].ProductID {
+			return keys[i].ProductID < keys[j].ProductID
+		}
+		return keys[i].VariantID < keys[j].VariantID
+	})
+
+	ranked := make([]entity.StockLocation, 0, len(locations))
+	for _, location := range locations {
+		ranked = append(ranked, location)
+	}
+	byProximity := config.Get().Inventory.Allocation == "proximity" && shipTo != nil
+	sort.Slice(ranked, func(i, j int) bool {
+		if byProximity {
+			if a, b := ranked[i].Proximity(*shipTo), ranked[j].Proximity(*shipTo); a != b {
+				return a > b
+			}
+		}
+		if ranked[i].Priority != ranked[j].Priority {
+			return ranked[i].Priority < ranked[j].Priority
+		}
+		return ranked[i].Code < ranked[j].Code
+	})
+
+	// Ship everything from one location when possible
+	for _, location := range ranked {
+		complete := true
+		for _, key := range keys {
+			complete = complete && stock[location.ID][key] >= demand[key]
+		}
+		if !complete {
+			continue
+		}
+		allocations := make([]stockAllocation, len(keys))
+		for i, key := range keys {
+			allocations[i] = stockAllocation{LocationID: location.ID, Key: key, Quantity: demand[key]}
+		}
+		return allocations, nil
+	}
+
+	// Otherwise split the shipment
+	var allocations []stockAllocation
+	for _, key := range keys {
+		remaining := demand[key]
+		for _, location := range ranked {
+			if remaining == 0 {
+				break
+			}
+			take := min(remaining, stock[location.ID][key])
+			if take <= 0 {
+				continue
+			}
+			allocations = append(allocations, stockAllocation{LocationID: location.ID, Key: key, Quantity: take})
+			remaining -= take
+		}
+		if remaining > 0 {
+			return nil, ErrInsufficientStock
+		}
+	}
+	return allocations, nil
+}
+
+// CommitOrder takes the reserved stock of a paid order out of the inventory
+// and records the sales in the ledger.
+// Stock of reservations that already expired is taken again if it is still
+// available; otherwise the order is oversold and a warning is logged.
+func (s *inventoryService) CommitOrder(tx *gorm.DB, orderID string) error {
+	var reservations []entity.InventoryReservation
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("order_id = ? AND status <> ?", orderID, entity.InventoryReservationCommitted).
+		Order("id").Find(&reservations).Error; err != nil {
+		return err
+	}
+
+	for _, reservation := range reservations {
+		key := newStockKey(reservation.ProductID, reservation.VariantID)
+		query := stockRow(tx.Model(&entity.Inventory{}), reservation.LocationID, key)
+		updates := map[string]interface{}{"quantity": gorm.Expr("quantity - ?", reservation.Quantity)}
+		if reservation.Status == entity.InventoryReservationActive {
+			updates["reserved"] = gorm.Expr("reserved - ?", reservation.Quantity)
+		} else {
+			query = query.Where("quantity - reserved >= ?", reservation.Quantity)
+		}
+
+		result := query.Updates(updates)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			logger.Warn("Order %s paid after its reservation of product %s lapsed and the stock is gone", orderID, reservation.ProductID)
+		} else {
+			// The sale is recorded at the average cost of the stock it came from
+			var row entity.Inventory
+			if err := stockRow(tx.Select("average_cost"), reservation.LocationID, key).Limit(1).Find(&row).Error; err != nil {
+				return err
+			}
+			movement := entity.StockMovement{
+				LocationID:    reservation.LocationID,
+				ProductID:     reservation.ProductID,
+				VariantID:     reservation.VariantID,
+				Quantity:      -reservation.Quantity,
+				Reason:        entity.StockMovementSale,
+				ReferenceType: entity.StockReferenceOrder,
+				ReferenceID:   &orderID,
+			}
+			if row.AverageCost > 0 {
+				movement.UnitCost = &row.AverageCost
+			}
+			if err := IStockMovementService.record(tx, &movement); err != nil {
+				return err
+			}
+		}
+
+		if err := tx.Model(&reservation).Update("status", entity.InventoryReservationCommitted).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ReleaseOrder puts the reserved stock of an order back on sale, e.g. when its
+// payment fails
+func (s *inventoryService) ReleaseOrder(tx *gorm.DB, orderID string) ([]string, error) {
+	return s.release(tx, entity.InventoryReservationReleased, "order_id = ?", orderID)
+}
+
+// ReleaseExpired is run by cronmanager. It cancels orders left unpaid past
+// the reservation TTL, giving back what checkout took for them, releases any
+// other reservation whose TTL has passed and lets subscribers know about
+// stock that is back on sale. Orders with a card payment still in progress
+// are left to the payment's outcome.
+func (s *inventoryService) ReleaseExpired() {
+	db := dbmanager.GetDB()
+	if db == nil {
+		return
+	}
+
+	released := make(map[string]bool)
+	expired := 0
+	for {
+		var productIDs []string
+		batchStart := expired
+		err := db.Transaction(func(tx *gorm.DB) error {
+			// Orders first, so their reservations are released along with
+			// the rest of what they took
+			var orderIDs []string
+			if err := tx.Model(&entity.Order{}).
+				Where("status = ? AND created_at < ?", entity.OrderStatusPending, time.Now().UTC().Add(-config.Get().Inventory.ReservationTTL)).
+				Where("NOT EXISTS (?)", tx.Model(&entity.Payment{}).Select("1").
+					Where("payments.order_id = orders.id AND payments.status = ?", entity.PaymentStatusInitiated)).
+				Order("created_at").Limit(reservationExpiryBatch).Pluck("id", &orderIDs).Error; err != nil {
+				return err
+			}
+			if len(orderIDs) > 0 {
+				for _, orderID := range orderIDs {
+					ids, err := IOrderService.expire(tx, orderID)
+					if err != nil {
+						return err
+					}
+					productIDs = append(productIDs, ids...)
+				}
+				expired += len(orderIDs)
+				return nil
+			}
+
+			var ids []string
+			if err := tx.Model(&entity.InventoryReservation{}).
+				Where("status = ? AND expires_at < ?", entity.InventoryReservationActive, time.Now().UTC()).
+				Where("NOT EXISTS (?)", tx.Model(&entity.Payment{}).Select("1").
+					Where("payments.order_id = inventoryReservations.order_id AND payments.status = ?", entity.PaymentStatusInitiated)).
+				Order("expires_at").Limit(reservationExpiryBatch).Pluck("id", &ids).Error; err != nil {
+				return err
+			}
+			if len(ids) == 0 {
+				return nil
+			}
+
+			var err error
+			productIDs, err = s.release(tx, entity.InventoryReservationExpired, "id IN ?", ids)
+			return err
+		})
+		if err != nil {
+			logger.Error("Error releasing expired reservations: %v", err)
+			break
+		}
+		if len(productIDs) == 0 && expired == batchStart {
+			break
+		}
+		for _, id := range productIDs {
+			released[id] = true
+		}
+	}
+
+	if expired > 0 {
+		logger.Info("Cancelled %d orders left unpaid", expired)
+	}
+	if len(released) == 0 {
+		return
+	}
+	productIDs := make([]string, 0, len(released))
+	for id := range released {
+		productIDs = append(productIDs, id)
+	}
+	logger.Info("Released expired stock reservations of %d products", len(productIDs))
+	s.stockChanged(productIDs)
+}
+
+// release ends the active reservations matching the condition with the given
+// status and returns the affected products
+func (s *inventoryService) release(tx *gorm.DB, status entity.InventoryReservationStatus, query string, args ...interface{}) ([]string, error) {
+	var reservations []entity.InventoryReservation
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where(query, args...).
+		Where("status = ?", entity.InventoryReservationActive).
+		Order("id").Find(&reservations).Error; err != nil {
+		return nil, err
+	}
+
+	productIDs := make([]string, 0, len(reservations))
+	for _, reservation := range reservations {
+		if err := stockRow(tx.Model(&entity.Inventory{}), reservation.LocationID, newStockKey(reservation.ProductID, reservation.VariantID)).
+			Update("reserved", gorm.Expr("GREATEST(reserved - ?, 0)", reservation.Quantity)).Error; err != nil {
+			return nil, err
+		}
+		if err := tx.Model(&reservation).Update("status", status).Error; err != nil {
+			return nil, err
+		}
+		productIDs = append(productIDs, reservation.ProductID)
+	}
+	return productIDs, nil
+}
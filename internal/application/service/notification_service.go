@@ -0,0 +1,173 @@
+package service
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"backend-ecommerce/internal/application/entity"
+	"backend-ecommerce/internal/application/tools"
+	"backend-ecommerce/internal/infrastructure/config"
+	"backend-ecommerce/internal/infrastructure/dbmanager"
+	"backend-ecommerce/internal/infrastructure/logger"
+	"backend-ecommerce/internal/infrastructure/notifier"
+)
+
+const (
+	// notificationMaxAttempts is how many times delivery is tried before giving up
+	notificationMaxAttempts = 5
+	// notificationMaxAge is how long a notification held back by rate limits stays relevant
+	notificationMaxAge      = 7 * 24 * time.Hour
+	notificationBatch       = 200
+	notificationSendTimeout = 30 * time.Second
+)
+
+// redactedNotificationBody replaces the body of a sensitive notification once
+// delivery has finished
+const redactedNotificationBody = "[removed after delivery]"
+
+type notificationService struct {
+	mu sync.Mutex
+}
+
+// Enqueue queues a notification for delivery. A notification whose DedupKey
+// was already queued is skipped; the result reports whether it was queued.
+func (s *notificationService) Enqueue(tx *gorm.DB, notification entity.Notification) (bool, error) {
+	notification.ID = tools.NewUuid()
+	notification.Status = entity.NotificationStatusPending
+	result := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&notification)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// DeliverPending is run by cronmanager. It sends queued notifications through
+// the configured notifier, holding back those that would take a user over the
+// daily limit until the limit allows them.
+func (s *notificationService) DeliverPending() {
+	db := dbmanager.GetDB()
+	if db == nil || notifier.GetNotifier() == nil {
+		return
+	}
+	if !s.mu.TryLock() {
+		return
+	}
+	defer s.mu.Unlock()
+
+	now := time.Now().UTC()
+	if err := db.Model(&entity.Notification{}).
+		Where("status = ? AND created_at < ?", entity.NotificationStatusPending, now.Add(-notificationMaxAge)).
+		Updates(map[string]interface{}{
+			"status": entity.NotificationStatusExpired,
+			"body":   gorm.Expr("IF(sensitive, ?, body)", redactedNotificationBody),
+		}).Error; err != nil {
+		logger.Error("Error expiring notifications: %v", err)
+	}
+
+	var pending []entity.Notification
+	if err := db.Where("status = ?", entity.NotificationStatusPending).
+		Order("created_at ASC").Limit(notificationBatch).Find(&pending).Error; err != nil {
+		logger.Error("Error fetching pending notifications: %v", err)
+		return
+	}
+	if len(pending) == 0 {
+		return
+	}
+
+	userIDs := make([]string, 0, len(pending))
+	for _, notification := range pending {
+		userIDs = append(userIDs, notification.UserID)
+	}
+
+	var users []entity.User
+	if err := db.Select("id", "email").Where("id IN ?", userIDs).Find(&users).Error; err != nil {
+		logger.Error("Error fetching notification recipients: %v", err)
+		return
+	}
+	emails := make(map[string]string, len(users))
+	for _, user := range users {
+		emails[user.ID] = user.Email
+	}
+
+	var sentCounts []struct {
+		UserID string
+		Count  int
+	}
+	if err := db.Model(&entity.Notification{}).Select("user_id, COUNT(*) AS count").
+		Where("user_id IN ? AND status = ? AND sent_at >= ?", userIDs, entity.NotificationStatusSent, now.Add(-24*time.Hour)).
+		Group("user_id").Scan(&sentCounts).Error; err != nil {
+		logger.Error("Error counting sent notifications: %v", err)
+		return
+	}
+	sentToday := make(map[string]int, len(sentCounts))
+	for _, row := range sentCounts {
+		sentToday[row.UserID] = row.Count
+	}
+
+	limit := config.Get().Notification.MaxPerUserPerDay
+	sent, deferred := 0, 0
+	for _, notification := range pending {
+		if limit > 0 && sentToday[notification.UserID] >= limit {
+			deferred++
+			continue
+		}
+
+		email := emails[notification.UserID]
+		if email == "" {
+			s.markFailed(db, notification, "recipient has no email address", true)
+			continue
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), notificationSendTimeout)
+		err := notifier.GetNotifier().Send(ctx, notifier.Message{To: email, Subject: notification.Subject, Body: notification.Body})
+		cancel()
+		if err != nil {
+			s.markFailed(db, notification, err.Error(), notification.Attempts+1 >= notificationMaxAttempts)
+			continue
+		}
+
+		sentAt := time.Now().UTC()
+		updates := map[string]interface{}{
+			"status":   entity.NotificationStatusSent,
+			"attempts": notification.Attempts + 1,
+			"sent_at":  sentAt,
+		}
+		if notification.Sensitive {
+			updates["body"] = redactedNotificationBody
+		}
+		if err := db.Model(&notification).Updates(updates).Error; err != nil {
+			logger.Error("Error marking notification %s as sent: %v", notification.ID, err)
+		}
+		sentToday[notification.UserID]++
+		sent++
+	}
+
+	if sent > 0 || deferred > 0 {
+		logger.Info("Notifications delivered: %d sent, %d held back by rate limits", sent, deferred)
+	}
+}
+
+// markFailed records a failed delivery attempt, giving up when final is set
+func (s *notificationService) markFailed(db *gorm.DB, notification entity.Notification, reason string, final bool) {
+	if len(reason) > 500 {
+		reason = reason[:500]
+	}
+	updates := map[string]interface{}{
+		"attempts":   notification.Attempts + 1,
+		"last_error": reason,
+	}
+	if final {
+		updates["status"] = entity.NotificationStatusFailed
+		if notification.Sensitive {
+			updates["body"] = redactedNotificationBody
+		}
+	}
+	if err := db.Model(&notification).Updates(updates).Error; err != nil {
+		logger.Error("Error updating notification %s: %v", notification.ID, err)
+	}
+	logger.Warn("Notification %s not delivered: %s", notification.ID, reason)
+}
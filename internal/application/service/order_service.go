@@ -1,24 +1,241 @@
 package service
 
 import (
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+
+	"backend-ecommerce/internal/application/dto"
 	"backend-ecommerce/internal/application/entity"
+	"backend-ecommerce/internal/application/tools"
+	"backend-ecommerce/internal/infrastructure/dbmanager"
+	"backend-ecommerce/internal/infrastructure/logger"
 )
 
+// errOrderFailure carries a message, and optionally data, for the client
+// out of a transaction
+type errOrderFailure struct {
+	message string
+	data    interface{}
+}
+
+func (e errOrderFailure) Error() string {
+	return e.message
+}
+
 type orderService struct {
 }
 
+// CreateOrder turns the user's cart into a pending order. The cart is priced
+// by the same engine as the cart view, the stock is reserved until payment,
+// the discount codes that applied are redeemed and the cart is emptied. Gift
+// cards given pay for as much of the order as they can and a Stripe payment
+// is started for the rest, its client secret coming back with the order; an
+// order with nothing left to pay is completed at once. Carts with
+// unacknowledged changes, such as a price rise, are refused with the cart
+// warnings as data.
+func (s *orderService) CreateOrder(userID string, req dto.OrderCreateRequest) dto.ResponseDto {
+	db := dbmanager.GetDB()
 
-func (s *orderService) CreateOrder(order entity.Order) (*entity.Order, error) {
+	var order entity.Order
+	var payment *entity.Payment
+	var productIDs []string
+	err := db.Transaction(func(tx *gorm.DB) error {
+		shipTo, err := findUserAddress(tx, userID, req.ShippingAddressID)
+		if err != nil {
+			if err == gorm.ErrRecordNotFound {
+				return errOrderFailure{message: "Shipping address not found"}
+			}
+			return err
+		}
+		billingID := shipTo.ID
+		if req.BillingAddressID != "" && req.BillingAddressID != shipTo.ID {
+			billing, err := findUserAddress(tx, userID, req.BillingAddressID)
+			if err != nil {
+				if err == gorm.ErrRecordNotFound {
+					return errOrderFailure{message: "Billing address not found"}
+				}
+				return err
+			}
+			billingID = billing.ID
+		}
 
-	return nil, nil
+		// The cart stays locked, which stops a second checkout of it
+		cart, err := cartStore.CheckoutCart(tx, userID)
+		if err != nil {
+			return err
+		}
+		if cart == nil || len(cart.Items) == 0 {
+			return errOrderFailure{message: "Your cart is empty"}
+		}
+
+		quote, _, err := IPricingService.quote(tx, ICartService.pricingCart(*cart), shipTo)
+		if err != nil {
+			return err
+		}
+		// The cart must be bought as the shopper last saw it
+		warnings := ICartService.warnings(*cart, *quote)
+		for _, warning := range warnings {
+			if warning.RequiresAcknowledgement {
+				return errOrderFailure{message: "Your cart has changed; please review and accept the changes", data: warnings}
+			}
+		}
+
+		order = entity.Order{
+			ID:                tools.NewUuid(),
+			UserID:            &userID,
+			Status:            entity.OrderStatusPending,
+			Subtotal:          quote.Subtotal,
+			DiscountTotal:     quote.DiscountTotal,
+			ShippingTotal:     quote.ShippingTotal,
+			TaxTotal:          quote.TaxTotal,
+			TotalAmount:       quote.GrandTotal,
+			Currency:          quote.Currency,
+			ShippingAddressID: &shipTo.ID,
+			BillingAddressID:  &billingID,
+		}
+		if err := tx.Create(&order).Error; err != nil {
+			return err
+		}
+
+		items, itemIDs, err := s.orderItems(tx, order.ID, quote.Lines)
+		if err != nil {
+			return err
+		}
+		if err := tx.Create(&items).Error; err != nil {
+			return err
+		}
+
+		if len(quote.Adjustments) > 0 {
+			adjustments := make([]entity.OrderAdjustment, len(quote.Adjustments))
+			for i, adjustment := range quote.Adjustments {
+				adjustments[i] = entity.OrderAdjustment{
+					ID:      tools.NewUuid(),
+					OrderID: order.ID,
+					Type:    adjustment.Type,
+					Source:  adjustment.Source,
+					Label:   adjustment.Label,
+					Amount:  adjustment.Amount,
+				}
+				if itemID, ok := itemIDs[adjustment.LineID]; ok {
+					adjustments[i].OrderItemID = &itemID
+				}
+			}
+			if err := tx.Create(&adjustments).Error; err != nil {
+				return err
+			}
+		}
+
+		if _, err := IInventoryService.ReserveOrder(tx, order.ID, items, shipTo); err != nil {
+			if errors.Is(err, ErrInsufficientStock) {
+				return errOrderFailure{message: "Some items in your cart have just sold out"}
+			}
+			return err
+		}
+
+		if err := ICouponService.redeem(tx, cart.ID, userID, order.ID, quote.Coupons); err != nil {
+			return err
+		}
+
+		due := toCents(order.TotalAmount)
+		if len(req.GiftCardCodes) > 0 {
+			paid, err := IGiftCardService.redeem(tx, &order, req.GiftCardCodes)
+			if err != nil {
+				return err
+			}
+			if err := tx.Model(&entity.Order{}).Where("id = ?", order.ID).Update("gift_card_total", paid).Error; err != nil {
+				return err
+			}
+			due -= toCents(paid)
+		}
+		if due <= 0 {
+			if err := IPaymentService.completeOrder(tx, order.ID); err != nil {
+				return err
+			}
+		} else {
+			// The card payment is confirmed by the client and settled by the
+			// Stripe webhook; if it fails the gift cards are credited back
+			payment, err = IPaymentService.startPayment(tx, order, fromCents(due))
+			if err != nil {
+				return err
+			}
+		}
+
+		if err := ICartRecoveryService.checkedOut(tx, userID, order.ID); err != nil {
+			return err
+		}
+
+		// Only the lines bought are removed, leaving any added meanwhile
+		boughtIDs := make([]string, len(cart.Items))
+		for i, item := range cart.Items {
+			productIDs = append(productIDs, item.ProductID)
+			boughtIDs[i] = item.ID
+		}
+		return cartStore.RemoveItems(tx, cart.ID, boughtIDs...)
+	})
+	if err != nil {
+		var failure errOrderFailure
+		if errors.As(err, &failure) {
+			return *dto.FailData(failure.message, failure.data)
+		}
+		logger.Error("Error creating order: %v", err)
+		return *dto.Fail("Error creating order")
+	}
+
+	IInventoryService.stockChanged(productIDs)
+
+	if err := s.preload(db).Where("id = ?", order.ID).First(&order).Error; err != nil {
+		logger.Error("Error fetching order: %v", err)
+		return *dto.Fail("Error fetching order")
+	}
+	response := dto.GetOrderResponse(order)
+	if payment != nil {
+		paymentResponse := dto.GetPaymentResponse(*payment)
+		response.Payment = &paymentResponse
+	}
+	return *dto.Success(response)
 }
 
-func (s *orderService) GetOrderByID(id string) (*entity.Order, error) {
-	return nil, nil
+// GetOrder returns one of the user's orders
+func (s *orderService) GetOrder(userID, id string) dto.ResponseDto {
+	db := dbmanager.GetDB()
+
+	var order entity.Order
+	if err := s.preload(db).Where("id = ? AND user_id = ?", id, userID).First(&order).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return *dto.Fail("Order not found")
+		}
+		logger.Error("Error fetching order: %v", err)
+		return *dto.Fail("Error fetching order")
+	}
+
+	return *dto.Success(dto.GetOrderResponse(order))
 }
 
-func (s *orderService) GetUserOrders(userID string, page, pageSize int) ([]entity.Order, int64, error) {
-	return []entity.Order{}, 0, nil
+// GetUserOrders returns the user's orders, newest first
+func (s *orderService) GetUserOrders(userID string, page, pageSize int) dto.ResponseDto {
+	db := dbmanager.GetDB()
+
+	var total int64
+	if err := db.Model(&entity.Order{}).Where("user_id = ?", userID).Count(&total).Error; err != nil {
+		logger.Error("Error counting orders: %v", err)
+		return *dto.Fail("Error fetching orders")
+	}
+
+	var orders []entity.Order
+	if err := s.preload(db).Where("user_id = ?", userID).Order("created_at DESC").
+		Offset((page - 1) * pageSize).Limit(pageSize).Find(&orders).Error; err != nil {
+		logger.Error("Error fetching orders: %v", err)
+		return *dto.Fail("Error fetching orders")
+	}
+
+	orderDtos := make([]dto.OrderResponse, len(orders))
+	for i, order := range orders {
+		orderDtos[i] = dto.GetOrderResponse(order)
+	}
+
+	return *dto.SuccessCount(orderDtos, total)
 }
 
 func (s *orderService) UpdateOrderStatus(id string, status string) (*entity.Order, error) {
@@ -27,4 +244,95 @@ func (s *orderService) UpdateOrderStatus(id string, status string) (*entity.Orde
 
 func (s *orderService) CancelOrder(id string) error {
 	return nil
-}
\ No newline at end of file
+}
+
+// expire cancels an order left unpaid past the reservation TTL and gives back
+// what checkout took for it: the discount codes it redeemed, the gift card
+// balances and the reserved stock. It returns the products whose stock was
+// released. Orders with a card payment in progress are not expired; the
+// payment's outcome settles them.
+func (s *orderService) expire(tx *gorm.DB, orderID string) ([]string, error) {
+	result := tx.Model(&entity.Order{}).Where("id = ? AND status = ?", orderID, entity.OrderStatusPending).
+		Update("status", entity.OrderStatusCancelled)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, nil
+	}
+
+	if err := ICouponService.releaseOrder(tx, orderID); err != nil {
+		return nil, err
+	}
+	if err := IGiftCardService.release(tx, orderID, "Order expired unpaid"); err != nil {
+		return nil, err
+	}
+	return IInventoryService.ReleaseOrder(tx, orderID)
+}
+
+// orderItems builds the order lines of quoted cart lines, expanding bundles
+// into their components. It also returns the order item ID of each cart line.
+func (s *orderService) orderItems(tx *gorm.DB, orderID string, lines []entity.QuoteLine) ([]entity.OrderItem, map[string]string, error) {
+	items := make([]entity.OrderItem, 0, len(lines))
+	itemIDs := make(map[string]string, len(lines))
+	productIDs := make([]string, len(lines))
+	for i, line := range lines {
+		productID := line.ProductID
+		item := entity.OrderItem{
+			ID:          tools.NewUuid(),
+			OrderID:     orderID,
+			ProductID:   &productID,
+			VariantID:   line.VariantID,
+			ProductName: line.Name,
+			SKU:         line.SKU,
+			Quantity:    line.Quantity,
+			UnitPrice:   line.UnitPrice,
+			TotalPrice:  line.Subtotal,
+		}
+		items = append(items, item)
+		itemIDs[line.LineID] = item.ID
+		productIDs[i] = productID
+	}
+
+	var bundles []entity.Product
+	if err := tx.Where("id IN ? AND type = ?", productIDs, entity.ProductTypeBundle).Find(&bundles).Error; err != nil {
+		return nil, nil, err
+	}
+	if len(bundles) == 0 {
+		return items, itemIDs, nil
+	}
+
+	now := time.Now().UTC()
+	prices, _, err := IPriceScheduleService.ResolvePrices(tx, bundles, now)
+	if err != nil {
+		return nil, nil, err
+	}
+	details, err := IBundleService.ResolveBundles(tx, bundles, prices, now)
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, item := range items[:len(lines)] {
+		if bundle, ok := details[*item.ProductID]; ok {
+			items = append(items, IBundleService.ExpandBundleLine(item, bundle)...)
+		}
+	}
+	return items, itemIDs, nil
+}
+
+// preload loads what order responses show
+func (s *orderService) preload(db *gorm.DB) *gorm.DB {
+	return db.Preload("Items", "parent_item_id IS NULL").Preload("Items.Components").
+		Preload("Adjustments", func(db *gorm.DB) *gorm.DB { return db.Order("type ASC").Order("source ASC") })
+}
+
+// findUserAddress loads an address belonging to a user
+func findUserAddress(db *gorm.DB, userID, id string) (*entity.Address, error) {
+	if userID == "" {
+		return nil, gorm.ErrRecordNotFound
+	}
+	var address entity.Address
+	if err := db.Where("id = ? AND user_id = ?", id, userID).First(&address).Error; err != nil {
+		return nil, err
+	}
+	return &address, nil
+}
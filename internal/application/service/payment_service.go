@@ -1,15 +1,34 @@
 package service
 
 import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"backend-ecommerce/internal/application/dto"
 	"backend-ecommerce/internal/application/entity"
-	// "backend-ecommerce/internal/infrastructure/stripe"
+	"backend-ecommerce/internal/application/tools"
+	"backend-ecommerce/internal/infrastructure/dbmanager"
+	"backend-ecommerce/internal/infrastructure/logger"
+	"backend-ecommerce/internal/infrastructure/stripe"
 )
 
+// ErrPaymentNotPending is returned when a provider event arrives for a
+// payment, or an order, that has already been settled, e.g. a replayed
+// confirmation or a failure reported after the payment succeeded
+var ErrPaymentNotPending = errors.New("payment is no longer pending")
+
+// ErrInvalidWebhook is returned for a webhook whose signature does not verify
+var ErrInvalidWebhook = errors.New("invalid webhook")
+
 type paymentService struct {
 	// stripeClient *stripe.StripeManager
 }
 
-
 // ProcessPayment processes a payment using Stripe
 func (s *paymentService) ProcessPayment(payment entity.Payment) (*entity.Payment, error) {
 	return nil, nil
@@ -23,32 +42,266 @@ func (s *paymentService) GetPaymentByID(id string) (*entity.Payment, error) {
 // GetPaymentsByOrderID retrieves all payments for a specific order
 func (s *paymentService) GetPaymentsByOrderID(orderID string) ([]entity.Payment, error) {
 	return nil, nil
-}	
+}
 
-// RefundPayment processes a refund for a payment
-func (s *paymentService) RefundPayment(paymentID string, amount float64) (*entity.Payment, error) {
-	return nil, nil
-}	
+// RefundPayment refunds all or part of a payment. A payment made with a gift
+// card is refunded to the card, with an entry in its ledger.
+func (s *paymentService) RefundPayment(paymentID string, req dto.PaymentRefundRequest) dto.ResponseDto {
+	db := dbmanager.GetDB()
+
+	var payment entity.Payment
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", paymentID).First(&payment).Error; err != nil {
+			if err == gorm.ErrRecordNotFound {
+				return errOrderFailure{message: "Payment not found"}
+			}
+			return err
+		}
+		if payment.Provider != entity.PaymentProviderGiftCard {
+			return errOrderFailure{message: fmt.Sprintf("Refunds of %s payments are not supported yet", payment.Provider)}
+		}
+		if payment.Status != entity.PaymentStatusSucceeded && payment.Status != entity.PaymentStatusPartiallyRefunded {
+			return errOrderFailure{message: "Only succeeded payments can be refunded"}
+		}
+
+		refunded, err := IGiftCardService.refunded(tx, payment.ID)
+		if err != nil {
+			return err
+		}
+		refundable := toCents(payment.Amount) - refunded
+		amount := toCents(req.Amount)
+		if amount == 0 {
+			amount = refundable
+		}
+		if amount <= 0 || amount > refundable {
+			return errOrderFailure{message: fmt.Sprintf("At most %.2f can be refunded", fromCents(max(0, refundable)))}
+		}
+
+		note := req.Reason
+		if note == "" {
+			note = "Refund"
+		}
+		if err := IGiftCardService.credit(tx, payment, entity.GiftCardRefund, amount, note); err != nil {
+			return err
+		}
+
+		payment.Status = entity.PaymentStatusPartiallyRefunded
+		if amount == refundable {
+			payment.Status = entity.PaymentStatusRefunded
+		}
+		return tx.Model(&entity.Payment{}).Where("id = ?", payment.ID).Update("status", payment.Status).Error
+	})
+	if err != nil {
+		var failure errOrderFailure
+		if errors.As(err, &failure) {
+			return *dto.Fail(failure.message)
+		}
+		logger.Error("Error refunding payment %s: %v", paymentID, err)
+		return *dto.Fail("Error refunding payment")
+	}
+
+	return *dto.Success(dto.GetPaymentResponse(payment))
+}
 
 // CreateCheckoutSession creates a new Stripe Checkout session
 func (s *paymentService) CreateCheckoutSession(orderID string, amount float64, currency string, customerEmail string) (map[string]interface{}, error) {
 	return nil, nil
-}	
+}
 
-// HandleWebhook handles Stripe webhook events
-func (s *paymentService) HandleWebhook(payload []byte, signature string) error {
+// startPayment opens a Stripe payment intent for the amount due on an order
+// and records it as an initiated payment. The client secret the client
+// confirms the card payment with is only set on the returned payment.
+func (s *paymentService) startPayment(tx *gorm.DB, order entity.Order, amount float64) (*entity.Payment, error) {
+	client := stripe.GetStripe()
+	if client == nil {
+		return nil, errOrderFailure{message: "Card payments are not available"}
+	}
+
+	intent, err := client.CreatePaymentIntent(toCents(amount), strings.ToLower(order.Currency), order.ID)
+	if err != nil {
+		return nil, fmt.Errorf("create payment intent for order %s: %w", order.ID, err)
+	}
+
+	payment := entity.Payment{
+		ID:                tools.NewUuid(),
+		OrderID:           order.ID,
+		Provider:          entity.PaymentProviderStripe,
+		ProviderPaymentID: intent.ID,
+		Amount:            amount,
+		Currency:          order.Currency,
+		Status:            entity.PaymentStatusInitiated,
+	}
+	if err := tx.Create(&payment).Error; err != nil {
+		return nil, err
+	}
+	payment.ClientSecret = intent.ClientSecret
+	return &payment, nil
+}
+
+// CompletePayment marks a payment and its order as paid once the provider has
+// confirmed it, takes the reserved stock out of the inventory and delivers
+// the digital goods and gift cards in the order
+func (s *paymentService) CompletePayment(paymentID string) error {
+	db := dbmanager.GetDB()
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		payment, err := s.lockPending(tx, paymentID)
+		if err != nil {
+			return err
+		}
+
+		if err := tx.Model(&entity.Payment{}).Where("id = ?", payment.ID).
+			Update("status", entity.PaymentStatusSucceeded).Error; err != nil {
+			return err
+		}
+		return s.completeOrder(tx, payment.OrderID)
+	})
+}
+
+// completeOrder marks a pending order as paid, takes the reserved stock out
+// of the inventory and delivers the digital goods and gift cards in it. It
+// runs when the provider confirms a payment, or when gift cards paid for the
+// whole order. An order that is no longer pending, because it was already
+// paid or has expired, fails with ErrPaymentNotPending so nothing is
+// delivered twice.
+func (s *paymentService) completeOrder(tx *gorm.DB, orderID string) error {
+	result := tx.Model(&entity.Order{}).Where("id = ? AND status = ?", orderID, entity.OrderStatusPending).
+		Update("status", entity.OrderStatusPaid)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrPaymentNotPending
+	}
+	if err := IInventoryService.CommitOrder(tx, orderID); err != nil {
+		return err
+	}
+	if err := IDigitalProductService.GrantDownloads(tx, orderID); err != nil {
+		return err
+	}
+
+	return IGiftCardService.issueOrder(tx, orderID)
+}
+
+// FailPayment marks a payment as failed, puts the stock reserved for its
+// order back on sale and gives back the discount codes it redeemed and the
+// gift card balances it took. Only an initiated payment of a pending order
+// can fail; anything else returns ErrPaymentNotPending.
+func (s *paymentService) FailPayment(paymentID string) error {
+	db := dbmanager.GetDB()
+
+	var productIDs []string
+	err := db.Transaction(func(tx *gorm.DB) error {
+		payment, err := s.lockPending(tx, paymentID)
+		if err != nil {
+			return err
+		}
+
+		var pending int64
+		if err := tx.Model(&entity.Order{}).Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id = ? AND status = ?", payment.OrderID, entity.OrderStatusPending).
+			Count(&pending).Error; err != nil {
+			return err
+		}
+		if pending == 0 {
+			return ErrPaymentNotPending
+		}
+
+		if err := tx.Model(&entity.Payment{}).Where("id = ?", payment.ID).
+			Update("status", entity.PaymentStatusFailed).Error; err != nil {
+			return err
+		}
+
+		if err := ICouponService.releaseOrder(tx, payment.OrderID); err != nil {
+			return err
+		}
+		if err := IGiftCardService.release(tx, payment.OrderID, "Order payment failed"); err != nil {
+			return err
+		}
+
+		productIDs, err = IInventoryService.ReleaseOrder(tx, payment.OrderID)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	IInventoryService.stockChanged(productIDs)
 	return nil
 }
 
-// // handlePaymentIntentSucceeded handles successful payment intents
-// func (s *paymentService) handlePaymentIntentSucceeded(event stripe.Event) error {
-// 	return nil
-// }
+// lockPending locks a payment still awaiting its provider's outcome
+func (s *paymentService) lockPending(tx *gorm.DB, paymentID string) (*entity.Payment, error) {
+	var payment entity.Payment
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", paymentID).
+		First(&payment).Error; err != nil {
+		return nil, err
+	}
+	if payment.Status != entity.PaymentStatusInitiated {
+		return nil, ErrPaymentNotPending
+	}
+	return &payment, nil
+}
 
-// // handlePaymentIntentFailed handles failed payment intents
-// func (s *paymentService) handlePaymentIntentFailed(event stripe.Event) error {
-// 	return nil
-// }
+// HandleWebhook handles Stripe webhook events. A succeeded payment intent
+// completes its payment and order; a failed or cancelled one fails them.
+// Events for payments already settled, or not made here, are ignored so
+// Stripe stops sending them; any other error asks for a retry.
+func (s *paymentService) HandleWebhook(payload []byte, signature string) error {
+	client := stripe.GetStripe()
+	if client == nil {
+		return errors.New("stripe is not configured")
+	}
+	event, err := client.HandleWebhook(payload, signature)
+	if err != nil {
+		logger.Warn("Rejected Stripe webhook: %v", err)
+		return ErrInvalidWebhook
+	}
+
+	var settle func(paymentID string) error
+	switch event.Type {
+	case "payment_intent.succeeded":
+		settle = s.CompletePayment
+	case "payment_intent.payment_failed", "payment_intent.canceled":
+		settle = s.FailPayment
+	default:
+		return nil
+	}
+
+	var intent struct {
+		ID             string `json:"id"`
+		AmountReceived int64  `json:"amount_received"`
+	}
+	if event.Data == nil {
+		return fmt.Errorf("stripe event %s has no data", event.ID)
+	}
+	if err := json.Unmarshal(event.Data.Raw, &intent); err != nil {
+		return fmt.Errorf("decode stripe event %s: %w", event.ID, err)
+	}
+
+	var payment entity.Payment
+	if err := dbmanager.GetDB().Where("provider = ? AND provider_payment_id = ?", entity.PaymentProviderStripe, intent.ID).
+		First(&payment).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			logger.Warn("Stripe event %s is for unknown payment intent %s", event.ID, intent.ID)
+			return nil
+		}
+		return err
+	}
+	if event.Type == "payment_intent.succeeded" && intent.AmountReceived != toCents(payment.Amount) {
+		logger.Error("Payment %s received %d cents instead of %.2f; left for review", payment.ID, intent.AmountReceived, payment.Amount)
+		return nil
+	}
+
+	if err := settle(payment.ID); err != nil {
+		if errors.Is(err, ErrPaymentNotPending) {
+			logger.Info("Stripe event %s for payment %s is already settled", event.ID, payment.ID)
+			return nil
+		}
+		return err
+	}
+	return nil
+}
 
 // // handleChargeRefunded handles refund events
 // func (s *paymentService) handleChargeRefunded(event stripe.Event) error {
@@ -63,4 +316,4 @@ func (s *paymentService) ConvertToStripeAmount(amount float64, currency string)
 // ConvertFromStripeAmount converts from Stripe's smallest currency unit to a float amount
 func (s *paymentService) ConvertFromStripeAmount(amount int64, currency string) float64 {
 	return 0
-}	
+}
@@ -0,0 +1,281 @@
+package service
+
+import (
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+
+	"backend-ecommerce/internal/application/dto"
+	"backend-ecommerce/internal/application/entity"
+	"backend-ecommerce/internal/application/tools"
+	"backend-ecommerce/internal/infrastructure/cachemanager"
+	"backend-ecommerce/internal/infrastructure/dbmanager"
+	"backend-ecommerce/internal/infrastructure/logger"
+)
+
+type priceScheduleService struct {
+	mu        sync.Mutex
+	lastCheck time.Time
+}
+
+// GetProductPriceSchedules returns every price schedule of a product, newest first
+func (s *priceScheduleService) GetProductPriceSchedules(productID string) dto.ResponseDto {
+	var schedules []entity.PriceSchedule
+
+	db := dbmanager.GetDB()
+	if err := db.Where("product_id = ?", productID).Order("starts_at DESC").Find(&schedules).Error; err != nil {
+		logger.Error("Error fetching price schedules: %v", err)
+		return *dto.Fail("Error fetching price schedules")
+	}
+
+	scheduleDtos := make([]dto.PriceScheduleResponse, len(schedules))
+	for i, schedule := range schedules {
+		scheduleDtos[i] = dto.GetPriceScheduleResponse(schedule)
+	}
+
+	return *dto.SuccessCount(scheduleDtos, int64(len(scheduleDtos)))
+}
+
+// CreatePriceSchedule schedules a sale window or a future permanent price for a product or variant
+func (s *priceScheduleService) CreatePriceSchedule(productID string, req dto.PriceScheduleCreateRequest, changedBy string) dto.ResponseDto {
+	db := dbmanager.GetDB()
+
+	var product entity.Product
+	if err := db.Select("id").Where("id = ?", productID).First(&product).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return *dto.Fail("Product not found")
+		}
+		logger.Error("Error fetching product: %v", err)
+		return *dto.Fail("Error fetching product")
+	}
+
+	if req.VariantID != nil {
+		var count int64
+		if err := db.Model(&entity.ProductVariant{}).Where("id = ? AND product_id = ?", *req.VariantID, productID).Count(&count).Error; err != nil {
+			logger.Error("Error fetching product variant: %v", err)
+			return *dto.Fail("Error fetching product variant")
+		}
+		if count == 0 {
+			return *dto.Fail("Product variant not found")
+		}
+	}
+
+	scheduleType := entity.PriceScheduleType(req.Type)
+	if scheduleType == entity.PriceSchedulePermanent && req.EndsAt != nil {
+		return *dto.Fail("A permanent price change cannot have an end time")
+	}
+	if req.EndsAt != nil && !req.EndsAt.After(req.StartsAt) {
+		return *dto.Fail("End time must be after start time")
+	}
+
+	var endsAt *time.Time
+	if req.EndsAt != nil {
+		t := req.EndsAt.UTC()
+		endsAt = &t
+	}
+
+	schedule := entity.PriceSchedule{
+		ID:        tools.NewUuid(),
+		ProductID: productID,
+		VariantID: req.VariantID,
+		Type:      scheduleType,
+		Price:     req.Price,
+		StartsAt:  req.StartsAt.UTC(),
+		EndsAt:    endsAt,
+	}
+
+	err := IProductRevisionService.Track(db, productID, changedBy, "Price scheduled", func(tx *gorm.DB) error {
+		return tx.Create(&schedule).Error
+	})
+	if err != nil {
+		logger.Error("Error creating price schedule: %v", err)
+		return *dto.Fail("Error creating price schedule")
+	}
+
+	ICollectionService.RefreshProductMemberships([]string{productID})
+	// A schedule starting in the past takes effect immediately
+	cachemanager.DeletePrefix(productCachePrefix)
+
+	return *dto.Success(dto.GetPriceScheduleResponse(schedule))
+}
+
+// DeletePriceSchedule removes a schedule. Applied permanent changes are kept
+// for history and cannot be deleted.
+func (s *priceScheduleService) DeletePriceSchedule(id, changedBy string) dto.ResponseDto {
+	db := dbmanager.GetDB()
+
+	var schedule entity.PriceSchedule
+	if err := db.Where("id = ?", id).First(&schedule).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return *dto.Fail("Price schedule not found")
+		}
+		logger.Error("Error fetching price schedule: %v", err)
+		return *dto.Fail("Error fetching price schedule")
+	}
+
+	if schedule.AppliedAt != nil {
+		return *dto.Fail("Price schedule has already been applied")
+	}
+
+	err := IProductRevisionService.Track(db, schedule.ProductID, changedBy, "Price schedule deleted", func(tx *gorm.DB) error {
+		return tx.Delete(&schedule).Error
+	})
+	if err != nil {
+		logger.Error("Error deleting price schedule: %v", err)
+		return *dto.Fail("Error deleting price schedule")
+	}
+
+	ICollectionService.RefreshProductMemberships([]string{schedule.ProductID})
+	cachemanager.DeletePrefix(productCachePrefix)
+
+	return *dto.Success("Price schedule deleted successfully")
+}
+
+// ResolvePrices returns the effective price of each product and each of their
+// variants at t, keyed by product ID and variant ID respectively.
+func (s *priceScheduleService) ResolvePrices(db *gorm.DB, products []entity.Product, t time.Time) (map[string]entity.EffectivePrice, map[string]entity.EffectivePrice, error) {
+	productPrices := make(map[string]entity.EffectivePrice, len(products))
+	variantPrices := make(map[string]entity.EffectivePrice)
+	if len(products) == 0 {
+		return productPrices, variantPrices, nil
+	}
+
+	productIDs := make([]string, len(products))
+	for i, product := range products {
+		productIDs[i] = product.ID
+	}
+
+	schedules, err := s.relevantSchedules(db, productIDs, t)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	productSchedules := make(map[string][]entity.PriceSchedule)
+	variantSchedules := make(map[string][]entity.PriceSchedule)
+	for _, schedule := range schedules {
+		if schedule.VariantID != nil {
+			variantSchedules[*schedule.VariantID] = append(variantSchedules[*schedule.VariantID], schedule)
+		} else {
+			productSchedules[schedule.ProductID] = append(productSchedules[schedule.ProductID], schedule)
+		}
+	}
+
+	for _, product := range products {
+		productPrices[product.ID] = entity.ResolvePrice(product.Price, productSchedules[product.ID], t)
+		for _, variant := range product.Variants {
+			variantPrices[variant.ID] = entity.ResolvePrice(variant.Price, variantSchedules[variant.ID], t)
+		}
+	}
+
+	return productPrices, variantPrices, nil
+}
+
+// ResolvePrice returns the effective price of a product, or of one of its
+// variants when variant is set, at t. Used wherever a price is charged.
+func (s *priceScheduleService) ResolvePrice(db *gorm.DB, product entity.Product, variant *entity.ProductVariant, t time.Time) (entity.EffectivePrice, error) {
+	schedules, err := s.relevantSchedules(db, []string{product.ID}, t)
+	if err != nil {
+		return entity.EffectivePrice{}, err
+	}
+
+	regular := product.Price
+	var targetID *string
+	if variant != nil {
+		regular = variant.Price
+		targetID = &variant.ID
+	}
+
+	var matching []entity.PriceSchedule
+	for _, schedule := range schedules {
+		if (targetID == nil && schedule.VariantID == nil) ||
+			(targetID != nil && schedule.VariantID != nil && *schedule.VariantID == *targetID) {
+			matching = append(matching, schedule)
+		}
+	}
+
+	return entity.ResolvePrice(regular, matching, t), nil
+}
+
+// relevantSchedules loads the schedules that can affect prices at t: started
+// sales that have not ended and permanent changes not yet applied.
+func (s *priceScheduleService) relevantSchedules(db *gorm.DB, productIDs []string, t time.Time) ([]entity.PriceSchedule, error) {
+	var schedules []entity.PriceSchedule
+	err := db.Where("product_id IN ? AND starts_at <= ?", productIDs, t).
+		Where("(type = ? AND (ends_at IS NULL OR ends_at > ?)) OR (type = ? AND applied_at IS NULL)",
+			entity.PriceScheduleSale, t, entity.PriceSchedulePermanent).
+		Find(&schedules).Error
+	return schedules, err
+}
+
+// ProcessPriceWindows is run by cronmanager. It writes due permanent price
+// changes to their product or variant and invalidates cached listings when
+// any sale window has opened or closed since the previous run.
+func (s *priceScheduleService) ProcessPriceWindows() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	db := dbmanager.GetDB()
+	if db == nil {
+		return
+	}
+
+	now := time.Now().UTC()
+	since := s.lastCheck
+	if since.IsZero() {
+		// Cached listings may predate the process, so treat the last day as unseen
+		since = now.Add(-24 * time.Hour)
+	}
+
+	var changed int64
+	if err := db.Model(&entity.PriceSchedule{}).
+		Where("(starts_at > ? AND starts_at <= ?) OR (ends_at > ? AND ends_at <= ?)", since, now, since, now).
+		Count(&changed).Error; err != nil {
+		logger.Error("Error checking price windows: %v", err)
+		return
+	}
+
+	applied, err := s.applyPermanentChanges(db, now)
+	if err != nil {
+		logger.Error("Error applying scheduled prices: %v", err)
+		return
+	}
+
+	if changed > 0 || applied > 0 {
+		cachemanager.DeletePrefix(productCachePrefix)
+		logger.Info("Price windows changed (%d schedules, %d applied), product cache invalidated", changed, applied)
+	}
+
+	s.lastCheck = now
+}
+
+// applyPermanentChanges writes every due permanent price change, oldest first,
+// so the most recent one ends up as the regular price.
+func (s *priceScheduleService) applyPermanentChanges(db *gorm.DB, now time.Time) (int, error) {
+	var due []entity.PriceSchedule
+	if err := db.Where("type = ? AND applied_at IS NULL AND starts_at <= ?", entity.PriceSchedulePermanent, now).
+		Order("starts_at ASC").
+		Find(&due).Error; err != nil {
+		return 0, err
+	}
+
+	for _, schedule := range due {
+		err := IProductRevisionService.Track(db, schedule.ProductID, "", "Scheduled price applied", func(tx *gorm.DB) error {
+			if schedule.VariantID != nil {
+				if err := tx.Model(&entity.ProductVariant{}).Where("id = ?", *schedule.VariantID).Update("price", schedule.Price).Error; err != nil {
+					return err
+				}
+			} else {
+				if err := tx.Model(&entity.Product{}).Where("id = ?", schedule.ProductID).Update("price", schedule.Price).Error; err != nil {
+					return err
+				}
+			}
+			return tx.Model(&entity.PriceSchedule{}).Where("id = ?", schedule.ID).Update("applied_at", now).Error
+		})
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	return len(due), nil
+}
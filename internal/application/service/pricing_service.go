@@ -0,0 +1,838 @@
+package service
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+
+	"backend-ecommerce/internal/application/dto"
+	"backend-ecommerce/internal/application/entity"
+	"backend-ecommerce/internal/infrastructure/config"
+)
+
+type pricingService struct {
+}
+
+// pricingCart is a cart to be priced: its lines and who is buying them.
+// The discount codes applied to the cart are looked up by CartID.
+type pricingCart struct {
+	CartID string
+	// UserID is empty for guests
+	UserID string
+	Lines  []pricingLine
+	// Promotions, when not nil, are evaluated instead of the active
+	// promotions, whatever their status and validity window. The promotion
+	// simulator prices sample carts with it.
+	Promotions []entity.Promotion
+}
+
+// pricingLine is a cart line to be priced
+type pricingLine struct {
+	LineID    string
+	ProductID string
+	VariantID *string
+	Quantity  int
+}
+
+// pricedLine is a line while a quote is built. Amounts are in cents so that
+// every step rounds the same way, whatever order the lines come in.
+type pricedLine struct {
+	pricingLine
+	Requested        int
+	Name             string
+	SKU              string
+	Offered          bool
+	Available        int
+	RequiresShipping bool
+	Unit             int64
+	Subtotal         int64
+	Discount         int64
+	Tax              int64
+	// Promotions explains the discounts of automatic promotions on the line
+	Promotions []entity.LinePromotion
+	// GiftCard lines are never discounted, as the card is worth its price
+	GiftCard bool
+}
+
+// pricingAdjustment is an adjustment in cents while a quote is built
+type pricingAdjustment struct {
+	Type   entity.AdjustmentType
+	Source string
+	Label  string
+	LineID string
+	// OnShipping marks discounts taken off the shipping charge rather than a line
+	OnShipping bool
+	Amount     int64
+}
+
+// pricingState is a quote being built by the pricing steps
+type pricingState struct {
+	db     *gorm.DB
+	cartID string
+	userID string
+	shipTo *entity.Address
+	lines  []*pricedLine
+	// products holds the published products among the lines, keyed by ID
+	products    map[string]dto.ProductResponse
+	adjustments []pricingAdjustment
+	coupons     []entity.QuoteCoupon
+	// candidates overrides the active promotions; promotions are the results
+	candidates []entity.Promotion
+	promotions []entity.QuotePromotion
+	// freeShipping holds the waivers of free shipping codes, applied by
+	// applyShipping once the shipping charge is known
+	freeShipping []pricingAdjustment
+}
+
+// pricingStep is one stage of the pricing pipeline. Steps that add
+// discounts run before shipping and tax, which depend on the discounted
+// amounts.
+type pricingStep func(state *pricingState) error
+
+// quote prices a cart for delivery to shipTo, which may be nil for an
+// estimate. The cart view and order creation both price through it, so a
+// customer is charged exactly what the cart showed. The returned products
+// are the published products among the lines, keyed by ID.
+func (s *pricingService) quote(db *gorm.DB, cart pricingCart, shipTo *entity.Address) (*entity.PriceQuote, map[string]dto.ProductResponse, error) {
+	state := &pricingState{db: db, cartID: cart.CartID, userID: cart.UserID, shipTo: shipTo, candidates: cart.Promotions}
+	for _, line := range cart.Lines {
+		state.lines = append(state.lines, &pricedLine{pricingLine: line, Requested: line.Quantity})
+	}
+
+	for _, step := range s.steps() {
+		if err := step(state); err != nil {
+			return nil, nil, err
+		}
+	}
+	return state.quote(), state.products, nil
+}
+
+// steps lists the pricing pipeline in order
+func (s *pricingService) steps() []pricingStep {
+	return []pricingStep{
+		s.priceLines,
+		s.applyPromotions,
+		s.applyCoupons,
+		s.applyShipping,
+		s.applyTax,
+	}
+}
+
+// priceLines prices each line at the current catalog price of its product
+// or variant, for no more than the stock available. Lines that are no longer
+// on sale stay in the quote, marked as not offered, but are left out of the
+// totals.
+func (s *pricingService) priceLines(state *pricingState) error {
+	productIDs := make([]string, len(state.lines))
+	for i, line := range state.lines {
+		productIDs[i] = line.ProductID
+	}
+	products, err := IProductService.publishedProductResponses(state.db, productIDs)
+	if err != nil {
+		return err
+	}
+	state.products = products
+	giftCards, err := IGiftCardService.giftCardProducts(state.db, productIDs)
+	if err != nil {
+		return err
+	}
+
+	for _, line := range state.lines {
+		product, ok := products[line.ProductID]
+		if !ok {
+			continue
+		}
+		price, inventory, ok := dto.CartLineOffer(product, line.VariantID)
+		if !ok {
+			continue
+		}
+
+		line.Name, line.SKU = product.Name, product.SKU
+		if line.VariantID != nil {
+			for _, variant := range product.Variants {
+				if variant.ID == *line.VariantID {
+					line.Name = product.Name + " - " + variant.Name
+					if variant.SKU != "" {
+						line.SKU = variant.SKU
+					}
+				}
+			}
+		}
+		line.Offered = true
+		line.Available = -1
+		if inventory != nil {
+			line.Available = inventory.Available
+			line.Quantity = min(line.Quantity, max(0, inventory.Available))
+		}
+		line.RequiresShipping = product.Type != string(entity.ProductTypeDigital)
+		line.GiftCard = giftCards[line.ProductID]
+		line.Unit = toCents(price)
+		line.Subtotal = line.Unit * int64(line.Quantity)
+	}
+	return nil
+}
+
+// applyPromotions applies the automatic promotions the cart qualifies for,
+// by descending priority, before any discount code. Once an exclusive
+// promotion applies no other promotion does, and an exclusive promotion is
+// skipped when others already applied. Promotions that do not apply stay in
+// the quote with the reason.
+func (s *pricingService) applyPromotions(state *pricingState) error {
+	promotions := state.candidates
+	if promotions == nil {
+		var err error
+		promotions, err = IPromotionService.active(state.db, time.Now().UTC())
+		if err != nil {
+			return err
+		}
+	}
+	sort.SliceStable(promotions, func(i, j int) bool { return promotions[i].Priority > promotions[j].Priority })
+
+	var exclusive *entity.Promotion
+	applied := 0
+	for i := range promotions {
+		promotion := &promotions[i]
+		result := entity.QuotePromotion{
+			PromotionID: promotion.ID,
+			Name:        promotion.Name,
+			Type:        promotion.Type,
+		}
+
+		var discounts []promotionDiscount
+		switch {
+		case exclusive != nil:
+			result.Reason = fmt.Sprintf("Not combined with %s", exclusive.Name)
+		case promotion.Exclusive && applied > 0:
+			result.Reason = "Not combined with the promotions already applied"
+		default:
+			discounts, result.Reason = s.promotionDiscounts(state, promotion)
+		}
+
+		source := "promotion:" + promotion.ID
+		for _, discount := range discounts {
+			amount := state.discount(discount.line, discount.amount, source, promotion.Name)
+			if amount == 0 {
+				continue
+			}
+			result.Applied = true
+			discount.line.Promotions = append(discount.line.Promotions, entity.LinePromotion{
+				PromotionID: promotion.ID,
+				Name:        promotion.Name,
+				Explanation: discount.explanation,
+				Amount:      fromCents(amount),
+			})
+		}
+		if !result.Applied && result.Reason == "" {
+			result.Reason = "Your cart is already discounted as much as this promotion allows"
+		}
+		if result.Applied {
+			applied++
+			if promotion.Exclusive {
+				exclusive = promotion
+			}
+		}
+		state.promotions = append(state.promotions, result)
+	}
+	return nil
+}
+
+// promotionDiscount is what a promotion takes off one line, and why
+type promotionDiscount struct {
+	line        *pricedLine
+	amount      int64
+	explanation string
+}
+
+// promotionDiscounts works out the discounts a promotion gives the cart. When
+// the cart does not qualify it returns why.
+func (s *pricingService) promotionDiscounts(state *pricingState, promotion *entity.Promotion) ([]promotionDiscount, string) {
+	rules := promotion.Rules
+	var qualifying []*pricedLine
+	for _, line := range state.lines {
+		if line.Offered && line.Quantity > 0 && !line.GiftCard && rules.Qualifies(line.ProductID, state.products[line.ProductID].CategoryID) {
+			qualifying = append(qualifying, line)
+		}
+	}
+
+	switch promotion.Type {
+	case entity.PromotionBuyXGetY:
+		var rewarding []*pricedLine
+		for _, line := range state.lines {
+			if line.Offered && line.Quantity > 0 && !line.GiftCard && rules.Rewards(line.ProductID, state.products[line.ProductID].CategoryID) {
+				rewarding = append(rewarding, line)
+			}
+		}
+		discounts := rewardUnits(qualifying, rewarding, rules.BuyQuantity, rules.GetQuantity, rules.Percent)
+		if len(discounts) == 0 {
+			return nil, fmt.Sprintf("Buy %d qualifying items to get %d at %s%% off", rules.BuyQuantity, rules.GetQuantity, formatRate(rules.Percent))
+		}
+		return discounts, ""
+	case entity.PromotionMultiBuy:
+		discounts := rewardUnits(qualifying, qualifying, rules.BuyQuantity-rules.GetQuantity, rules.GetQuantity, 100)
+		if len(discounts) == 0 {
+			return nil, fmt.Sprintf("Buy %d qualifying items to pay for %d", rules.BuyQuantity, rules.BuyQuantity-rules.GetQuantity)
+		}
+		return discounts, ""
+	case entity.PromotionSpendThreshold:
+		return spendDiscounts(qualifying, rules.Tiers)
+	case entity.PromotionBundle:
+		return bundleDiscounts(qualifying, rules)
+	}
+	return nil, "Unknown promotion type"
+}
+
+// promotionUnit is one unit of a line, for promotions counted in units
+type promotionUnit struct {
+	line *pricedLine
+	n    int
+}
+
+// rewardUnits groups units of the cart into buy units and get units and
+// discounts the get units by percent. The most expensive qualifying units are
+// bought and the cheapest reward units given, so a unit is never counted
+// twice and the shop gives away the least.
+func rewardUnits(qualifying, rewarding []*pricedLine, buy, get int, percent float64) []promotionDiscount {
+	if buy <= 0 || get <= 0 {
+		return nil
+	}
+	units := func(lines []*pricedLine) []promotionUnit {
+		var units []promotionUnit
+		for _, line := range lines {
+			for n := 0; n < line.Quantity; n++ {
+				units = append(units, promotionUnit{line: line, n: n})
+			}
+		}
+		return units
+	}
+	bought := units(qualifying)
+	sort.SliceStable(bought, func(i, j int) bool { return bought[i].line.Unit > bought[j].line.Unit })
+	given := units(rewarding)
+	sort.SliceStable(given, func(i, j int) bool { return given[i].line.Unit < given[j].line.Unit })
+
+	used := make(map[promotionUnit]bool)
+	free := make(map[*pricedLine]int)
+	next := func(units []promotionUnit, from *int, count int) []promotionUnit {
+		var picked []promotionUnit
+		for len(picked) < count && *from < len(units) {
+			unit := units[*from]
+			*from++
+			if !used[unit] {
+				used[unit] = true
+				picked = append(picked, unit)
+			}
+		}
+		return picked
+	}
+	b, g := 0, 0
+	for {
+		if len(next(bought, &b, buy)) < buy {
+			break
+		}
+		rewards := next(given, &g, get)
+		if len(rewards) < get {
+			break
+		}
+		for _, unit := range rewards {
+			free[unit.line]++
+		}
+	}
+
+	basisPoints := int64(math.Round(percent * 100))
+	var discounts []promotionDiscount
+	for _, line := range rewarding {
+		count := free[line]
+		if count == 0 {
+			continue
+		}
+		explanation := fmt.Sprintf("%d of %d free", count, line.Quantity)
+		if basisPoints < 10000 {
+			explanation = fmt.Sprintf("%d of %d at %s%% off", count, line.Quantity, formatRate(percent))
+		}
+		discounts = append(discounts, promotionDiscount{
+			line:        line,
+			amount:      percentOf(line.Unit*int64(count), basisPoints),
+			explanation: explanation,
+		})
+	}
+	return discounts
+}
+
+// spendDiscounts takes the percentage of the highest tier reached off the
+// qualifying lines, after their earlier discounts
+func spendDiscounts(lines []*pricedLine, tiers []entity.PromotionTier) ([]promotionDiscount, string) {
+	var subtotal int64
+	for _, line := range lines {
+		subtotal += line.Subtotal - line.Discount
+	}
+	var reached, lowest *entity.PromotionTier
+	for i := range tiers {
+		tier := &tiers[i]
+		if lowest == nil || tier.MinSubtotal < lowest.MinSubtotal {
+			lowest = tier
+		}
+		if subtotal >= toCents(tier.MinSubtotal) && (reached == nil || tier.MinSubtotal > reached.MinSubtotal) {
+			reached = tier
+		}
+	}
+	if reached == nil {
+		if lowest == nil {
+			return nil, "This promotion has no tiers"
+		}
+		return nil, fmt.Sprintf("Spend %.2f on qualifying items to save %s%%", lowest.MinSubtotal, formatRate(lowest.Percent))
+	}
+
+	basisPoints := int64(math.Round(reached.Percent * 100))
+	explanation := fmt.Sprintf("%s%% off for spending %.2f", formatRate(reached.Percent), reached.MinSubtotal)
+	discounts := make([]promotionDiscount, len(lines))
+	for i, line := range lines {
+		discounts[i] = promotionDiscount{line: line, amount: percentOf(line.Subtotal-line.Discount, basisPoints), explanation: explanation}
+	}
+	return discounts, ""
+}
+
+// bundleDiscounts discounts every complete set of the bundle's products. A
+// fixed amount per set is spread over the bundled units in proportion to
+// their price.
+func bundleDiscounts(lines []*pricedLine, rules entity.PromotionRules) ([]promotionDiscount, string) {
+	if len(rules.ProductIDs) == 0 {
+		return nil, "This bundle has no products"
+	}
+	quantities := make(map[string]int)
+	for _, line := range lines {
+		quantities[line.ProductID] += line.Quantity
+	}
+	sets := -1
+	for _, productID := range rules.ProductIDs {
+		if sets < 0 || quantities[productID] < sets {
+			sets = quantities[productID]
+		}
+	}
+	if sets <= 0 {
+		return nil, fmt.Sprintf("Buy all %d products of the bundle together", len(rules.ProductIDs))
+	}
+
+	// Take the units of each set from the product's lines in cart order
+	bundled := make(map[*pricedLine]int)
+	remaining := make(map[string]int)
+	for _, productID := range rules.ProductIDs {
+		remaining[productID] = sets
+	}
+	var base int64
+	var bundledLines []*pricedLine
+	for _, line := range lines {
+		count := min(line.Quantity, remaining[line.ProductID])
+		if count == 0 {
+			continue
+		}
+		remaining[line.ProductID] -= count
+		bundled[line] = count
+		bundledLines = append(bundledLines, line)
+		base += line.Unit * int64(count)
+	}
+
+	var discounts []promotionDiscount
+	if rules.Amount > 0 {
+		amount := min(toCents(rules.Amount)*int64(sets), base)
+		left := amount
+		for i, line := range bundledLines {
+			share := left
+			if i < len(bundledLines)-1 {
+				share = amount * line.Unit * int64(bundled[line]) / base
+			}
+			left -= share
+			discounts = append(discounts, promotionDiscount{
+				line:        line,
+				amount:      share,
+				explanation: fmt.Sprintf("%d bundled, %.2f off each set", bundled[line], rules.Amount),
+			})
+		}
+		return discounts, ""
+	}
+	basisPoints := int64(math.Round(rules.Percent * 100))
+	for _, line := range bundledLines {
+		discounts = append(discounts, promotionDiscount{
+			line:        line,
+			amount:      percentOf(line.Unit*int64(bundled[line]), basisPoints),
+			explanation: fmt.Sprintf("%d bundled at %s%% off", bundled[line], formatRate(rules.Percent)),
+		})
+	}
+	return discounts, ""
+}
+
+// applyCoupons applies the discount codes on the cart in the order they were
+// added. Codes that cannot be used, or do not apply to the cart as it is,
+// stay in the quote with the reason. Free shipping codes are waived by
+// applyShipping.
+func (s *pricingService) applyCoupons(state *pricingState) error {
+	if state.cartID == "" {
+		return nil
+	}
+	var applied []entity.CartCoupon
+	if err := state.db.Preload("CouponCode.Coupon").Where("cart_id = ?", state.cartID).
+		Order("created_at ASC").Find(&applied).Error; err != nil {
+		return err
+	}
+
+	now := time.Now().UTC()
+	subtotal := state.discountedSubtotal()
+	var used []*entity.Coupon
+	for _, cartCoupon := range applied {
+		code := cartCoupon.CouponCode
+		if code == nil || code.Coupon == nil {
+			continue
+		}
+		coupon := code.Coupon
+		result := entity.QuoteCoupon{
+			CouponID: coupon.ID,
+			CodeID:   code.ID,
+			Code:     code.Code,
+			Name:     coupon.Name,
+			Type:     coupon.Type,
+		}
+
+		reason, err := ICouponService.unusable(state.db, code, state.userID, now)
+		if err != nil {
+			return err
+		}
+		if reason == "" {
+			reason = couponConflict(coupon, used)
+		}
+		var eligible []*pricedLine
+		if reason == "" {
+			eligible = s.couponLines(state, coupon)
+			if subtotal < toCents(coupon.MinSubtotal) {
+				reason = fmt.Sprintf("Spend at least %.2f to use this code", coupon.MinSubtotal)
+			} else if len(eligible) == 0 {
+				reason = "No items in your cart qualify for this code"
+			}
+		}
+		if reason != "" {
+			result.Reason = reason
+			state.coupons = append(state.coupons, result)
+			continue
+		}
+
+		result.Applied = true
+		state.coupons = append(state.coupons, result)
+		used = append(used, coupon)
+		s.discountLines(state, coupon, "coupon:"+code.Code, eligible)
+	}
+	return nil
+}
+
+// couponLines returns the lines a coupon can discount: for free shipping,
+// the eligible lines that ship. Gift cards are never eligible.
+func (s *pricingService) couponLines(state *pricingState, coupon *entity.Coupon) []*pricedLine {
+	var lines []*pricedLine
+	for _, line := range state.lines {
+		if !line.Offered || line.Quantity == 0 || line.GiftCard {
+			continue
+		}
+		if coupon.Type == entity.CouponFreeShipping && !line.RequiresShipping {
+			continue
+		}
+		if coupon.Eligible(line.ProductID, state.products[line.ProductID].CategoryID) {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
+
+// discountLines applies a coupon to its eligible lines. A fixed amount is
+// spread over the lines in proportion to their price, so each line is taxed
+// on what it actually costs.
+func (s *pricingService) discountLines(state *pricingState, coupon *entity.Coupon, source string, lines []*pricedLine) {
+	switch coupon.Type {
+	case entity.CouponPercentage:
+		basisPoints := int64(math.Round(coupon.Value * 100))
+		for _, line := range lines {
+			state.discount(line, percentOf(line.Subtotal-line.Discount, basisPoints), source, coupon.Name)
+		}
+	case entity.CouponFixedAmount:
+		var base int64
+		for _, line := range lines {
+			base += line.Subtotal - line.Discount
+		}
+		if base <= 0 {
+			return
+		}
+		amount := min(toCents(coupon.Value), base)
+		remaining := amount
+		for i, line := range lines {
+			share := remaining
+			if i < len(lines)-1 {
+				share = amount * (line.Subtotal - line.Discount) / base
+			}
+			remaining -= share
+			state.discount(line, share, source, coupon.Name)
+		}
+	case entity.CouponFreeShipping:
+		state.freeShipping = append(state.freeShipping, pricingAdjustment{
+			Type:       entity.AdjustmentDiscount,
+			Source:     source,
+			Label:      coupon.Name,
+			OnShipping: true,
+		})
+	}
+}
+
+// applyShipping charges the configured flat rate once for carts with goods
+// that ship, and waives it from the free shipping threshold or for a free
+// shipping code
+func (s *pricingService) applyShipping(state *pricingState) error {
+	cfg := config.Get().Pricing
+
+	shipping := false
+	for _, line := range state.lines {
+		if line.Offered && line.RequiresShipping {
+			shipping = true
+		}
+	}
+	rate := toCents(cfg.ShippingRate)
+	if !shipping || rate <= 0 {
+		return nil
+	}
+
+	state.adjustments = append(state.adjustments, pricingAdjustment{
+		Type:   entity.AdjustmentShipping,
+		Source: "shipping:flat_rate",
+		Label:  "Shipping",
+		Amount: rate,
+	})
+
+	threshold := toCents(cfg.FreeShippingThreshold)
+	if threshold > 0 && state.discountedSubtotal() >= threshold {
+		state.adjustments = append(state.adjustments, pricingAdjustment{
+			Type:       entity.AdjustmentDiscount,
+			Source:     "shipping:free_threshold",
+			Label:      fmt.Sprintf("Free shipping on orders over %.2f", cfg.FreeShippingThreshold),
+			OnShipping: true,
+			Amount:     -rate,
+		})
+	} else if len(state.freeShipping) > 0 {
+		waiver := state.freeShipping[0]
+		waiver.Amount = -rate
+		state.adjustments = append(state.adjustments, waiver)
+	}
+	return nil
+}
+
+// applyTax charges tax on each line after its discounts, and on shipping
+// when configured. Tax is rounded per line, half away from zero, and the
+// rate comes from the shipping address, falling back to the default rate
+// for estimates.
+func (s *pricingService) applyTax(state *pricingState) error {
+	region, rate := taxRate(state.shipTo)
+	basisPoints := int64(math.Round(rate * 100))
+	if basisPoints <= 0 {
+		return nil
+	}
+	source := "tax:" + region
+	label := fmt.Sprintf("Tax (%s%%)", formatRate(rate))
+
+	for _, line := range state.lines {
+		if !line.Offered {
+			continue
+		}
+		tax := percentOf(line.Subtotal-line.Discount, basisPoints)
+		if tax == 0 {
+			continue
+		}
+		line.Tax = tax
+		state.adjustments = append(state.adjustments, pricingAdjustment{
+			Type:   entity.AdjustmentTax,
+			Source: source,
+			Label:  label,
+			LineID: line.LineID,
+			Amount: tax,
+		})
+	}
+
+	if config.Get().Pricing.TaxShipping {
+		var shipping int64
+		for _, adjustment := range state.adjustments {
+			if adjustment.Type == entity.AdjustmentShipping || adjustment.OnShipping {
+				shipping += adjustment.Amount
+			}
+		}
+		if tax := percentOf(shipping, basisPoints); tax > 0 {
+			state.adjustments = append(state.adjustments, pricingAdjustment{
+				Type:   entity.AdjustmentTax,
+				Source: source,
+				Label:  label + " on shipping",
+				Amount: tax,
+			})
+		}
+	}
+	return nil
+}
+
+// discount takes amount cents off a line, as a discount adjustment, and
+// returns what was taken off. A line is never discounted below zero, and a
+// gift card line not at all.
+func (state *pricingState) discount(line *pricedLine, amount int64, source, label string) int64 {
+	amount = min(amount, line.Subtotal-line.Discount)
+	if amount <= 0 || line.GiftCard {
+		return 0
+	}
+	line.Discount += amount
+	state.adjustments = append(state.adjustments, pricingAdjustment{
+		Type:   entity.AdjustmentDiscount,
+		Source: source,
+		Label:  label,
+		LineID: line.LineID,
+		Amount: -amount,
+	})
+	return amount
+}
+
+// discountedSubtotal is the subtotal of the offered lines after line discounts
+func (state *pricingState) discountedSubtotal() int64 {
+	var total int64
+	for _, line := range state.lines {
+		if line.Offered {
+			total += line.Subtotal - line.Discount
+		}
+	}
+	return total
+}
+
+// quote converts the state into the quote returned to callers
+func (state *pricingState) quote() *entity.PriceQuote {
+	quote := &entity.PriceQuote{
+		Currency:    strings.ToUpper(config.Get().Pricing.Currency),
+		Lines:       make([]entity.QuoteLine, len(state.lines)),
+		Adjustments: make([]entity.PriceAdjustment, len(state.adjustments)),
+		Coupons:     make([]entity.QuoteCoupon, len(state.coupons)),
+		Promotions:  make([]entity.QuotePromotion, len(state.promotions)),
+	}
+
+	var subtotal, discounts, shipping, tax int64
+	for i, line := range state.lines {
+		quote.Lines[i] = entity.QuoteLine{
+			LineID:           line.LineID,
+			ProductID:        line.ProductID,
+			VariantID:        line.VariantID,
+			Name:             line.Name,
+			SKU:              line.SKU,
+			Quantity:         line.Quantity,
+			Requested:        line.Requested,
+			UnitPrice:        fromCents(line.Unit),
+			Subtotal:         fromCents(line.Subtotal),
+			Discount:         fromCents(line.Discount),
+			Tax:              fromCents(line.Tax),
+			Total:            fromCents(line.Subtotal - line.Discount + line.Tax),
+			Offered:          line.Offered,
+			Available:        line.Available,
+			RequiresShipping: line.RequiresShipping,
+			Promotions:       line.Promotions,
+		}
+		if line.Offered {
+			subtotal += line.Subtotal
+		}
+	}
+	for i, adjustment := range state.adjustments {
+		quote.Adjustments[i] = entity.PriceAdjustment{
+			Type:   adjustment.Type,
+			Source: adjustment.Source,
+			Label:  adjustment.Label,
+			LineID: adjustment.LineID,
+			Amount: fromCents(adjustment.Amount),
+		}
+		switch adjustment.Type {
+		case entity.AdjustmentDiscount:
+			discounts -= adjustment.Amount
+		case entity.AdjustmentShipping:
+			shipping += adjustment.Amount
+		case entity.AdjustmentTax:
+			tax += adjustment.Amount
+		}
+	}
+
+	for i, coupon := range state.coupons {
+		var amount int64
+		for _, adjustment := range state.adjustments {
+			if adjustment.Source == "coupon:"+coupon.Code {
+				amount -= adjustment.Amount
+			}
+		}
+		coupon.Amount = fromCents(amount)
+		quote.Coupons[i] = coupon
+	}
+
+	for i, promotion := range state.promotions {
+		var amount int64
+		for _, adjustment := range state.adjustments {
+			if adjustment.Source == "promotion:"+promotion.PromotionID {
+				amount -= adjustment.Amount
+			}
+		}
+		promotion.Amount = fromCents(amount)
+		quote.Promotions[i] = promotion
+	}
+
+	quote.Subtotal = fromCents(subtotal)
+	quote.DiscountTotal = fromCents(discounts)
+	quote.ShippingTotal = fromCents(shipping)
+	quote.TaxTotal = fromCents(tax)
+	quote.GrandTotal = fromCents(max(0, subtotal-discounts+shipping+tax))
+	return quote
+}
+
+// taxRate returns the tax region and rate in percent for an address. Rates
+// are looked up by "country-state", then country, then the default rate.
+func taxRate(address *entity.Address) (string, float64) {
+	cfg := config.Get().Pricing
+	if address != nil {
+		country := strings.ToLower(strings.TrimSpace(address.Country))
+		state := strings.ToLower(strings.TrimSpace(address.State))
+		if rate, ok := cfg.TaxRates[country+"-"+state]; ok && state != "" {
+			return country + "-" + state, rate
+		}
+		if rate, ok := cfg.TaxRates[country]; ok {
+			return country, rate
+		}
+	}
+	return "default", cfg.DefaultTaxRate
+}
+
+// toCents converts an amount to cents, rounding half away from zero
+func toCents(amount float64) int64 {
+	return int64(math.Round(amount * 100))
+}
+
+func fromCents(cents int64) float64 {
+	return float64(cents) / 100
+}
+
+// percentOf returns basisPoints/100 percent of cents, rounded half away from zero
+func percentOf(cents, basisPoints int64) int64 {
+	product := cents * basisPoints
+	if product < 0 {
+		return -((-product + 5000) / 10000)
+	}
+	return (product + 5000) / 10000
+}
+
+// couponConflict returns why a coupon cannot be used with the coupons already
+// applied, or "" if it can
+func couponConflict(coupon *entity.Coupon, applied []*entity.Coupon) string {
+	for _, other := range applied {
+		if other.ID == coupon.ID {
+			return "A code for this offer is already applied"
+		}
+		if !coupon.Combinable || !other.Combinable {
+			return "This code cannot be combined with the other codes applied"
+		}
+	}
+	return ""
+}
+
+// formatRate formats a percentage without trailing zeros, e.g. 7.25 or 20
+func formatRate(rate float64) string {
+	return strings.TrimRight(strings.TrimRight(fmt.Sprintf("%.3f", rate), "0"), ".")
+}
@@ -0,0 +1,442 @@
+package service
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"gorm.io/gorm"
+
+	"backend-ecommerce/internal/application/dto"
+	"backend-ecommerce/internal/application/entity"
+	"backend-ecommerce/internal/application/tools"
+	"backend-ecommerce/internal/infrastructure/dbmanager"
+	"backend-ecommerce/internal/infrastructure/logger"
+)
+
+// alertCheckBatch is how many products are checked at a time
+const alertCheckBatch = 500
+
+type productAlertService struct {
+}
+
+// alertOffer is what a customer would see for a product right now
+type alertOffer struct {
+	// prices holds the effective price of the product and of each active variant, keyed by ID
+	prices    map[string]float64
+	available map[string]int
+	unlimited map[string]bool
+}
+
+func (o alertOffer) inStock(productID string) bool {
+	return o.unlimited[productID] || o.available[productID] > 0
+}
+
+// GetUserAlerts returns a customer's alerts, newest first
+func (s *productAlertService) GetUserAlerts(userID string) dto.ResponseDto {
+	db := dbmanager.GetDB()
+
+	var alerts []entity.ProductAlert
+	if err := db.Preload("Product").Preload("Variant").
+		Where("user_id = ? AND status <> ?", userID, entity.ProductAlertStatusCancelled).
+		Order("created_at DESC").Find(&alerts).Error; err != nil {
+		logger.Error("Error fetching product alerts: %v", err)
+		return *dto.Fail("Error fetching alerts")
+	}
+
+	alertDtos := make([]dto.ProductAlertResponse, len(alerts))
+	for i, alert := range alerts {
+		alertDtos[i] = dto.GetProductAlertResponse(alert)
+	}
+
+	return *dto.SuccessCount(alertDtos, int64(len(alertDtos)))
+}
+
+// CreateAlert subscribes a customer to a product, variant or wishlist item.
+// Subscribing again to the same target updates the existing alert.
+func (s *productAlertService) CreateAlert(userID string, req dto.ProductAlertCreateRequest) dto.ResponseDto {
+	db := dbmanager.GetDB()
+
+	productID := req.ProductID
+	if req.WishlistItemID != nil {
+		var item entity.WishlistItem
+		if err := db.Joins("JOIN wishlists ON wishlists.id = wishlistItems.wishlist_id").
+			Where("wishlistItems.id = ? AND wishlists.user_id = ?", *req.WishlistItemID, userID).
+			First(&item).Error; err != nil {
+			if err == gorm.ErrRecordNotFound {
+				return *dto.Fail("Wishlist item not found")
+			}
+			logger.Error("Error fetching wishlist item: %v", err)
+			return *dto.Fail("Error creating alert")
+		}
+		productID = item.ProductID
+	}
+	if productID == "" {
+		return *dto.Fail("A product or wishlist item is required")
+	}
+
+	var product entity.Product
+	if err := publishedProducts(db.Preload("Variants", "is_active = ?", true)).
+		Where("id = ?", productID).First(&product).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return *dto.Fail("Product not found")
+		}
+		logger.Error("Error fetching product: %v", err)
+		return *dto.Fail("Error creating alert")
+	}
+
+	target := product.ID
+	if req.VariantID != nil {
+		found := false
+		for _, variant := range product.Variants {
+			found = found || variant.ID == *req.VariantID
+		}
+		if !found {
+			return *dto.Fail("Variant not found")
+		}
+		target = *req.VariantID
+	}
+
+	offer, err := s.currentOffer(db, []entity.Product{product}, time.Now().UTC())
+	if err != nil {
+		logger.Error("Error resolving product offer: %v", err)
+		return *dto.Fail("Error creating alert")
+	}
+
+	alertType := entity.ProductAlertType(req.Type)
+	switch alertType {
+	case entity.ProductAlertBackInStock:
+		if offer.inStock(product.ID) {
+			return *dto.Fail("This product is in stock")
+		}
+	case entity.ProductAlertPriceDrop:
+		if req.TargetPrice != nil && *req.TargetPrice >= offer.prices[target] {
+			return *dto.Fail("The target price must be below the current price")
+		}
+	}
+
+	query := db.Where("user_id = ? AND type = ? AND product_id = ? AND status = ?",
+		userID, alertType, product.ID, entity.ProductAlertStatusActive)
+	if req.VariantID != nil {
+		query = query.Where("variant_id = ?", *req.VariantID)
+	} else {
+		query = query.Where("variant_id IS NULL")
+	}
+
+	var alert entity.ProductAlert
+	err = query.First(&alert).Error
+	switch {
+	case err == nil:
+		alert.TargetPrice = req.TargetPrice
+		alert.PriceAtSignup = offer.prices[target]
+		if req.WishlistItemID != nil {
+			alert.WishlistItemID = req.WishlistItemID
+		}
+		err = db.Save(&alert).Error
+	case err == gorm.ErrRecordNotFound:
+		alert = entity.ProductAlert{
+			ID:             tools.NewUuid(),
+			UserID:         userID,
+			Type:           alertType,
+			ProductID:      product.ID,
+			VariantID:      req.VariantID,
+			WishlistItemID: req.WishlistItemID,
+			TargetPrice:    req.TargetPrice,
+			PriceAtSignup:  offer.prices[target],
+			Status:         entity.ProductAlertStatusActive,
+		}
+		err = db.Create(&alert).Error
+	}
+	if err != nil {
+		logger.Error("Error saving product alert: %v", err)
+		return *dto.Fail("Error creating alert")
+	}
+
+	alert.Product = &product
+	for i := range product.Variants {
+		if req.VariantID != nil && product.Variants[i].ID == *req.VariantID {
+			alert.Variant = &product.Variants[i]
+		}
+	}
+
+	return *dto.Success(dto.GetProductAlertResponse(alert))
+}
+
+// CancelAlert stops an active alert of a customer
+func (s *productAlertService) CancelAlert(userID, id string) dto.ResponseDto {
+	db := dbmanager.GetDB()
+
+	result := db.Model(&entity.ProductAlert{}).
+		Where("id = ? AND user_id = ? AND status = ?", id, userID, entity.ProductAlertStatusActive).
+		Update("status", entity.ProductAlertStatusCancelled)
+	if result.Error != nil {
+		logger.Error("Error cancelling product alert: %v", result.Error)
+		return *dto.Fail("Error cancelling alert")
+	}
+	if result.RowsAffected == 0 {
+		return *dto.Fail("Alert not found")
+	}
+
+	return *dto.Success("Alert cancelled successfully")
+}
+
+// ProcessAlerts is run by cronmanager. It checks every product with active
+// alerts and queues notifications for those that came back in stock or
+// dropped in price.
+func (s *productAlertService) ProcessAlerts() {
+	db := dbmanager.GetDB()
+	if db == nil {
+		return
+	}
+
+	var productIDs []string
+	if err := db.Model(&entity.ProductAlert{}).Distinct("product_id").
+		Where("status = ?", entity.ProductAlertStatusActive).Pluck("product_id", &productIDs).Error; err != nil {
+		logger.Error("Error fetching product alerts: %v", err)
+		return
+	}
+
+	for start := 0; start < len(productIDs); start += alertCheckBatch {
+		end := min(start+alertCheckBatch, len(productIDs))
+		s.CheckProducts(productIDs[start:end])
+	}
+}
+
+// CheckProducts triggers the active alerts of the given products whose
+// condition is now met. Code that changes stock or prices can call it to
+// notify customers without waiting for the next scheduled run.
+func (s *productAlertService) CheckProducts(productIDs []string) {
+	if len(productIDs) == 0 {
+		return
+	}
+	db := dbmanager.GetDB()
+
+	var alerts []entity.ProductAlert
+	if err := db.Where("product_id IN ? AND status = ?", productIDs, entity.ProductAlertStatusActive).
+		Find(&alerts).Error; err != nil {
+		logger.Error("Error fetching product alerts: %v", err)
+		return
+	}
+	if len(alerts) == 0 {
+		return
+	}
+
+	// Unpublished products cannot be bought, so their alerts wait
+	var products []entity.Product
+	if err := publishedProducts(db.Preload("Variants", "is_active = ?", true)).
+		Where("id IN ?", productIDs).Find(&products).Error; err != nil {
+		logger.Error("Error fetching products: %v", err)
+		return
+	}
+	offer, err := s.currentOffer(db, products, time.Now().UTC())
+	if err != nil {
+		logger.Error("Error resolving product offers: %v", err)
+		return
+	}
+
+	byID := make(map[string]entity.Product, len(products))
+	variants := make(map[string]entity.ProductVariant)
+	for _, product := range products {
+		byID[product.ID] = product
+		for _, variant := range product.Variants {
+			variants[variant.ID] = variant
+		}
+	}
+
+	triggered := 0
+	for _, alert := range alerts {
+		product, ok := byID[alert.ProductID]
+		if !ok {
+			continue
+		}
+		target := alert.ProductID
+		name := product.Name
+		if alert.VariantID != nil {
+			variant, ok := variants[*alert.VariantID]
+			if !ok {
+				continue
+			}
+			target = variant.ID
+			name = fmt.Sprintf("%s (%s)", product.Name, variant.Name)
+		}
+		price, ok := offer.prices[target]
+		if !ok {
+			continue
+		}
+
+		var notification entity.Notification
+		switch alert.Type {
+		case entity.ProductAlertBackInStock:
+			if !offer.inStock(product.ID) {
+				continue
+			}
+			notification = entity.Notification{
+				Subject: fmt.Sprintf("%s is back in stock", name),
+				Body:    fmt.Sprintf("Good news: %s is available again at %.2f %s. Stock may be limited.", name, price, product.Currency),
+			}
+		case entity.ProductAlertPriceDrop:
+			if price >= alert.Threshold() {
+				continue
+			}
+			notification = entity.Notification{
+				Subject: fmt.Sprintf("Price drop on %s", name),
+				Body:    fmt.Sprintf("%s is now %.2f %s, down from %.2f %s.", name, price, product.Currency, alert.PriceAtSignup, product.Currency),
+			}
+		default:
+			continue
+		}
+		notification.UserID = alert.UserID
+		notification.Kind = string(alert.Type)
+		// Alerts for the same target, e.g. one from a wishlist and one from the
+		// product page, produce one message per day
+		notification.DedupKey = fmt.Sprintf("%s:%s:%s:%s", alert.Type, alert.UserID, target, time.Now().UTC().Format("2006-01-02"))
+
+		now := time.Now().UTC()
+		fired := false
+		err := db.Transaction(func(tx *gorm.DB) error {
+			// The status check guards against another run triggering the alert first
+			result := tx.Model(&entity.ProductAlert{}).
+				Where("id = ? AND status = ?", alert.ID, entity.ProductAlertStatusActive).
+				Updates(map[string]interface{}{"status": entity.ProductAlertStatusTriggered, "triggered_at": now})
+			if result.Error != nil || result.RowsAffected == 0 {
+				return result.Error
+			}
+			fired = true
+			_, err := INotificationService.Enqueue(tx, notification)
+			return err
+		})
+		if err != nil {
+			logger.Error("Error triggering product alert %s: %v", alert.ID, err)
+			continue
+		}
+		if fired {
+			triggered++
+		}
+	}
+
+	if triggered > 0 {
+		logger.Info("Product alerts triggered: %d", triggered)
+	}
+}
+
+// GetDemandReport lists active subscriber counts per SKU, most wanted
+// first, so admins can prioritise restocking
+func (s *productAlertService) GetDemandReport() dto.ResponseDto {
+	db := dbmanager.GetDB()
+
+	var rows []struct {
+		ProductID   string
+		VariantID   *string
+		BackInStock int
+		PriceDrop   int
+		Oldest      time.Time
+	}
+	if err := db.Model(&entity.ProductAlert{}).
+		Select("product_id, variant_id, "+
+			"SUM(CASE WHEN type = ? THEN 1 ELSE 0 END) AS back_in_stock, "+
+			"SUM(CASE WHEN type = ? THEN 1 ELSE 0 END) AS price_drop, "+
+			"MIN(created_at) AS oldest", entity.ProductAlertBackInStock, entity.ProductAlertPriceDrop).
+		Where("status = ?", entity.ProductAlertStatusActive).
+		Group("product_id, variant_id").
+		Scan(&rows).Error; err != nil {
+		logger.Error("Error building alert demand report: %v", err)
+		return *dto.Fail("Error building demand report")
+	}
+
+	productIDs := make([]string, 0, len(rows))
+	for _, row := range rows {
+		productIDs = append(productIDs, row.ProductID)
+	}
+
+	var products []entity.Product
+	if err := db.Preload("Variants").Where("id IN ?", productIDs).Find(&products).Error; err != nil {
+		logger.Error("Error fetching products: %v", err)
+		return *dto.Fail("Error building demand report")
+	}
+	availability, err := IInventoryService.GetAvailability(db, productIDs)
+	if err != nil {
+		logger.Error("Error fetching availability: %v", err)
+		return *dto.Fail("Error building demand report")
+	}
+
+	byID := make(map[string]entity.Product, len(products))
+	variants := make(map[string]entity.ProductVariant)
+	for _, product := range products {
+		byID[product.ID] = product
+		for _, variant := range product.Variants {
+			variants[variant.ID] = variant
+		}
+	}
+
+	report := make([]dto.ProductAlertDemandResponse, 0, len(rows))
+	for _, row := range rows {
+		product := byID[row.ProductID]
+		entry := dto.ProductAlertDemandResponse{
+			ProductID:          row.ProductID,
+			VariantID:          row.VariantID,
+			SKU:                product.SKU,
+			Name:               product.Name,
+			BackInStockCount:   row.BackInStock,
+			PriceDropCount:     row.PriceDrop,
+			Available:          availability[row.ProductID],
+			OldestSubscription: row.Oldest.UTC().Format(time.RFC3339),
+		}
+		if row.VariantID != nil {
+			if variant, ok := variants[*row.VariantID]; ok {
+				entry.SKU = variant.SKU
+				entry.Name = fmt.Sprintf("%s (%s)", product.Name, variant.Name)
+			}
+		}
+		report = append(report, entry)
+	}
+	sort.SliceStable(report, func(i, j int) bool {
+		if report[i].BackInStockCount != report[j].BackInStockCount {
+			return report[i].BackInStockCount > report[j].BackInStockCount
+		}
+		return report[i].PriceDropCount > report[j].PriceDropCount
+	})
+
+	return *dto.SuccessCount(report, int64(len(report)))
+}
+
+// currentOffer resolves the prices and stock of products as the storefront
+// shows them. Products must have their active variants loaded.
+func (s *productAlertService) currentOffer(db *gorm.DB, products []entity.Product, t time.Time) (alertOffer, error) {
+	offer := alertOffer{
+		prices:    make(map[string]float64),
+		available: make(map[string]int),
+		unlimited: make(map[string]bool),
+	}
+
+	productPrices, variantPrices, err := IPriceScheduleService.ResolvePrices(db, products, t)
+	if err != nil {
+		return offer, err
+	}
+	bundles, err := IBundleService.ResolveBundles(db, products, productPrices, t)
+	if err != nil {
+		return offer, err
+	}
+
+	productIDs := make([]string, len(products))
+	for i, product := range products {
+		productIDs[i] = product.ID
+	}
+	availability, err := IInventoryService.GetAvailability(db, productIDs)
+	if err != nil {
+		return offer, err
+	}
+
+	for _, product := range products {
+		offer.prices[product.ID] = productPrices[product.ID].Price
+		offer.available[product.ID] = availability[product.ID]
+		offer.unlimited[product.ID] = product.Type == entity.ProductTypeDigital
+		if bundle, ok := bundles[product.ID]; ok {
+			offer.prices[product.ID] = bundle.Price.Price
+			offer.available[product.ID] = bundle.Response.Available
+		}
+		for _, variant := range product.Variants {
+			offer.prices[variant.ID] = variantPrices[variant.ID].Price
+		}
+	}
+	return offer, nil
+}
@@ -0,0 +1,270 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"mime/multipart"
+	"path"
+
+	"gorm.io/gorm"
+
+	"backend-ecommerce/internal/application/dto"
+	"backend-ecommerce/internal/application/entity"
+	"backend-ecommerce/internal/application/tools"
+	"backend-ecommerce/internal/infrastructure/cachemanager"
+	"backend-ecommerce/internal/infrastructure/dbmanager"
+	"backend-ecommerce/internal/infrastructure/imagemanager"
+	"backend-ecommerce/internal/infrastructure/logger"
+	"backend-ecommerce/internal/infrastructure/storage"
+)
+
+const (
+	// maxProductImageSize is the largest accepted upload (10 MB)
+	maxProductImageSize = 10 << 20
+	productImageFolder  = "products"
+)
+
+type productImageService struct {
+}
+
+// GetProductImages returns a product's images in display order
+func (s *productImageService) GetProductImages(productID string) dto.ResponseDto {
+	var images []entity.ProductImage
+
+	db := dbmanager.GetDB()
+	if err := db.Preload("Renditions").
+		Where("product_id = ?", productID).
+		Order("sort_order ASC, created_at ASC").
+		Find(&images).Error; err != nil {
+		logger.Error("Error fetching product images: %v", err)
+		return *dto.Fail("Error fetching product images")
+	}
+
+	imageDtos := make([]dto.ProductImageResponse, len(images))
+	for i, image := range images {
+		imageDtos[i] = dto.GetProductImageResponse(image)
+	}
+
+	return *dto.SuccessCount(imageDtos, int64(len(imageDtos)))
+}
+
+// UploadProductImage validates an uploaded image, generates its renditions,
+// stores every file and records the image against the product.
+func (s *productImageService) UploadProductImage(ctx context.Context, productID string, file multipart.File, header *multipart.FileHeader, altText, changedBy string) dto.ResponseDto {
+	db := dbmanager.GetDB()
+
+	var product entity.Product
+	if err := db.Select("id").Where("id = ?", productID).First(&product).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return *dto.Fail("Product not found")
+		}
+		logger.Error("Error fetching product: %v", err)
+		return *dto.Fail("Error fetching product")
+	}
+
+	if header.Size > maxProductImageSize {
+		return *dto.Fail("Image exceeds the maximum size of 10 MB")
+	}
+
+	data, err := io.ReadAll(io.LimitReader(file, maxProductImageSize+1))
+	if err != nil {
+		logger.Error("Error reading uploaded image: %v", err)
+		return *dto.Fail("Error reading uploaded image")
+	}
+	if len(data) > maxProductImageSize {
+		return *dto.Fail("Image exceeds the maximum size of 10 MB")
+	}
+
+	// Validate by content, not by the client-supplied Content-Type
+	if _, err := imagemanager.DetectType(data); err != nil {
+		return *dto.Fail("Unsupported image type, expected JPEG, PNG, GIF or WebP")
+	}
+
+	result, err := imagemanager.Process(data, imagemanager.DefaultSpecs)
+	if errors.Is(err, imagemanager.ErrTooLarge) {
+		return *dto.Fail("Image dimensions are too large")
+	}
+	if err != nil {
+		logger.Error("Error processing product image: %v", err)
+		return *dto.Fail("Invalid or corrupt image")
+	}
+
+	imageID := tools.NewUuid()
+	folder := path.Join(productImageFolder, productID, imageID)
+	var storedKeys []string
+
+	originalKey := path.Join(folder, "original"+result.Ext)
+	originalURL, err := storeProductImageFile(ctx, originalKey, result.MimeType, data)
+	if err != nil {
+		logger.Error("Error storing product image: %v", err)
+		return *dto.Fail("Error storing product image")
+	}
+	storedKeys = append(storedKeys, originalKey)
+
+	image := entity.ProductImage{
+		ID:         imageID,
+		ProductID:  productID,
+		URL:        originalURL,
+		AltText:    altText,
+		StorageKey: originalKey,
+		MimeType:   result.MimeType,
+		Width:      result.Width,
+		Height:     result.Height,
+		FileSize:   int64(len(data)),
+	}
+
+	for _, rendition := range result.Renditions {
+		key := path.Join(folder, rendition.Name+rendition.Ext)
+		url, err := storeProductImageFile(ctx, key, rendition.MimeType, rendition.Data)
+		if err != nil {
+			logger.Error("Error storing %s rendition: %v", rendition.Name, err)
+			deleteProductImageFiles(ctx, storedKeys)
+			return *dto.Fail("Error storing product image")
+		}
+		storedKeys = append(storedKeys, key)
+
+		image.Renditions = append(image.Renditions, entity.ProductImageRendition{
+			ID:         tools.NewUuid(),
+			ImageID:    imageID,
+			Name:       rendition.Name,
+			Format:     rendition.Format,
+			URL:        url,
+			StorageKey: key,
+			Width:      rendition.Width,
+			Height:     rendition.Height,
+			FileSize:   int64(len(rendition.Data)),
+		})
+	}
+
+	err = IProductRevisionService.Track(db, productID, changedBy, "Image added", func(tx *gorm.DB) error {
+		// New images go to the end of the gallery
+		var count int64
+		if err := tx.Model(&entity.ProductImage{}).Where("product_id = ?", productID).Count(&count).Error; err != nil {
+			return err
+		}
+		image.SortOrder = int(count)
+		return tx.Create(&image).Error
+	})
+	if err != nil {
+		logger.Error("Error saving product image: %v", err)
+		deleteProductImageFiles(ctx, storedKeys)
+		return *dto.Fail("Error saving product image")
+	}
+
+	cachemanager.DeletePrefix(productCachePrefix)
+
+	return *dto.Success(dto.GetProductImageResponse(image))
+}
+
+// ReorderProductImages sets the display order of a product's images. imageIDs
+// must contain every image of the product exactly once.
+func (s *productImageService) ReorderProductImages(productID string, imageIDs []string, changedBy string) dto.ResponseDto {
+	db := dbmanager.GetDB()
+
+	var images []entity.ProductImage
+	if err := db.Where("product_id = ?", productID).Find(&images).Error; err != nil {
+		logger.Error("Error fetching product images: %v", err)
+		return *dto.Fail("Error fetching product images")
+	}
+
+	if len(imageIDs) != len(images) {
+		return *dto.Fail("Image order must include every image of the product exactly once")
+	}
+	existing := make(map[string]bool, len(images))
+	for _, image := range images {
+		existing[image.ID] = true
+	}
+	seen := make(map[string]bool, len(imageIDs))
+	for _, id := range imageIDs {
+		if !existing[id] || seen[id] {
+			return *dto.Fail("Image order must include every image of the product exactly once")
+		}
+		seen[id] = true
+	}
+
+	err := IProductRevisionService.Track(db, productID, changedBy, "Images reordered", func(tx *gorm.DB) error {
+		for i, id := range imageIDs {
+			if err := tx.Model(&entity.ProductImage{}).
+				Where("id = ? AND product_id = ?", id, productID).
+				Update("sort_order", i).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		logger.Error("Error reordering product images: %v", err)
+		return *dto.Fail("Error reordering product images")
+	}
+
+	cachemanager.DeletePrefix(productCachePrefix)
+
+	return s.GetProductImages(productID)
+}
+
+// DeleteProductImage removes an image, its renditions and their stored files
+func (s *productImageService) DeleteProductImage(ctx context.Context, productID, imageID, changedBy string) dto.ResponseDto {
+	db := dbmanager.GetDB()
+
+	var image entity.ProductImage
+	if err := db.Preload("Renditions").
+		Where("id = ? AND product_id = ?", imageID, productID).
+		First(&image).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return *dto.Fail("Product image not found")
+		}
+		logger.Error("Error fetching product image: %v", err)
+		return *dto.Fail("Error fetching product image")
+	}
+
+	err := IProductRevisionService.Track(db, productID, changedBy, "Image deleted", func(tx *gorm.DB) error {
+		if err := tx.Where("image_id = ?", image.ID).Delete(&entity.ProductImageRendition{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Unscoped().Delete(&image).Error; err != nil {
+			return err
+		}
+		// Close the gap left in the gallery order
+		return tx.Model(&entity.ProductImage{}).
+			Where("product_id = ? AND sort_order > ?", productID, image.SortOrder).
+			Update("sort_order", gorm.Expr("sort_order - 1")).Error
+	})
+	if err != nil {
+		logger.Error("Error deleting product image: %v", err)
+		return *dto.Fail("Error deleting product image")
+	}
+
+	keys := []string{image.StorageKey}
+	for _, rendition := range image.Renditions {
+		keys = append(keys, rendition.StorageKey)
+	}
+	deleteProductImageFiles(ctx, keys)
+	cachemanager.DeletePrefix(productCachePrefix)
+
+	return *dto.Success("Product image deleted successfully")
+}
+
+// storeProductImageFile writes data to the configured storage backend and
+// returns its public URL
+func storeProductImageFile(ctx context.Context, key, contentType string, data []byte) (string, error) {
+	store := storage.GetStorage()
+	if _, err := store.Put(ctx, key, bytes.NewReader(data), int64(len(data)), contentType); err != nil {
+		return "", err
+	}
+	return store.URL(key), nil
+}
+
+// deleteProductImageFiles removes stored files, logging rather than failing
+// so a missing file never blocks cleanup of the rest.
+func deleteProductImageFiles(ctx context.Context, keys []string) {
+	for _, key := range keys {
+		if key == "" {
+			continue
+		}
+		if err := storage.GetStorage().Delete(ctx, key); err != nil {
+			logger.Warn("Failed to delete product image file %s: %v", key, err)
+		}
+	}
+}
@@ -0,0 +1,216 @@
+package service
+
+import (
+	"fmt"
+	"net/url"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+
+	"backend-ecommerce/internal/application/dto"
+	"backend-ecommerce/internal/application/entity"
+	"backend-ecommerce/internal/infrastructure/cachemanager"
+	"backend-ecommerce/internal/infrastructure/config"
+	"backend-ecommerce/internal/infrastructure/dbmanager"
+	"backend-ecommerce/internal/infrastructure/logger"
+)
+
+const (
+	// previewTokenTTL is how long a product preview link stays valid
+	previewTokenTTL      = 24 * time.Hour
+	previewTokenAudience = "product-preview"
+)
+
+// productStatusTransitions lists the statuses each status can move to
+var productStatusTransitions = map[entity.ProductStatus][]entity.ProductStatus{
+	entity.ProductStatusDraft:     {entity.ProductStatusInReview},
+	entity.ProductStatusInReview:  {entity.ProductStatusDraft, entity.ProductStatusPublished},
+	entity.ProductStatusPublished: {entity.ProductStatusDraft},
+}
+
+type productPublishingService struct {
+	mu sync.Mutex
+}
+
+// UpdateProductStatus moves a product through the workflow: drafts are
+// submitted for review, reviewed products are published or sent back to
+// draft, and published products are unpublished back to draft.
+func (s *productPublishingService) UpdateProductStatus(productID string, req dto.ProductStatusUpdateRequest) dto.ResponseDto {
+	db := dbmanager.GetDB()
+
+	var product entity.Product
+	if err := db.Select("id", "status").Where("id = ?", productID).First(&product).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return *dto.Fail("Product not found")
+		}
+		logger.Error("Error fetching product: %v", err)
+		return *dto.Fail("Error fetching product")
+	}
+
+	status := entity.ProductStatus(req.Status)
+	if status == product.Status {
+		return IProductService.GetProductForAdmin(productID)
+	}
+	allowed := false
+	for _, next := range productStatusTransitions[product.Status] {
+		if next == status {
+			allowed = true
+			break
+		}
+	}
+	if !allowed {
+		return *dto.Fail(fmt.Sprintf("A %s product cannot be moved to %s", product.Status, status))
+	}
+
+	// A manual change overrides whatever was scheduled for the old status
+	updates := map[string]interface{}{"status": status}
+	switch status {
+	case entity.ProductStatusPublished:
+		updates["published_at"] = time.Now().UTC()
+		updates["publish_at"] = nil
+	case entity.ProductStatusDraft:
+		updates["publish_at"] = nil
+		updates["unpublish_at"] = nil
+	}
+
+	if err := db.Model(&entity.Product{}).Where("id = ?", productID).Updates(updates).Error; err != nil {
+		logger.Error("Error updating product status: %v", err)
+		return *dto.Fail("Error updating product status")
+	}
+
+	cachemanager.DeletePrefix(productCachePrefix)
+
+	return IProductService.GetProductForAdmin(productID)
+}
+
+// ScheduleProductPublication sets when a product goes live and when it is
+// taken down again. Only reviewed products can be scheduled for publishing.
+func (s *productPublishingService) ScheduleProductPublication(productID string, req dto.ProductPublicationScheduleRequest) dto.ResponseDto {
+	db := dbmanager.GetDB()
+
+	var product entity.Product
+	if err := db.Select("id", "status").Where("id = ?", productID).First(&product).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return *dto.Fail("Product not found")
+		}
+		logger.Error("Error fetching product: %v", err)
+		return *dto.Fail("Error fetching product")
+	}
+
+	now := time.Now().UTC()
+	var publishAt, unpublishAt *time.Time
+	if req.PublishAt != nil {
+		if product.Status != entity.ProductStatusInReview {
+			return *dto.Fail("Only products in review can be scheduled for publishing")
+		}
+		if !req.PublishAt.After(now) {
+			return *dto.Fail("Publish time must be in the future")
+		}
+		t := req.PublishAt.UTC()
+		publishAt = &t
+	}
+	if req.UnpublishAt != nil {
+		if product.Status == entity.ProductStatusDraft {
+			return *dto.Fail("Draft products cannot be scheduled for unpublishing")
+		}
+		if !req.UnpublishAt.After(now) {
+			return *dto.Fail("Unpublish time must be in the future")
+		}
+		if publishAt != nil && !req.UnpublishAt.After(*publishAt) {
+			return *dto.Fail("Unpublish time must be after publish time")
+		}
+		t := req.UnpublishAt.UTC()
+		unpublishAt = &t
+	}
+
+	if err := db.Model(&entity.Product{}).Where("id = ?", productID).Updates(map[string]interface{}{
+		"publish_at":   publishAt,
+		"unpublish_at": unpublishAt,
+	}).Error; err != nil {
+		logger.Error("Error scheduling product publication: %v", err)
+		return *dto.Fail("Error scheduling product publication")
+	}
+
+	return IProductService.GetProductForAdmin(productID)
+}
+
+// CreatePreviewToken issues a signed token that lets anyone holding it view
+// the product, whatever its status, until the token expires.
+func (s *productPublishingService) CreatePreviewToken(productID string) dto.ResponseDto {
+	db := dbmanager.GetDB()
+
+	var count int64
+	if err := db.Model(&entity.Product{}).Where("id = ?", productID).Count(&count).Error; err != nil {
+		logger.Error("Error fetching product: %v", err)
+		return *dto.Fail("Error fetching product")
+	}
+	if count == 0 {
+		return *dto.Fail("Product not found")
+	}
+
+	expiresAt := time.Now().UTC().Add(previewTokenTTL)
+	token, err := config.JWT.SignScoped(productID, previewTokenAudience, previewTokenTTL)
+	if err != nil {
+		logger.Error("Error signing preview token: %v", err)
+		return *dto.Fail("Error creating preview token")
+	}
+
+	return *dto.Success(dto.ProductPreviewTokenResponse{
+		Token:     token,
+		URL:       fmt.Sprintf("/api/products/%s/preview?token=%s", url.PathEscape(productID), url.QueryEscape(token)),
+		ExpiresAt: expiresAt.Format(time.RFC3339),
+	})
+}
+
+// GetProductPreview returns a product of any status to the holder of a valid preview token
+func (s *productPublishingService) GetProductPreview(productID, token string) dto.ResponseDto {
+	if err := config.JWT.VerifyScoped(token, productID, previewTokenAudience); err != nil {
+		return *dto.Fail("Invalid or expired preview token")
+	}
+
+	return IProductService.GetProductForAdmin(productID)
+}
+
+// ProcessScheduledPublishing is run by cronmanager. It publishes reviewed
+// products whose publish time has come and unpublishes those whose unpublish
+// time has passed.
+func (s *productPublishingService) ProcessScheduledPublishing() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	db := dbmanager.GetDB()
+	if db == nil {
+		return
+	}
+
+	now := time.Now().UTC()
+
+	published := db.Model(&entity.Product{}).
+		Where("status = ? AND publish_at IS NOT NULL AND publish_at <= ?", entity.ProductStatusInReview, now).
+		Updates(map[string]interface{}{
+			"status":       entity.ProductStatusPublished,
+			"published_at": now,
+			"publish_at":   nil,
+		})
+	if published.Error != nil {
+		logger.Error("Error publishing scheduled products: %v", published.Error)
+		return
+	}
+
+	unpublished := db.Model(&entity.Product{}).
+		Where("status = ? AND unpublish_at IS NOT NULL AND unpublish_at <= ?", entity.ProductStatusPublished, now).
+		Updates(map[string]interface{}{
+			"status":       entity.ProductStatusDraft,
+			"unpublish_at": nil,
+		})
+	if unpublished.Error != nil {
+		logger.Error("Error unpublishing scheduled products: %v", unpublished.Error)
+		return
+	}
+
+	if published.RowsAffected > 0 || unpublished.RowsAffected > 0 {
+		cachemanager.DeletePrefix(productCachePrefix)
+		logger.Info("Scheduled publishing: %d published, %d unpublished", published.RowsAffected, unpublished.RowsAffected)
+	}
+}
@@ -0,0 +1,403 @@
+package service
+
+import (
+	"sort"
+	"time"
+
+	"gorm.io/gorm"
+
+	"backend-ecommerce/internal/application/dto"
+	"backend-ecommerce/internal/application/entity"
+	"backend-ecommerce/internal/application/tools"
+	"backend-ecommerce/internal/infrastructure/cachemanager"
+	"backend-ecommerce/internal/infrastructure/config"
+	"backend-ecommerce/internal/infrastructure/dbmanager"
+	"backend-ecommerce/internal/infrastructure/logger"
+)
+
+// recommendationLimit caps each list of suggested products
+const recommendationLimit = 8
+
+// boughtOrderStatuses are the order statuses that count as a purchase
+var boughtOrderStatuses = []entity.OrderStatus{
+	entity.OrderStatusPaid,
+	entity.OrderStatusProcessing,
+	entity.OrderStatusShipped,
+	entity.OrderStatusCompleted,
+}
+
+type productRelationService struct {
+}
+
+type productPair struct {
+	first, second string
+}
+
+// GetProductRelations returns the curated relations and computed associations of a product
+func (s *productRelationService) GetProductRelations(productID string) dto.ResponseDto {
+	db := dbmanager.GetDB()
+
+	if err := db.Select("id").Where("id = ?", productID).First(&entity.Product{}).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return *dto.Fail("Product not found")
+		}
+		logger.Error("Error fetching product: %v", err)
+		return *dto.Fail("Error fetching product")
+	}
+
+	var relations []entity.ProductRelation
+	if err := db.Preload("RelatedProduct").Where("product_id = ?", productID).
+		Order("type ASC, position ASC").Find(&relations).Error; err != nil {
+		logger.Error("Error fetching product relations: %v", err)
+		return *dto.Fail("Error fetching product relations")
+	}
+
+	var associations []entity.FrequentlyBoughtTogether
+	if err := db.Where("product_id = ?", productID).
+		Order("confidence DESC, lift DESC").Find(&associations).Error; err != nil {
+		logger.Error("Error fetching frequently bought together: %v", err)
+		return *dto.Fail("Error fetching product relations")
+	}
+
+	names := make(map[string]string, len(associations))
+	if len(associations) > 0 {
+		ids := make([]string, len(associations))
+		for i, association := range associations {
+			ids[i] = association.RelatedProductID
+		}
+		var products []entity.Product
+		if err := db.Select("id", "name").Where("id IN ?", ids).Find(&products).Error; err != nil {
+			logger.Error("Error fetching products: %v", err)
+			return *dto.Fail("Error fetching product relations")
+		}
+		for _, product := range products {
+			names[product.ID] = product.Name
+		}
+	}
+
+	response := dto.ProductRelationsResponse{
+		Relations:                make([]dto.ProductRelationResponse, len(relations)),
+		FrequentlyBoughtTogether: make([]dto.FrequentlyBoughtTogetherResponse, len(associations)),
+	}
+	for i, relation := range relations {
+		response.Relations[i] = dto.GetProductRelationResponse(relation)
+	}
+	for i, association := range associations {
+		response.FrequentlyBoughtTogether[i] = dto.GetFrequentlyBoughtTogetherResponse(association, names[association.RelatedProductID])
+	}
+
+	return *dto.Success(response)
+}
+
+// SetProductRelations replaces the relations of one type from a product, in display order
+func (s *productRelationService) SetProductRelations(productID string, relationType entity.ProductRelationType, relatedIDs []string) dto.ResponseDto {
+	switch relationType {
+	case entity.ProductRelationRelated, entity.ProductRelationUpsell, entity.ProductRelationCrossSell, entity.ProductRelationAccessory:
+	default:
+		return *dto.Fail("Invalid relation type")
+	}
+
+	db := dbmanager.GetDB()
+
+	if err := db.Select("id").Where("id = ?", productID).First(&entity.Product{}).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return *dto.Fail("Product not found")
+		}
+		logger.Error("Error fetching product: %v", err)
+		return *dto.Fail("Error fetching product")
+	}
+
+	seen := make(map[string]bool, len(relatedIDs))
+	relations := make([]entity.ProductRelation, 0, len(relatedIDs))
+	for _, relatedID := range relatedIDs {
+		if relatedID == productID {
+			return *dto.Fail("A product cannot be related to itself")
+		}
+		if seen[relatedID] {
+			return *dto.Fail("Each product can appear only once")
+		}
+		seen[relatedID] = true
+		relations = append(relations, entity.ProductRelation{
+			ID:               tools.NewUuid(),
+			ProductID:        productID,
+			RelatedProductID: relatedID,
+			Type:             relationType,
+			Position:         len(relations),
+		})
+	}
+
+	if len(relatedIDs) > 0 {
+		var count int64
+		if err := db.Model(&entity.Product{}).Where("id IN ?", relatedIDs).Count(&count).Error; err != nil {
+			logger.Error("Error fetching products: %v", err)
+			return *dto.Fail("Error fetching products")
+		}
+		if int(count) != len(relatedIDs) {
+			return *dto.Fail("One or more products were not found")
+		}
+	}
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("product_id = ? AND type = ?", productID, relationType).Delete(&entity.ProductRelation{}).Error; err != nil {
+			return err
+		}
+		if len(relations) == 0 {
+			return nil
+		}
+		return tx.Create(&relations).Error
+	})
+	if err != nil {
+		logger.Error("Error updating product relations: %v", err)
+		return *dto.Fail("Error updating product relations")
+	}
+
+	cachemanager.DeletePrefix(productCachePrefix)
+
+	return s.GetProductRelations(productID)
+}
+
+// GetRecommendations returns the published products suggested on a product's detail page
+func (s *productRelationService) GetRecommendations(db *gorm.DB, productID string) (*dto.ProductRecommendationsResponse, error) {
+	var relations []entity.ProductRelation
+	if err := db.Where("product_id = ?", productID).Order("type ASC, position ASC").Find(&relations).Error; err != nil {
+		return nil, err
+	}
+
+	var associations []entity.FrequentlyBoughtTogether
+	if err := db.Where("product_id = ?", productID).Order("confidence DESC, lift DESC").
+		Limit(recommendationLimit * 2).Find(&associations).Error; err != nil {
+		return nil, err
+	}
+
+	ids := make([]string, 0, len(relations)+len(associations))
+	for _, relation := range relations {
+		ids = append(ids, relation.RelatedProductID)
+	}
+	for _, association := range associations {
+		ids = append(ids, association.RelatedProductID)
+	}
+	products, err := IProductService.publishedProductResponses(db, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	recommendations := &dto.ProductRecommendationsResponse{}
+	for _, relation := range relations {
+		product, ok := products[relation.RelatedProductID]
+		if !ok {
+			continue
+		}
+		switch relation.Type {
+		case entity.ProductRelationRelated:
+			recommendations.Related = appendRecommendation(recommendations.Related, product)
+		case entity.ProductRelationUpsell:
+			recommendations.Upsells = appendRecommendation(recommendations.Upsells, product)
+		case entity.ProductRelationCrossSell:
+			recommendations.CrossSells = appendRecommendation(recommendations.CrossSells, product)
+		case entity.ProductRelationAccessory:
+			recommendations.Accessories = appendRecommendation(recommendations.Accessories, product)
+		}
+	}
+	for _, association := range associations {
+		if product, ok := products[association.RelatedProductID]; ok {
+			recommendations.FrequentlyBoughtTogether = appendRecommendation(recommendations.FrequentlyBoughtTogether, product)
+		}
+	}
+
+	return recommendations, nil
+}
+
+// GetCartRecommendations returns the cross-sells, accessories and products
+// frequently bought with the given cart contents, leaving out what is
+// already in the cart
+func (s *productRelationService) GetCartRecommendations(db *gorm.DB, cartProductIDs []string) (*dto.ProductRecommendationsResponse, error) {
+	recommendations := &dto.ProductRecommendationsResponse{}
+	if len(cartProductIDs) == 0 {
+		return recommendations, nil
+	}
+
+	inCart := make(map[string]bool, len(cartProductIDs))
+	for _, id := range cartProductIDs {
+		inCart[id] = true
+	}
+
+	var relations []entity.ProductRelation
+	if err := db.Where("product_id IN ? AND type IN ?", cartProductIDs,
+		[]entity.ProductRelationType{entity.ProductRelationCrossSell, entity.ProductRelationAccessory}).
+		Order("position ASC").Find(&relations).Error; err != nil {
+		return nil, err
+	}
+
+	var associations []entity.FrequentlyBoughtTogether
+	if err := db.Where("product_id IN ? AND related_product_id NOT IN ?", cartProductIDs, cartProductIDs).
+		Order("confidence DESC, lift DESC").Find(&associations).Error; err != nil {
+		return nil, err
+	}
+
+	ids := make([]string, 0, len(relations)+len(associations))
+	for _, relation := range relations {
+		if !inCart[relation.RelatedProductID] {
+			ids = append(ids, relation.RelatedProductID)
+		}
+	}
+	for _, association := range associations {
+		ids = append(ids, association.RelatedProductID)
+	}
+	products, err := IProductService.publishedProductResponses(db, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	// A product suggested for several cart items is listed once, at its best position
+	suggested := make(map[string]bool)
+	for _, relation := range relations {
+		product, ok := products[relation.RelatedProductID]
+		if !ok || inCart[relation.RelatedProductID] || suggested[relation.RelatedProductID] {
+			continue
+		}
+		suggested[relation.RelatedProductID] = true
+		if relation.Type == entity.ProductRelationAccessory {
+			recommendations.Accessories = appendRecommendation(recommendations.Accessories, product)
+		} else {
+			recommendations.CrossSells = appendRecommendation(recommendations.CrossSells, product)
+		}
+	}
+	for _, association := range associations {
+		product, ok := products[association.RelatedProductID]
+		if !ok || suggested[association.RelatedProductID] {
+			continue
+		}
+		suggested[association.RelatedProductID] = true
+		recommendations.FrequentlyBoughtTogether = appendRecommendation(recommendations.FrequentlyBoughtTogether, product)
+	}
+
+	return recommendations, nil
+}
+
+// RecomputeFrequentlyBoughtTogether is run nightly by cronmanager. It counts
+// how often products appear in the same order and keeps the associations that
+// meet the configured support and confidence thresholds.
+func (s *productRelationService) RecomputeFrequentlyBoughtTogether() {
+	db := dbmanager.GetDB()
+	if db == nil {
+		return
+	}
+	cfg := config.Get().Recommendations
+	now := time.Now().UTC()
+
+	// Bundle component lines are left out so a bundle counts as one product
+	rows, err := db.Table("order_items").
+		Select("DISTINCT order_items.order_id, order_items.product_id").
+		Joins("JOIN orders ON orders.id = order_items.order_id").
+		Where("orders.status IN ? AND orders.created_at >= ?", boughtOrderStatuses, now.AddDate(0, 0, -cfg.LookbackDays)).
+		Where("order_items.product_id IS NOT NULL AND order_items.parent_item_id IS NULL").
+		Order("order_items.order_id").
+		Rows()
+	if err != nil {
+		logger.Error("Error reading order items: %v", err)
+		return
+	}
+
+	productOrders := make(map[string]int)
+	pairOrders := make(map[productPair]int)
+	totalOrders := 0
+
+	countOrder := func(products []string) {
+		if len(products) == 0 {
+			return
+		}
+		totalOrders++
+		sort.Strings(products)
+		for i, first := range products {
+			productOrders[first]++
+			for _, second := range products[i+1:] {
+				pairOrders[productPair{first, second}]++
+			}
+		}
+	}
+
+	var currentOrder string
+	var basket []string
+	for rows.Next() {
+		var orderID, productID string
+		if err := rows.Scan(&orderID, &productID); err != nil {
+			rows.Close()
+			logger.Error("Error reading order items: %v", err)
+			return
+		}
+		if orderID != currentOrder {
+			countOrder(basket)
+			currentOrder = orderID
+			basket = nil
+		}
+		basket = append(basket, productID)
+	}
+	countOrder(basket)
+	if err := rows.Err(); err != nil {
+		rows.Close()
+		logger.Error("Error reading order items: %v", err)
+		return
+	}
+	rows.Close()
+
+	byProduct := make(map[string][]entity.FrequentlyBoughtTogether)
+	for pair, together := range pairOrders {
+		support := float64(together) / float64(totalOrders)
+		if together < cfg.MinOrders || support < cfg.MinSupport {
+			continue
+		}
+		for _, direction := range []productPair{pair, {pair.second, pair.first}} {
+			confidence := float64(together) / float64(productOrders[direction.first])
+			if confidence < cfg.MinConfidence {
+				continue
+			}
+			byProduct[direction.first] = append(byProduct[direction.first], entity.FrequentlyBoughtTogether{
+				ProductID:        direction.first,
+				RelatedProductID: direction.second,
+				OrderCount:       together,
+				Support:          support,
+				Confidence:       confidence,
+				Lift:             confidence / (float64(productOrders[direction.second]) / float64(totalOrders)),
+				ComputedAt:       now,
+			})
+		}
+	}
+
+	var associations []entity.FrequentlyBoughtTogether
+	for _, candidates := range byProduct {
+		sort.Slice(candidates, func(i, j int) bool {
+			if candidates[i].Confidence != candidates[j].Confidence {
+				return candidates[i].Confidence > candidates[j].Confidence
+			}
+			return candidates[i].Lift > candidates[j].Lift
+		})
+		if cfg.MaxPerProduct > 0 && len(candidates) > cfg.MaxPerProduct {
+			candidates = candidates[:cfg.MaxPerProduct]
+		}
+		associations = append(associations, candidates...)
+	}
+
+	err = db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("1 = 1").Delete(&entity.FrequentlyBoughtTogether{}).Error; err != nil {
+			return err
+		}
+		if len(associations) == 0 {
+			return nil
+		}
+		return tx.CreateInBatches(&associations, 500).Error
+	})
+	if err != nil {
+		logger.Error("Error saving frequently bought together: %v", err)
+		return
+	}
+
+	cachemanager.DeletePrefix(productCachePrefix)
+	logger.Info("Frequently bought together recomputed from %d orders: %d associations", totalOrders, len(associations))
+}
+
+func appendRecommendation(list []dto.ProductResponse, product dto.ProductResponse) []dto.ProductResponse {
+	if len(list) >= recommendationLimit {
+		return list
+	}
+	return append(list, product)
+}
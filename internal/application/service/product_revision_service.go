@@ -0,0 +1,403 @@
+package service
+
+import (
+	"fmt"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"backend-ecommerce/internal/application/dto"
+	"backend-ecommerce/internal/application/entity"
+	"backend-ecommerce/internal/application/tools"
+	"backend-ecommerce/internal/infrastructure/cachemanager"
+	"backend-ecommerce/internal/infrastructure/dbmanager"
+	"backend-ecommerce/internal/infrastructure/logger"
+)
+
+// revisionDiffIgnore lists bookkeeping fields left out of revision diffs
+var revisionDiffIgnore = []string{"created_at", "updated_at"}
+
+type productRevisionService struct {
+}
+
+// Track runs fn in a transaction and records the product's resulting state
+// as a new revision. The first tracked change also records the state before
+// it, so every edit can be rolled back. changedBy is the acting user's ID,
+// empty for system changes. Nothing is recorded when fn leaves the product
+// unchanged.
+func (s *productRevisionService) Track(db *gorm.DB, productID, changedBy, summary string, fn func(tx *gorm.DB) error) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		// Lock the product so concurrent edits get consecutive versions
+		var product entity.Product
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id").Where("id = ?", productID).First(&product).Error; err != nil {
+			return err
+		}
+
+		var latest entity.ProductRevision
+		err := tx.Where("product_id = ?", productID).Order("version DESC").First(&latest).Error
+		if err == gorm.ErrRecordNotFound {
+			snapshot, err := s.takeSnapshot(tx, productID)
+			if err != nil {
+				return err
+			}
+			latest = entity.ProductRevision{
+				ID:        tools.NewUuid(),
+				ProductID: productID,
+				Version:   1,
+				Summary:   "Initial version",
+				Snapshot:  snapshot,
+			}
+			if err := tx.Create(&latest).Error; err != nil {
+				return err
+			}
+		} else if err != nil {
+			return err
+		}
+
+		if err := fn(tx); err != nil {
+			return err
+		}
+
+		snapshot, err := s.takeSnapshot(tx, productID)
+		if err != nil {
+			return err
+		}
+		changes, err := tools.DiffJSON(latest.Snapshot, snapshot, revisionDiffIgnore...)
+		if err != nil {
+			return err
+		}
+		if len(changes) == 0 {
+			return nil
+		}
+
+		revision := entity.ProductRevision{
+			ID:        tools.NewUuid(),
+			ProductID: productID,
+			Version:   latest.Version + 1,
+			Summary:   summary,
+			Snapshot:  snapshot,
+		}
+		if changedBy != "" {
+			revision.ChangedBy = &changedBy
+		}
+		return tx.Create(&revision).Error
+	})
+}
+
+// GetProductRevisions lists a product's revisions, newest first
+func (s *productRevisionService) GetProductRevisions(productID string) dto.ResponseDto {
+	var revisions []entity.ProductRevision
+
+	db := dbmanager.GetDB()
+	if err := db.Omit("snapshot").
+		Where("product_id = ?", productID).
+		Order("version DESC").
+		Find(&revisions).Error; err != nil {
+		logger.Error("Error fetching product revisions: %v", err)
+		return *dto.Fail("Error fetching product revisions")
+	}
+
+	revisionDtos := make([]dto.ProductRevisionResponse, len(revisions))
+	for i, revision := range revisions {
+		revisionDtos[i] = dto.GetProductRevisionResponse(revision, false)
+	}
+
+	return *dto.SuccessCount(revisionDtos, int64(len(revisionDtos)))
+}
+
+// GetProductRevision returns one revision including its snapshot
+func (s *productRevisionService) GetProductRevision(productID string, version int) dto.ResponseDto {
+	revision, err := s.findRevision(dbmanager.GetDB(), productID, version)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return *dto.Fail("Revision not found")
+		}
+		logger.Error("Error fetching product revision: %v", err)
+		return *dto.Fail("Error fetching product revision")
+	}
+
+	return *dto.Success(dto.GetProductRevisionResponse(*revision, true))
+}
+
+// DiffProductRevisions lists the changes between two revisions of a product.
+// A zero toVersion means the latest revision and a zero fromVersion the one
+// before toVersion.
+func (s *productRevisionService) DiffProductRevisions(productID string, fromVersion, toVersion int) dto.ResponseDto {
+	db := dbmanager.GetDB()
+
+	if toVersion == 0 {
+		var latest entity.ProductRevision
+		if err := db.Select("version").Where("product_id = ?", productID).Order("version DESC").First(&latest).Error; err != nil {
+			if err == gorm.ErrRecordNotFound {
+				return *dto.Fail("Product has no revisions")
+			}
+			logger.Error("Error fetching product revision: %v", err)
+			return *dto.Fail("Error fetching product revision")
+		}
+		toVersion = latest.Version
+	}
+	if fromVersion == 0 {
+		fromVersion = max(1, toVersion-1)
+	}
+
+	from, err := s.findRevision(db, productID, fromVersion)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return *dto.Fail(fmt.Sprintf("Revision %d not found", fromVersion))
+		}
+		logger.Error("Error fetching product revision: %v", err)
+		return *dto.Fail("Error fetching product revision")
+	}
+	to, err := s.findRevision(db, productID, toVersion)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return *dto.Fail(fmt.Sprintf("Revision %d not found", toVersion))
+		}
+		logger.Error("Error fetching product revision: %v", err)
+		return *dto.Fail("Error fetching product revision")
+	}
+
+	changes, err := tools.DiffJSON(from.Snapshot, to.Snapshot, revisionDiffIgnore...)
+	if err != nil {
+		logger.Error("Error comparing product revisions: %v", err)
+		return *dto.Fail("Error comparing product revisions")
+	}
+
+	return *dto.Success(dto.ProductRevisionDiffResponse{
+		ProductID:   productID,
+		FromVersion: fromVersion,
+		ToVersion:   toVersion,
+		Changes:     changes,
+	})
+}
+
+// RestoreProductRevision puts a product, its variants, prices, images and
+// bundle components back to the state recorded in a revision, in a single
+// transaction, and records the result as a new revision. Images added since
+// the revision are detached rather than deleted, so restoring a later
+// revision brings them back. Image files are not versioned, so images
+// deleted since the revision cannot be brought back and are reported instead.
+func (s *productRevisionService) RestoreProductRevision(productID string, version int, changedBy string) dto.ResponseDto {
+	db := dbmanager.GetDB()
+
+	var missingImages []string
+	summary := fmt.Sprintf("Restored revision %d", version)
+	err := s.Track(db, productID, changedBy, summary, func(tx *gorm.DB) error {
+		revision, err := s.findRevision(tx, productID, version)
+		if err != nil {
+			return err
+		}
+		missingImages, err = s.applySnapshot(tx, productID, revision.Snapshot)
+		return err
+	})
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return *dto.Fail("Product or revision not found")
+		}
+		logger.Error("Error restoring product revision: %v", err)
+		return *dto.Fail("Error restoring product revision")
+	}
+
+	ICollectionService.RefreshProductMemberships([]string{productID})
+	cachemanager.DeletePrefix(productCachePrefix)
+
+	var latest entity.ProductRevision
+	if err := db.Omit("snapshot").Where("product_id = ?", productID).Order("version DESC").First(&latest).Error; err != nil {
+		logger.Error("Error fetching product revision: %v", err)
+		return *dto.Fail("Error fetching product revision")
+	}
+
+	return *dto.Success(dto.ProductRevisionRestoreResponse{
+		RestoredVersion: version,
+		Revision:        dto.GetProductRevisionResponse(latest, false),
+		MissingImages:   missingImages,
+	})
+}
+
+func (s *productRevisionService) findRevision(db *gorm.DB, productID string, version int) (*entity.ProductRevision, error) {
+	var revision entity.ProductRevision
+	if err := db.Where("product_id = ? AND version = ?", productID, version).First(&revision).Error; err != nil {
+		return nil, err
+	}
+	return &revision, nil
+}
+
+// takeSnapshot reads the current editable state of a product
+func (s *productRevisionService) takeSnapshot(tx *gorm.DB, productID string) (entity.ProductSnapshot, error) {
+	var product entity.Product
+	if err := tx.Where("id = ?", productID).First(&product).Error; err != nil {
+		return entity.ProductSnapshot{}, err
+	}
+
+	snapshot := entity.ProductSnapshot{
+		SKU:              product.SKU,
+		Name:             product.Name,
+		Slug:             product.Slug,
+		Description:      product.Description,
+		Price:            product.Price,
+		Currency:         product.Currency,
+		CategoryID:       product.CategoryID,
+		IsActive:         product.IsActive,
+		Type:             product.Type,
+		BundlePricing:    product.BundlePricing,
+		BundleDiscount:   product.BundleDiscount,
+		Attributes:       product.Attributes,
+		Tags:             product.Tags,
+		Variants:         []entity.ProductVariant{},
+		PriceSchedules:   []entity.PriceSchedule{},
+		Images:           []entity.ProductImageSnapshot{},
+		BundleComponents: []entity.BundleComponent{},
+	}
+
+	if err := tx.Where("product_id = ?", productID).Order("sort_order ASC, id ASC").Find(&snapshot.Variants).Error; err != nil {
+		return entity.ProductSnapshot{}, err
+	}
+	if err := tx.Where("product_id = ?", productID).Order("starts_at ASC, id ASC").Find(&snapshot.PriceSchedules).Error; err != nil {
+		return entity.ProductSnapshot{}, err
+	}
+	if err := tx.Where("bundle_id = ?", productID).Order("sort_order ASC, id ASC").Find(&snapshot.BundleComponents).Error; err != nil {
+		return entity.ProductSnapshot{}, err
+	}
+
+	var images []entity.ProductImage
+	if err := tx.Where("product_id = ?", productID).Order("sort_order ASC, id ASC").Find(&images).Error; err != nil {
+		return entity.ProductSnapshot{}, err
+	}
+	for _, image := range images {
+		snapshot.Images = append(snapshot.Images, entity.ProductImageSnapshot{
+			ID:        image.ID,
+			URL:       image.URL,
+			AltText:   image.AltText,
+			SortOrder: image.SortOrder,
+		})
+	}
+
+	return snapshot, nil
+}
+
+// applySnapshot overwrites the product's current state with snapshot. It
+// returns the IDs of snapshot images that no longer exist.
+func (s *productRevisionService) applySnapshot(tx *gorm.DB, productID string, snapshot entity.ProductSnapshot) ([]string, error) {
+	if err := tx.Model(&entity.Product{}).Where("id = ?", productID).Updates(map[string]interface{}{
+		"sku":             snapshot.SKU,
+		"name":            snapshot.Name,
+		"slug":            snapshot.Slug,
+		"description":     snapshot.Description,
+		"price":           snapshot.Price,
+		"currency":        snapshot.Currency,
+		"category_id":     snapshot.CategoryID,
+		"is_active":       snapshot.IsActive,
+		"type":            snapshot.Type,
+		"bundle_pricing":  snapshot.BundlePricing,
+		"bundle_discount": snapshot.BundleDiscount,
+		"attributes":      snapshot.Attributes,
+		"tags":            snapshot.Tags,
+	}).Error; err != nil {
+		return nil, err
+	}
+
+	// Variants
+	variantIDs := make([]string, len(snapshot.Variants))
+	for i, variant := range snapshot.Variants {
+		variantIDs[i] = variant.ID
+	}
+	removeVariants := tx.Where("product_id = ?", productID)
+	if len(variantIDs) > 0 {
+		removeVariants = removeVariants.Where("id NOT IN ?", variantIDs)
+	}
+	if err := removeVariants.Delete(&entity.ProductVariant{}).Error; err != nil {
+		return nil, err
+	}
+	if len(snapshot.Variants) > 0 {
+		if err := tx.Clauses(clause.OnConflict{UpdateAll: true}).Create(&snapshot.Variants).Error; err != nil {
+			return nil, err
+		}
+	}
+
+	// Price schedules. Applied permanent changes are history and stay applied,
+	// otherwise restoring one would write its price again on the next run.
+	var currentSchedules []entity.PriceSchedule
+	if err := tx.Where("product_id = ?", productID).Find(&currentSchedules).Error; err != nil {
+		return nil, err
+	}
+	appliedAt := make(map[string]*entity.PriceSchedule, len(currentSchedules))
+	for i := range currentSchedules {
+		if currentSchedules[i].AppliedAt != nil {
+			appliedAt[currentSchedules[i].ID] = &currentSchedules[i]
+		}
+	}
+	scheduleIDs := make([]string, len(snapshot.PriceSchedules))
+	for i := range snapshot.PriceSchedules {
+		scheduleIDs[i] = snapshot.PriceSchedules[i].ID
+		if current, ok := appliedAt[scheduleIDs[i]]; ok {
+			snapshot.PriceSchedules[i].AppliedAt = current.AppliedAt
+		}
+	}
+	removeSchedules := tx.Where("product_id = ? AND applied_at IS NULL", productID)
+	if len(scheduleIDs) > 0 {
+		removeSchedules = removeSchedules.Where("id NOT IN ?", scheduleIDs)
+	}
+	if err := removeSchedules.Delete(&entity.PriceSchedule{}).Error; err != nil {
+		return nil, err
+	}
+	if len(snapshot.PriceSchedules) > 0 {
+		if err := tx.Clauses(clause.OnConflict{UpdateAll: true}).Create(&snapshot.PriceSchedules).Error; err != nil {
+			return nil, err
+		}
+	}
+
+	// Bundle components
+	if err := tx.Where("bundle_id = ?", productID).Delete(&entity.BundleComponent{}).Error; err != nil {
+		return nil, err
+	}
+	if len(snapshot.BundleComponents) > 0 {
+		if err := tx.Create(&snapshot.BundleComponents).Error; err != nil {
+			return nil, err
+		}
+	}
+
+	// Images: those the revision did not have are detached, and detached
+	// ones it had are attached again
+	imageIDs := make([]string, len(snapshot.Images))
+	for i, image := range snapshot.Images {
+		imageIDs[i] = image.ID
+	}
+	detach := tx.Where("product_id = ?", productID)
+	if len(imageIDs) > 0 {
+		detach = detach.Where("id NOT IN ?", imageIDs)
+	}
+	if err := detach.Delete(&entity.ProductImage{}).Error; err != nil {
+		return nil, err
+	}
+
+	var existingIDs []string
+	if len(imageIDs) > 0 {
+		if err := tx.Unscoped().Model(&entity.ProductImage{}).Where("product_id = ? AND id IN ?", productID, imageIDs).
+			Pluck("id", &existingIDs).Error; err != nil {
+			return nil, err
+		}
+	}
+	existing := make(map[string]bool, len(existingIDs))
+	for _, id := range existingIDs {
+		existing[id] = true
+	}
+
+	var missingImages []string
+	sortOrder := 0
+	for _, image := range snapshot.Images {
+		if !existing[image.ID] {
+			missingImages = append(missingImages, image.ID)
+			continue
+		}
+		if err := tx.Unscoped().Model(&entity.ProductImage{}).Where("id = ?", image.ID).Updates(map[string]interface{}{
+			"alt_text":    image.AltText,
+			"sort_order":  sortOrder,
+			"detached_at": nil,
+		}).Error; err != nil {
+			return nil, err
+		}
+		sortOrder++
+	}
+
+	return missingImages, nil
+}
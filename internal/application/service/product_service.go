@@ -1,28 +1,290 @@
 package service
 
 import (
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+
+	"backend-ecommerce/internal/application/dto"
 	"backend-ecommerce/internal/application/entity"
+	"backend-ecommerce/internal/infrastructure/cachemanager"
+	"backend-ecommerce/internal/infrastructure/dbmanager"
+	"backend-ecommerce/internal/infrastructure/logger"
+)
+
+const (
+	// productCachePrefix prefixes every cached catalog response
+	productCachePrefix = "products:"
+	productCacheTTL    = 5 * time.Minute
 )
 
 type productService struct {
 }
 
-func (s *productService) GetAllProducts(page, pageSize int, categoryID *string) ([]entity.Product, int64, error) {
-	return []entity.Product{}, 0, nil
+type cachedProductList struct {
+	Products []dto.ProductResponse `json:"products"`
+	Total    int64                 `json:"total"`
 }
 
-func (s *productService) GetProductByID(id string) (*entity.Product, error) {
-	return nil, nil
+// GetAllProducts returns a paginated list of published products priced at the current time
+func (s *productService) GetAllProducts(page, pageSize int, categoryID *string) dto.ResponseDto {
+	category := ""
+	if categoryID != nil {
+		category = *categoryID
+	}
+	cacheKey := fmt.Sprintf("%slist:%d:%d:%s", productCachePrefix, page, pageSize, category)
+
+	var cached cachedProductList
+	if cachemanager.GetJSON(cacheKey, &cached) {
+		return *dto.SuccessCount(cached.Products, cached.Total)
+	}
+
+	var products []entity.Product
+	var totalCount int64
+
+	db := dbmanager.GetDB()
+
+	query := publishedProducts(preloadProductDetails(db.Model(&entity.Product{})))
+	if categoryID != nil {
+		query = query.Where("category_id = ?", *categoryID)
+	}
+	query = query.Order("created_at DESC")
+
+	// Apply pagination
+	if pageSize > 0 {
+		offset := (page - 1) * pageSize
+		if err := query.Count(&totalCount).Limit(pageSize).Offset(offset).Find(&products).Error; err != nil {
+			logger.Error("Error fetching products with pagination: %v", err)
+			return *dto.Fail("Error fetching products")
+		}
+	} else {
+		if err := query.Count(&totalCount).Find(&products).Error; err != nil {
+			logger.Error("Error fetching products with no pagination: %v", err)
+			return *dto.Fail("Error fetching products")
+		}
+	}
+
+	productDtos, err := s.toProductResponses(db, products, time.Now().UTC())
+	if err != nil {
+		logger.Error("Error resolving product prices: %v", err)
+		return *dto.Fail("Error fetching products")
+	}
+
+	cachemanager.SetJSON(cacheKey, cachedProductList{Products: productDtos, Total: totalCount}, productCacheTTL)
+
+	return *dto.SuccessCount(productDtos, totalCount)
+}
+
+// GetProductByID returns a published product priced at the current time
+func (s *productService) GetProductByID(id string) dto.ResponseDto {
+	cacheKey := productCachePrefix + "detail:" + id
+
+	var cached dto.ProductResponse
+	if cachemanager.GetJSON(cacheKey, &cached) {
+		return *dto.Success(cached)
+	}
+
+	response := s.getProduct(id, true)
+	if response.Code != 0 {
+		return response
+	}
+
+	product := response.Data.(dto.ProductResponse)
+	recommendations, err := IProductRelationService.GetRecommendations(dbmanager.GetDB(), id)
+	if err != nil {
+		// The page is still usable without suggestions
+		logger.Warn("Error fetching recommendations for product %s: %v", id, err)
+	} else {
+		product.Recommendations = recommendations
+		response = *dto.Success(product)
+	}
+	cachemanager.SetJSON(cacheKey, response.Data, productCacheTTL)
+
+	return response
+}
+
+// GetProductForAdmin returns a product whatever its status. The
+// result is not cached so admins always see their latest edits.
+func (s *productService) GetProductForAdmin(id string) dto.ResponseDto {
+	return s.getProduct(id, false)
+}
+
+func (s *productService) getProduct(id string, publishedOnly bool) dto.ResponseDto {
+	var product entity.Product
+
+	db := dbmanager.GetDB()
+	query := preloadProductDetails(db).Where("id = ?", id)
+	if publishedOnly {
+		query = publishedProducts(query)
+	}
+	if err := query.First(&product).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return *dto.Fail("Product not found")
+		}
+		logger.Error("Error fetching product by ID: %v", err)
+		return *dto.Fail("Error fetching product")
+	}
+
+	productDtos, err := s.toProductResponses(db, []entity.Product{product}, time.Now().UTC())
+	if err != nil {
+		logger.Error("Error resolving product prices: %v", err)
+		return *dto.Fail("Error fetching product")
+	}
+
+	return *dto.Success(productDtos[0])
 }
 
 func (s *productService) CreateProduct(product entity.Product) (*entity.Product, error) {
 	return nil, nil
 }
 
-func (s *productService) UpdateProduct(product entity.Product) (*entity.Product, error) {
-	return nil, nil
+// UpdateProduct applies the fields set in req to a product and records the
+// change in its revision history. changedBy is the acting user's ID.
+func (s *productService) UpdateProduct(id string, req dto.ProductUpdateRequest, changedBy string) dto.ResponseDto {
+	db := dbmanager.GetDB()
+
+	updates := make(map[string]interface{})
+	if req.SKU != nil {
+		updates["sku"] = *req.SKU
+	}
+	if req.Name != nil {
+		if len(*req.Name) < 2 || len(*req.Name) > 255 {
+			return *dto.Fail("Name must be between 2 and 255 characters")
+		}
+		updates["name"] = *req.Name
+	}
+	if req.Slug != nil {
+		if *req.Slug == "" {
+			return *dto.Fail("Slug cannot be empty")
+		}
+		updates["slug"] = *req.Slug
+	}
+	if req.Description != nil {
+		updates["description"] = *req.Description
+	}
+	if req.Price != nil {
+		if *req.Price <= 0 {
+			return *dto.Fail("Price must be greater than zero")
+		}
+		updates["price"] = *req.Price
+	}
+	if req.Currency != nil {
+		updates["currency"] = *req.Currency
+	}
+	if req.CategoryID != nil {
+		updates["category_id"] = *req.CategoryID
+	}
+	if req.IsActive != nil {
+		updates["is_active"] = *req.IsActive
+	}
+	if req.Attributes != nil {
+		updates["attributes"] = entity.ProductAttributes(req.Attributes)
+	}
+	if req.Tags != nil {
+		updates["tags"] = entity.ProductTags(req.Tags)
+	}
+	if len(updates) == 0 {
+		return *dto.Fail("No changes provided")
+	}
+
+	err := IProductRevisionService.Track(db, id, changedBy, "Product updated", func(tx *gorm.DB) error {
+		return tx.Model(&entity.Product{}).Where("id = ?", id).Updates(updates).Error
+	})
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return *dto.Fail("Product not found")
+		}
+		logger.Error("Error updating product: %v", err)
+		return *dto.Fail("Error updating product")
+	}
+
+	// Rules may test any product field, so membership is re-evaluated on every edit
+	ICollectionService.RefreshProductMemberships([]string{id})
+	cachemanager.DeletePrefix(productCachePrefix)
+
+	return s.GetProductForAdmin(id)
 }
 
 func (s *productService) DeleteProduct(id string) error {
 	return nil
 }
+
+// toProductResponses converts products to DTOs with prices resolved at t
+func (s *productService) toProductResponses(db *gorm.DB, products []entity.Product, t time.Time) ([]dto.ProductResponse, error) {
+	productPrices, variantPrices, err := IPriceScheduleService.ResolvePrices(db, products, t)
+	if err != nil {
+		return nil, err
+	}
+
+	bundles, err := IBundleService.ResolveBundles(db, products, productPrices, t)
+	if err != nil {
+		return nil, err
+	}
+
+	productIDs := make([]string, len(products))
+	for i, product := range products {
+		productIDs[i] = product.ID
+	}
+	productStock, variantStock, err := IInventoryService.GetStockLevels(db, productIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	productDtos := make([]dto.ProductResponse, len(products))
+	for i, product := range products {
+		price := productPrices[product.ID]
+		bundle, isBundle := bundles[product.ID]
+		if isBundle {
+			price = bundle.Price
+		}
+		productDtos[i] = dto.GetProductResponse(product, price, variantPrices)
+		switch {
+		case isBundle:
+			productDtos[i].Bundle = &bundle.Response
+			productDtos[i].Inventory = dto.NewInventoryResponse(bundle.Response.Available)
+		case product.Type != entity.ProductTypeDigital:
+			productDtos[i].Inventory = dto.NewInventoryResponse(productStock[product.ID])
+			for j, variant := range productDtos[i].Variants {
+				if available, ok := variantStock[variant.ID]; ok {
+					productDtos[i].Variants[j].Inventory = dto.NewInventoryResponse(available)
+				}
+			}
+		}
+	}
+	return productDtos, nil
+}
+
+// publishedProductResponses loads the published products among ids, keyed by ID
+func (s *productService) publishedProductResponses(db *gorm.DB, ids []string) (map[string]dto.ProductResponse, error) {
+	responses := make(map[string]dto.ProductResponse)
+	if len(ids) == 0 {
+		return responses, nil
+	}
+
+	var products []entity.Product
+	if err := publishedProducts(preloadProductDetails(db)).Where("id IN ?", ids).Find(&products).Error; err != nil {
+		return nil, err
+	}
+	productDtos, err := s.toProductResponses(db, products, time.Now().UTC())
+	if err != nil {
+		return nil, err
+	}
+	for _, product := range productDtos {
+		responses[product.ID] = product
+	}
+	return responses, nil
+}
+
+// publishedProducts restricts a query to products visible in the storefront
+func publishedProducts(db *gorm.DB) *gorm.DB {
+	return db.Where("is_active = ? AND status = ?", true, entity.ProductStatusPublished)
+}
+
+// preloadProductDetails loads the relations shown on catalog pages
+func preloadProductDetails(db *gorm.DB) *gorm.DB {
+	return db.
+		Preload("Images", func(db *gorm.DB) *gorm.DB { return db.Order("sort_order ASC") }).
+		Preload("Images.Renditions").
+		Preload("Variants", func(db *gorm.DB) *gorm.DB { return db.Order("sort_order ASC") })
+}
@@ -0,0 +1,271 @@
+package service
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+
+	"backend-ecommerce/internal/application/dto"
+	"backend-ecommerce/internal/application/entity"
+	"backend-ecommerce/internal/application/tools"
+	"backend-ecommerce/internal/infrastructure/dbmanager"
+	"backend-ecommerce/internal/infrastructure/logger"
+)
+
+type promotionService struct {
+}
+
+// GetPromotions returns every promotion in the order they are evaluated
+func (s *promotionService) GetPromotions() dto.ResponseDto {
+	db := dbmanager.GetDB()
+
+	var promotions []entity.Promotion
+	if err := db.Order("priority DESC, created_at ASC").Find(&promotions).Error; err != nil {
+		logger.Error("Error fetching promotions: %v", err)
+		return *dto.Fail("Error fetching promotions")
+	}
+
+	promotionDtos := make([]dto.PromotionResponse, len(promotions))
+	for i, promotion := range promotions {
+		promotionDtos[i] = dto.GetPromotionResponse(promotion)
+	}
+
+	return *dto.SuccessCount(promotionDtos, int64(len(promotionDtos)))
+}
+
+// GetPromotion returns a promotion
+func (s *promotionService) GetPromotion(id string) dto.ResponseDto {
+	promotion, response := s.findPromotion(dbmanager.GetDB(), id)
+	if promotion == nil {
+		return response
+	}
+	return *dto.Success(dto.GetPromotionResponse(*promotion))
+}
+
+// CreatePromotion creates an automatic promotion. It applies to carts as
+// soon as it is active and within its validity window.
+func (s *promotionService) CreatePromotion(req dto.PromotionCreateRequest) dto.ResponseDto {
+	promotion := s.newPromotion(req)
+	if failure := s.validate(&promotion); failure != "" {
+		return *dto.Fail(failure)
+	}
+
+	if err := dbmanager.GetDB().Create(&promotion).Error; err != nil {
+		logger.Error("Error creating promotion: %v", err)
+		return *dto.Fail("Error creating promotion")
+	}
+
+	return *dto.Success(dto.GetPromotionResponse(promotion))
+}
+
+// UpdatePromotion updates a promotion. Carts are priced with the change from
+// their next view; orders already placed keep the discounts they got.
+func (s *promotionService) UpdatePromotion(id string, req dto.PromotionUpdateRequest) dto.ResponseDto {
+	db := dbmanager.GetDB()
+
+	promotion, response := s.findPromotion(db, id)
+	if promotion == nil {
+		return response
+	}
+
+	if req.Name != nil {
+		promotion.Name = strings.TrimSpace(*req.Name)
+	}
+	if req.Description != nil {
+		promotion.Description = *req.Description
+	}
+	if req.Rules != nil {
+		promotion.Rules = dto.GetPromotionRules(*req.Rules)
+	}
+	if req.Priority != nil {
+		promotion.Priority = *req.Priority
+	}
+	if req.Exclusive != nil {
+		promotion.Exclusive = *req.Exclusive
+	}
+	if req.StartsAt != nil {
+		promotion.StartsAt = req.StartsAt
+	}
+	if req.EndsAt != nil {
+		promotion.EndsAt = req.EndsAt
+	}
+	if req.IsActive != nil {
+		promotion.IsActive = *req.IsActive
+	}
+	if failure := s.validate(promotion); failure != "" {
+		return *dto.Fail(failure)
+	}
+
+	if err := db.Save(promotion).Error; err != nil {
+		logger.Error("Error updating promotion: %v", err)
+		return *dto.Fail("Error updating promotion")
+	}
+
+	return *dto.Success(dto.GetPromotionResponse(*promotion))
+}
+
+// DeletePromotion removes a promotion. Orders keep the discounts it gave.
+func (s *promotionService) DeletePromotion(id string) dto.ResponseDto {
+	result := dbmanager.GetDB().Where("id = ?", id).Delete(&entity.Promotion{})
+	if result.Error != nil {
+		logger.Error("Error deleting promotion: %v", result.Error)
+		return *dto.Fail("Error deleting promotion")
+	}
+	if result.RowsAffected == 0 {
+		return *dto.Fail("Promotion not found")
+	}
+
+	return *dto.Success("Promotion deleted successfully")
+}
+
+// SimulatePromotion prices a sample cart with a saved or draft promotion, so
+// admins can check what it gives before customers see it. The promotion is
+// evaluated whatever its status and validity window; the sample cart is
+// priced at current catalog prices and stock, without discount codes.
+func (s *promotionService) SimulatePromotion(req dto.PromotionSimulateRequest) dto.ResponseDto {
+	db := dbmanager.GetDB()
+
+	var promotion entity.Promotion
+	switch {
+	case req.Promotion != nil:
+		promotion = s.newPromotion(*req.Promotion)
+		if failure := s.validate(&promotion); failure != "" {
+			return *dto.Fail(failure)
+		}
+	case req.PromotionID != "":
+		found, response := s.findPromotion(db, req.PromotionID)
+		if found == nil {
+			return response
+		}
+		promotion = *found
+	default:
+		return *dto.Fail("Give the ID of a promotion or a draft promotion to simulate")
+	}
+
+	promotions := []entity.Promotion{promotion}
+	if req.IncludeActive {
+		active, err := s.active(db, time.Now().UTC())
+		if err != nil {
+			logger.Error("Error fetching active promotions: %v", err)
+			return *dto.Fail("Error simulating promotion")
+		}
+		for _, other := range active {
+			if other.ID != promotion.ID {
+				promotions = append(promotions, other)
+			}
+		}
+	}
+
+	cart := pricingCart{Promotions: promotions}
+	for i, item := range req.Items {
+		cart.Lines = append(cart.Lines, pricingLine{
+			LineID:    fmt.Sprintf("line-%d", i+1),
+			ProductID: item.ProductID,
+			VariantID: item.VariantID,
+			Quantity:  item.Quantity,
+		})
+	}
+	quote, _, err := IPricingService.quote(db, cart, nil)
+	if err != nil {
+		logger.Error("Error simulating promotion: %v", err)
+		return *dto.Fail("Error simulating promotion")
+	}
+
+	return *dto.Success(dto.GetPromotionSimulationResponse(*quote))
+}
+
+// active returns the promotions that apply to carts at now, highest
+// priority first
+func (s *promotionService) active(db *gorm.DB, now time.Time) ([]entity.Promotion, error) {
+	var promotions []entity.Promotion
+	err := db.Where("is_active = ?", true).
+		Where("starts_at IS NULL OR starts_at <= ?", now).
+		Where("ends_at IS NULL OR ends_at > ?", now).
+		Order("priority DESC, created_at ASC").Find(&promotions).Error
+	return promotions, err
+}
+
+// newPromotion builds a promotion from a create request
+func (s *promotionService) newPromotion(req dto.PromotionCreateRequest) entity.Promotion {
+	promotion := entity.Promotion{
+		ID:          tools.NewUuid(),
+		Name:        strings.TrimSpace(req.Name),
+		Description: req.Description,
+		Type:        entity.PromotionType(req.Type),
+		Rules:       dto.GetPromotionRules(req.Rules),
+		Priority:    req.Priority,
+		Exclusive:   req.Exclusive,
+		StartsAt:    req.StartsAt,
+		EndsAt:      req.EndsAt,
+		IsActive:    true,
+	}
+	if req.IsActive != nil {
+		promotion.IsActive = *req.IsActive
+	}
+	return promotion
+}
+
+// findPromotion loads a promotion. On failure it returns nil and the response to send.
+func (s *promotionService) findPromotion(db *gorm.DB, id string) (*entity.Promotion, dto.ResponseDto) {
+	var promotion entity.Promotion
+	if err := db.Where("id = ?", id).First(&promotion).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, *dto.Fail("Promotion not found")
+		}
+		logger.Error("Error fetching promotion: %v", err)
+		return nil, *dto.Fail("Error fetching promotion")
+	}
+	return &promotion, dto.ResponseDto{}
+}
+
+// validate returns why a promotion's settings are invalid, or "" if they are
+// valid. A buy X get Y promotion without a percentage gives its units free.
+func (s *promotionService) validate(promotion *entity.Promotion) string {
+	rules := &promotion.Rules
+	switch promotion.Type {
+	case entity.PromotionBuyXGetY:
+		if rules.BuyQuantity < 1 || rules.GetQuantity < 1 {
+			return "Buy X get Y needs a buy quantity and a get quantity of at least 1"
+		}
+		if rules.Percent == 0 {
+			rules.Percent = 100
+		}
+	case entity.PromotionMultiBuy:
+		if rules.BuyQuantity < 2 || rules.GetQuantity < 1 || rules.GetQuantity >= rules.BuyQuantity {
+			return "A multi-buy needs a group of at least 2 items with fewer free items than the group, e.g. 3 for 2"
+		}
+		if len(rules.ProductIDs) == 0 && len(rules.CategoryIDs) == 0 {
+			return "A multi-buy must be limited to products or categories"
+		}
+	case entity.PromotionSpendThreshold:
+		if len(rules.Tiers) == 0 {
+			return "A spend threshold promotion needs at least one tier"
+		}
+		seen := make(map[float64]bool, len(rules.Tiers))
+		for i, tier := range rules.Tiers {
+			rules.Tiers[i].MinSubtotal = tools.RoundPrice(tier.MinSubtotal)
+			if seen[rules.Tiers[i].MinSubtotal] {
+				return "Each tier needs a different minimum subtotal"
+			}
+			seen[rules.Tiers[i].MinSubtotal] = true
+		}
+	case entity.PromotionBundle:
+		products := make(map[string]bool, len(rules.ProductIDs))
+		for _, productID := range rules.ProductIDs {
+			products[productID] = true
+		}
+		if len(products) < 2 || len(products) != len(rules.ProductIDs) {
+			return "A bundle needs at least two different products"
+		}
+		if (rules.Percent > 0) == (rules.Amount > 0) {
+			return "A bundle needs either a percentage or an amount off"
+		}
+		rules.Amount = tools.RoundPrice(rules.Amount)
+	}
+	if promotion.StartsAt != nil && promotion.EndsAt != nil && !promotion.EndsAt.After(*promotion.StartsAt) {
+		return "The validity window must end after it starts"
+	}
+	return ""
+}
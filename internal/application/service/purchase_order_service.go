@@ -0,0 +1,430 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"backend-ecommerce/internal/application/dto"
+	"backend-ecommerce/internal/application/entity"
+	"backend-ecommerce/internal/application/tools"
+	"backend-ecommerce/internal/infrastructure/dbmanager"
+	"backend-ecommerce/internal/infrastructure/logger"
+)
+
+// errPurchaseOrderFailure carries a message for the client out of a transaction
+type errPurchaseOrderFailure struct {
+	message string
+}
+
+func (e errPurchaseOrderFailure) Error() string {
+	return e.message
+}
+
+type purchaseOrderService struct {
+}
+
+// GetPurchaseOrders returns purchase orders, newest first, optionally
+// filtered by status and supplier
+func (s *purchaseOrderService) GetPurchaseOrders(status, supplierID string, page, pageSize int) dto.ResponseDto {
+	db := dbmanager.GetDB()
+
+	query := db.Model(&entity.PurchaseOrder{})
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+	if supplierID != "" {
+		query = query.Where("supplier_id = ?", supplierID)
+	}
+
+	var total int64
+	if err := query.Session(&gorm.Session{}).Count(&total).Error; err != nil {
+		logger.Error("Error counting purchase orders: %v", err)
+		return *dto.Fail("Error fetching purchase orders")
+	}
+
+	var orders []entity.PurchaseOrder
+	if err := s.preload(query.Session(&gorm.Session{})).Order("created_at DESC").
+		Offset((page - 1) * pageSize).Limit(pageSize).Find(&orders).Error; err != nil {
+		logger.Error("Error fetching purchase orders: %v", err)
+		return *dto.Fail("Error fetching purchase orders")
+	}
+
+	orderDtos := make([]dto.PurchaseOrderResponse, len(orders))
+	for i, order := range orders {
+		orderDtos[i] = dto.GetPurchaseOrderResponse(order)
+	}
+
+	return *dto.SuccessCount(orderDtos, total)
+}
+
+// GetPurchaseOrder returns a purchase order with its lines
+func (s *purchaseOrderService) GetPurchaseOrder(id string) dto.ResponseDto {
+	db := dbmanager.GetDB()
+
+	var order entity.PurchaseOrder
+	if err := s.preload(db).Where("id = ?", id).First(&order).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return *dto.Fail("Purchase order not found")
+		}
+		logger.Error("Error fetching purchase order: %v", err)
+		return *dto.Fail("Error fetching purchase order")
+	}
+
+	return *dto.Success(dto.GetPurchaseOrderResponse(order))
+}
+
+// CreatePurchaseOrder raises a draft purchase order
+func (s *purchaseOrderService) CreatePurchaseOrder(userID string, req dto.PurchaseOrderRequest) dto.ResponseDto {
+	db := dbmanager.GetDB()
+
+	order := entity.PurchaseOrder{
+		ID:        tools.NewUuid(),
+		Status:    entity.PurchaseOrderDraft,
+		CreatedBy: userID,
+	}
+	if failure, err := s.fill(db, &order, req); err != nil || failure != "" {
+		if err != nil {
+			logger.Error("Error preparing purchase order: %v", err)
+			return *dto.Fail("Error creating purchase order")
+		}
+		return *dto.Fail(failure)
+	}
+
+	number, err := s.nextNumber(db)
+	if err != nil {
+		logger.Error("Error numbering purchase order: %v", err)
+		return *dto.Fail("Error creating purchase order")
+	}
+	order.Number = number
+
+	if err := db.Create(&order).Error; err != nil {
+		logger.Error("Error creating purchase order: %v", err)
+		return *dto.Fail("Error creating purchase order")
+	}
+
+	return s.GetPurchaseOrder(order.ID)
+}
+
+// UpdatePurchaseOrder replaces the details and lines of a draft purchase order
+func (s *purchaseOrderService) UpdatePurchaseOrder(id string, req dto.PurchaseOrderRequest) dto.ResponseDto {
+	db := dbmanager.GetDB()
+
+	var order entity.PurchaseOrder
+	if err := db.Where("id = ?", id).First(&order).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return *dto.Fail("Purchase order not found")
+		}
+		logger.Error("Error fetching purchase order: %v", err)
+		return *dto.Fail("Error fetching purchase order")
+	}
+	if order.Status != entity.PurchaseOrderDraft {
+		return *dto.Fail("Only draft purchase orders can be edited")
+	}
+
+	if failure, err := s.fill(db, &order, req); err != nil || failure != "" {
+		if err != nil {
+			logger.Error("Error preparing purchase order: %v", err)
+			return *dto.Fail("Error updating purchase order")
+		}
+		return *dto.Fail(failure)
+	}
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&entity.PurchaseOrder{}).Where("id = ? AND status = ?", order.ID, entity.PurchaseOrderDraft).
+			Updates(map[string]interface{}{
+				"supplier_id":   order.SupplierID,
+				"location_id":   order.LocationID,
+				"currency":      order.Currency,
+				"shipping_cost": order.ShippingCost,
+				"other_costs":   order.OtherCosts,
+				"notes":         order.Notes,
+				"expected_at":   order.ExpectedAt,
+				"updated_at":    time.Now().UTC(),
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return errPurchaseOrderFailure{"Only draft purchase orders can be edited"}
+		}
+		if err := tx.Where("purchase_order_id = ?", order.ID).Delete(&entity.PurchaseOrderLine{}).Error; err != nil {
+			return err
+		}
+		return tx.Create(&order.Lines).Error
+	})
+	if err != nil {
+		var failure errPurchaseOrderFailure
+		if errors.As(err, &failure) {
+			return *dto.Fail(failure.message)
+		}
+		logger.Error("Error updating purchase order: %v", err)
+		return *dto.Fail("Error updating purchase order")
+	}
+
+	return s.GetPurchaseOrder(order.ID)
+}
+
+// DeletePurchaseOrder removes a draft purchase order
+func (s *purchaseOrderService) DeletePurchaseOrder(id string) dto.ResponseDto {
+	db := dbmanager.GetDB()
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		var order entity.PurchaseOrder
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", id).First(&order).Error; err != nil {
+			return err
+		}
+		if order.Status != entity.PurchaseOrderDraft {
+			return errPurchaseOrderFailure{"Only draft purchase orders can be deleted; close it instead"}
+		}
+		if err := tx.Where("purchase_order_id = ?", id).Delete(&entity.PurchaseOrderLine{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&order).Error
+	})
+	if err != nil {
+		var failure errPurchaseOrderFailure
+		switch {
+		case err == gorm.ErrRecordNotFound:
+			return *dto.Fail("Purchase order not found")
+		case errors.As(err, &failure):
+			return *dto.Fail(failure.message)
+		}
+		logger.Error("Error deleting purchase order: %v", err)
+		return *dto.Fail("Error deleting purchase order")
+	}
+
+	return *dto.Success("Purchase order deleted successfully")
+}
+
+// SendPurchaseOrder marks a draft purchase order as sent to the supplier,
+// after which goods can be received against it
+func (s *purchaseOrderService) SendPurchaseOrder(id string) dto.ResponseDto {
+	return s.transition(id, entity.PurchaseOrderSent, "sent_at", []entity.PurchaseOrderStatus{entity.PurchaseOrderDraft},
+		"Only draft purchase orders can be sent")
+}
+
+// ClosePurchaseOrder stops a purchase order from accepting deliveries, e.g.
+// when the supplier cannot ship the rest
+func (s *purchaseOrderService) ClosePurchaseOrder(id string) dto.ResponseDto {
+	return s.transition(id, entity.PurchaseOrderClosed, "closed_at",
+		[]entity.PurchaseOrderStatus{entity.PurchaseOrderSent, entity.PurchaseOrderPartiallyReceived, entity.PurchaseOrderReceived},
+		"Only sent or received purchase orders can be closed")
+}
+
+// ReceiveGoods books goods arriving against a purchase order into stock at a
+// location. Each unit enters the inventory at its landed cost and the receipt
+// is recorded in the stock ledger with the purchase order as reference.
+func (s *purchaseOrderService) ReceiveGoods(userID, id string, req dto.GoodsReceiptRequest) dto.ResponseDto {
+	db := dbmanager.GetDB()
+
+	var productIDs []string
+	err := db.Transaction(func(tx *gorm.DB) error {
+		var order entity.PurchaseOrder
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Preload("Lines").
+			Where("id = ?", id).First(&order).Error; err != nil {
+			return err
+		}
+		if !order.Status.CanReceive() {
+			return errPurchaseOrderFailure{"Goods can only be received against sent purchase orders"}
+		}
+
+		locationID := order.LocationID
+		if req.LocationID != nil && *req.LocationID != locationID {
+			locationID = *req.LocationID
+			if err := tx.Where("id = ?", locationID).First(&entity.StockLocation{}).Error; err != nil {
+				if err == gorm.ErrRecordNotFound {
+					return errPurchaseOrderFailure{"Stock location not found"}
+				}
+				return err
+			}
+		}
+
+		note := req.Note
+		if note == "" {
+			note = "Received on " + order.Number
+		}
+
+		lines := make(map[string]*entity.PurchaseOrderLine, len(order.Lines))
+		for i := range order.Lines {
+			lines[order.Lines[i].ID] = &order.Lines[i]
+		}
+
+		for _, received := range req.Lines {
+			line, ok := lines[received.LineID]
+			if !ok {
+				return errPurchaseOrderFailure{"Purchase order line not found"}
+			}
+			if received.Quantity > line.Outstanding() {
+				return errPurchaseOrderFailure{fmt.Sprintf("Only %d units of %s are outstanding", line.Outstanding(), line.SKU)}
+			}
+
+			landed := order.LandedUnitCost(*line)
+			movement := entity.StockMovement{
+				LocationID:    locationID,
+				ProductID:     line.ProductID,
+				VariantID:     line.VariantID,
+				SKU:           line.SKU,
+				Quantity:      received.Quantity,
+				UnitCost:      &landed,
+				Reason:        entity.StockMovementRestock,
+				ReferenceType: entity.StockReferencePurchaseOrder,
+				ReferenceID:   &order.ID,
+				ActorID:       &userID,
+				Note:          note,
+			}
+			if err := IStockMovementService.apply(tx, &movement); err != nil {
+				return err
+			}
+
+			line.ReceivedQuantity += received.Quantity
+			line.LandedUnitCost = landed
+			if err := tx.Model(line).Updates(map[string]interface{}{
+				"received_quantity": line.ReceivedQuantity,
+				"landed_unit_cost":  landed,
+			}).Error; err != nil {
+				return err
+			}
+			productIDs = append(productIDs, line.ProductID)
+		}
+
+		status := entity.PurchaseOrderReceived
+		for _, line := range order.Lines {
+			if line.Outstanding() > 0 {
+				status = entity.PurchaseOrderPartiallyReceived
+			}
+		}
+		return tx.Model(&order).Updates(map[string]interface{}{
+			"status":      status,
+			"received_at": time.Now().UTC(),
+		}).Error
+	})
+	if err != nil {
+		var failure errPurchaseOrderFailure
+		switch {
+		case err == gorm.ErrRecordNotFound:
+			return *dto.Fail("Purchase order not found")
+		case errors.As(err, &failure):
+			return *dto.Fail(failure.message)
+		}
+		logger.Error("Error receiving goods: %v", err)
+		return *dto.Fail("Error receiving goods")
+	}
+
+	IInventoryService.stockChanged(productIDs)
+
+	return s.GetPurchaseOrder(id)
+}
+
+// transition moves a purchase order to a new status, stamping the given column
+func (s *purchaseOrderService) transition(id string, status entity.PurchaseOrderStatus, stampColumn string, from []entity.PurchaseOrderStatus, failure string) dto.ResponseDto {
+	db := dbmanager.GetDB()
+
+	result := db.Model(&entity.PurchaseOrder{}).Where("id = ? AND status IN ?", id, from).
+		Updates(map[string]interface{}{"status": status, stampColumn: time.Now().UTC()})
+	if result.Error != nil {
+		logger.Error("Error updating purchase order status: %v", result.Error)
+		return *dto.Fail("Error updating purchase order")
+	}
+	if result.RowsAffected == 0 {
+		var count int64
+		if err := db.Model(&entity.PurchaseOrder{}).Where("id = ?", id).Count(&count).Error; err != nil {
+			logger.Error("Error fetching purchase order: %v", err)
+			return *dto.Fail("Error updating purchase order")
+		}
+		if count == 0 {
+			return *dto.Fail("Purchase order not found")
+		}
+		return *dto.Fail(failure)
+	}
+
+	return s.GetPurchaseOrder(id)
+}
+
+// fill validates a request and copies it onto the order, building new lines.
+// The returned message explains why the request cannot be used.
+func (s *purchaseOrderService) fill(db *gorm.DB, order *entity.PurchaseOrder, req dto.PurchaseOrderRequest) (string, error) {
+	var supplier entity.Supplier
+	if err := db.Where("id = ?", req.SupplierID).First(&supplier).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return "Supplier not found", nil
+		}
+		return "", err
+	}
+	if !supplier.IsActive && supplier.ID != order.SupplierID {
+		return "The supplier is inactive", nil
+	}
+
+	order.SupplierID = supplier.ID
+	order.LocationID = req.LocationID
+	order.Currency = supplier.Currency
+	if req.Currency != "" {
+		order.Currency = strings.ToUpper(req.Currency)
+	}
+	order.ShippingCost = req.ShippingCost
+	order.OtherCosts = req.OtherCosts
+	order.Notes = req.Notes
+	order.ExpectedAt = nil
+	if req.ExpectedAt != nil && *req.ExpectedAt != "" {
+		expected, err := time.Parse("2006-01-02", *req.ExpectedAt)
+		if err != nil {
+			return "Invalid expected date, expected YYYY-MM-DD", nil
+		}
+		order.ExpectedAt = &expected
+	}
+
+	order.Lines = make([]entity.PurchaseOrderLine, 0, len(req.Lines))
+	seen := make(map[stockKey]bool)
+	for i, lineReq := range req.Lines {
+		failure, err := checkStockTarget(db, req.LocationID, lineReq.ProductID, lineReq.VariantID)
+		if err != nil || failure != "" {
+			return failure, err
+		}
+		key := newStockKey(lineReq.ProductID, lineReq.VariantID)
+		if seen[key] {
+			return "Each SKU can only appear on one line", nil
+		}
+		seen[key] = true
+
+		line := entity.PurchaseOrderLine{
+			ID:              tools.NewUuid(),
+			PurchaseOrderID: order.ID,
+			ProductID:       lineReq.ProductID,
+			VariantID:       lineReq.VariantID,
+			Quantity:        lineReq.Quantity,
+			UnitCost:        lineReq.UnitCost,
+			SortOrder:       i,
+		}
+		if line.SKU, err = stockSKU(db, line.ProductID, line.VariantID); err != nil {
+			return "", err
+		}
+		order.Lines = append(order.Lines, line)
+	}
+	return "", nil
+}
+
+// nextNumber picks an unused purchase order number such as PO-20240131-4821
+func (s *purchaseOrderService) nextNumber(db *gorm.DB) (string, error) {
+	prefix := "PO-" + time.Now().UTC().Format("20060102") + "-"
+	for attempt := 0; attempt < 10; attempt++ {
+		number := prefix + tools.CreateCode()
+		var count int64
+		if err := db.Model(&entity.PurchaseOrder{}).Where("number = ?", number).Count(&count).Error; err != nil {
+			return "", err
+		}
+		if count == 0 {
+			return number, nil
+		}
+	}
+	return "", errors.New("no free purchase order number")
+}
+
+// preload loads what purchase order responses show
+func (s *purchaseOrderService) preload(db *gorm.DB) *gorm.DB {
+	return db.Preload("Supplier").Preload("Location").
+		Preload("Lines", func(db *gorm.DB) *gorm.DB { return db.Order("sort_order ASC") })
+}
@@ -0,0 +1,160 @@
+package service
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+
+	"backend-ecommerce/internal/application/dto"
+	"backend-ecommerce/internal/application/entity"
+	"backend-ecommerce/internal/application/tools"
+	"backend-ecommerce/internal/infrastructure/dbmanager"
+	"backend-ecommerce/internal/infrastructure/logger"
+)
+
+// recentlyViewedLimit is how many products are remembered per shopper
+const recentlyViewedLimit = 20
+
+type recentlyViewedService struct {
+}
+
+// GetRecentlyViewed returns a shopper's recently viewed products that are still published, newest first
+func (s *recentlyViewedService) GetRecentlyViewed(userID, guestToken string) dto.ResponseDto {
+	db := dbmanager.GetDB()
+
+	var views []entity.RecentlyViewed
+	if err := db.Scopes(shopperScope(userID, guestToken)).
+		Order("viewed_at DESC").Limit(recentlyViewedLimit).Find(&views).Error; err != nil {
+		logger.Error("Error fetching recently viewed products: %v", err)
+		return *dto.Fail("Error fetching recently viewed products")
+	}
+
+	ids := make([]string, len(views))
+	for i, view := range views {
+		ids[i] = view.ProductID
+	}
+	products, err := IProductService.publishedProductResponses(db, ids)
+	if err != nil {
+		logger.Error("Error fetching products: %v", err)
+		return *dto.Fail("Error fetching recently viewed products")
+	}
+
+	viewDtos := make([]dto.RecentlyViewedResponse, 0, len(views))
+	for _, view := range views {
+		if product, ok := products[view.ProductID]; ok {
+			viewDtos = append(viewDtos, dto.RecentlyViewedResponse{
+				ProductID: view.ProductID,
+				ViewedAt:  view.ViewedAt.Format(time.RFC3339),
+				Product:   product,
+			})
+		}
+	}
+
+	return *dto.SuccessCount(viewDtos, int64(len(viewDtos)))
+}
+
+// RecordView moves a product to the top of the shopper's recently viewed
+// list, dropping the oldest entries beyond the limit
+func (s *recentlyViewedService) RecordView(userID, guestToken, productID string) dto.ResponseDto {
+	db := dbmanager.GetDB()
+
+	if err := publishedProducts(db.Select("id")).Where("id = ?", productID).First(&entity.Product{}).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return *dto.Fail("Product not found")
+		}
+		logger.Error("Error fetching product: %v", err)
+		return *dto.Fail("Error fetching product")
+	}
+
+	now := time.Now().UTC()
+	err := db.Transaction(func(tx *gorm.DB) error {
+		var view entity.RecentlyViewed
+		err := tx.Scopes(shopperScope(userID, guestToken)).Where("product_id = ?", productID).First(&view).Error
+		switch {
+		case err == nil:
+			err = tx.Model(&view).Update("viewed_at", now).Error
+		case err == gorm.ErrRecordNotFound:
+			view = entity.RecentlyViewed{ID: tools.NewUuid(), ProductID: productID, ViewedAt: now}
+			if userID != "" {
+				view.UserID = &userID
+			} else {
+				view.GuestToken = guestToken
+			}
+			err = tx.Create(&view).Error
+		}
+		if err != nil {
+			return err
+		}
+		return s.trim(tx, userID, guestToken)
+	})
+	if err != nil {
+		logger.Error("Error recording product view: %v", err)
+		return *dto.Fail("Error recording product view")
+	}
+
+	return *dto.Success("Product view recorded")
+}
+
+// ClearRecentlyViewed forgets every product the shopper has viewed
+func (s *recentlyViewedService) ClearRecentlyViewed(userID, guestToken string) dto.ResponseDto {
+	db := dbmanager.GetDB()
+
+	if err := db.Scopes(shopperScope(userID, guestToken)).Delete(&entity.RecentlyViewed{}).Error; err != nil {
+		logger.Error("Error clearing recently viewed products: %v", err)
+		return *dto.Fail("Error clearing recently viewed products")
+	}
+
+	return *dto.Success("Recently viewed products cleared")
+}
+
+// mergeGuest moves a guest's views into a user's list, keeping the latest view of each product
+func (s *recentlyViewedService) mergeGuest(tx *gorm.DB, userID, guestToken string) error {
+	var guestViews []entity.RecentlyViewed
+	if err := tx.Scopes(shopperScope("", guestToken)).Find(&guestViews).Error; err != nil {
+		return err
+	}
+	if len(guestViews) == 0 {
+		return nil
+	}
+
+	var userViews []entity.RecentlyViewed
+	if err := tx.Scopes(shopperScope(userID, "")).Find(&userViews).Error; err != nil {
+		return err
+	}
+	existing := make(map[string]entity.RecentlyViewed, len(userViews))
+	for _, view := range userViews {
+		existing[view.ProductID] = view
+	}
+
+	for _, view := range guestViews {
+		if userView, ok := existing[view.ProductID]; ok {
+			if view.ViewedAt.After(userView.ViewedAt) {
+				if err := tx.Model(&userView).Update("viewed_at", view.ViewedAt).Error; err != nil {
+					return err
+				}
+			}
+			if err := tx.Delete(&view).Error; err != nil {
+				return err
+			}
+			continue
+		}
+		if err := tx.Model(&view).Updates(map[string]interface{}{"user_id": userID, "guest_token": ""}).Error; err != nil {
+			return err
+		}
+	}
+
+	return s.trim(tx, userID, "")
+}
+
+// trim drops a shopper's views beyond recentlyViewedLimit
+func (s *recentlyViewedService) trim(tx *gorm.DB, userID, guestToken string) error {
+	var stale []string
+	if err := tx.Model(&entity.RecentlyViewed{}).Scopes(shopperScope(userID, guestToken)).
+		Order("viewed_at DESC").Offset(recentlyViewedLimit).Limit(1000).Pluck("id", &stale).Error; err != nil {
+		return err
+	}
+	if len(stale) == 0 {
+		return nil
+	}
+	return tx.Where("id IN ?", stale).Delete(&entity.RecentlyViewed{}).Error
+}
@@ -0,0 +1,289 @@
+package service
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"backend-ecommerce/internal/application/dto"
+	"backend-ecommerce/internal/application/entity"
+	"backend-ecommerce/internal/application/tools"
+	"backend-ecommerce/internal/infrastructure/config"
+	"backend-ecommerce/internal/infrastructure/dbmanager"
+	"backend-ecommerce/internal/infrastructure/logger"
+)
+
+// lowStockDigestLines is how many stock levels are listed in a digest
+const lowStockDigestLines = 50
+
+// soldOrderStatuses are the order statuses that count as sales
+var soldOrderStatuses = []entity.OrderStatus{
+	entity.OrderStatusPaid,
+	entity.OrderStatusProcessing,
+	entity.OrderStatusShipped,
+	entity.OrderStatusCompleted,
+}
+
+type replenishmentService struct {
+	mu sync.Mutex
+}
+
+// SetReorderSettings sets the reorder point and quantity of a product or
+// variant at a location
+func (s *replenishmentService) SetReorderSettings(req dto.ReorderSettingsRequest) dto.ResponseDto {
+	db := dbmanager.GetDB()
+
+	failure, err := checkStockTarget(db, req.LocationID, req.ProductID, req.VariantID)
+	if err != nil {
+		logger.Error("Error checking stock target: %v", err)
+		return *dto.Fail("Error updating reorder settings")
+	}
+	if failure != "" {
+		return *dto.Fail(failure)
+	}
+
+	key := newStockKey(req.ProductID, req.VariantID)
+	var row entity.Inventory
+	err = db.Transaction(func(tx *gorm.DB) error {
+		err := stockRow(tx.Clauses(clause.Locking{Strength: "UPDATE"}), req.LocationID, key).First(&row).Error
+		switch {
+		case err == nil:
+			row.ReorderPoint = req.ReorderPoint
+			row.ReorderQuantity = req.ReorderQuantity
+			return tx.Model(&row).Updates(map[string]interface{}{
+				"reorder_point":    req.ReorderPoint,
+				"reorder_quantity": req.ReorderQuantity,
+			}).Error
+		case err == gorm.ErrRecordNotFound:
+			// Thresholds can be set before the first stock arrives
+			row = entity.Inventory{
+				ID:              tools.NewUuid(),
+				LocationID:      req.LocationID,
+				ProductID:       req.ProductID,
+				VariantID:       key.variantID(),
+				ReorderPoint:    req.ReorderPoint,
+				ReorderQuantity: req.ReorderQuantity,
+			}
+			return tx.Create(&row).Error
+		default:
+			return err
+		}
+	})
+	if err != nil {
+		logger.Error("Error updating reorder settings: %v", err)
+		return *dto.Fail("Error updating reorder settings")
+	}
+
+	return *dto.Success(dto.GetStockLevelResponse(row))
+}
+
+// GetLowStockReport lists the stock levels at active locations that are at or
+// below their reorder point, the ones running out soonest first
+func (s *replenishmentService) GetLowStockReport() dto.ResponseDto {
+	db := dbmanager.GetDB()
+
+	items, err := s.lowStock(db)
+	if err != nil {
+		logger.Error("Error building low-stock report: %v", err)
+		return *dto.Fail("Error fetching low-stock report")
+	}
+
+	return *dto.SuccessCount(items, int64(len(items)))
+}
+
+// SendLowStockDigest is run by cronmanager. It sends every admin one message
+// a day listing the stock levels that need reordering.
+func (s *replenishmentService) SendLowStockDigest() {
+	db := dbmanager.GetDB()
+	if db == nil {
+		return
+	}
+	if !s.mu.TryLock() {
+		return
+	}
+	defer s.mu.Unlock()
+
+	items, err := s.lowStock(db)
+	if err != nil {
+		logger.Error("Error building low-stock digest: %v", err)
+		return
+	}
+	if len(items) == 0 {
+		return
+	}
+
+	var adminIDs []string
+	if err := db.Model(&entity.User{}).Where("is_admin = ?", true).Pluck("id", &adminIDs).Error; err != nil {
+		logger.Error("Error fetching admins: %v", err)
+		return
+	}
+
+	var body strings.Builder
+	fmt.Fprintf(&body, "%d stock levels are at or below their reorder point:\n\n", len(items))
+	for i, item := range items {
+		if i == lowStockDigestLines {
+			fmt.Fprintf(&body, "...and %d more. See the low-stock report for the full list.\n", len(items)-i)
+			break
+		}
+		fmt.Fprintf(&body, "- %s %s at %s: %d available (reorder point %d), reorder %d",
+			item.SKU, item.Name, item.LocationCode, item.Available, item.ReorderPoint, item.ReorderQuantity)
+		if item.DaysOfCover != nil {
+			fmt.Fprintf(&body, ", about %.1f days of cover", *item.DaysOfCover)
+		}
+		body.WriteString("\n")
+	}
+
+	today := time.Now().UTC().Format("2006-01-02")
+	queued := 0
+	for _, adminID := range adminIDs {
+		ok, err := INotificationService.Enqueue(db, entity.Notification{
+			UserID:   adminID,
+			Kind:     "low_stock_digest",
+			DedupKey: fmt.Sprintf("low_stock_digest:%s:%s", adminID, today),
+			Subject:  fmt.Sprintf("Low stock: %d items need reordering", len(items)),
+			Body:     body.String(),
+		})
+		if err != nil {
+			logger.Error("Error queueing low-stock digest for %s: %v", adminID, err)
+			continue
+		}
+		if ok {
+			queued++
+		}
+	}
+
+	if queued > 0 {
+		logger.Info("Low-stock digest with %d items queued for %d admins", len(items), queued)
+	}
+}
+
+// lowStock builds the low-stock report
+func (s *replenishmentService) lowStock(db *gorm.DB) ([]dto.LowStockItemResponse, error) {
+	var rows []entity.Inventory
+	if err := db.Joins("Location").
+		Where("Location.is_active = ?", true).
+		Where("inventory.reorder_point > 0 AND inventory.quantity - inventory.reserved <= inventory.reorder_point").
+		Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	if len(rows) == 0 {
+		return []dto.LowStockItemResponse{}, nil
+	}
+
+	productIDs := make([]string, 0, len(rows))
+	seen := make(map[string]bool)
+	for _, row := range rows {
+		if !seen[row.ProductID] {
+			seen[row.ProductID] = true
+			productIDs = append(productIDs, row.ProductID)
+		}
+	}
+
+	var products []entity.Product
+	if err := db.Preload("Variants").Where("id IN ?", productIDs).Find(&products).Error; err != nil {
+		return nil, err
+	}
+	names := make(map[stockKey][2]string)
+	for _, product := range products {
+		names[stockKey{ProductID: product.ID}] = [2]string{product.SKU, product.Name}
+		for _, variant := range product.Variants {
+			names[stockKey{ProductID: product.ID, VariantID: variant.ID}] = [2]string{variant.SKU, product.Name + " - " + variant.Name}
+		}
+	}
+
+	// Days of cover are worked out per SKU over all active locations
+	var allRows []entity.Inventory
+	if err := activeStock(db, productIDs).Find(&allRows).Error; err != nil {
+		return nil, err
+	}
+	totals := make(map[stockKey]int)
+	variantStocked := make(map[string]bool)
+	for _, row := range allRows {
+		row.CalculateAvailable()
+		totals[newStockKey(row.ProductID, row.VariantID)] += row.Available
+		if row.VariantID != nil {
+			variantStocked[*row.VariantID] = true
+		}
+	}
+
+	days := config.Get().Inventory.VelocityDays
+	sold, err := s.unitsSold(db, productIDs, time.Now().UTC().AddDate(0, 0, -days), variantStocked)
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]dto.LowStockItemResponse, len(rows))
+	for i, row := range rows {
+		row.CalculateAvailable()
+		key := newStockKey(row.ProductID, row.VariantID)
+		items[i] = dto.LowStockItemResponse{
+			InventoryID:     row.ID,
+			LocationID:      row.LocationID,
+			ProductID:       row.ProductID,
+			VariantID:       row.VariantID,
+			SKU:             names[key][0],
+			Name:            names[key][1],
+			Quantity:        row.Quantity,
+			Reserved:        row.Reserved,
+			Available:       row.Available,
+			ReorderPoint:    row.ReorderPoint,
+			ReorderQuantity: row.ReorderQuantity,
+			TotalAvailable:  totals[key],
+			DailySales:      float64(sold[key]) / float64(days),
+		}
+		if row.Location != nil {
+			items[i].LocationCode = row.Location.Code
+		}
+		if items[i].DailySales > 0 {
+			cover := float64(totals[key]) / items[i].DailySales
+			items[i].DaysOfCover = &cover
+		}
+	}
+
+	sort.SliceStable(items, func(i, j int) bool {
+		a, b := items[i].DaysOfCover, items[j].DaysOfCover
+		switch {
+		case a != nil && b != nil && *a != *b:
+			return *a < *b
+		case (a == nil) != (b == nil):
+			return a != nil
+		}
+		return items[i].SKU < items[j].SKU
+	})
+	return items, nil
+}
+
+// unitsSold counts the units of each SKU in orders placed since the given
+// time. Sales of variants without stock of their own count towards their
+// product, which they are sold from.
+func (s *replenishmentService) unitsSold(db *gorm.DB, productIDs []string, since time.Time, variantStocked map[string]bool) (map[stockKey]int, error) {
+	var rows []struct {
+		ProductID string
+		VariantID *string
+		Units     int
+	}
+	if err := db.Model(&entity.OrderItem{}).
+		Select("order_items.product_id, order_items.variant_id, SUM(order_items.quantity) AS units").
+		Joins("JOIN orders ON orders.id = order_items.order_id").
+		Where("orders.status IN ? AND orders.created_at >= ?", soldOrderStatuses, since).
+		Where("order_items.product_id IN ?", productIDs).
+		Group("order_items.product_id, order_items.variant_id").
+		Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	sold := make(map[stockKey]int, len(rows))
+	for _, row := range rows {
+		key := newStockKey(row.ProductID, row.VariantID)
+		if key.VariantID != "" && !variantStocked[key.VariantID] {
+			key.VariantID = ""
+		}
+		sold[key] += row.Units
+	}
+	return sold, nil
+}
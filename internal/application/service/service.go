@@ -7,4 +7,28 @@ var (
 	IOrderService = &orderService{}
 	IPaymentService = &paymentService{}
 	ICartService = &cartService{}
+	IProductImageService = &productImageService{}
+	IPriceScheduleService = &priceScheduleService{}
+	IInventoryService = &inventoryService{}
+	IBundleService = &bundleService{}
+	IDigitalProductService = &digitalProductService{}
+	IProductRevisionService = &productRevisionService{}
+	IProductPublishingService = &productPublishingService{}
+	ICollectionService = &collectionService{}
+	IProductRelationService = &productRelationService{}
+	IRecentlyViewedService = &recentlyViewedService{}
+	IWishlistService = &wishlistService{}
+	INotificationService = &notificationService{}
+	IProductAlertService = &productAlertService{}
+	IStockLocationService = &stockLocationService{}
+	IStockMovementService = &stockMovementService{}
+	IReplenishmentService = &replenishmentService{}
+	ISupplierService = &supplierService{}
+	IPurchaseOrderService = &purchaseOrderService{}
+	IPricingService = &pricingService{}
+	ICartRecoveryService = &cartRecoveryService{}
+	ICartShareService = &cartShareService{}
+	ICouponService = &couponService{}
+	IPromotionService = &promotionService{}
+	IGiftCardService = &giftCardService{}
 )
\ No newline at end of file
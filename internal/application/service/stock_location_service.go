@@ -0,0 +1,178 @@
+package service
+
+import (
+	"strings"
+
+	"gorm.io/gorm"
+
+	"backend-ecommerce/internal/application/dto"
+	"backend-ecommerce/internal/application/entity"
+	"backend-ecommerce/internal/application/tools"
+	"backend-ecommerce/internal/infrastructure/dbmanager"
+	"backend-ecommerce/internal/infrastructure/logger"
+)
+
+type stockLocationService struct {
+}
+
+// GetStockLocations returns every stock location in allocation order
+func (s *stockLocationService) GetStockLocations() dto.ResponseDto {
+	db := dbmanager.GetDB()
+
+	var locations []entity.StockLocation
+	if err := db.Order("priority ASC").Order("code ASC").Find(&locations).Error; err != nil {
+		logger.Error("Error fetching stock locations: %v", err)
+		return *dto.Fail("Error fetching stock locations")
+	}
+
+	locationDtos := make([]dto.StockLocationResponse, len(locations))
+	for i, location := range locations {
+		locationDtos[i] = dto.GetStockLocationResponse(location)
+	}
+
+	return *dto.SuccessCount(locationDtos, int64(len(locationDtos)))
+}
+
+// CreateStockLocation creates a warehouse or store
+func (s *stockLocationService) CreateStockLocation(req dto.StockLocationCreateRequest) dto.ResponseDto {
+	db := dbmanager.GetDB()
+
+	location := entity.StockLocation{
+		ID:         tools.NewUuid(),
+		Code:       strings.ToUpper(strings.TrimSpace(req.Code)),
+		Name:       req.Name,
+		Type:       entity.StockLocationType(req.Type),
+		Street:     req.Street,
+		City:       req.City,
+		State:      req.State,
+		PostalCode: req.PostalCode,
+		Country:    req.Country,
+		Priority:   req.Priority,
+		IsActive:   true,
+	}
+	if req.IsActive != nil {
+		location.IsActive = *req.IsActive
+	}
+	if location.Code == "" {
+		return *dto.Fail("Code is required")
+	}
+
+	var count int64
+	if err := db.Model(&entity.StockLocation{}).Where("code = ?", location.Code).Count(&count).Error; err != nil {
+		logger.Error("Error checking stock location code: %v", err)
+		return *dto.Fail("Error creating stock location")
+	}
+	if count > 0 {
+		return *dto.Fail("A stock location with this code already exists")
+	}
+
+	if err := db.Create(&location).Error; err != nil {
+		logger.Error("Error creating stock location: %v", err)
+		return *dto.Fail("Error creating stock location")
+	}
+
+	return *dto.Success(dto.GetStockLocationResponse(location))
+}
+
+// UpdateStockLocation updates a stock location. Activating or deactivating it
+// changes what the storefront reports as available.
+func (s *stockLocationService) UpdateStockLocation(id string, req dto.StockLocationUpdateRequest) dto.ResponseDto {
+	db := dbmanager.GetDB()
+
+	var location entity.StockLocation
+	if err := db.Where("id = ?", id).First(&location).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return *dto.Fail("Stock location not found")
+		}
+		logger.Error("Error fetching stock location: %v", err)
+		return *dto.Fail("Error fetching stock location")
+	}
+
+	wasActive := location.IsActive
+	if req.Name != nil {
+		location.Name = *req.Name
+	}
+	if req.Type != nil {
+		location.Type = entity.StockLocationType(*req.Type)
+	}
+	if req.Street != nil {
+		location.Street = *req.Street
+	}
+	if req.City != nil {
+		location.City = *req.City
+	}
+	if req.State != nil {
+		location.State = *req.State
+	}
+	if req.PostalCode != nil {
+		location.PostalCode = *req.PostalCode
+	}
+	if req.Country != nil {
+		location.Country = *req.Country
+	}
+	if req.Priority != nil {
+		location.Priority = *req.Priority
+	}
+	if req.IsActive != nil {
+		location.IsActive = *req.IsActive
+	}
+
+	if err := db.Save(&location).Error; err != nil {
+		logger.Error("Error updating stock location: %v", err)
+		return *dto.Fail("Error updating stock location")
+	}
+
+	if location.IsActive != wasActive {
+		var productIDs []string
+		if err := db.Model(&entity.Inventory{}).Distinct("product_id").
+			Where("location_id = ?", location.ID).Pluck("product_id", &productIDs).Error; err != nil {
+			logger.Error("Error fetching stock of location %s: %v", location.ID, err)
+		}
+		IInventoryService.stockChanged(productIDs)
+	}
+
+	return *dto.Success(dto.GetStockLocationResponse(location))
+}
+
+// DeleteStockLocation removes a stock location that holds no stock. Locations
+// with stock or open reservations can be deactivated instead.
+func (s *stockLocationService) DeleteStockLocation(id string) dto.ResponseDto {
+	db := dbmanager.GetDB()
+
+	var failure string
+	err := db.Transaction(func(tx *gorm.DB) error {
+		var stocked int64
+		if err := tx.Model(&entity.Inventory{}).Where("location_id = ? AND (quantity > 0 OR reserved > 0)", id).
+			Count(&stocked).Error; err != nil {
+			return err
+		}
+		if stocked > 0 {
+			failure = "The location still holds stock; deactivate it instead"
+			return nil
+		}
+
+		if err := tx.Where("location_id = ?", id).Delete(&entity.Inventory{}).Error; err != nil {
+			return err
+		}
+		result := tx.Where("id = ?", id).Delete(&entity.StockLocation{})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		return nil
+	})
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return *dto.Fail("Stock location not found")
+		}
+		logger.Error("Error deleting stock location: %v", err)
+		return *dto.Fail("Error deleting stock location")
+	}
+	if failure != "" {
+		return *dto.Fail(failure)
+	}
+
+	return *dto.Success("Stock location deleted successfully")
+}
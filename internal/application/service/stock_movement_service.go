@@ -0,0 +1,323 @@
+package service
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"backend-ecommerce/internal/application/dto"
+	"backend-ecommerce/internal/application/entity"
+	"backend-ecommerce/internal/application/tools"
+	"backend-ecommerce/internal/infrastructure/dbmanager"
+	"backend-ecommerce/internal/infrastructure/logger"
+)
+
+type stockMovementService struct {
+}
+
+// AdjustStock changes the quantity on hand of a product or variant at a
+// location by a signed amount, e.g. for returns, damaged goods or restocks
+// outside of purchase orders
+func (s *stockMovementService) AdjustStock(actorID string, req dto.StockAdjustmentRequest) dto.ResponseDto {
+	db := dbmanager.GetDB()
+
+	if (req.ReferenceType == "") != (req.ReferenceID == nil) {
+		return *dto.Fail("A reference needs both a type and an ID")
+	}
+	failure, err := checkStockTarget(db, req.LocationID, req.ProductID, req.VariantID)
+	if err != nil {
+		logger.Error("Error checking stock target: %v", err)
+		return *dto.Fail("Error adjusting stock")
+	}
+	if failure != "" {
+		return *dto.Fail(failure)
+	}
+
+	movement := entity.StockMovement{
+		LocationID:    req.LocationID,
+		ProductID:     req.ProductID,
+		VariantID:     req.VariantID,
+		Quantity:      req.Quantity,
+		Reason:        entity.StockMovementReason(req.Reason),
+		ReferenceType: entity.StockMovementReference(req.ReferenceType),
+		ReferenceID:   req.ReferenceID,
+		ActorID:       &actorID,
+		Note:          req.Note,
+	}
+	err = db.Transaction(func(tx *gorm.DB) error {
+		return s.apply(tx, &movement)
+	})
+	if err != nil {
+		if err == ErrInsufficientStock {
+			return *dto.Fail("The adjustment would leave less stock than is on hand or reserved")
+		}
+		logger.Error("Error adjusting stock: %v", err)
+		return *dto.Fail("Error adjusting stock")
+	}
+
+	IInventoryService.stockChanged([]string{req.ProductID})
+
+	return *dto.Success(dto.GetStockMovementResponse(movement))
+}
+
+// TransferStock moves stock between two locations. Both sides are recorded
+// as transfer movements sharing a reference.
+func (s *stockMovementService) TransferStock(actorID string, req dto.StockTransferRequest) dto.ResponseDto {
+	db := dbmanager.GetDB()
+
+	for _, locationID := range []string{req.FromLocationID, req.ToLocationID} {
+		failure, err := checkStockTarget(db, locationID, req.ProductID, req.VariantID)
+		if err != nil {
+			logger.Error("Error checking stock target: %v", err)
+			return *dto.Fail("Error transferring stock")
+		}
+		if failure != "" {
+			return *dto.Fail(failure)
+		}
+	}
+
+	transferID := tools.NewUuid()
+	movements := []entity.StockMovement{
+		{LocationID: req.FromLocationID, Quantity: -req.Quantity},
+		{LocationID: req.ToLocationID, Quantity: req.Quantity},
+	}
+	for i := range movements {
+		movements[i].ProductID = req.ProductID
+		movements[i].VariantID = req.VariantID
+		movements[i].Reason = entity.StockMovementTransfer
+		movements[i].ReferenceType = entity.StockReferenceTransfer
+		movements[i].ReferenceID = &transferID
+		movements[i].ActorID = &actorID
+		movements[i].Note = req.Note
+	}
+	// Rows are locked in location order so opposite transfers cannot deadlock
+	if movements[1].LocationID < movements[0].LocationID {
+		movements[0], movements[1] = movements[1], movements[0]
+	}
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		for i := range movements {
+			if err := s.apply(tx, &movements[i]); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		if err == ErrInsufficientStock {
+			return *dto.Fail("Not enough unreserved stock at the source location")
+		}
+		logger.Error("Error transferring stock: %v", err)
+		return *dto.Fail("Error transferring stock")
+	}
+
+	IInventoryService.stockChanged([]string{req.ProductID})
+
+	movementDtos := make([]dto.StockMovementResponse, len(movements))
+	for i, movement := range movements {
+		movementDtos[i] = dto.GetStockMovementResponse(movement)
+	}
+	return *dto.Success(movementDtos)
+}
+
+// GetMovements reports stock movements, newest first, with the totals per
+// reason of every movement matching the filters
+func (s *stockMovementService) GetMovements(query dto.StockMovementQuery) dto.ResponseDto {
+	db := dbmanager.GetDB()
+
+	if query.Page < 1 {
+		query.Page = 1
+	}
+	if query.PageSize < 1 || query.PageSize > 200 {
+		query.PageSize = 50
+	}
+
+	filter := db.Model(&entity.StockMovement{})
+	if query.SKU != "" {
+		filter = filter.Where("stockMovements.sku = ?", query.SKU)
+	}
+	if query.ProductID != "" {
+		filter = filter.Where("stockMovements.product_id = ?", query.ProductID)
+	}
+	if query.LocationID != "" {
+		filter = filter.Where("stockMovements.location_id = ?", query.LocationID)
+	}
+	if query.Reason != "" {
+		filter = filter.Where("stockMovements.reason = ?", query.Reason)
+	}
+	if query.From != "" {
+		from, err := time.Parse("2006-01-02", query.From)
+		if err != nil {
+			return *dto.Fail("Invalid from date, expected YYYY-MM-DD")
+		}
+		filter = filter.Where("stockMovements.created_at >= ?", from)
+	}
+	if query.To != "" {
+		to, err := time.Parse("2006-01-02", query.To)
+		if err != nil {
+			return *dto.Fail("Invalid to date, expected YYYY-MM-DD")
+		}
+		filter = filter.Where("stockMovements.created_at < ?", to.AddDate(0, 0, 1))
+	}
+
+	var total int64
+	if err := filter.Session(&gorm.Session{}).Count(&total).Error; err != nil {
+		logger.Error("Error counting stock movements: %v", err)
+		return *dto.Fail("Error fetching stock movements")
+	}
+
+	var totals []struct {
+		Reason   string
+		Quantity int
+	}
+	if err := filter.Session(&gorm.Session{}).Select("stockMovements.reason AS reason, SUM(stockMovements.quantity) AS quantity").
+		Group("stockMovements.reason").Scan(&totals).Error; err != nil {
+		logger.Error("Error totalling stock movements: %v", err)
+		return *dto.Fail("Error fetching stock movements")
+	}
+
+	var movements []entity.StockMovement
+	if err := filter.Session(&gorm.Session{}).Preload("Location").
+		Order("stockMovements.created_at DESC").Order("stockMovements.id").
+		Offset((query.Page - 1) * query.PageSize).Limit(query.PageSize).
+		Find(&movements).Error; err != nil {
+		logger.Error("Error fetching stock movements: %v", err)
+		return *dto.Fail("Error fetching stock movements")
+	}
+
+	report := dto.StockMovementReportResponse{
+		Movements: make([]dto.StockMovementResponse, len(movements)),
+		Totals:    make(map[string]int, len(totals)),
+	}
+	for i, movement := range movements {
+		report.Movements[i] = dto.GetStockMovementResponse(movement)
+	}
+	for _, row := range totals {
+		report.Totals[row.Reason] = row.Quantity
+		report.Net += row.Quantity
+	}
+
+	return *dto.SuccessCount(report, total)
+}
+
+// ReconcileStock checks every stock level against the sum of its ledger
+// entries and returns those that differ
+func (s *stockMovementService) ReconcileStock() dto.ResponseDto {
+	db := dbmanager.GetDB()
+
+	ledger := db.Model(&entity.StockMovement{}).Select("COALESCE(SUM(stockMovements.quantity), 0)").
+		Where("stockMovements.location_id = inventory.location_id AND stockMovements.product_id = inventory.product_id").
+		Where("stockMovements.variant_id <=> inventory.variant_id")
+
+	var rows []struct {
+		ID             string
+		LocationID     string
+		ProductID      string
+		VariantID      *string
+		Quantity       int
+		LedgerQuantity int
+	}
+	if err := db.Model(&entity.Inventory{}).
+		Select("inventory.id, inventory.location_id, inventory.product_id, inventory.variant_id, inventory.quantity, (?) AS ledger_quantity", ledger).
+		Where("inventory.quantity <> (?)", ledger).
+		Order("inventory.product_id").Scan(&rows).Error; err != nil {
+		logger.Error("Error reconciling stock: %v", err)
+		return *dto.Fail("Error reconciling stock")
+	}
+
+	discrepancies := make([]dto.StockDiscrepancyResponse, len(rows))
+	for i, row := range rows {
+		discrepancies[i] = dto.StockDiscrepancyResponse{
+			InventoryID:    row.ID,
+			LocationID:     row.LocationID,
+			ProductID:      row.ProductID,
+			VariantID:      row.VariantID,
+			Quantity:       row.Quantity,
+			LedgerQuantity: row.LedgerQuantity,
+			Difference:     row.Quantity - row.LedgerQuantity,
+		}
+	}
+
+	return *dto.SuccessCount(discrepancies, int64(len(discrepancies)))
+}
+
+// apply changes the quantity on hand of the movement's stock row and records
+// the movement. Rows are created as needed. A change that would leave less
+// on hand than is reserved fails with ErrInsufficientStock. Incoming units
+// with a cost update the row's moving average cost.
+func (s *stockMovementService) apply(tx *gorm.DB, movement *entity.StockMovement) error {
+	key := newStockKey(movement.ProductID, movement.VariantID)
+
+	var row entity.Inventory
+	err := stockRow(tx.Clauses(clause.Locking{Strength: "UPDATE"}), movement.LocationID, key).First(&row).Error
+	switch {
+	case err == gorm.ErrRecordNotFound:
+		if movement.Quantity < 0 {
+			return ErrInsufficientStock
+		}
+		row = entity.Inventory{
+			ID:         tools.NewUuid(),
+			LocationID: movement.LocationID,
+			ProductID:  movement.ProductID,
+			VariantID:  key.variantID(),
+			Quantity:   movement.Quantity,
+		}
+		if movement.UnitCost != nil {
+			row.AverageCost = *movement.UnitCost
+		}
+		if err := tx.Create(&row).Error; err != nil {
+			return err
+		}
+	case err != nil:
+		return err
+	default:
+		if row.Quantity+movement.Quantity < row.Reserved {
+			return ErrInsufficientStock
+		}
+		updates := map[string]interface{}{"quantity": gorm.Expr("quantity + ?", movement.Quantity)}
+		if movement.UnitCost != nil && movement.Quantity > 0 {
+			onHand := max(0, row.Quantity)
+			updates["average_cost"] = (row.AverageCost*float64(onHand) + *movement.UnitCost*float64(movement.Quantity)) /
+				float64(onHand+movement.Quantity)
+		}
+		if err := tx.Model(&row).Updates(updates).Error; err != nil {
+			return err
+		}
+	}
+
+	return s.record(tx, movement)
+}
+
+// record appends a movement to the ledger without touching stock levels.
+// Callers that changed the quantity on hand themselves use it directly.
+func (s *stockMovementService) record(tx *gorm.DB, movement *entity.StockMovement) error {
+	movement.ID = tools.NewUuid()
+	if movement.SKU == "" {
+		sku, err := stockSKU(tx, movement.ProductID, movement.VariantID)
+		if err != nil {
+			return err
+		}
+		movement.SKU = sku
+	}
+	return tx.Create(movement).Error
+}
+
+// stockSKU returns the SKU of a variant, falling back to the product's own
+// SKU for product-level stock or variants without one
+func stockSKU(db *gorm.DB, productID string, variantID *string) (string, error) {
+	if variantID != nil {
+		var variant entity.ProductVariant
+		if err := db.Select("sku").Where("id = ?", *variantID).Limit(1).Find(&variant).Error; err != nil {
+			return "", err
+		}
+		if variant.SKU != "" {
+			return variant.SKU, nil
+		}
+	}
+	var product entity.Product
+	if err := db.Select("sku").Where("id = ?", productID).Limit(1).Find(&product).Error; err != nil {
+		return "", err
+	}
+	return product.SKU, nil
+}
@@ -0,0 +1,171 @@
+package service
+
+import (
+	"strings"
+
+	"gorm.io/gorm"
+
+	"backend-ecommerce/internal/application/dto"
+	"backend-ecommerce/internal/application/entity"
+	"backend-ecommerce/internal/application/tools"
+	"backend-ecommerce/internal/infrastructure/dbmanager"
+	"backend-ecommerce/internal/infrastructure/logger"
+)
+
+type supplierService struct {
+}
+
+// GetSuppliers returns every supplier by name
+func (s *supplierService) GetSuppliers() dto.ResponseDto {
+	db := dbmanager.GetDB()
+
+	var suppliers []entity.Supplier
+	if err := db.Order("name ASC").Find(&suppliers).Error; err != nil {
+		logger.Error("Error fetching suppliers: %v", err)
+		return *dto.Fail("Error fetching suppliers")
+	}
+
+	supplierDtos := make([]dto.SupplierResponse, len(suppliers))
+	for i, supplier := range suppliers {
+		supplierDtos[i] = dto.GetSupplierResponse(supplier)
+	}
+
+	return *dto.SuccessCount(supplierDtos, int64(len(supplierDtos)))
+}
+
+// CreateSupplier creates a supplier
+func (s *supplierService) CreateSupplier(req dto.SupplierCreateRequest) dto.ResponseDto {
+	db := dbmanager.GetDB()
+
+	supplier := entity.Supplier{
+		ID:           tools.NewUuid(),
+		Name:         strings.TrimSpace(req.Name),
+		ContactName:  req.ContactName,
+		Email:        req.Email,
+		Phone:        req.Phone,
+		Street:       req.Street,
+		City:         req.City,
+		State:        req.State,
+		PostalCode:   req.PostalCode,
+		Country:      req.Country,
+		Currency:     "USD",
+		LeadTimeDays: req.LeadTimeDays,
+		IsActive:     true,
+		Notes:        req.Notes,
+	}
+	if req.Currency != "" {
+		supplier.Currency = strings.ToUpper(req.Currency)
+	}
+
+	var count int64
+	if err := db.Model(&entity.Supplier{}).Where("name = ?", supplier.Name).Count(&count).Error; err != nil {
+		logger.Error("Error checking supplier name: %v", err)
+		return *dto.Fail("Error creating supplier")
+	}
+	if count > 0 {
+		return *dto.Fail("A supplier with this name already exists")
+	}
+
+	if err := db.Create(&supplier).Error; err != nil {
+		logger.Error("Error creating supplier: %v", err)
+		return *dto.Fail("Error creating supplier")
+	}
+
+	return *dto.Success(dto.GetSupplierResponse(supplier))
+}
+
+// UpdateSupplier updates a supplier
+func (s *supplierService) UpdateSupplier(id string, req dto.SupplierUpdateRequest) dto.ResponseDto {
+	db := dbmanager.GetDB()
+
+	var supplier entity.Supplier
+	if err := db.Where("id = ?", id).First(&supplier).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return *dto.Fail("Supplier not found")
+		}
+		logger.Error("Error fetching supplier: %v", err)
+		return *dto.Fail("Error fetching supplier")
+	}
+
+	if req.Name != nil && strings.TrimSpace(*req.Name) != supplier.Name {
+		name := strings.TrimSpace(*req.Name)
+		var count int64
+		if err := db.Model(&entity.Supplier{}).Where("name = ? AND id <> ?", name, id).Count(&count).Error; err != nil {
+			logger.Error("Error checking supplier name: %v", err)
+			return *dto.Fail("Error updating supplier")
+		}
+		if count > 0 {
+			return *dto.Fail("A supplier with this name already exists")
+		}
+		supplier.Name = name
+	}
+	if req.ContactName != nil {
+		supplier.ContactName = *req.ContactName
+	}
+	if req.Email != nil {
+		supplier.Email = *req.Email
+	}
+	if req.Phone != nil {
+		supplier.Phone = *req.Phone
+	}
+	if req.Street != nil {
+		supplier.Street = *req.Street
+	}
+	if req.City != nil {
+		supplier.City = *req.City
+	}
+	if req.State != nil {
+		supplier.State = *req.State
+	}
+	if req.PostalCode != nil {
+		supplier.PostalCode = *req.PostalCode
+	}
+	if req.Country != nil {
+		supplier.Country = *req.Country
+	}
+	if req.Currency != nil {
+		supplier.Currency = strings.ToUpper(*req.Currency)
+	}
+	if req.LeadTimeDays != nil {
+		supplier.LeadTimeDays = *req.LeadTimeDays
+	}
+	if req.IsActive != nil {
+		supplier.IsActive = *req.IsActive
+	}
+	if req.Notes != nil {
+		supplier.Notes = *req.Notes
+	}
+
+	if err := db.Save(&supplier).Error; err != nil {
+		logger.Error("Error updating supplier: %v", err)
+		return *dto.Fail("Error updating supplier")
+	}
+
+	return *dto.Success(dto.GetSupplierResponse(supplier))
+}
+
+// DeleteSupplier removes a supplier that has no purchase orders. Suppliers
+// with order history can be deactivated instead.
+func (s *supplierService) DeleteSupplier(id string) dto.ResponseDto {
+	db := dbmanager.GetDB()
+
+	var count int64
+	if err := db.Model(&entity.PurchaseOrder{}).Where("supplier_id = ?", id).Count(&count).Error; err != nil {
+		logger.Error("Error checking supplier purchase orders: %v", err)
+		return *dto.Fail("Error deleting supplier")
+	}
+	if count > 0 {
+		return *dto.Fail("The supplier has purchase orders; deactivate it instead")
+	}
+
+	result := db.Where("id = ?", id).Delete(&entity.Supplier{})
+	if result.Error != nil {
+		logger.Error("Error deleting supplier: %v", result.Error)
+		return *dto.Fail("Error deleting supplier")
+	}
+	if result.RowsAffected == 0 {
+		return *dto.Fail("Supplier not found")
+	}
+
+	return *dto.Success("Supplier deleted successfully")
+}
@@ -10,10 +10,17 @@ import (
 	"backend-ecommerce/internal/application/dto"
 	"backend-ecommerce/internal/application/entity"
 	"backend-ecommerce/internal/application/tools"
+	"backend-ecommerce/internal/infrastructure/config"
 	"backend-ecommerce/internal/infrastructure/dbmanager"
 	"backend-ecommerce/internal/infrastructure/logger"
 )
 
+const (
+	marketingUnsubscribeAudience = "marketing-unsubscribe"
+	// marketingUnsubscribeTTL is how long unsubscribe links in marketing emails work
+	marketingUnsubscribeTTL = 365 * 24 * time.Hour
+)
+
 type userService struct {
 }
 
@@ -212,3 +219,70 @@ func (s *userService) SoftDeleteUser(id string) dto.ResponseDto {
 	
 	return *dto.Success("User soft deleted successfully")
 }
+
+// MergeGuestData moves what a shopper saved as a guest, such as their cart,
+// recently viewed products and wishlists, into their account. Signing in
+// does not call it: clients must, after every sign-in, sending the guest
+// token they used before. Nothing is left to merge on a second call.
+func (s *userService) MergeGuestData(userID, guestToken string) dto.ResponseDto {
+	if guestToken == "" {
+		return *dto.Success("Nothing to merge")
+	}
+	db := dbmanager.GetDB()
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := ICartService.mergeGuest(tx, userID, guestToken); err != nil {
+			return err
+		}
+		if err := IRecentlyViewedService.mergeGuest(tx, userID, guestToken); err != nil {
+			return err
+		}
+		return IWishlistService.mergeGuest(tx, userID, guestToken)
+	})
+	if err != nil {
+		logger.Error("Error merging guest data into user %s: %v", userID, err)
+		return *dto.Fail("Error merging guest data")
+	}
+
+	return *dto.Success("Guest data merged successfully")
+}
+
+// Unsubscribe opts a user out of marketing emails through the signed link
+// in one of them, so it works without signing in. Reminders already queued
+// are dropped.
+func (s *userService) Unsubscribe(req dto.MarketingUnsubscribeRequest) dto.ResponseDto {
+	if err := config.JWT.VerifyScoped(req.Token, req.UserID, marketingUnsubscribeAudience); err != nil {
+		return *dto.Fail("Invalid or expired unsubscribe link")
+	}
+	return s.setMarketingOptOut(req.UserID, true)
+}
+
+// UpdateMarketingPreferences lets a signed-in user opt in to or out of
+// marketing emails
+func (s *userService) UpdateMarketingPreferences(userID string, req dto.MarketingPreferencesRequest) dto.ResponseDto {
+	return s.setMarketingOptOut(userID, !*req.Subscribed)
+}
+
+func (s *userService) setMarketingOptOut(userID string, optOut bool) dto.ResponseDto {
+	db := dbmanager.GetDB()
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if !optOut {
+			return tx.Model(&entity.User{}).Where("id = ?", userID).Update("marketing_opt_out_at", nil).Error
+		}
+		if err := tx.Model(&entity.User{}).Where("id = ? AND marketing_opt_out_at IS NULL", userID).
+			Update("marketing_opt_out_at", time.Now().UTC()).Error; err != nil {
+			return err
+		}
+		return cancelPendingReminders(tx, userID)
+	})
+	if err != nil {
+		logger.Error("Error updating marketing preferences of user %s: %v", userID, err)
+		return *dto.Fail("Error updating marketing preferences")
+	}
+
+	if optOut {
+		return *dto.Success("You have been unsubscribed from marketing emails")
+	}
+	return *dto.Success("You are subscribed to marketing emails")
+}
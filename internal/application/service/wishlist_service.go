@@ -0,0 +1,385 @@
+package service
+
+import (
+	"errors"
+
+	"gorm.io/gorm"
+
+	"backend-ecommerce/internal/application/dto"
+	"backend-ecommerce/internal/application/entity"
+	"backend-ecommerce/internal/application/tools"
+	"backend-ecommerce/internal/infrastructure/dbmanager"
+	"backend-ecommerce/internal/infrastructure/logger"
+)
+
+// maxWishlists caps how many wishlists a shopper can keep
+const maxWishlists = 20
+
+type wishlistService struct {
+}
+
+// GetWishlists returns the wishlists of a shopper with their items
+func (s *wishlistService) GetWishlists(userID, guestToken string) dto.ResponseDto {
+	db := dbmanager.GetDB()
+
+	var wishlists []entity.Wishlist
+	if err := db.Scopes(shopperScope(userID, guestToken)).
+		Preload("Items", func(db *gorm.DB) *gorm.DB { return db.Order("created_at DESC") }).
+		Order("created_at ASC").Find(&wishlists).Error; err != nil {
+		logger.Error("Error fetching wishlists: %v", err)
+		return *dto.Fail("Error fetching wishlists")
+	}
+
+	wishlistDtos, err := s.toWishlistResponses(db, wishlists, true)
+	if err != nil {
+		logger.Error("Error fetching wishlist products: %v", err)
+		return *dto.Fail("Error fetching wishlists")
+	}
+
+	return *dto.SuccessCount(wishlistDtos, int64(len(wishlistDtos)))
+}
+
+// CreateWishlist creates an empty wishlist
+func (s *wishlistService) CreateWishlist(userID, guestToken string, req dto.WishlistCreateRequest) dto.ResponseDto {
+	db := dbmanager.GetDB()
+
+	var count int64
+	if err := db.Model(&entity.Wishlist{}).Scopes(shopperScope(userID, guestToken)).Count(&count).Error; err != nil {
+		logger.Error("Error counting wishlists: %v", err)
+		return *dto.Fail("Error creating wishlist")
+	}
+	if count >= maxWishlists {
+		return *dto.Fail("You have reached the maximum number of wishlists")
+	}
+
+	wishlist := entity.Wishlist{ID: tools.NewUuid(), Name: req.Name}
+	if userID != "" {
+		wishlist.UserID = &userID
+	} else {
+		wishlist.GuestToken = guestToken
+	}
+	if err := db.Create(&wishlist).Error; err != nil {
+		logger.Error("Error creating wishlist: %v", err)
+		return *dto.Fail("Error creating wishlist")
+	}
+
+	return *dto.Success(dto.GetWishlistResponse(wishlist, nil, true))
+}
+
+// RenameWishlist changes the name of a wishlist
+func (s *wishlistService) RenameWishlist(userID, guestToken, id string, req dto.WishlistUpdateRequest) dto.ResponseDto {
+	db := dbmanager.GetDB()
+
+	wishlist, response := s.findWishlist(db, userID, guestToken, id)
+	if wishlist == nil {
+		return response
+	}
+
+	if err := db.Model(wishlist).Update("name", req.Name).Error; err != nil {
+		logger.Error("Error updating wishlist: %v", err)
+		return *dto.Fail("Error updating wishlist")
+	}
+
+	return s.getWishlist(db, userID, guestToken, id)
+}
+
+// DeleteWishlist removes a wishlist and its items
+func (s *wishlistService) DeleteWishlist(userID, guestToken, id string) dto.ResponseDto {
+	db := dbmanager.GetDB()
+
+	wishlist, response := s.findWishlist(db, userID, guestToken, id)
+	if wishlist == nil {
+		return response
+	}
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("wishlist_id = ?", id).Delete(&entity.WishlistItem{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(wishlist).Error
+	})
+	if err != nil {
+		logger.Error("Error deleting wishlist: %v", err)
+		return *dto.Fail("Error deleting wishlist")
+	}
+
+	return *dto.Success("Wishlist deleted successfully")
+}
+
+// AddWishlistItem saves a published product to a wishlist. Saving a product
+// that is already on the list updates its note.
+func (s *wishlistService) AddWishlistItem(userID, guestToken, id string, req dto.WishlistItemAddRequest) dto.ResponseDto {
+	db := dbmanager.GetDB()
+
+	wishlist, response := s.findWishlist(db, userID, guestToken, id)
+	if wishlist == nil {
+		return response
+	}
+
+	if err := publishedProducts(db.Select("id")).Where("id = ?", req.ProductID).First(&entity.Product{}).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return *dto.Fail("Product not found")
+		}
+		logger.Error("Error fetching product: %v", err)
+		return *dto.Fail("Error fetching product")
+	}
+
+	var item entity.WishlistItem
+	err := db.Where("wishlist_id = ? AND product_id = ?", id, req.ProductID).First(&item).Error
+	switch {
+	case err == nil:
+		err = db.Model(&item).Update("note", req.Note).Error
+	case err == gorm.ErrRecordNotFound:
+		item = entity.WishlistItem{
+			ID:         tools.NewUuid(),
+			WishlistID: id,
+			ProductID:  req.ProductID,
+			Note:       req.Note,
+		}
+		err = db.Create(&item).Error
+	}
+	if err != nil {
+		logger.Error("Error adding wishlist item: %v", err)
+		return *dto.Fail("Error adding item to wishlist")
+	}
+
+	return s.getWishlist(db, userID, guestToken, id)
+}
+
+// RemoveWishlistItem removes an item from a wishlist
+func (s *wishlistService) RemoveWishlistItem(userID, guestToken, id, itemID string) dto.ResponseDto {
+	db := dbmanager.GetDB()
+
+	wishlist, response := s.findWishlist(db, userID, guestToken, id)
+	if wishlist == nil {
+		return response
+	}
+
+	result := db.Where("id = ? AND wishlist_id = ?", itemID, id).Delete(&entity.WishlistItem{})
+	if result.Error != nil {
+		logger.Error("Error removing wishlist item: %v", result.Error)
+		return *dto.Fail("Error removing item from wishlist")
+	}
+	if result.RowsAffected == 0 {
+		return *dto.Fail("Wishlist item not found")
+	}
+
+	return s.getWishlist(db, userID, guestToken, id)
+}
+
+// MoveItemToCart adds a wishlist item to the shopper's cart at the current
+// price and removes it from the wishlist
+func (s *wishlistService) MoveItemToCart(userID, guestToken, id, itemID string, quantity int) dto.ResponseDto {
+	if quantity < 1 {
+		quantity = 1
+	}
+	db := dbmanager.GetDB()
+
+	wishlist, response := s.findWishlist(db, userID, guestToken, id)
+	if wishlist == nil {
+		return response
+	}
+
+	var item entity.WishlistItem
+	if err := db.Where("id = ? AND wishlist_id = ?", itemID, id).First(&item).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return *dto.Fail("Wishlist item not found")
+		}
+		logger.Error("Error fetching wishlist item: %v", err)
+		return *dto.Fail("Error moving item to cart")
+	}
+
+	var product entity.Product
+	if err := publishedProducts(db).Where("id = ?", item.ProductID).First(&product).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return *dto.Fail("This product is no longer available")
+		}
+		logger.Error("Error fetching product: %v", err)
+		return *dto.Fail("Error moving item to cart")
+	}
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		cart, err := ICartService.findOrCreateCart(tx, userID, guestToken)
+		if err != nil {
+			return err
+		}
+		if err := ICartService.addItem(tx, cart, product.ID, nil, quantity); err != nil {
+			return err
+		}
+		return tx.Delete(&item).Error
+	})
+	if err != nil {
+		var failure errCartFailure
+		if errors.As(err, &failure) {
+			return *dto.Fail(failure.message)
+		}
+		logger.Error("Error moving wishlist item to cart: %v", err)
+		return *dto.Fail("Error moving item to cart")
+	}
+
+	return s.getWishlist(db, userID, guestToken, id)
+}
+
+// ShareWishlist creates a public link to a wishlist. Sharing again issues a
+// new link and disables the old one.
+func (s *wishlistService) ShareWishlist(userID, guestToken, id string) dto.ResponseDto {
+	db := dbmanager.GetDB()
+
+	wishlist, response := s.findWishlist(db, userID, guestToken, id)
+	if wishlist == nil {
+		return response
+	}
+
+	if err := db.Model(wishlist).Update("share_token", tools.NewSecureToken()).Error; err != nil {
+		logger.Error("Error sharing wishlist: %v", err)
+		return *dto.Fail("Error sharing wishlist")
+	}
+
+	return s.getWishlist(db, userID, guestToken, id)
+}
+
+// UnshareWishlist disables the public link to a wishlist
+func (s *wishlistService) UnshareWishlist(userID, guestToken, id string) dto.ResponseDto {
+	db := dbmanager.GetDB()
+
+	wishlist, response := s.findWishlist(db, userID, guestToken, id)
+	if wishlist == nil {
+		return response
+	}
+
+	if err := db.Model(wishlist).Update("share_token", nil).Error; err != nil {
+		logger.Error("Error unsharing wishlist: %v", err)
+		return *dto.Fail("Error unsharing wishlist")
+	}
+
+	return s.getWishlist(db, userID, guestToken, id)
+}
+
+// GetSharedWishlist returns a wishlist by its public link
+func (s *wishlistService) GetSharedWishlist(shareToken string) dto.ResponseDto {
+	db := dbmanager.GetDB()
+
+	var wishlist entity.Wishlist
+	if err := db.Preload("Items", func(db *gorm.DB) *gorm.DB { return db.Order("created_at DESC") }).
+		Where("share_token = ?", shareToken).First(&wishlist).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return *dto.Fail("Wishlist not found")
+		}
+		logger.Error("Error fetching shared wishlist: %v", err)
+		return *dto.Fail("Error fetching wishlist")
+	}
+
+	wishlistDtos, err := s.toWishlistResponses(db, []entity.Wishlist{wishlist}, false)
+	if err != nil {
+		logger.Error("Error fetching wishlist products: %v", err)
+		return *dto.Fail("Error fetching wishlist")
+	}
+
+	return *dto.Success(wishlistDtos[0])
+}
+
+// mergeGuest moves a guest's wishlists into a user's account. A guest list
+// with the same name as one of the user's is folded into it.
+func (s *wishlistService) mergeGuest(tx *gorm.DB, userID, guestToken string) error {
+	var guestLists []entity.Wishlist
+	if err := tx.Scopes(shopperScope("", guestToken)).Preload("Items").Find(&guestLists).Error; err != nil {
+		return err
+	}
+	if len(guestLists) == 0 {
+		return nil
+	}
+
+	var userLists []entity.Wishlist
+	if err := tx.Scopes(shopperScope(userID, "")).Preload("Items").Find(&userLists).Error; err != nil {
+		return err
+	}
+	byName := make(map[string]entity.Wishlist, len(userLists))
+	for _, list := range userLists {
+		byName[list.Name] = list
+	}
+
+	for _, guestList := range guestLists {
+		userList, ok := byName[guestList.Name]
+		if !ok {
+			if err := tx.Model(&guestList).Updates(map[string]interface{}{"user_id": userID, "guest_token": ""}).Error; err != nil {
+				return err
+			}
+			continue
+		}
+
+		saved := make(map[string]bool, len(userList.Items))
+		for _, item := range userList.Items {
+			saved[item.ProductID] = true
+		}
+		for _, item := range guestList.Items {
+			if saved[item.ProductID] {
+				if err := tx.Delete(&item).Error; err != nil {
+					return err
+				}
+				continue
+			}
+			if err := tx.Model(&item).Update("wishlist_id", userList.ID).Error; err != nil {
+				return err
+			}
+		}
+		if err := tx.Delete(&guestList).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// findWishlist loads a wishlist owned by the shopper. On failure it returns
+// nil and the response to send.
+func (s *wishlistService) findWishlist(db *gorm.DB, userID, guestToken, id string) (*entity.Wishlist, dto.ResponseDto) {
+	var wishlist entity.Wishlist
+	if err := db.Scopes(shopperScope(userID, guestToken)).Where("id = ?", id).First(&wishlist).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, *dto.Fail("Wishlist not found")
+		}
+		logger.Error("Error fetching wishlist: %v", err)
+		return nil, *dto.Fail("Error fetching wishlist")
+	}
+	return &wishlist, dto.ResponseDto{}
+}
+
+func (s *wishlistService) getWishlist(db *gorm.DB, userID, guestToken, id string) dto.ResponseDto {
+	var wishlist entity.Wishlist
+	if err := db.Scopes(shopperScope(userID, guestToken)).
+		Preload("Items", func(db *gorm.DB) *gorm.DB { return db.Order("created_at DESC") }).
+		Where("id = ?", id).First(&wishlist).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return *dto.Fail("Wishlist not found")
+		}
+		logger.Error("Error fetching wishlist: %v", err)
+		return *dto.Fail("Error fetching wishlist")
+	}
+
+	wishlistDtos, err := s.toWishlistResponses(db, []entity.Wishlist{wishlist}, true)
+	if err != nil {
+		logger.Error("Error fetching wishlist products: %v", err)
+		return *dto.Fail("Error fetching wishlist")
+	}
+
+	return *dto.Success(wishlistDtos[0])
+}
+
+// toWishlistResponses converts wishlists to DTOs with their published products priced now
+func (s *wishlistService) toWishlistResponses(db *gorm.DB, wishlists []entity.Wishlist, forOwner bool) ([]dto.WishlistResponse, error) {
+	var ids []string
+	for _, wishlist := range wishlists {
+		for _, item := range wishlist.Items {
+			ids = append(ids, item.ProductID)
+		}
+	}
+	products, err := IProductService.publishedProductResponses(db, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	wishlistDtos := make([]dto.WishlistResponse, len(wishlists))
+	for i, wishlist := range wishlists {
+		wishlistDtos[i] = dto.GetWishlistResponse(wishlist, products, forOwner)
+	}
+	return wishlistDtos, nil
+}
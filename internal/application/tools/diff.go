@@ -0,0 +1,160 @@
+package tools
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+)
+
+// Change is a single difference between two JSON documents. From is nil for
+// added values and To is nil for removed ones.
+type Change struct {
+	Path string      `json:"path"`
+	From interface{} `json:"from"`
+	To   interface{} `json:"to"`
+}
+
+// DiffJSON compares the JSON encodings of from and to and returns every
+// changed leaf value. Arrays of objects that carry an "id" are matched by id
+// rather than by position, so reordering or inserting items only reports the
+// fields that actually changed. Object keys listed in ignore are skipped at
+// any depth.
+func DiffJSON(from, to interface{}, ignore ...string) ([]Change, error) {
+	a, err := toGeneric(from)
+	if err != nil {
+		return nil, err
+	}
+	b, err := toGeneric(to)
+	if err != nil {
+		return nil, err
+	}
+
+	ignored := make(map[string]bool, len(ignore))
+	for _, key := range ignore {
+		ignored[key] = true
+	}
+
+	changes := []Change{}
+	diffValues("", a, b, ignored, &changes)
+	return changes, nil
+}
+
+func toGeneric(v interface{}) (interface{}, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var generic interface{}
+	if err := json.Unmarshal(data, &generic); err != nil {
+		return nil, err
+	}
+	return generic, nil
+}
+
+func diffValues(path string, a, b interface{}, ignored map[string]bool, changes *[]Change) {
+	switch av := a.(type) {
+	case map[string]interface{}:
+		if bv, ok := b.(map[string]interface{}); ok {
+			diffObjects(path, av, bv, ignored, changes)
+			return
+		}
+	case []interface{}:
+		if bv, ok := b.([]interface{}); ok {
+			diffArrays(path, av, bv, ignored, changes)
+			return
+		}
+	}
+
+	if !reflect.DeepEqual(a, b) {
+		*changes = append(*changes, Change{Path: path, From: a, To: b})
+	}
+}
+
+func diffObjects(path string, a, b map[string]interface{}, ignored map[string]bool, changes *[]Change) {
+	keys := make([]string, 0, len(a)+len(b))
+	for key := range a {
+		keys = append(keys, key)
+	}
+	for key := range b {
+		if _, ok := a[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		if ignored[key] {
+			continue
+		}
+		diffValues(joinPath(path, key), a[key], b[key], ignored, changes)
+	}
+}
+
+func diffArrays(path string, a, b []interface{}, ignored map[string]bool, changes *[]Change) {
+	aByID, aOK := indexByID(a)
+	bByID, bOK := indexByID(b)
+	if !aOK || !bOK {
+		for i := 0; i < max(len(a), len(b)); i++ {
+			var av, bv interface{}
+			if i < len(a) {
+				av = a[i]
+			}
+			if i < len(b) {
+				bv = b[i]
+			}
+			diffValues(fmt.Sprintf("%s[%d]", path, i), av, bv, ignored, changes)
+		}
+		return
+	}
+
+	var ids []string
+	for _, item := range a {
+		ids = append(ids, item.(map[string]interface{})["id"].(string))
+	}
+	for _, item := range b {
+		id := item.(map[string]interface{})["id"].(string)
+		if _, ok := aByID[id]; !ok {
+			ids = append(ids, id)
+		}
+	}
+
+	for _, id := range ids {
+		itemPath := fmt.Sprintf("%s[%s]", path, id)
+		av, inA := aByID[id]
+		bv, inB := bByID[id]
+		switch {
+		case !inA:
+			*changes = append(*changes, Change{Path: itemPath, To: bv})
+		case !inB:
+			*changes = append(*changes, Change{Path: itemPath, From: av})
+		default:
+			diffValues(itemPath, av, bv, ignored, changes)
+		}
+	}
+}
+
+// indexByID maps each element by its "id" field, reporting false when any
+// element is not an object with a string id
+func indexByID(items []interface{}) (map[string]interface{}, bool) {
+	byID := make(map[string]interface{}, len(items))
+	for _, item := range items {
+		object, ok := item.(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+		id, ok := object["id"].(string)
+		if !ok {
+			return nil, false
+		}
+		byID[id] = object
+	}
+	return byID, true
+}
+
+func joinPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
@@ -1,7 +1,10 @@
 package tools
 
 import (
+	crand "crypto/rand"
+	"encoding/hex"
 	"fmt"
+	"math"
 	"math/rand"
 	"regexp"
 	"time"
@@ -15,6 +18,16 @@ func NewUuid() string {
 	return id.String()
 }
 
+// NewSecureToken generates a random, URL-safe token suitable for links that grant access
+func NewSecureToken() string {
+	b := make([]byte, 24)
+	if _, err := crand.Read(b); err != nil {
+		// Fall back to a UUID, which is also generated from a secure source
+		return NewUuid()
+	}
+	return hex.EncodeToString(b)
+}
+
 // IsValidEmail checks if the email has a valid format
 func IsValidEmail(email string) bool {
 	emailRegex := regexp.MustCompile(`^[a-zA-Z0-9._%+\-]+@[a-zA-Z0-9.\-]+\.[a-zA-Z]{2,}$`)
@@ -25,3 +38,27 @@ func IsValidEmail(email string) bool {
 func CreateCode() string {
 	return fmt.Sprintf("%04v", rand.New(rand.NewSource(time.Now().UnixNano())).Int31n(10000))
 }
+
+// codeAlphabet leaves out characters that are easily confused, such as 0 and O
+const codeAlphabet = "ABCDEFGHJKLMNPQRSTUVWXYZ23456789"
+
+// NewRandomCode generates a random code of length characters, suitable for
+// codes customers type in, such as discount codes
+func NewRandomCode(length int) string {
+	b := make([]byte, length)
+	if _, err := crand.Read(b); err != nil {
+		// Fall back to UUIDs, which are also generated from a secure source
+		for i := 0; i < length; i += 16 {
+			copy(b[i:], uuid.NewV4().Bytes())
+		}
+	}
+	for i := range b {
+		b[i] = codeAlphabet[int(b[i])%len(codeAlphabet)]
+	}
+	return string(b)
+}
+
+// RoundPrice rounds an amount to two decimal places (cents)
+func RoundPrice(amount float64) float64 {
+	return math.Round(amount*100) / 100
+}
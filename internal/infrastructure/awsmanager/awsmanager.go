@@ -58,6 +58,21 @@ func Init() error {
 	return nil
 }
 
+// IsInitialized reports whether the S3 client is configured and ready to use
+func IsInitialized() bool {
+	return s3Client != nil
+}
+
+// GetClient returns the shared S3 client, or nil when AWS is not configured
+func GetClient() *s3.Client {
+	return s3Client
+}
+
+// GetBucket returns the configured bucket name and region
+func GetBucket() (string, string) {
+	return bucketName, region
+}
+
 // UploadFileResult contains the result of a file upload
 type UploadFileResult struct {
 	URL      string
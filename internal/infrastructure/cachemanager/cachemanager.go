@@ -0,0 +1,199 @@
+package cachemanager
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"backend-ecommerce/internal/infrastructure/redismanager"
+)
+
+type entry struct {
+	value     string
+	expiresAt time.Time
+}
+
+var (
+	useRedis bool
+	mu       sync.RWMutex
+	local    = make(map[string]entry)
+)
+
+// sweepInterval is how often expired values are removed from the in-memory
+// cache, so keys that are never read again do not pile up
+const sweepInterval = time.Minute
+
+// Init connects the cache to Redis when it is configured and reachable,
+// otherwise values are cached in process memory and swept periodically.
+func Init() {
+	redismanager.Init()
+
+	if redismanager.Redis != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+		defer cancel()
+		if err := redismanager.Redis.Ping(ctx).Err(); err == nil {
+			useRedis = true
+			log.Println("cachemanager: using Redis")
+			return
+		}
+	}
+
+	log.Println("cachemanager: using in-memory cache")
+	go func() {
+		for range time.Tick(sweepInterval) {
+			sweep()
+		}
+	}()
+}
+
+// sweep removes the expired values from the in-memory cache
+func sweep() {
+	now := time.Now()
+	mu.Lock()
+	for key, e := range local {
+		if now.After(e.expiresAt) {
+			delete(local, key)
+		}
+	}
+	mu.Unlock()
+}
+
+// Get returns a cached value and whether it was found
+func Get(key string) (string, bool) {
+	if useRedis {
+		value, err := redismanager.Redis.Get(context.Background(), key).Result()
+		if err != nil {
+			return "", false
+		}
+		return value, true
+	}
+
+	mu.RLock()
+	e, ok := local[key]
+	mu.RUnlock()
+	if !ok {
+		return "", false
+	}
+	if time.Now().After(e.expiresAt) {
+		mu.Lock()
+		// The value may have been set again meanwhile
+		if e, ok := local[key]; ok && time.Now().After(e.expiresAt) {
+			delete(local, key)
+		}
+		mu.Unlock()
+		return "", false
+	}
+	return e.value, true
+}
+
+// Set caches a value for ttl
+func Set(key, value string, ttl time.Duration) {
+	if useRedis {
+		if err := redismanager.Redis.Set(context.Background(), key, value, ttl).Err(); err != nil {
+			log.Printf("cachemanager: failed to set %s: %v", key, err)
+		}
+		return
+	}
+
+	mu.Lock()
+	local[key] = entry{value: value, expiresAt: time.Now().Add(ttl)}
+	mu.Unlock()
+}
+
+// Incr increments a counter and returns its new value. The counter expires
+// ttl after its first increment, so it counts events within a fixed window.
+func Incr(key string, ttl time.Duration) int64 {
+	if useRedis {
+		ctx := context.Background()
+		count, err := redismanager.Redis.Incr(ctx, key).Result()
+		if err != nil {
+			log.Printf("cachemanager: failed to increment %s: %v", key, err)
+			return 0
+		}
+		if count == 1 {
+			if err := redismanager.Redis.Expire(ctx, key, ttl).Err(); err != nil {
+				log.Printf("cachemanager: failed to set expiry of %s: %v", key, err)
+			}
+		}
+		return count
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	e, ok := local[key]
+	if !ok || time.Now().After(e.expiresAt) {
+		e = entry{value: "0", expiresAt: time.Now().Add(ttl)}
+	}
+	count, _ := strconv.ParseInt(e.value, 10, 64)
+	count++
+	e.value = strconv.FormatInt(count, 10)
+	local[key] = e
+	return count
+}
+
+// GetJSON decodes a cached JSON value into dest and reports whether it was found
+func GetJSON(key string, dest interface{}) bool {
+	value, ok := Get(key)
+	if !ok {
+		return false
+	}
+	return json.Unmarshal([]byte(value), dest) == nil
+}
+
+// SetJSON caches value encoded as JSON for ttl
+func SetJSON(key string, value interface{}, ttl time.Duration) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		log.Printf("cachemanager: failed to encode %s: %v", key, err)
+		return
+	}
+	Set(key, string(data), ttl)
+}
+
+// Delete removes cached values
+func Delete(keys ...string) {
+	if len(keys) == 0 {
+		return
+	}
+	if useRedis {
+		if err := redismanager.Redis.Del(context.Background(), keys...).Err(); err != nil {
+			log.Printf("cachemanager: failed to delete keys: %v", err)
+		}
+		return
+	}
+
+	mu.Lock()
+	for _, key := range keys {
+		delete(local, key)
+	}
+	mu.Unlock()
+}
+
+// DeletePrefix removes every cached value whose key starts with prefix
+func DeletePrefix(prefix string) {
+	if useRedis {
+		ctx := context.Background()
+		iter := redismanager.Redis.Scan(ctx, 0, prefix+"*", 100).Iterator()
+		var keys []string
+		for iter.Next(ctx) {
+			keys = append(keys, iter.Val())
+		}
+		if err := iter.Err(); err != nil {
+			log.Printf("cachemanager: failed to scan %s*: %v", prefix, err)
+		}
+		Delete(keys...)
+		return
+	}
+
+	mu.Lock()
+	for key := range local {
+		if strings.HasPrefix(key, prefix) {
+			delete(local, key)
+		}
+	}
+	mu.Unlock()
+}
@@ -49,7 +49,86 @@ type AppConfig struct {
 	CronJob struct {
 		CleanupInterval string
 		EmailReport     string
+		PriceWindows    string
+		Publishing      string
+		Collections     string
+		Recommendations string
+		Alerts          string
+		Notifications   string
+		Reservations    string
+		LowStock        string
+		AbandonedCarts  string
 	}
+	// Recommendations tunes the nightly "frequently bought together" computation
+	Recommendations struct {
+		MinSupport    float64 `mapstructure:"min_support"`    // share of orders that must contain both products
+		MinConfidence float64 `mapstructure:"min_confidence"` // share of orders with a product that must contain the other
+		MinOrders     int     `mapstructure:"min_orders"`     // orders that must contain both products
+		MaxPerProduct int     `mapstructure:"max_per_product"`
+		LookbackDays  int     `mapstructure:"lookback_days"`
+	} `mapstructure:"recommendations"`
+	Inventory struct {
+		// ReservationTTL is how long stock stays reserved for an unpaid order
+		ReservationTTL time.Duration `mapstructure:"reservation_ttl"`
+		// Allocation picks the stock locations of an order: priority or proximity
+		Allocation string `mapstructure:"allocation"`
+		// VelocityDays is how many days of sales the days of cover estimate is based on
+		VelocityDays int `mapstructure:"velocity_days"`
+	} `mapstructure:"inventory"`
+	Cart struct {
+		// Backend stores carts: sql, or redis for high traffic
+		Backend string `mapstructure:"backend"`
+		// GuestSecret signs guest tokens; a random secret is generated when empty
+		GuestSecret string `mapstructure:"guest_secret"`
+		// GuestTTL is how long the guest token cookie lives
+		GuestTTL time.Duration `mapstructure:"guest_ttl"`
+		// MaxQuantity caps the quantity of a single cart line
+		MaxQuantity int `mapstructure:"max_quantity"`
+		// RecoveryWindows are how long a user's cart must sit unchanged before
+		// each abandoned cart reminder, e.g. ["1h", "24h", "72h"]
+		RecoveryWindows []time.Duration `mapstructure:"recovery_windows"`
+		// RecoveryTTL is how long the restore link in a reminder works; an order
+		// placed within it counts as recovered
+		RecoveryTTL time.Duration `mapstructure:"recovery_ttl"`
+		// RecoveryURL is the storefront page restore links point to
+		RecoveryURL string `mapstructure:"recovery_url"`
+		// ShareTTL is how long a shared cart link works
+		ShareTTL time.Duration `mapstructure:"share_ttl"`
+		// ShareURL is the storefront page shared cart links point to
+		ShareURL string `mapstructure:"share_url"`
+	} `mapstructure:"cart"`
+	// Pricing configures the shipping and tax steps of cart and order pricing
+	Pricing struct {
+		Currency string `mapstructure:"currency"`
+		// ShippingRate is charged once per order containing goods that ship
+		ShippingRate float64 `mapstructure:"shipping_rate"`
+		// FreeShippingThreshold waives shipping from this discounted subtotal; 0 disables it
+		FreeShippingThreshold float64 `mapstructure:"free_shipping_threshold"`
+		// TaxRates holds tax rates in percent keyed by country code or "country-state", e.g. "us-ca"
+		TaxRates       map[string]float64 `mapstructure:"tax_rates"`
+		DefaultTaxRate float64            `mapstructure:"default_tax_rate"`
+		TaxShipping    bool               `mapstructure:"tax_shipping"`
+	} `mapstructure:"pricing"`
+	// GiftCard throttles balance checks, so codes cannot be guessed by trying them
+	GiftCard struct {
+		// BalanceChecksPerMinute caps the balance checks of a client IP
+		BalanceChecksPerMinute int `mapstructure:"balance_checks_per_minute"`
+		// MaxFailedChecks blocks a client IP for FailedCheckWindow once it has
+		// tried that many unknown codes
+		MaxFailedChecks   int           `mapstructure:"max_failed_checks"`
+		FailedCheckWindow time.Duration `mapstructure:"failed_check_window"`
+	} `mapstructure:"gift_card"`
+	Notification struct {
+		Backend          string `mapstructure:"backend"` // log, smtp or memory
+		From             string `mapstructure:"from"`
+		SMTPHost         string `mapstructure:"smtp_host"`
+		SMTPPort         int    `mapstructure:"smtp_port"`
+		SMTPUsername     string `mapstructure:"smtp_username"`
+		SMTPPassword     string `mapstructure:"smtp_password"`
+		MaxPerUserPerDay int    `mapstructure:"max_per_user_per_day"`
+		// UnsubscribeURL is the storefront page unsubscribe links in marketing emails point to
+		UnsubscribeURL string `mapstructure:"unsubscribe_url"`
+	} `mapstructure:"notification"`
 	Log struct {
 		Level string
 		File  string
@@ -60,6 +139,20 @@ type AppConfig struct {
 		Region          string `mapstructure:"region"`
 		S3Bucket        string `mapstructure:"s3_bucket"`
 	} `mapstructure:"aws"`
+	Storage struct {
+		Backend    string `mapstructure:"backend"` // local, s3 or memory
+		LocalDir   string `mapstructure:"local_dir"`
+		PublicURL  string `mapstructure:"public_url"`
+		SignedURL  string `mapstructure:"signed_url"`
+		SigningKey string `mapstructure:"signing_key"`
+	} `mapstructure:"storage"`
+	// Image bounds uploaded images before they are decoded, so a small file
+	// declaring huge dimensions cannot exhaust memory
+	Image struct {
+		MaxWidth  int `mapstructure:"max_width"`
+		MaxHeight int `mapstructure:"max_height"`
+		MaxPixels int `mapstructure:"max_pixels"`
+	} `mapstructure:"image"`
 	AI struct {
 		OpenAI struct {
 			APIKey      string  `mapstructure:"api_key"`
@@ -104,6 +197,97 @@ func Load() AppConfig {
 	if cfg.Database.Timeout == "" {
 		cfg.Database.Timeout = (10 * time.Second).String()
 	}
+	if cfg.Storage.Backend == "" {
+		cfg.Storage.Backend = "local"
+	}
+	if cfg.Storage.LocalDir == "" {
+		cfg.Storage.LocalDir = "./uploads"
+	}
+	if cfg.Storage.PublicURL == "" {
+		cfg.Storage.PublicURL = "/api/files"
+	}
+	if cfg.Storage.SignedURL == "" {
+		cfg.Storage.SignedURL = "/api/signed-files"
+	}
+	if cfg.Image.MaxWidth == 0 {
+		cfg.Image.MaxWidth = 10000
+	}
+	if cfg.Image.MaxHeight == 0 {
+		cfg.Image.MaxHeight = 10000
+	}
+	if cfg.Image.MaxPixels == 0 {
+		cfg.Image.MaxPixels = 40000000
+	}
+
+	if cfg.Inventory.ReservationTTL == 0 {
+		cfg.Inventory.ReservationTTL = 15 * time.Minute
+	}
+	if cfg.Inventory.Allocation == "" {
+		cfg.Inventory.Allocation = "priority"
+	}
+	if cfg.Inventory.VelocityDays == 0 {
+		cfg.Inventory.VelocityDays = 30
+	}
+	if cfg.Cart.Backend == "" {
+		cfg.Cart.Backend = "sql"
+	}
+	if cfg.Cart.GuestTTL == 0 {
+		cfg.Cart.GuestTTL = 30 * 24 * time.Hour
+	}
+	if cfg.Cart.MaxQuantity == 0 {
+		cfg.Cart.MaxQuantity = 99
+	}
+	if len(cfg.Cart.RecoveryWindows) == 0 {
+		cfg.Cart.RecoveryWindows = []time.Duration{time.Hour, 24 * time.Hour, 72 * time.Hour}
+	}
+	if cfg.Cart.RecoveryTTL == 0 {
+		cfg.Cart.RecoveryTTL = 7 * 24 * time.Hour
+	}
+	if cfg.Cart.RecoveryURL == "" {
+		cfg.Cart.RecoveryURL = "/cart/recover"
+	}
+	if cfg.Cart.ShareTTL == 0 {
+		cfg.Cart.ShareTTL = 14 * 24 * time.Hour
+	}
+	if cfg.Cart.ShareURL == "" {
+		cfg.Cart.ShareURL = "/cart/shared"
+	}
+	if cfg.Pricing.Currency == "" {
+		cfg.Pricing.Currency = cfg.Stripe.DefaultCurrency
+	}
+	if cfg.Pricing.Currency == "" {
+		cfg.Pricing.Currency = "USD"
+	}
+	if cfg.GiftCard.BalanceChecksPerMinute == 0 {
+		cfg.GiftCard.BalanceChecksPerMinute = 10
+	}
+	if cfg.GiftCard.MaxFailedChecks == 0 {
+		cfg.GiftCard.MaxFailedChecks = 10
+	}
+	if cfg.GiftCard.FailedCheckWindow == 0 {
+		cfg.GiftCard.FailedCheckWindow = time.Hour
+	}
+	if cfg.Notification.Backend == "" {
+		cfg.Notification.Backend = "log"
+	}
+	if cfg.Notification.MaxPerUserPerDay == 0 {
+		cfg.Notification.MaxPerUserPerDay = 5
+	}
+	if cfg.Notification.UnsubscribeURL == "" {
+		cfg.Notification.UnsubscribeURL = "/unsubscribe"
+	}
+	if cfg.Recommendations.MinConfidence == 0 {
+		cfg.Recommendations.MinConfidence = 0.1
+	}
+	if cfg.Recommendations.MinOrders == 0 {
+		cfg.Recommendations.MinOrders = 2
+	}
+	if cfg.Recommendations.MaxPerProduct == 0 {
+		cfg.Recommendations.MaxPerProduct = 10
+	}
+	if cfg.Recommendations.LookbackDays == 0 {
+		cfg.Recommendations.LookbackDays = 365
+	}
 
 	log.Printf("config loaded: env=%s port=%d", cfg.App.Env, cfg.App.Port)
 	return cfg
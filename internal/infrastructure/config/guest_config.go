@@ -0,0 +1,90 @@
+package config
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"backend-ecommerce/internal/application/tools"
+)
+
+// GuestTokenCookie carries the guest token for browser clients, alongside the X-Guest-Token header
+const GuestTokenCookie = "guest_token"
+
+var guestSecret []byte
+
+// InitGuestTokens sets up the key guest tokens are signed with
+func InitGuestTokens() {
+	secret := Get().Cart.GuestSecret
+	if secret == "" {
+		generated, err := generateRandomKey(64)
+		if err != nil {
+			panic(fmt.Sprintf("Failed to generate guest token secret: %v", err))
+		}
+		secret = generated
+	}
+	guestSecret = []byte(secret)
+}
+
+// SignGuestToken returns the value handed to the client for a guest token
+func SignGuestToken(token string) string {
+	return token + "." + guestSignature(token)
+}
+
+// VerifyGuestToken checks a signed guest token and returns the token it carries
+func VerifyGuestToken(signed string) (string, bool) {
+	token, signature, found := strings.Cut(signed, ".")
+	if !found || token == "" {
+		return "", false
+	}
+	if !hmac.Equal([]byte(signature), []byte(guestSignature(token))) {
+		return "", false
+	}
+	return token, true
+}
+
+// GuestToken returns the verified guest token sent with a request, from the
+// X-Guest-Token header or the guest token cookie, or "" if there is none
+func GuestToken(c *gin.Context) string {
+	signed := c.GetHeader(GuestTokenHeader)
+	if signed == "" {
+		signed, _ = c.Cookie(GuestTokenCookie)
+	}
+	if signed == "" || len(signed) > 255 {
+		return ""
+	}
+	token, ok := VerifyGuestToken(signed)
+	if !ok {
+		return ""
+	}
+	return token
+}
+
+// issueGuestToken creates a guest token and sends it to the client in both
+// the X-Guest-Token response header and an HTTP-only cookie
+func issueGuestToken(c *gin.Context) string {
+	token := tools.NewSecureToken()
+	signed := SignGuestToken(token)
+	c.Header(GuestTokenHeader, signed)
+	c.SetSameSite(http.SameSiteLaxMode)
+	c.SetCookie(GuestTokenCookie, signed, int(Get().Cart.GuestTTL.Seconds()), "/api", "", Get().App.Env == "production", true)
+	return token
+}
+
+// ClearGuestToken removes the guest token cookie, e.g. once guest data has
+// been merged into an account
+func ClearGuestToken(c *gin.Context) {
+	c.SetSameSite(http.SameSiteLaxMode)
+	c.SetCookie(GuestTokenCookie, "", -1, "/api", "", Get().App.Env == "production", true)
+}
+
+func guestSignature(token string) string {
+	mac := hmac.New(sha256.New, guestSecret)
+	mac.Write([]byte(token))
+	return hex.EncodeToString(mac.Sum(nil))
+}
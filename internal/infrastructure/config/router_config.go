@@ -9,6 +9,9 @@ import (
 	"golang.org/x/exp/slices"
 )
 
+// GuestTokenHeader carries the token that identifies a shopper who is not signed in
+const GuestTokenHeader = "X-Guest-Token"
+
 // CORSMiddleware handles CORS configuration
 func CORSMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
@@ -23,8 +26,8 @@ func CORSMiddleware() gin.HandlerFunc {
 		if origin != "" && slices.Contains(allowedOrigins, origin) {
 			c.Header("Access-Control-Allow-Origin", origin)
 			c.Header("Access-Control-Allow-Methods", "POST, GET, OPTIONS, PUT, DELETE, UPDATE")
-			c.Header("Access-Control-Allow-Headers", "Origin, X-Requested-With, Content-Type, Accept, Authorization")
-			c.Header("Access-Control-Expose-Headers", "Content-Length, Access-Control-Allow-Origin, Access-Control-Allow-Headers, Cache-Control, Content-Language, Content-Type")
+			c.Header("Access-Control-Allow-Headers", "Origin, X-Requested-With, Content-Type, Accept, Authorization, "+GuestTokenHeader)
+			c.Header("Access-Control-Expose-Headers", "Content-Length, Access-Control-Allow-Origin, Access-Control-Allow-Headers, Cache-Control, Content-Language, Content-Type, "+GuestTokenHeader)
 			c.Header("Access-Control-Allow-Credentials", "true")
 			c.Header("Access-Control-Max-Age", "86400")
 		}
@@ -51,12 +54,16 @@ func getWhitelist() *list.List {
 	whitelist.PushBack("/api/products")
 	whitelist.PushBack("/api/products/")
 	whitelist.PushBack("/api/products/:id")
+	whitelist.PushBack("/api/collections")
+	whitelist.PushBack("/api/collections/")
+	whitelist.PushBack("/api/shared-wishlists/")
 	whitelist.PushBack("/api/categories")
 	whitelist.PushBack("/api/categories/")
 	whitelist.PushBack("/api/categories/:id")
 
 	// Public file routes
-	whitelist.PushBack("/api/files/:filename")
+	whitelist.PushBack("/api/files/")
+	whitelist.PushBack("/api/signed-files/")
 
 	// Health check
 	whitelist.PushBack("/health")
@@ -113,6 +120,39 @@ func AuthMiddleware() gin.HandlerFunc {
 	}
 }
 
+// ShopperMiddleware identifies the shopper for routes open to guests. A valid
+// bearer token identifies a signed-in user; otherwise the shopper is a guest
+// identified by a signed token sent in the X-Guest-Token header or the guest
+// token cookie. Guests without a valid token are issued one in both, which
+// web and mobile clients send back on later requests.
+func ShopperMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if authHeader := c.GetHeader("Authorization"); authHeader != "" {
+			parts := strings.Split(authHeader, " ")
+			if len(parts) != 2 || parts[0] != "Bearer" {
+				c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Invalid authorization header format"})
+				return
+			}
+			claims, err := JWT.Verify(parts[1])
+			if err != nil {
+				c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Invalid or expired token"})
+				return
+			}
+			c.Set("user_id", claims.UserID)
+			c.Set("organization_id", claims.OrganizationID)
+			c.Set("role", claims.Role)
+		}
+
+		// The guest token is kept for signed-in users too, so guest data can be merged into the account
+		guestToken := GuestToken(c)
+		if guestToken == "" && c.GetString("user_id") == "" {
+			guestToken = issueGuestToken(c)
+		}
+		c.Set("guest_token", guestToken)
+		c.Next()
+	}
+}
+
 // AdminMiddleware checks if the user has admin privileges
 func AdminMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
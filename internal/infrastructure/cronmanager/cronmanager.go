@@ -6,6 +6,7 @@ import (
 
 	"github.com/robfig/cron/v3"
 
+	"backend-ecommerce/internal/application/service"
 	"backend-ecommerce/internal/infrastructure/config"
 )
 
@@ -17,7 +18,10 @@ func Init() {
 	cfg := config.Get()
 
 	// Skip initialization if no cron jobs are configured
-	if cfg.CronJob.CleanupInterval == "" && cfg.CronJob.EmailReport == "" {
+	if cfg.CronJob.CleanupInterval == "" && cfg.CronJob.EmailReport == "" && cfg.CronJob.PriceWindows == "" &&
+		cfg.CronJob.Publishing == "" && cfg.CronJob.Collections == "" &&
+		cfg.CronJob.Recommendations == "" && cfg.CronJob.Alerts == "" && cfg.CronJob.Notifications == "" &&
+		cfg.CronJob.Reservations == "" && cfg.CronJob.LowStock == "" && cfg.CronJob.AbandonedCarts == "" {
 		log.Println("cron: no cron jobs configured, skipping cron scheduler")
 		return
 	}
@@ -49,6 +53,96 @@ func Init() {
 		}
 	}
 
+	if cfg.CronJob.PriceWindows != "" {
+		if _, err := c.AddFunc(cfg.CronJob.PriceWindows, func() {
+			service.IPriceScheduleService.ProcessPriceWindows()
+		}); err != nil {
+			log.Printf("cron: failed to schedule price windows: %v", err)
+		} else {
+			jobsScheduled++
+		}
+	}
+
+	if cfg.CronJob.Publishing != "" {
+		if _, err := c.AddFunc(cfg.CronJob.Publishing, func() {
+			service.IProductPublishingService.ProcessScheduledPublishing()
+		}); err != nil {
+			log.Printf("cron: failed to schedule publishing: %v", err)
+		} else {
+			jobsScheduled++
+		}
+	}
+
+	if cfg.CronJob.Collections != "" {
+		if _, err := c.AddFunc(cfg.CronJob.Collections, func() {
+			service.ICollectionService.RefreshAllCollections()
+		}); err != nil {
+			log.Printf("cron: failed to schedule collection refresh: %v", err)
+		} else {
+			jobsScheduled++
+		}
+	}
+
+	if cfg.CronJob.Recommendations != "" {
+		if _, err := c.AddFunc(cfg.CronJob.Recommendations, func() {
+			service.IProductRelationService.RecomputeFrequentlyBoughtTogether()
+		}); err != nil {
+			log.Printf("cron: failed to schedule recommendations: %v", err)
+		} else {
+			jobsScheduled++
+		}
+	}
+
+	if cfg.CronJob.Alerts != "" {
+		if _, err := c.AddFunc(cfg.CronJob.Alerts, func() {
+			service.IProductAlertService.ProcessAlerts()
+		}); err != nil {
+			log.Printf("cron: failed to schedule product alerts: %v", err)
+		} else {
+			jobsScheduled++
+		}
+	}
+
+	if cfg.CronJob.Notifications != "" {
+		if _, err := c.AddFunc(cfg.CronJob.Notifications, func() {
+			service.INotificationService.DeliverPending()
+		}); err != nil {
+			log.Printf("cron: failed to schedule notification delivery: %v", err)
+		} else {
+			jobsScheduled++
+		}
+	}
+
+	if cfg.CronJob.Reservations != "" {
+		if _, err := c.AddFunc(cfg.CronJob.Reservations, func() {
+			service.IInventoryService.ReleaseExpired()
+		}); err != nil {
+			log.Printf("cron: failed to schedule reservation expiry: %v", err)
+		} else {
+			jobsScheduled++
+		}
+	}
+
+	if cfg.CronJob.LowStock != "" {
+		if _, err := c.AddFunc(cfg.CronJob.LowStock, func() {
+			service.IReplenishmentService.SendLowStockDigest()
+		}); err != nil {
+			log.Printf("cron: failed to schedule low-stock digest: %v", err)
+		} else {
+			jobsScheduled++
+		}
+	}
+
+	if cfg.CronJob.AbandonedCarts != "" {
+		if _, err := c.AddFunc(cfg.CronJob.AbandonedCarts, func() {
+			service.ICartRecoveryService.SendReminders()
+		}); err != nil {
+			log.Printf("cron: failed to schedule abandoned cart reminders: %v", err)
+		} else {
+			jobsScheduled++
+		}
+	}
+
 	if jobsScheduled > 0 {
 		log.Printf("cron: started with %d job(s) scheduled", jobsScheduled)
 		c.Start()
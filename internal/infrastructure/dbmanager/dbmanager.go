@@ -9,6 +9,7 @@ import (
 	"gorm.io/gorm/logger"
 
 	"backend-ecommerce/internal/application/entity"
+	"backend-ecommerce/internal/application/tools"
 	"backend-ecommerce/internal/infrastructure/config"
 )
 
@@ -50,13 +51,148 @@ func Init() {
 	// Auto-migrate all models if database is connected
 	err = _db.AutoMigrate(
 		&entity.User{},
+		&entity.Product{},
+		&entity.ProductImage{},
+		&entity.ProductImageRendition{},
+		&entity.ProductVariant{},
+		&entity.PriceSchedule{},
+		&entity.BundleComponent{},
+		&entity.DigitalAsset{},
+		&entity.LicenseKey{},
+		&entity.DownloadEntitlement{},
+		&entity.ProductRevision{},
+		&entity.Collection{},
+		&entity.CollectionProduct{},
+		&entity.ProductRelation{},
+		&entity.FrequentlyBoughtTogether{},
+		&entity.RecentlyViewed{},
+		&entity.Wishlist{},
+		&entity.WishlistItem{},
+		&entity.ProductAlert{},
+		&entity.Notification{},
+		&entity.StockLocation{},
+		&entity.Inventory{},
+		&entity.InventoryReservation{},
+		&entity.StockMovement{},
+		&entity.Supplier{},
+		&entity.PurchaseOrder{},
+		&entity.PurchaseOrderLine{},
+		&entity.Cart{},
+		&entity.CartItem{},
+		&entity.CartRecovery{},
+		&entity.CartSavedItem{},
+		&entity.CartSnapshot{},
+		&entity.CartSnapshotItem{},
+		&entity.Coupon{},
+		&entity.CouponCode{},
+		&entity.CartCoupon{},
+		&entity.CouponRedemption{},
+		&entity.Promotion{},
+		&entity.GiftCard{},
+		&entity.GiftCardTransaction{},
+		&entity.GiftCardProduct{},
+		&entity.Address{},
+		&entity.Order{},
+		&entity.OrderItem{},
+		&entity.OrderAdjustment{},
 	)
 	if err != nil {
 	}
+	if err := migrateStockLocations(_db); err != nil {
+		log.Printf("Warning: Failed to migrate inventory to stock locations: %v", err)
+	}
+	if err := migrateStockLedger(_db); err != nil {
+		log.Printf("Warning: Failed to record opening stock balances: %v", err)
+	}
 	log.Println("dbmanager: connected and migrated")
 }
 
+// migrateStockLocations moves stock recorded before stock locations existed,
+// when a product could only have one inventory row, to a default location
+func migrateStockLocations(db *gorm.DB) error {
+	if db.Migrator().HasIndex(&entity.Inventory{}, "idx_inventory_product_id") {
+		if err := db.Migrator().DropIndex(&entity.Inventory{}, "idx_inventory_product_id"); err != nil {
+			return err
+		}
+	}
+
+	var unassigned int64
+	if err := db.Model(&entity.Inventory{}).Where("location_id = ?", "").Count(&unassigned).Error; err != nil {
+		return err
+	}
+	if unassigned == 0 {
+		return nil
+	}
+
+	location := entity.StockLocation{ID: tools.NewUuid(), Code: "MAIN", Name: "Main warehouse", Type: entity.StockLocationWarehouse, IsActive: true}
+	if err := db.Where("code = ?", location.Code).FirstOrCreate(&location).Error; err != nil {
+		return err
+	}
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&entity.Inventory{}).Where("location_id = ?", "").
+			Update("location_id", location.ID).Error; err != nil {
+			return err
+		}
+		return tx.Model(&entity.InventoryReservation{}).Where("location_id = ?", "").
+			Update("location_id", location.ID).Error
+	})
+}
+
+// migrateStockLedger records stock on hand that has no ledger entries, such
+// as stock from before the ledger existed, as an opening balance so that
+// quantities can be checked against the ledger
+func migrateStockLedger(db *gorm.DB) error {
+	ledger := db.Model(&entity.StockMovement{}).Select("1").
+		Where("stockMovements.location_id = inventory.location_id AND stockMovements.product_id = inventory.product_id").
+		Where("stockMovements.variant_id <=> inventory.variant_id")
+
+	var rows []entity.Inventory
+	if err := db.Where("inventory.quantity <> 0 AND NOT EXISTS (?)", ledger).Find(&rows).Error; err != nil {
+		return err
+	}
+
+	for _, row := range rows {
+		var sku string
+		if row.VariantID != nil {
+			var variant entity.ProductVariant
+			if err := db.Select("sku").Where("id = ?", *row.VariantID).Limit(1).Find(&variant).Error; err != nil {
+				return err
+			}
+			sku = variant.SKU
+		}
+		if sku == "" {
+			var product entity.Product
+			if err := db.Select("sku").Where("id = ?", row.ProductID).Limit(1).Find(&product).Error; err != nil {
+				return err
+			}
+			sku = product.SKU
+		}
+
+		if err := db.Create(&entity.StockMovement{
+			ID:         tools.NewUuid(),
+			LocationID: row.LocationID,
+			ProductID:  row.ProductID,
+			VariantID:  row.VariantID,
+			SKU:        sku,
+			Quantity:   row.Quantity,
+			Reason:     entity.StockMovementAdjustment,
+			Note:       "Opening balance",
+		}).Error; err != nil {
+			return err
+		}
+	}
+	if len(rows) > 0 {
+		log.Printf("dbmanager: recorded opening stock balances for %d stock levels", len(rows))
+	}
+	return nil
+}
+
 // GetDB is an alias for DB() to maintain backward compatibility
 func GetDB() *gorm.DB {
 	return _db
 }
+
+// SetDB replaces the connection, e.g. with an in-memory database in tests
+func SetDB(db *gorm.DB) {
+	_db = db
+}
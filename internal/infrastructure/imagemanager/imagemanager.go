@@ -0,0 +1,202 @@
+package imagemanager
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	_ "image/gif" // registers the GIF decoder
+	"image/jpeg"
+	"image/png"
+	"net/http"
+
+	"github.com/HugoSmits86/nativewebp"
+	"golang.org/x/image/draw"
+	_ "golang.org/x/image/webp" // registers the WebP decoder
+
+	"backend-ecommerce/internal/infrastructure/config"
+)
+
+// Supported image MIME types, detected from file content rather than the
+// client-supplied Content-Type header.
+const (
+	MimeJPEG = "image/jpeg"
+	MimePNG  = "image/png"
+	MimeGIF  = "image/gif"
+	MimeWebP = "image/webp"
+)
+
+// Rendition formats
+const (
+	FormatJPEG = "jpeg"
+	FormatPNG  = "png"
+	FormatWebP = "webp"
+)
+
+// Spec describes a resized rendition. The image is scaled to fit inside
+// MaxWidth x MaxHeight, preserving aspect ratio and never upscaling.
+type Spec struct {
+	Name      string
+	MaxWidth  int
+	MaxHeight int
+}
+
+// DefaultSpecs are the renditions generated for every product image
+var DefaultSpecs = []Spec{
+	{Name: "thumbnail", MaxWidth: 150, MaxHeight: 150},
+	{Name: "medium", MaxWidth: 600, MaxHeight: 600},
+	{Name: "large", MaxWidth: 1200, MaxHeight: 1200},
+}
+
+// Rendition is a single encoded output of the pipeline
+type Rendition struct {
+	Name     string
+	Format   string
+	MimeType string
+	Ext      string
+	Width    int
+	Height   int
+	Data     []byte
+}
+
+// Result contains the decoded original's details and every rendition generated from it
+type Result struct {
+	MimeType   string
+	Ext        string
+	Width      int
+	Height     int
+	Renditions []Rendition
+}
+
+// ErrTooLarge is returned for images whose dimensions exceed the configured
+// limits. They are rejected before decoding, which would allocate memory for
+// every pixel.
+var ErrTooLarge = errors.New("image dimensions exceed the allowed maximum")
+
+var allowedTypes = map[string]string{
+	MimeJPEG: ".jpg",
+	MimePNG:  ".png",
+	MimeGIF:  ".gif",
+	MimeWebP: ".webp",
+}
+
+// DetectType sniffs the content type of data and returns it if it is a supported image type
+func DetectType(data []byte) (string, error) {
+	mimeType := http.DetectContentType(data)
+	if _, ok := allowedTypes[mimeType]; !ok {
+		return "", fmt.Errorf("unsupported image type: %s", mimeType)
+	}
+	return mimeType, nil
+}
+
+// Extension returns the file extension for a supported MIME type
+func Extension(mimeType string) string {
+	return allowedTypes[mimeType]
+}
+
+// Process validates data, decodes it and generates every rendition in specs.
+// Each spec produces one rendition in the source family (JPEG for JPEG input,
+// PNG otherwise so transparency is kept) and one WebP rendition.
+func Process(data []byte, specs []Spec) (*Result, error) {
+	mimeType, err := DetectType(data)
+	if err != nil {
+		return nil, err
+	}
+
+	header, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode image: %w", err)
+	}
+	limits := config.Get().Image
+	if (limits.MaxWidth > 0 && header.Width > limits.MaxWidth) ||
+		(limits.MaxHeight > 0 && header.Height > limits.MaxHeight) ||
+		(limits.MaxPixels > 0 && int64(header.Width)*int64(header.Height) > int64(limits.MaxPixels)) {
+		return nil, fmt.Errorf("%w: %dx%d", ErrTooLarge, header.Width, header.Height)
+	}
+
+	src, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode image: %w", err)
+	}
+
+	bounds := src.Bounds()
+	result := &Result{
+		MimeType: mimeType,
+		Ext:      Extension(mimeType),
+		Width:    bounds.Dx(),
+		Height:   bounds.Dy(),
+	}
+
+	baseFormat := FormatPNG
+	if mimeType == MimeJPEG {
+		baseFormat = FormatJPEG
+	}
+
+	for _, spec := range specs {
+		resized := Resize(src, spec.MaxWidth, spec.MaxHeight)
+		for _, format := range []string{baseFormat, FormatWebP} {
+			encoded, err := Encode(resized, format)
+			if err != nil {
+				return nil, fmt.Errorf("failed to encode %s rendition as %s: %w", spec.Name, format, err)
+			}
+			encoded.Name = spec.Name
+			result.Renditions = append(result.Renditions, *encoded)
+		}
+	}
+
+	return result, nil
+}
+
+// Resize scales img to fit within maxWidth x maxHeight, preserving aspect ratio.
+// Images already within bounds are returned unchanged.
+func Resize(img image.Image, maxWidth, maxHeight int) image.Image {
+	bounds := img.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+	if width <= maxWidth && height <= maxHeight {
+		return img
+	}
+
+	scale := float64(maxWidth) / float64(width)
+	if s := float64(maxHeight) / float64(height); s < scale {
+		scale = s
+	}
+	newWidth := max(1, int(float64(width)*scale+0.5))
+	newHeight := max(1, int(float64(height)*scale+0.5))
+
+	dst := image.NewRGBA(image.Rect(0, 0, newWidth, newHeight))
+	draw.CatmullRom.Scale(dst, dst.Bounds(), img, bounds, draw.Over, nil)
+	return dst
+}
+
+// Encode encodes img in the given format
+func Encode(img image.Image, format string) (*Rendition, error) {
+	var buf bytes.Buffer
+	rendition := &Rendition{
+		Format: format,
+		Width:  img.Bounds().Dx(),
+		Height: img.Bounds().Dy(),
+	}
+
+	switch format {
+	case FormatJPEG:
+		if err := jpeg.Encode(&buf, img, &jpeg.Options{Quality: 85}); err != nil {
+			return nil, err
+		}
+		rendition.MimeType, rendition.Ext = MimeJPEG, ".jpg"
+	case FormatPNG:
+		if err := png.Encode(&buf, img); err != nil {
+			return nil, err
+		}
+		rendition.MimeType, rendition.Ext = MimePNG, ".png"
+	case FormatWebP:
+		if err := nativewebp.Encode(&buf, img, nil); err != nil {
+			return nil, err
+		}
+		rendition.MimeType, rendition.Ext = MimeWebP, ".webp"
+	default:
+		return nil, fmt.Errorf("unsupported output format: %s", format)
+	}
+
+	rendition.Data = buf.Bytes()
+	return rendition, nil
+}
@@ -52,7 +52,33 @@ func (m *Manager) Verify(tokenStr string) (*Claims, error) {
 		return nil, err
 	}
 	if claims, ok := token.Claims.(*Claims); ok && token.Valid {
+		// Scoped tokens carry an audience and must not pass as access tokens
+		if len(claims.Audience) > 0 {
+			return nil, jwt.ErrTokenInvalidAudience
+		}
 		return claims, nil
 	}
 	return nil, jwt.ErrTokenInvalidClaims
 }
+
+// SignScoped creates a signed JWT that only grants audience access to subject,
+// e.g. previewing a single unpublished product.
+func (m *Manager) SignScoped(subject, audience string, expiresIn time.Duration) (string, error) {
+	claims := &jwt.RegisteredClaims{
+		Issuer:    m.Issuer,
+		Subject:   subject,
+		Audience:  jwt.ClaimStrings{audience},
+		ExpiresAt: jwt.NewNumericDate(time.Now().Add(expiresIn)),
+		IssuedAt:  jwt.NewNumericDate(time.Now()),
+	}
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	return token.SignedString(m.Secret)
+}
+
+// VerifyScoped checks that a token issued by SignScoped is valid for subject and audience.
+func (m *Manager) VerifyScoped(tokenStr, subject, audience string) error {
+	_, err := jwt.ParseWithClaims(tokenStr, &jwt.RegisteredClaims{}, func(t *jwt.Token) (interface{}, error) {
+		return m.Secret, nil
+	}, jwt.WithAudience(audience), jwt.WithSubject(subject), jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}))
+	return err
+}
@@ -0,0 +1,16 @@
+package notifier
+
+import (
+	"context"
+	"log"
+)
+
+// LogNotifier writes messages to the application log instead of delivering
+// them. It is the default until a real backend is configured.
+type LogNotifier struct{}
+
+// Send logs the message
+func (l *LogNotifier) Send(ctx context.Context, msg Message) error {
+	log.Printf("notifier: to=%s subject=%q\n%s", msg.To, msg.Subject, msg.Body)
+	return nil
+}
@@ -0,0 +1,56 @@
+package notifier
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"backend-ecommerce/internal/infrastructure/config"
+)
+
+// Message is a notification addressed to one recipient
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Notifier is implemented by every notification delivery backend
+type Notifier interface {
+	// Send delivers a message. An error means it was not delivered and may be retried.
+	Send(ctx context.Context, msg Message) error
+}
+
+var sender Notifier
+
+// Init creates the notification backend selected in config
+func Init() error {
+	cfg := config.Get().Notification
+
+	switch cfg.Backend {
+	case "log":
+		sender = &LogNotifier{}
+	case "smtp":
+		if cfg.SMTPHost == "" || cfg.From == "" {
+			return fmt.Errorf("notifier: smtp backend selected but smtp_host or from is not configured")
+		}
+		sender = NewSMTPNotifier(cfg.SMTPHost, cfg.SMTPPort, cfg.SMTPUsername, cfg.SMTPPassword, cfg.From)
+	case "memory":
+		sender = NewMemoryNotifier()
+	default:
+		return fmt.Errorf("notifier: unknown backend %q", cfg.Backend)
+	}
+
+	log.Printf("notifier: using %s backend", cfg.Backend)
+	return nil
+}
+
+// GetNotifier returns the configured backend. Call Init() first.
+func GetNotifier() Notifier {
+	return sender
+}
+
+// SetNotifier replaces the backend, e.g. with an in-memory one in tests
+func SetNotifier(n Notifier) {
+	sender = n
+}
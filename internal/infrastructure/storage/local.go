@@ -0,0 +1,178 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/fs"
+	"mime"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// LocalStorage stores files in a directory on the local filesystem
+type LocalStorage struct {
+	root      string
+	publicURL string
+	signer    *URLSigner
+}
+
+// NewLocalStorage creates a local backend rooted at dir, creating it if needed
+func NewLocalStorage(dir, publicURL string, signer *URLSigner) (*LocalStorage, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create storage directory: %w", err)
+	}
+	return &LocalStorage{
+		root:      dir,
+		publicURL: strings.TrimRight(publicURL, "/"),
+		signer:    signer,
+	}, nil
+}
+
+// Put writes the object to disk through a temporary file so readers never see partial content
+func (l *LocalStorage) Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) (*Object, error) {
+	filePath := l.path(key)
+	if err := os.MkdirAll(filepath.Dir(filePath), 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create directory: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(filePath), ".upload-*")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create file: %w", err)
+	}
+	written, err := io.Copy(tmp, r)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+		return nil, fmt.Errorf("failed to write file: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), filePath); err != nil {
+		os.Remove(tmp.Name())
+		return nil, fmt.Errorf("failed to write file: %w", err)
+	}
+
+	if contentType == "" {
+		contentType = contentTypeByKey(key)
+	}
+	return &Object{Key: CleanKey(key), Size: written, ContentType: contentType, LastModified: time.Now().UTC()}, nil
+}
+
+// Get opens the file for reading
+func (l *LocalStorage) Get(ctx context.Context, key string) (io.ReadCloser, *Object, error) {
+	file, err := os.Open(l.path(key))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil, ErrNotFound
+		}
+		return nil, nil, err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return nil, nil, err
+	}
+	if info.IsDir() {
+		file.Close()
+		return nil, nil, ErrNotFound
+	}
+	return file, l.object(key, info), nil
+}
+
+// Delete removes the file
+func (l *LocalStorage) Delete(ctx context.Context, key string) error {
+	if err := os.Remove(l.path(key)); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// Stat returns the file's metadata
+func (l *LocalStorage) Stat(ctx context.Context, key string) (*Object, error) {
+	info, err := os.Stat(l.path(key))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	if info.IsDir() {
+		return nil, ErrNotFound
+	}
+	return l.object(key, info), nil
+}
+
+// List walks the storage directory and returns every file under prefix
+func (l *LocalStorage) List(ctx context.Context, prefix string) ([]Object, error) {
+	var objects []Object
+	err := filepath.WalkDir(l.root, func(filePath string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || strings.HasPrefix(d.Name(), ".upload-") {
+			return nil
+		}
+		rel, err := filepath.Rel(l.root, filePath)
+		if err != nil {
+			return err
+		}
+		key := filepath.ToSlash(rel)
+		if !strings.HasPrefix(key, prefix) {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		objects = append(objects, *l.object(key, info))
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(objects, func(i, j int) bool { return objects[i].Key < objects[j].Key })
+	return objects, nil
+}
+
+// SignedURL returns an HMAC-signed URL served by the application's signed file handler
+func (l *LocalStorage) SignedURL(ctx context.Context, key string, expiresIn time.Duration) (string, error) {
+	return l.signer.Sign(CleanKey(key), expiresIn), nil
+}
+
+// URL returns the public URL of the file
+func (l *LocalStorage) URL(key string) string {
+	return l.publicURL + "/" + escapeKey(CleanKey(key))
+}
+
+// path maps a key to a file path that cannot escape the storage root
+func (l *LocalStorage) path(key string) string {
+	return filepath.Join(l.root, filepath.FromSlash(CleanKey(key)))
+}
+
+func (l *LocalStorage) object(key string, info fs.FileInfo) *Object {
+	return &Object{
+		Key:          CleanKey(key),
+		Size:         info.Size(),
+		ContentType:  contentTypeByKey(key),
+		LastModified: info.ModTime().UTC(),
+	}
+}
+
+// CleanKey normalises a key the way every backend stores it, stripping any
+// leading slash and resolving parent references
+func CleanKey(key string) string {
+	return strings.TrimPrefix(path.Clean("/"+key), "/")
+}
+
+func contentTypeByKey(key string) string {
+	if contentType := mime.TypeByExtension(path.Ext(key)); contentType != "" {
+		return contentType
+	}
+	return "application/octet-stream"
+}
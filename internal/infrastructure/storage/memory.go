@@ -0,0 +1,111 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+type memoryObject struct {
+	data []byte
+	meta Object
+}
+
+// MemoryStorage keeps files in memory. It is intended for tests and local
+// experiments; content is lost when the process exits.
+type MemoryStorage struct {
+	mu        sync.RWMutex
+	objects   map[string]memoryObject
+	publicURL string
+	signer    *URLSigner
+}
+
+// NewMemoryStorage creates an empty in-memory backend
+func NewMemoryStorage(publicURL string, signer *URLSigner) *MemoryStorage {
+	return &MemoryStorage{
+		objects:   make(map[string]memoryObject),
+		publicURL: strings.TrimRight(publicURL, "/"),
+		signer:    signer,
+	}
+}
+
+// Put stores a copy of the content
+func (m *MemoryStorage) Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) (*Object, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	if contentType == "" {
+		contentType = contentTypeByKey(key)
+	}
+
+	key = CleanKey(key)
+	meta := Object{Key: key, Size: int64(len(data)), ContentType: contentType, LastModified: time.Now().UTC()}
+
+	m.mu.Lock()
+	m.objects[key] = memoryObject{data: data, meta: meta}
+	m.mu.Unlock()
+
+	return &meta, nil
+}
+
+// Get returns a reader over the stored content
+func (m *MemoryStorage) Get(ctx context.Context, key string) (io.ReadCloser, *Object, error) {
+	m.mu.RLock()
+	obj, ok := m.objects[CleanKey(key)]
+	m.mu.RUnlock()
+	if !ok {
+		return nil, nil, ErrNotFound
+	}
+	meta := obj.meta
+	return io.NopCloser(bytes.NewReader(obj.data)), &meta, nil
+}
+
+// Delete removes the object
+func (m *MemoryStorage) Delete(ctx context.Context, key string) error {
+	m.mu.Lock()
+	delete(m.objects, CleanKey(key))
+	m.mu.Unlock()
+	return nil
+}
+
+// Stat returns the object's metadata
+func (m *MemoryStorage) Stat(ctx context.Context, key string) (*Object, error) {
+	m.mu.RLock()
+	obj, ok := m.objects[CleanKey(key)]
+	m.mu.RUnlock()
+	if !ok {
+		return nil, ErrNotFound
+	}
+	meta := obj.meta
+	return &meta, nil
+}
+
+// List returns every object under prefix
+func (m *MemoryStorage) List(ctx context.Context, prefix string) ([]Object, error) {
+	m.mu.RLock()
+	var objects []Object
+	for key, obj := range m.objects {
+		if strings.HasPrefix(key, prefix) {
+			objects = append(objects, obj.meta)
+		}
+	}
+	m.mu.RUnlock()
+
+	sort.Slice(objects, func(i, j int) bool { return objects[i].Key < objects[j].Key })
+	return objects, nil
+}
+
+// SignedURL returns an HMAC-signed URL served by the application's signed file handler
+func (m *MemoryStorage) SignedURL(ctx context.Context, key string, expiresIn time.Duration) (string, error) {
+	return m.signer.Sign(CleanKey(key), expiresIn), nil
+}
+
+// URL returns the public URL of the object
+func (m *MemoryStorage) URL(key string) string {
+	return m.publicURL + "/" + escapeKey(CleanKey(key))
+}
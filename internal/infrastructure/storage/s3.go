@@ -0,0 +1,139 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+
+	"backend-ecommerce/internal/infrastructure/awsmanager"
+)
+
+// S3Storage stores files in the S3 bucket configured for awsmanager
+type S3Storage struct {
+	client *s3.Client
+	bucket string
+	region string
+}
+
+// NewS3Storage creates an S3 backend using the shared awsmanager client
+func NewS3Storage(client *s3.Client) *S3Storage {
+	bucket, region := awsmanager.GetBucket()
+	return &S3Storage{client: client, bucket: bucket, region: region}
+}
+
+// Put uploads the object
+func (s *S3Storage) Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) (*Object, error) {
+	key = CleanKey(key)
+	if contentType == "" {
+		contentType = contentTypeByKey(key)
+	}
+
+	input := &s3.PutObjectInput{
+		Bucket:      aws.String(s.bucket),
+		Key:         aws.String(key),
+		Body:        r,
+		ContentType: aws.String(contentType),
+	}
+	if size >= 0 {
+		input.ContentLength = aws.Int64(size)
+	}
+	if _, err := s.client.PutObject(ctx, input); err != nil {
+		return nil, fmt.Errorf("failed to upload file to S3: %w", err)
+	}
+
+	return &Object{Key: key, Size: size, ContentType: contentType, LastModified: time.Now().UTC()}, nil
+}
+
+// Get opens the object for reading
+func (s *S3Storage) Get(ctx context.Context, key string) (io.ReadCloser, *Object, error) {
+	key = CleanKey(key)
+	out, err := s.client.GetObject(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(s.bucket),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		var noSuchKey *types.NoSuchKey
+		if errors.As(err, &noSuchKey) {
+			return nil, nil, ErrNotFound
+		}
+		return nil, nil, fmt.Errorf("failed to get file from S3: %w", err)
+	}
+
+	return out.Body, &Object{
+		Key:          key,
+		Size:         aws.ToInt64(out.ContentLength),
+		ContentType:  aws.ToString(out.ContentType),
+		LastModified: aws.ToTime(out.LastModified),
+	}, nil
+}
+
+// Delete removes the object
+func (s *S3Storage) Delete(ctx context.Context, key string) error {
+	return awsmanager.DeleteFile(ctx, CleanKey(key))
+}
+
+// Stat returns the object's metadata
+func (s *S3Storage) Stat(ctx context.Context, key string) (*Object, error) {
+	key = CleanKey(key)
+	out, err := s.client.HeadObject(ctx, &s3.HeadObjectInput{
+		Bucket: aws.String(s.bucket),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		var notFound *types.NotFound
+		if errors.As(err, &notFound) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to stat file in S3: %w", err)
+	}
+
+	return &Object{
+		Key:          key,
+		Size:         aws.ToInt64(out.ContentLength),
+		ContentType:  aws.ToString(out.ContentType),
+		LastModified: aws.ToTime(out.LastModified),
+	}, nil
+}
+
+// List returns every object under prefix
+func (s *S3Storage) List(ctx context.Context, prefix string) ([]Object, error) {
+	var objects []Object
+	paginator := s3.NewListObjectsV2Paginator(s.client, &s3.ListObjectsV2Input{
+		Bucket: aws.String(s.bucket),
+		Prefix: aws.String(prefix),
+	})
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list files in S3: %w", err)
+		}
+		for _, item := range page.Contents {
+			objects = append(objects, Object{
+				Key:          aws.ToString(item.Key),
+				Size:         aws.ToInt64(item.Size),
+				ContentType:  contentTypeByKey(aws.ToString(item.Key)),
+				LastModified: aws.ToTime(item.LastModified),
+			})
+		}
+	}
+
+	sort.Slice(objects, func(i, j int) bool { return objects[i].Key < objects[j].Key })
+	return objects, nil
+}
+
+// SignedURL returns an S3 presigned URL
+func (s *S3Storage) SignedURL(ctx context.Context, key string, expiresIn time.Duration) (string, error) {
+	return awsmanager.GeneratePresignedURL(ctx, CleanKey(key), expiresIn)
+}
+
+// URL returns the public bucket URL of the object
+func (s *S3Storage) URL(key string) string {
+	return fmt.Sprintf("https://%s.s3.%s.amazonaws.com/%s", s.bucket, s.region, escapeKey(CleanKey(key)))
+}
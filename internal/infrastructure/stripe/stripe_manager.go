@@ -9,6 +9,8 @@ import (
 	"github.com/stripe/stripe-go/v76/paymentintent"
 	"github.com/stripe/stripe-go/v76/refund"
 	"github.com/stripe/stripe-go/v76/webhook"
+
+	"backend-ecommerce/internal/infrastructure/config"
 )
 
 // StripeManager handles Stripe payment operations
@@ -34,6 +36,26 @@ func NewStripeManager(apiKey, webhookSecret string) *StripeManager {
 	return instance
 }
 
+// Init creates the Stripe client from config. Without an API key card
+// payments are unavailable.
+func Init() error {
+	cfg, err := config.NewStripeConfig()
+	if err != nil {
+		return err
+	}
+	if cfg.APIKey == "" || cfg.WebhookSecret == "" {
+		return fmt.Errorf("stripe: api_key or webhook_secret is not configured, card payments are disabled")
+	}
+	NewStripeManager(cfg.APIKey, cfg.WebhookSecret)
+	return nil
+}
+
+// GetStripe returns the Stripe client, or nil when it is not configured.
+// Call Init() first.
+func GetStripe() *StripeManager {
+	return instance
+}
+
 // CreateCheckoutSession creates a new Stripe Checkout session
 func (sm *StripeManager) CreateCheckoutSession(amount int64, currency, successURL, cancelURL, orderID, customerEmail string) (*stripe.CheckoutSession, error) {
 	params := &stripe.CheckoutSessionParams{
@@ -2,12 +2,18 @@ package main
 
 import (
 	"fmt"
+	"log"
 
 	"backend-ecommerce/internal/application/router"
+	"backend-ecommerce/internal/application/service"
+	"backend-ecommerce/internal/infrastructure/awsmanager"
 	"backend-ecommerce/internal/infrastructure/cachemanager"
 	"backend-ecommerce/internal/infrastructure/config"
 	"backend-ecommerce/internal/infrastructure/cronmanager"
 	"backend-ecommerce/internal/infrastructure/dbmanager"
+	"backend-ecommerce/internal/infrastructure/notifier"
+	"backend-ecommerce/internal/infrastructure/storage"
+	"backend-ecommerce/internal/infrastructure/stripe"
 	"github.com/gin-gonic/gin"
 )
 
@@ -18,7 +24,22 @@ func main() {
 	// Initialize infrastructure managers
 	dbmanager.Init()
 	cachemanager.Init()
+	if err := service.InitCartRepository(); err != nil {
+		log.Fatalf("failed to initialize cart storage: %v", err)
+	}
 	cronmanager.Init()
+	if err := awsmanager.Init(); err != nil {
+		log.Printf("Warning: %v", err)
+	}
+	if err := stripe.Init(); err != nil {
+		log.Printf("Warning: %v", err)
+	}
+	if err := storage.Init(); err != nil {
+		log.Fatalf("failed to initialize storage: %v", err)
+	}
+	if err := notifier.Init(); err != nil {
+		log.Fatalf("failed to initialize notifier: %v", err)
+	}
 
 	// Create Gin router with default middleware
 	r := gin.Default()
@@ -32,6 +53,7 @@ func main() {
 
 	// Initialize JWT
 	config.InitJWT()
+	config.InitGuestTokens()
 
 	// Register application routes
 	router.Register(r, db)